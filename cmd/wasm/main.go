@@ -3,18 +3,18 @@
 package main
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"syscall/js"
 
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 // Global map to store active state machines
 // Key: Session ID (string)
-var sessions = make(map[string]tss.StateMachine)
+var sessions = make(map[string]*session)
 
 func main() {
 	c := make(chan struct{}, 0)
@@ -23,9 +23,14 @@ func main() {
 
 	// Expose Go functions to JS
 	js.Global().Set("GoCGGMP", map[string]interface{}{
-		"NewKeyGen": js.FuncOf(NewKeyGen),
-		"Update":    js.FuncOf(Update),
-		"Result":    js.FuncOf(Result),
+		"NewKeyGen":     js.FuncOf(NewKeyGen),
+		"NewSign":       js.FuncOf(NewSign),
+		"NewPreSign":    js.FuncOf(NewPreSign),
+		"NewOnlineSign": js.FuncOf(NewOnlineSign),
+		"Update":        js.FuncOf(Update),
+		"Result":        js.FuncOf(Result),
+		"CloseSession":  js.FuncOf(CloseSession),
+		"ListSessions":  js.FuncOf(ListSessions),
 	})
 
 	<-c
@@ -41,69 +46,129 @@ func NewKeyGen(this js.Value, args []js.Value) interface{} {
 		return "error: expected 1 argument (jsonParams)"
 	}
 
-	paramsJSON := args[0].String()
+	params, input, err := parseParams(args[0].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
 
-	// Define a struct to unmarshal JSON params
-	// This mirrors tss.Parameters but with simplifications for JSON
-	type ParamsInput struct {
-		PartyID        string   `json:"partyID"`
-		AllParties     []string `json:"allParties"`
-		Threshold      int      `json:"threshold"`
-		SessionID      string   `json:"sessionID"`
-		OneRoundKeyGen bool     `json:"oneRoundKeyGen"`
+	// Initialize State Machine
+	sm, outMsgs, err := keygen.NewStateMachine(params)
+	if err != nil {
+		return fmt.Sprintf("error: failed to create state machine: %v", err)
 	}
 
-	var input ParamsInput
-	err := json.Unmarshal([]byte(paramsJSON), &input)
+	return startSession("keygen", input, sm, outMsgs)
+}
+
+// NewSign initializes a new Signing session.
+// Arguments:
+// 0: JSON string of parameters (allParties lists the signers)
+// 1: JSON string of key data, as returned by Result(sessionID, true) for a keygen session
+// 2: Hex string of the message hash
+// Returns:
+// JSON string { sessionID, messages } or error string
+func NewSign(this js.Value, args []js.Value) interface{} {
+	if len(args) != 3 {
+		return "error: expected 3 arguments (jsonParams, keyDataJSON, msgHashHex)"
+	}
+	params, input, err := parseParams(args[0].String())
 	if err != nil {
-		return fmt.Sprintf("error: invalid json: %v", err)
+		return fmt.Sprintf("error: %v", err)
+	}
+	keyData, err := parseKeyData(args[1].String(), params)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	hash, err := parseHash(args[2].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
 	}
 
-	// Create PartyIDs
-	parties := make([]tss.PartyID, len(input.AllParties))
-	var localParty tss.PartyID
-	for i, pid := range input.AllParties {
-		p := &SimplePartyID{IDVal: pid, MonikerVal: pid}
-		parties[i] = p
-		if pid == input.PartyID {
-			localParty = p
-		}
+	sm, outMsgs, err := sign.NewStateMachine(params, keyData, hash)
+	if err != nil {
+		return fmt.Sprintf("error: failed to create state machine: %v", err)
 	}
+	return startSession("sign", input, sm, outMsgs)
+}
 
-	if localParty == nil {
-		return "error: local party ID not found in allParties"
+// NewPreSign initializes a new Pre-Signing (offline) session. Its result is
+// the pre-signature to pass to NewOnlineSign.
+// Arguments:
+// 0: JSON string of parameters (allParties lists the signers)
+// 1: JSON string of key data, as returned by Result(sessionID, true) for a keygen session
+// Returns:
+// JSON string { sessionID, messages } or error string
+func NewPreSign(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 {
+		return "error: expected 2 arguments (jsonParams, keyDataJSON)"
+	}
+	params, input, err := parseParams(args[0].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	keyData, err := parseKeyData(args[1].String(), params)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
 	}
 
-	params := &tss.Parameters{
-		PartyID:        localParty,
-		Parties:        parties,
-		Threshold:      input.Threshold,
-		Curve:          "secp256k1",
-		SessionID:      []byte(input.SessionID),
-		OneRoundKeyGen: input.OneRoundKeyGen,
+	sm, outMsgs, err := sign.NewPreSignStateMachine(params, keyData)
+	if err != nil {
+		return fmt.Sprintf("error: failed to create state machine: %v", err)
 	}
+	return startSession("sign", input, sm, outMsgs)
+}
 
-	// Initialize State Machine
-	sm, outMsgs, err := keygen.NewStateMachine(params)
+// NewOnlineSign initializes a new Online Signing session from a pre-signature.
+// Arguments:
+// 0: JSON string of parameters (the same signers as the pre-signing session)
+// 1: JSON string of key data, as returned by Result(sessionID, true) for a keygen session
+// 2: JSON string of the pre-signature, as returned by Result for a NewPreSign session
+// 3: Hex string of the message hash
+// Returns:
+// JSON string { sessionID, messages } or error string
+func NewOnlineSign(this js.Value, args []js.Value) interface{} {
+	if len(args) != 4 {
+		return "error: expected 4 arguments (jsonParams, keyDataJSON, preSignatureJSON, msgHashHex)"
+	}
+	params, input, err := parseParams(args[0].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	keyData, err := parseKeyData(args[1].String(), params)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	preSig, err := parsePreSignature(args[2].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	hash, err := parseHash(args[3].String())
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+
+	sm, outMsgs, err := sign.NewOnlineStateMachine(params, keyData, preSig, hash)
 	if err != nil {
 		return fmt.Sprintf("error: failed to create state machine: %v", err)
 	}
+	return startSession("sign", input, sm, outMsgs)
+}
 
-	sessionHandle := fmt.Sprintf("%s-%s", input.PartyID, input.SessionID)
-	// Store session
-	sessions[sessionHandle] = sm
+// startSession stores a new session and returns its handle together with the
+// initial messages as JSON: { sessionID: "...", messages: [...] }
+func startSession(protocol string, input *paramsInput, sm tss.StateMachine, outMsgs []tss.Message) interface{} {
+	encoded, err := encodeMessages(outMsgs)
+	if err != nil {
+		return fmt.Sprintf("error: encode messages failed: %v", err)
+	}
 
-	// Return initial messages (if any)
-	// We need to return both sessionID and initial messages?
-	// The API said "NewKeyGen -> sessionID".
-	// But `NewStateMachine` might produce round 1 messages immediately.
-	// Let's change the API slightly to return a JSON object: { sessionID: "...", initialMessages: [...] }
+	sessionHandle := fmt.Sprintf("%s-%s", input.PartyID, input.SessionID)
+	sessions[sessionHandle] = &session{protocol: protocol, sm: sm}
 
 	resp := map[string]interface{}{
 		"sessionID": sessionHandle,
-		"messages":  encodeMessages(outMsgs),
+		"messages":  encoded,
 	}
-
 	respBytes, _ := json.Marshal(resp)
 	return string(respBytes)
 }
@@ -122,142 +187,79 @@ func Update(this js.Value, args []js.Value) interface{} {
 	sessionID := args[0].String()
 	msgJSON := args[1].String()
 
-	fmt.Printf("DEBUG: Update requested for '%s'\n", sessionID)
-	// Debug dump keys
-	for k := range sessions {
-		if k == sessionID {
-			fmt.Printf("DEBUG: Found key '%s' in map\n", k)
-		}
-	}
-
-	sm, ok := sessions[sessionID]
+	sess, ok := sessions[sessionID]
 	if !ok {
 		return "error: session not found"
 	}
 
-	// Unmarshal message
-	// We need a concrete type to unmarshal into.
-	// But `tss.Message` is an interface.
-	// We use `keygen.KeyGenMessage` for now, assuming only KeyGen.
-	// In a real app we might need a wrapper or type sniffing.
-	var msg keygen.KeyGenMessage
-	err := json.Unmarshal([]byte(msgJSON), &msg)
-	if err != nil {
-		return fmt.Sprintf("error: invalid message json: %v", err)
-	}
-
-	// Helper to reconstruct PartyID interfaces from string IDs in JSON
-	// This is tricky because `KeyGenMessage` fields (FromParty, ToParties) are interfaces.
-	// If `json.Unmarshal` worked on `keygen.KeyGenMessage`, it likely failed on interface fields or left them nil.
-	// We might need a DTO.
-
-	type MessageDTO struct {
-		From        string   `json:"from"`
-		To          []string `json:"to"`
-		IsBroadcast bool     `json:"isBroadcast"`
-		Data        string   `json:"data"` // Hex encoded
-		Type        string   `json:"type"`
-		Round       uint32   `json:"round"`
-	}
-
-	var dto MessageDTO
-	err = json.Unmarshal([]byte(msgJSON), &dto)
-	if err != nil {
-		return fmt.Sprintf("error: invalid message dto: %v", err)
-	}
-
-	dataBytes, err := hex.DecodeString(dto.Data)
-	if err != nil {
-		return fmt.Sprintf("error: invalid hex data: %v", err)
-	}
-
-	// Reconstruct Message
-	// We need to find the FromParty object from the session params to match identity
-	// But we don't have easy access to params here.
-	// We'll verify signature/identity later?
-	// For now, reuse SimplePartyID.
-
-	fromParty := &SimplePartyID{IDVal: dto.From, MonikerVal: dto.From}
-	var toParties []tss.PartyID
-	if dto.To != nil {
-		for _, t := range dto.To {
-			toParties = append(toParties, &SimplePartyID{IDVal: t, MonikerVal: t})
-		}
-	}
-
-	realMsg := &keygen.KeyGenMessage{
-		FromParty:  fromParty,
-		ToParties:  toParties,
-		IsBcast:    dto.IsBroadcast,
-		Data:       dataBytes,
-		TypeString: dto.Type,
-		RoundNum:   dto.Round,
-	}
-
-	nextSm, outMsgs, err := sm.Update(realMsg)
+	outMsgs, err := sess.update([]byte(msgJSON))
 	if err != nil {
-		return fmt.Sprintf("error: update failed: %v", err)
-	}
-
-	if nextSm != nil {
-		sessions[sessionID] = nextSm
-	} else {
-		// Possibly finished or failed?
-		// If nextSm is nil but no error, usually means ignored message?
-		// Or if finished, it might return a finished state?
-		// Our implementation returns finishedState struct, which is not nil.
-		// So nil usually implies "no state transition" (ignored) or error.
-		// `state.Update` returns `s, nil, nil` if ignoring.
-		// So we keep `sm`.
+		return fmt.Sprintf("error: %v", err)
 	}
 
 	return marshalMessages(outMsgs)
 }
 
-// Result returns the final result if available.
+// Result returns the final result if available, and then removes the
+// session: a result can be retrieved only once. Integers are 0x-prefixed
+// hex strings. Key data omits the Paillier private key unless exportPrivate
+// is set; pass true to get key data that NewSign and NewPreSign accept.
 // Arguments:
 // 0: Session ID (string)
+// 1: exportPrivate (bool, optional)
 // Returns:
 // JSON string or null
 func Result(this js.Value, args []js.Value) interface{} {
-	if len(args) != 1 {
-		return "error: expected 1 argument (sessionID)"
+	if len(args) != 1 && len(args) != 2 {
+		return "error: expected 1 or 2 arguments (sessionID, exportPrivate)"
 	}
 	sessionID := args[0].String()
-	sm, ok := sessions[sessionID]
+	exportPrivate := len(args) == 2 && args[1].Truthy()
+	sess, ok := sessions[sessionID]
 	if !ok {
 		return "error: session not found"
 	}
 
-	res := sm.Result()
+	res := sess.sm.Result()
 	if res == nil {
 		return nil // Not finished
 	}
 
-	// Marshal result
-	// LocalPartySaveData contains big.Ints which json.Marshal handles as numbers or strings?
-	// standard `json` marshals `*big.Int` as number. JS might lose precision.
-	// We should wrap them in string.
-	// But `LocalPartySaveData` is defined in `keygen` package with `*big.Int`.
-	// We can't change it easily.
-	// However, `math/big` implements `MarshalJSON`? No, `Int` doesn't implement it to return string by default?
-	// Actually `big.Int` marshals as a JSON number.
-	// For large integers, this is bad for JS.
-	// We should verify if we need a custom marshaler or DTO.
-	// For this POC, let's trust that users handle BigInt in JS or we might get truncation.
-	// BETTER: transform to a DTO with strings.
-
-	// Reflection or specialized DTO?
-	// Let's assume standard marshal for now and warn user.
-	// Or better: Create a simplistic DTO map.
-
-	resBytes, err := json.Marshal(res)
+	resBytes, err := marshalResult(res, exportPrivate)
 	if err != nil {
 		return fmt.Sprintf("error: marshal result failed: %v", err)
 	}
+
+	// The caller has the result now; drop the session and its secrets
+	closeSession(sessionID)
 	return string(resBytes)
 }
 
+// CloseSession removes a session, e.g. one that was abandoned or failed,
+// and zeroes the secrets of its result. Finished sessions are removed
+// automatically once Result has returned their result.
+// Arguments:
+// 0: Session ID (string)
+// Returns:
+// null or error string
+func CloseSession(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return "error: expected 1 argument (sessionID)"
+	}
+	if !closeSession(args[0].String()) {
+		return "error: session not found"
+	}
+	return nil
+}
+
+// ListSessions returns the IDs of the active sessions, for debugging.
+// Returns:
+// JSON string of session IDs (array)
+func ListSessions(this js.Value, args []js.Value) interface{} {
+	b, _ := json.Marshal(sessionHandles())
+	return string(b)
+}
+
 // Helpers
 
 type SimplePartyID struct {
@@ -269,29 +271,23 @@ func (p *SimplePartyID) ID() string      { return p.IDVal }
 func (p *SimplePartyID) Moniker() string { return p.MonikerVal }
 func (p *SimplePartyID) Key() []byte     { return []byte(p.IDVal) }
 
-func encodeMessages(msgs []tss.Message) []interface{} {
-	var out []interface{} // JS array
+func encodeMessages(msgs []tss.Message) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, 0, len(msgs)) // JS array
 	for _, m := range msgs {
-		out = append(out, map[string]interface{}{
-			"from": m.From().ID(),
-			"to": func() []string {
-				var ids []string
-				for _, p := range m.To() {
-					ids = append(ids, p.ID())
-				}
-				return ids
-			}(),
-			"isBroadcast": m.IsBroadcast(),
-			"data":        hex.EncodeToString(m.Payload()),
-			"type":        m.Type(),
-			"round":       m.RoundNumber(),
-		})
+		b, err := tss.EncodeMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
 	}
-	return out
+	return out, nil
 }
 
 func marshalMessages(msgs []tss.Message) string {
-	encoded := encodeMessages(msgs)
+	encoded, err := encodeMessages(msgs)
+	if err != nil {
+		return fmt.Sprintf("error: encode messages failed: %v", err)
+	}
 	b, _ := json.Marshal(encoded)
 	return string(b)
 }
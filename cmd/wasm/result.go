@@ -0,0 +1,303 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+)
+
+// The DTOs below are the JSON forms of protocol results handed to JavaScript.
+// Every integer is a 0x-prefixed hex string: JSON numbers lose precision
+// beyond 2^53 in JavaScript.
+
+// keyDataDTO is the JSON form of keygen.LocalPartySaveData. The Paillier
+// private key (PaillierLambda, PaillierMu) is only present if exported
+// explicitly; signing needs it.
+type keyDataDTO struct {
+	ShareID            string                  `json:"shareID"`
+	ShareIDs           map[string]string       `json:"shareIDs"`
+	PaillierN          string                  `json:"paillierN"`
+	PaillierLambda     string                  `json:"paillierLambda,omitempty"`
+	PaillierMu         string                  `json:"paillierMu,omitempty"`
+	PeerPaillierN      map[string]string       `json:"peerPaillierN"`
+	PedersenParams     *pedersenDTO            `json:"pedersenParams,omitempty"`
+	PeerPedersenParams map[string]*pedersenDTO `json:"peerPedersenParams,omitempty"`
+	Ui                 string                  `json:"ui,omitempty"`
+	Xi                 string                  `json:"xi"`
+	XiX                string                  `json:"xiX"`
+	XiY                string                  `json:"xiY"`
+	PeerXiX            map[string]string       `json:"peerXiX,omitempty"`
+	PeerXiY            map[string]string       `json:"peerXiY,omitempty"`
+	PublicKeyX         string                  `json:"publicKeyX"`
+	PublicKeyY         string                  `json:"publicKeyY"`
+	Epoch              uint64                  `json:"epoch"`
+	Mode               string                  `json:"mode,omitempty"`
+	Threshold          int                     `json:"threshold"`
+}
+
+type pedersenDTO struct {
+	N      string   `json:"n"`
+	S      string   `json:"s"`
+	T      string   `json:"t"`
+	ProofA []string `json:"proofA,omitempty"`
+	ProofZ []string `json:"proofZ,omitempty"`
+}
+
+type signatureDTO struct {
+	R       string   `json:"r"`
+	S       string   `json:"s"`
+	RecID   int      `json:"recID"`
+	Signers []string `json:"signers,omitempty"`
+}
+
+type preSignatureDTO struct {
+	R      string `json:"r"`
+	Rx     string `json:"rx"`
+	Ry     string `json:"ry"`
+	Ki     string `json:"ki"`
+	SigmaI string `json:"sigmaI"`
+	Tweak  string `json:"tweak,omitempty"`
+
+	PreSignSessionID string `json:"preSignSessionID,omitempty"`
+}
+
+// marshalResult returns the JSON form of a protocol result. The Paillier
+// private key of key data is only included if exportPrivate is set.
+func marshalResult(res interface{}, exportPrivate bool) ([]byte, error) {
+	switch r := res.(type) {
+	case *keygen.LocalPartySaveData:
+		return json.Marshal(exportKeyData(r, exportPrivate))
+	case *sign.Signature:
+		return json.Marshal(&signatureDTO{R: toHex(r.R), S: toHex(r.S), RecID: r.RecID, Signers: r.Signers})
+	case *sign.PreSignature:
+		return json.Marshal(&preSignatureDTO{
+			R:      toHex(r.R),
+			Rx:     toHex(r.Rx),
+			Ry:     toHex(r.Ry),
+			Ki:     toHex(r.Ki),
+			SigmaI: toHex(r.SigmaI),
+			Tweak:  toHex(r.Tweak),
+
+			PreSignSessionID: toHexBytes(r.PreSignSessionID),
+		})
+	default:
+		return json.Marshal(res)
+	}
+}
+
+func exportKeyData(d *keygen.LocalPartySaveData, exportPrivate bool) *keyDataDTO {
+	dto := &keyDataDTO{
+		ShareID:            toHex(d.ShareID),
+		ShareIDs:           toHexMap(d.ShareIDs),
+		PeerPaillierN:      make(map[string]string, len(d.PeerPaillierPks)),
+		PedersenParams:     exportPedersen(d.PedersenParams),
+		PeerPedersenParams: make(map[string]*pedersenDTO, len(d.PeerPedersenParams)),
+		Ui:                 toHex(d.Ui),
+		Xi:                 toHex(d.Xi),
+		XiX:                toHex(d.XiX),
+		XiY:                toHex(d.XiY),
+		PeerXiX:            toHexMap(d.PeerXiX),
+		PeerXiY:            toHexMap(d.PeerXiY),
+		PublicKeyX:         toHex(d.PublicKeyX),
+		PublicKeyY:         toHex(d.PublicKeyY),
+		Epoch:              d.Epoch,
+		Mode:               d.Mode,
+		Threshold:          d.Threshold,
+	}
+	if d.PaillierPk != nil {
+		dto.PaillierN = toHex(d.PaillierPk.N)
+	}
+	if exportPrivate && d.PaillierSk != nil {
+		dto.PaillierLambda = toHex(d.PaillierSk.Lambda)
+		dto.PaillierMu = toHex(d.PaillierSk.Mu)
+	}
+	for id, pk := range d.PeerPaillierPks {
+		if pk != nil {
+			dto.PeerPaillierN[id] = toHex(pk.N)
+		}
+	}
+	for id, pp := range d.PeerPedersenParams {
+		dto.PeerPedersenParams[id] = exportPedersen(pp)
+	}
+	return dto
+}
+
+func exportPedersen(pp *commitment.PedersenParams) *pedersenDTO {
+	if pp == nil {
+		return nil
+	}
+	dto := &pedersenDTO{N: toHex(pp.N), S: toHex(pp.S), T: toHex(pp.T)}
+	if pp.Proof != nil {
+		dto.ProofA = toHexSlice(pp.Proof.A)
+		dto.ProofZ = toHexSlice(pp.Proof.Z)
+	}
+	return dto
+}
+
+// importKeyData converts the JSON form back into save data. LocalPartyID is
+// left for the caller to set.
+func importKeyData(dto *keyDataDTO) (*keygen.LocalPartySaveData, error) {
+	var p hexParser
+	d := &keygen.LocalPartySaveData{
+		ShareID:            p.int(dto.ShareID),
+		ShareIDs:           p.intMap(dto.ShareIDs),
+		PeerPaillierPks:    make(map[string]*paillier.PublicKey, len(dto.PeerPaillierN)),
+		PedersenParams:     p.pedersen(dto.PedersenParams),
+		PeerPedersenParams: make(map[string]*commitment.PedersenParams, len(dto.PeerPedersenParams)),
+		Ui:                 p.int(dto.Ui),
+		Xi:                 p.int(dto.Xi),
+		XiX:                p.int(dto.XiX),
+		XiY:                p.int(dto.XiY),
+		PeerXiX:            p.intMap(dto.PeerXiX),
+		PeerXiY:            p.intMap(dto.PeerXiY),
+		PublicKeyX:         p.int(dto.PublicKeyX),
+		PublicKeyY:         p.int(dto.PublicKeyY),
+		Epoch:              dto.Epoch,
+		Mode:               dto.Mode,
+		Threshold:          dto.Threshold,
+	}
+	if n := p.int(dto.PaillierN); n != nil {
+		d.PaillierPk = paillier.NewPublicKey(n)
+		if dto.PaillierLambda != "" || dto.PaillierMu != "" {
+			d.PaillierSk = &paillier.PrivateKey{
+				PublicKey: *d.PaillierPk,
+				Lambda:    p.int(dto.PaillierLambda),
+				Mu:        p.int(dto.PaillierMu),
+			}
+			d.PaillierPk = &d.PaillierSk.PublicKey
+		}
+	}
+	for id, n := range dto.PeerPaillierN {
+		d.PeerPaillierPks[id] = paillier.NewPublicKey(p.int(n))
+	}
+	for id, pp := range dto.PeerPedersenParams {
+		d.PeerPedersenParams[id] = p.pedersen(pp)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return d, nil
+}
+
+func importPreSignature(dto *preSignatureDTO) (*sign.PreSignature, error) {
+	var p hexParser
+	preSig := &sign.PreSignature{
+		R:      p.int(dto.R),
+		Rx:     p.int(dto.Rx),
+		Ry:     p.int(dto.Ry),
+		Ki:     p.int(dto.Ki),
+		SigmaI: p.int(dto.SigmaI),
+		Tweak:  p.int(dto.Tweak),
+
+		PreSignSessionID: p.bytes(dto.PreSignSessionID),
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return preSig, nil
+}
+
+// toHex returns n as a 0x-prefixed hex string, or "" for nil.
+func toHex(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return "0x" + n.Text(16)
+}
+
+// toHexBytes returns b as a 0x-prefixed hex string, or "" if empty.
+func toHexBytes(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+func toHexMap(m map[string]*big.Int) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = toHex(v)
+	}
+	return out
+}
+
+func toHexSlice(s []*big.Int) []string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = toHex(v)
+	}
+	return out
+}
+
+// hexParser parses hex strings produced by toHex, keeping the first error.
+type hexParser struct {
+	err error
+}
+
+// int parses a 0x-prefixed hex string; "" is nil.
+func (p *hexParser) int(s string) *big.Int {
+	if s == "" || p.err != nil {
+		return nil
+	}
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok || !strings.HasPrefix(s, "0x") {
+		p.err = fmt.Errorf("invalid hex integer %q", s)
+		return nil
+	}
+	return n
+}
+
+// bytes parses a 0x-prefixed hex string produced by toHexBytes; "" is nil.
+func (p *hexParser) bytes(s string) []byte {
+	if s == "" || p.err != nil {
+		return nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || !strings.HasPrefix(s, "0x") {
+		p.err = fmt.Errorf("invalid hex string %q", s)
+		return nil
+	}
+	return b
+}
+
+func (p *hexParser) intMap(m map[string]string) map[string]*big.Int {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]*big.Int, len(m))
+	for k, v := range m {
+		out[k] = p.int(v)
+	}
+	return out
+}
+
+func (p *hexParser) intSlice(s []string) []*big.Int {
+	out := make([]*big.Int, len(s))
+	for i, v := range s {
+		out[i] = p.int(v)
+	}
+	return out
+}
+
+func (p *hexParser) pedersen(dto *pedersenDTO) *commitment.PedersenParams {
+	if dto == nil {
+		return nil
+	}
+	pp := &commitment.PedersenParams{N: p.int(dto.N), S: p.int(dto.S), T: p.int(dto.T)}
+	if dto.ProofA != nil || dto.ProofZ != nil {
+		pp.Proof = &commitment.PedersenProof{A: p.intSlice(dto.ProofA), Z: p.intSlice(dto.ProofZ)}
+	}
+	return pp
+}
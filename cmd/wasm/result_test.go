@@ -0,0 +1,137 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+)
+
+// testKeyData returns save data with every field set. The values only need
+// to exceed 2^53, not to be a consistent key.
+func testKeyData() *keygen.LocalPartySaveData {
+	hex := func(s string) *big.Int {
+		n, _ := new(big.Int).SetString(s, 16)
+		return n
+	}
+	sk := &paillier.PrivateKey{
+		PublicKey: *paillier.NewPublicKey(hex("c4f1a3b5d7e9f10213243546576879")),
+		Lambda:    hex("a1b2c3d4e5f60718293a4b5c6d7e8f"),
+		Mu:        hex("0f1e2d3c4b5a69788796a5b4c3d2e1"),
+	}
+	pedersen := &commitment.PedersenParams{
+		N: hex("d1d2d3d4d5d6d7d8d9dadbdcdddedf"),
+		S: hex("1111111111111111111111"),
+		T: hex("2222222222222222222222"),
+		Proof: &commitment.PedersenProof{
+			A: []*big.Int{hex("3333333333333333333333")},
+			Z: []*big.Int{hex("4444444444444444444444")},
+		},
+	}
+	return &keygen.LocalPartySaveData{
+		ShareID:            hex("1"),
+		ShareIDs:           map[string]*big.Int{"1": hex("1"), "2": hex("2")},
+		PaillierSk:         sk,
+		PaillierPk:         &sk.PublicKey,
+		PeerPaillierPks:    map[string]*paillier.PublicKey{"2": paillier.NewPublicKey(hex("e5e6e7e8e9eaebecedeeeff0f1f2f3"))},
+		PedersenParams:     pedersen,
+		PeerPedersenParams: map[string]*commitment.PedersenParams{"2": pedersen},
+		Ui:                 hex("5555555555555555555555"),
+		Xi:                 hex("6666666666666666666666"),
+		XiX:                hex("7777777777777777777777"),
+		XiY:                hex("8888888888888888888888"),
+		PeerXiX:            map[string]*big.Int{"2": hex("9999999999999999999999")},
+		PeerXiY:            map[string]*big.Int{"2": hex("aaaaaaaaaaaaaaaaaaaaaa")},
+		PublicKeyX:         hex("bbbbbbbbbbbbbbbbbbbbbb"),
+		PublicKeyY:         hex("cccccccccccccccccccccc"),
+		Epoch:              3,
+		Threshold:          1,
+	}
+}
+
+func TestKeyDataResultRoundTrip(t *testing.T) {
+	params, _, err := parseParams(`{"partyID":"1","allParties":["1","2"],"threshold":1,"sessionID":"s"}`)
+	if err != nil {
+		t.Fatalf("parseParams: %v", err)
+	}
+	saved := testKeyData()
+
+	// Default export: hex strings only, no Paillier private key
+	out, err := marshalResult(saved, false)
+	if err != nil {
+		t.Fatalf("marshalResult: %v", err)
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(out, &generic); err != nil {
+		t.Fatalf("exported JSON does not parse: %v", err)
+	}
+	if _, ok := generic["paillierLambda"]; ok {
+		t.Fatal("Paillier private key exported by default")
+	}
+	for _, field := range []string{"xi", "publicKeyX", "paillierN"} {
+		if v, ok := generic[field].(string); !ok || !strings.HasPrefix(v, "0x") {
+			t.Fatalf("%s = %v, want a 0x-prefixed hex string", field, generic[field])
+		}
+	}
+	public, err := parseKeyData(string(out), params)
+	if err != nil {
+		t.Fatalf("parseKeyData: %v", err)
+	}
+	if public.PaillierSk != nil || public.PaillierPk.N.Cmp(saved.PaillierPk.N) != 0 {
+		t.Fatal("public export did not round-trip the Paillier public key alone")
+	}
+
+	// Private export round-trips every field
+	out, err = marshalResult(saved, true)
+	if err != nil {
+		t.Fatalf("marshalResult: %v", err)
+	}
+	keyData, err := parseKeyData(string(out), params)
+	if err != nil {
+		t.Fatalf("parseKeyData: %v", err)
+	}
+	if keyData.LocalPartyID.ID() != "1" {
+		t.Fatalf("LocalPartyID = %s, want 1", keyData.LocalPartyID.ID())
+	}
+	if keyData.PaillierSk == nil || keyData.PaillierSk.Lambda.Cmp(saved.PaillierSk.Lambda) != 0 || keyData.PaillierSk.Mu.Cmp(saved.PaillierSk.Mu) != 0 {
+		t.Fatal("Paillier private key did not round-trip")
+	}
+	if !reflect.DeepEqual(exportKeyData(keyData, true), exportKeyData(saved, true)) {
+		t.Fatalf("key data did not round-trip:\n got %+v\nwant %+v", exportKeyData(keyData, true), exportKeyData(saved, true))
+	}
+}
+
+func TestPreSignatureResultRoundTrip(t *testing.T) {
+	preSig := &sign.PreSignature{
+		R:      new(big.Int).Lsh(big.NewInt(1), 200),
+		Rx:     new(big.Int).Lsh(big.NewInt(3), 200),
+		Ry:     new(big.Int).Lsh(big.NewInt(5), 200),
+		Ki:     new(big.Int).Lsh(big.NewInt(7), 200),
+		SigmaI: new(big.Int).Lsh(big.NewInt(9), 200),
+
+		PreSignSessionID: []byte("presign-session"),
+	}
+	out, err := marshalResult(preSig, false)
+	if err != nil {
+		t.Fatalf("marshalResult: %v", err)
+	}
+	got, err := parsePreSignature(string(out))
+	if err != nil {
+		t.Fatalf("parsePreSignature: %v", err)
+	}
+	if !reflect.DeepEqual(got, preSig) {
+		t.Fatalf("pre-signature did not round-trip: got %+v, want %+v", got, preSig)
+	}
+
+	if _, err := parsePreSignature(`{"r":"12"}`); err == nil {
+		t.Fatal("integer without 0x prefix accepted")
+	}
+}
@@ -0,0 +1,163 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// session is an active state machine together with the protocol it runs.
+// Update only feeds it messages registered by that protocol, so a keygen
+// message can never reach a sign session or vice versa.
+type session struct {
+	protocol string
+	sm       tss.StateMachine
+}
+
+// update decodes msgJSON and feeds it to the session's state machine.
+func (s *session) update(msgJSON []byte) ([]tss.Message, error) {
+	msg, err := tss.DecodeMessage(msgJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	if protocol, _ := tss.MessageProtocol(msg.Type()); protocol != s.protocol {
+		return nil, fmt.Errorf("%w: %s message %q sent to a %s session", tss.ErrInvalidMsg, protocol, msg.Type(), s.protocol)
+	}
+
+	next, out, err := s.sm.Update(msg)
+	if err != nil {
+		return nil, fmt.Errorf("update failed: %w", err)
+	}
+	// A nil state machine without an error means the message was ignored
+	if next != nil {
+		s.sm = next
+	}
+	return out, nil
+}
+
+// closeSession removes a session and zeroes the secrets of its result, if
+// it has one. In-progress state is dropped for the garbage collector.
+// It reports whether the session existed.
+func closeSession(handle string) bool {
+	sess, ok := sessions[handle]
+	if !ok {
+		return false
+	}
+	delete(sessions, handle)
+	if res := sess.sm.Result(); res != nil {
+		zeroResult(res)
+	}
+	sess.sm = nil
+	return true
+}
+
+// sessionHandles returns the handles of the active sessions, sorted.
+func sessionHandles() []string {
+	handles := make([]string, 0, len(sessions))
+	for h := range sessions {
+		handles = append(handles, h)
+	}
+	sort.Strings(handles)
+	return handles
+}
+
+// zeroResult overwrites the secret values of a protocol result in place.
+func zeroResult(res interface{}) {
+	switch r := res.(type) {
+	case *keygen.LocalPartySaveData:
+		r.Zeroize()
+	case *sign.PreSignature:
+		zeroize.Ints(r.Ki, r.SigmaI)
+	}
+}
+
+// paramsInput mirrors tss.Parameters with simplifications for JSON.
+type paramsInput struct {
+	PartyID        string   `json:"partyID"`
+	AllParties     []string `json:"allParties"`
+	Threshold      int      `json:"threshold"`
+	SessionID      string   `json:"sessionID"`
+	OneRoundKeyGen bool     `json:"oneRoundKeyGen"`
+}
+
+// parseParams builds tss.Parameters from the JSON parameters. For signing,
+// allParties lists the signers.
+func parseParams(paramsJSON string) (*tss.Parameters, *paramsInput, error) {
+	var input paramsInput
+	if err := json.Unmarshal([]byte(paramsJSON), &input); err != nil {
+		return nil, nil, fmt.Errorf("invalid json: %v", err)
+	}
+
+	parties := make([]tss.PartyID, len(input.AllParties))
+	var localParty tss.PartyID
+	for i, pid := range input.AllParties {
+		p := &SimplePartyID{IDVal: pid, MonikerVal: pid}
+		parties[i] = p
+		if pid == input.PartyID {
+			localParty = p
+		}
+	}
+	if localParty == nil {
+		return nil, nil, fmt.Errorf("local party ID not found in allParties")
+	}
+
+	params := &tss.Parameters{
+		PartyID:        localParty,
+		Parties:        parties,
+		Threshold:      input.Threshold,
+		Curve:          "secp256k1",
+		SessionID:      []byte(input.SessionID),
+		OneRoundKeyGen: input.OneRoundKeyGen,
+	}
+	return params, &input, nil
+}
+
+// parseKeyData decodes key data as returned by Result for a keygen session.
+// Signing needs the Paillier private key, so it must have been exported.
+// The local party is taken from params.
+func parseKeyData(keyDataJSON string, params *tss.Parameters) (*keygen.LocalPartySaveData, error) {
+	var dto keyDataDTO
+	if err := json.Unmarshal([]byte(keyDataJSON), &dto); err != nil {
+		return nil, fmt.Errorf("invalid key data: %v", err)
+	}
+	keyData, err := importKeyData(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key data: %v", err)
+	}
+	keyData.LocalPartyID = params.PartyID
+	return keyData, nil
+}
+
+// parsePreSignature decodes a pre-signature as returned by Result for a
+// pre-signing session.
+func parsePreSignature(preSigJSON string) (*sign.PreSignature, error) {
+	var dto preSignatureDTO
+	if err := json.Unmarshal([]byte(preSigJSON), &dto); err != nil {
+		return nil, fmt.Errorf("invalid pre-signature: %v", err)
+	}
+	preSig, err := importPreSignature(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pre-signature: %v", err)
+	}
+	return preSig, nil
+}
+
+// parseHash decodes a hex message digest, with or without a 0x prefix.
+func parseHash(msgHashHex string) ([]byte, error) {
+	if len(msgHashHex) >= 2 && msgHashHex[:2] == "0x" {
+		msgHashHex = msgHashHex[2:]
+	}
+	hash, err := hex.DecodeString(msgHashHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message hash: %v", err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,125 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"syscall/js"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// recordingMachine records the messages it is fed.
+type recordingMachine struct {
+	got []tss.Message
+}
+
+func (m *recordingMachine) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	m.got = append(m.got, msg)
+	return m, nil, nil
+}
+func (m *recordingMachine) Result() interface{} { return nil }
+func (m *recordingMachine) Details() string     { return "recording" }
+
+// finishedMachine is a state machine that has finished with result.
+type finishedMachine struct {
+	result interface{}
+}
+
+func (m *finishedMachine) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+func (m *finishedMachine) Result() interface{} { return m.result }
+func (m *finishedMachine) Details() string     { return "finished" }
+
+func encodeTestMessage(t *testing.T, msgType string) []byte {
+	t.Helper()
+	b, err := tss.EncodeMessage(&tss.BasicMessage{
+		FromParty:  &tss.BasicPartyID{IDVal: "2"},
+		IsBcast:    true,
+		TypeString: msgType,
+		RoundNum:   1,
+		Session:    []byte("sess"),
+	})
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	return b
+}
+
+func TestSessionDispatch(t *testing.T) {
+	sm := &recordingMachine{}
+	sess := &session{protocol: "sign", sm: sm}
+
+	if _, err := sess.update(encodeTestMessage(t, "SignRound1")); err != nil {
+		t.Fatalf("sign message rejected by sign session: %v", err)
+	}
+	if len(sm.got) != 1 || sm.got[0].Type() != "SignRound1" {
+		t.Fatalf("sign message not delivered: %v", sm.got)
+	}
+
+	_, err := sess.update(encodeTestMessage(t, "KeyGenRound1"))
+	if !errors.Is(err, tss.ErrInvalidMsg) {
+		t.Fatalf("keygen message to sign session: got %v, want ErrInvalidMsg", err)
+	}
+	if _, err := sess.update([]byte(`{"type":"Unknown","from":"2"}`)); err == nil {
+		t.Fatal("unregistered message type accepted")
+	}
+	if len(sm.got) != 1 {
+		t.Fatalf("rejected messages reached the state machine: %d delivered", len(sm.got))
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	for _, in := range []string{"0a0b", "0x0a0b"} {
+		h, err := parseHash(in)
+		if err != nil || len(h) != 2 || h[0] != 0x0a || h[1] != 0x0b {
+			t.Fatalf("parseHash(%q) = %x, %v", in, h, err)
+		}
+	}
+	if _, err := parseHash("zz"); err == nil {
+		t.Fatal("invalid hex accepted")
+	}
+}
+
+func TestSessionCleanup(t *testing.T) {
+	saved := &keygen.LocalPartySaveData{Xi: big.NewInt(5), Ui: big.NewInt(7)}
+	sessions["1-done"] = &session{protocol: "keygen", sm: &finishedMachine{result: saved}}
+	sessions["1-running"] = &session{protocol: "sign", sm: &recordingMachine{}}
+	defer func() { sessions = make(map[string]*session) }()
+
+	var listed []string
+	if err := json.Unmarshal([]byte(ListSessions(js.Undefined(), nil).(string)), &listed); err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if !reflect.DeepEqual(listed, []string{"1-done", "1-running"}) {
+		t.Fatalf("ListSessions = %v", listed)
+	}
+
+	// Retrieving the result removes the finished session and zeroes its secrets
+	if res, ok := Result(js.Undefined(), []js.Value{js.ValueOf("1-done")}).(string); !ok || res == "" {
+		t.Fatalf("Result = %v, want the key data", res)
+	}
+	if _, ok := sessions["1-done"]; ok {
+		t.Fatal("finished session still present after Result")
+	}
+	if saved.Xi.Sign() != 0 || saved.Ui.Sign() != 0 {
+		t.Fatal("secret shares not zeroed")
+	}
+
+	// Sessions still running are removed by CloseSession
+	if res := CloseSession(js.Undefined(), []js.Value{js.ValueOf("1-running")}); res != nil {
+		t.Fatalf("CloseSession = %v", res)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("sessions left after closing: %v", sessionHandles())
+	}
+	if res := CloseSession(js.Undefined(), []js.Value{js.ValueOf("1-running")}); res != "error: session not found" {
+		t.Fatalf("closing twice: %v", res)
+	}
+}
@@ -64,39 +64,34 @@ func main() {
 
 // runKeyGen simulates the distributed key generation protocol.
 func runKeyGen(parties []tss.PartyID, threshold int) ([]*keygen.LocalPartySaveData, error) {
-	n := len(parties)
-	sms := make([]tss.StateMachine, n)
-	outMsgs := make([][]tss.Message, n)
+	coord := tss.NewLocalCoordinator()
 
 	// Initialize state machines
-	for i := 0; i < n; i++ {
+	for _, p := range parties {
 		params := &tss.Parameters{
-			PartyID:   parties[i],
+			PartyID:   p,
 			Parties:   parties,
 			Threshold: threshold,
 			Curve:     "secp256k1",
-			SessionID: []byte("keygen-example-session"),
+			SessionID: tss.DeriveSessionID("keygen", parties, []byte("example-nonce")),
 		}
-		var err error
-		sms[i], outMsgs[i], err = keygen.NewStateMachine(params)
+		sm, out, err := keygen.NewStateMachine(params)
 		if err != nil {
 			return nil, err
 		}
+		coord.Add(p.ID(), sm, out)
 	}
 
-	// Run 4 rounds of the protocol
-	for round := 1; round <= 4; round++ {
-		sms, outMsgs = routeMessages(parties, sms, outMsgs)
+	// Route messages until every party has finished
+	results, err := coord.Run()
+	if err != nil {
+		return nil, err
 	}
 
 	// Collect results
-	keyData := make([]*keygen.LocalPartySaveData, n)
-	for i := 0; i < n; i++ {
-		result := sms[i].Result()
-		if result == nil {
-			return nil, fmt.Errorf("party %d did not complete", i)
-		}
-		keyData[i] = result.(*keygen.LocalPartySaveData)
+	keyData := make([]*keygen.LocalPartySaveData, len(parties))
+	for i, p := range parties {
+		keyData[i] = results[p.ID()].(*keygen.LocalPartySaveData)
 	}
 
 	return keyData, nil
@@ -104,85 +99,30 @@ func runKeyGen(parties []tss.PartyID, threshold int) ([]*keygen.LocalPartySaveDa
 
 // runSign simulates the threshold signing protocol.
 func runSign(parties []tss.PartyID, keyData []*keygen.LocalPartySaveData, msgHash []byte) (*sign.Signature, error) {
-	n := len(parties)
-	sms := make([]tss.StateMachine, n)
-	outMsgs := make([][]tss.Message, n)
+	coord := tss.NewLocalCoordinator()
 
 	// Initialize state machines
-	for i := 0; i < n; i++ {
+	for i, p := range parties {
 		params := &tss.Parameters{
-			PartyID:   parties[i],
+			PartyID:   p,
 			Parties:   parties,
 			Threshold: 1,
 			Curve:     "secp256k1",
-			SessionID: []byte("sign-example-session"),
+			SessionID: tss.DeriveSessionID("sign", parties, msgHash),
 		}
-		var err error
-		sms[i], outMsgs[i], err = sign.NewStateMachine(params, keyData[i], msgHash)
+		sm, out, err := sign.NewStateMachine(params, keyData[i], msgHash)
 		if err != nil {
 			return nil, err
 		}
+		coord.Add(p.ID(), sm, out)
 	}
 
-	// Run 5 rounds of the protocol
-	for round := 1; round <= 5; round++ {
-		sms, outMsgs = routeMessages(parties, sms, outMsgs)
+	// Route messages until every party has finished
+	results, err := coord.Run()
+	if err != nil {
+		return nil, err
 	}
 
 	// Get result from first party
-	result := sms[0].Result()
-	if result == nil {
-		return nil, fmt.Errorf("signing did not complete")
-	}
-
-	return result.(*sign.Signature), nil
-}
-
-// routeMessages simulates message routing between parties.
-func routeMessages(parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Message) ([]tss.StateMachine, [][]tss.Message) {
-	// Collect all messages
-	allMsgs := []tss.Message{}
-	for _, msgs := range outMsgs {
-		allMsgs = append(allMsgs, msgs...)
-	}
-	newOutMsgs := make([][]tss.Message, len(sms))
-
-	// Deliver messages to each party
-	for i := 0; i < len(sms); i++ {
-		if sms[i] == nil {
-			continue
-		}
-
-		for _, msg := range allMsgs {
-			// Skip own messages
-			if msg.From().ID() == parties[i].ID() {
-				continue
-			}
-			// Check destination for P2P messages
-			if !msg.IsBroadcast() {
-				found := false
-				for _, dest := range msg.To() {
-					if dest.ID() == parties[i].ID() {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
-			}
-
-			// Update state machine
-			next, newOut, err := sms[i].Update(msg)
-			if err != nil {
-				log.Printf("Party %d error: %v", i, err)
-				continue
-			}
-			sms[i] = next
-			if newOut != nil {
-				newOutMsgs[i] = append(newOutMsgs[i], newOut...)
-			}
-		}
-	}
-	return sms, newOutMsgs
+	return results[parties[0].ID()].(*sign.Signature), nil
 }
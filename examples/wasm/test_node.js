@@ -114,11 +114,11 @@ async function startTest() {
                 process.exit(1);
             }
             const res = JSON.parse(resStr);
-            console.log(`[${pid}] Finished. PubKeyX: ${res.PublicKeyX}`);
+            console.log(`[${pid}] Finished. PubKeyX: ${res.publicKeyX}`);
 
             if (pubKeyX === "") {
-                pubKeyX = res.PublicKeyX;
-            } else if (pubKeyX !== res.PublicKeyX) {
+                pubKeyX = res.publicKeyX;
+            } else if (pubKeyX !== res.publicKeyX) {
                 console.error("Public Key Mismatch!");
                 process.exit(1);
             }
@@ -0,0 +1,73 @@
+// Package base58 implements the Base58 encoding with the Bitcoin alphabet,
+// as used for Bitcoin and Solana addresses.
+package base58
+
+import (
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ErrInvalidCharacter is returned when decoding a string with a character
+// outside the Base58 alphabet.
+var ErrInvalidCharacter = errors.New("base58: invalid character")
+
+var (
+	radix   = big.NewInt(58)
+	indexOf [256]int
+)
+
+func init() {
+	for i := range indexOf {
+		indexOf[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		indexOf[alphabet[i]] = i
+	}
+}
+
+// Encode returns the Base58 encoding of b.
+// Each leading zero byte is encoded as a leading '1'.
+func Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, alphabet[0])
+	}
+
+	// Digits were produced least significant first
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Decode decodes a Base58 string.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		d := indexOf[s[i]]
+		if d < 0 {
+			return nil, ErrInvalidCharacter
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,53 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"00", "1"},
+		{"0000", "11"},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+		// Solana SPL Token program ID
+		{"06ddf6e1d765a193d9cbe146ceeb79ac1cb485ed5f5b37913a8cf5857eff00a9", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"},
+	}
+	for _, tt := range tests {
+		raw, _ := hex.DecodeString(tt.hex)
+		if got := Encode(raw); got != tt.encoded {
+			t.Errorf("Encode(%s) = %q, want %q", tt.hex, got, tt.encoded)
+		}
+		decoded, err := Decode(tt.encoded)
+		if err != nil {
+			t.Errorf("Decode(%q) failed: %v", tt.encoded, err)
+			continue
+		}
+		if !bytes.Equal(decoded, raw) {
+			t.Errorf("Decode(%q) = %x, want %s", tt.encoded, decoded, tt.hex)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, s := range []string{"0", "O", "I", "l", "abc!"} {
+		if _, err := Decode(s); err != ErrInvalidCharacter {
+			t.Errorf("Decode(%q): expected ErrInvalidCharacter, got %v", s, err)
+		}
+	}
+}
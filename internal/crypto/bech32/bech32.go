@@ -0,0 +1,104 @@
+// Package bech32 implements the Bech32 and Bech32m encodings of SegWit
+// addresses (BIP-173, BIP-350). Only encoding is provided.
+package bech32
+
+import (
+	"errors"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants: witness version 0 uses Bech32, later versions Bech32m
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// ErrInvalidProgram is returned for a witness version or program that no
+// SegWit address can encode.
+var ErrInvalidProgram = errors.New("bech32: invalid witness program")
+
+// EncodeSegwit returns the SegWit address of the witness program with the
+// given version under the human-readable part hrp (e.g. "bc" or "tb").
+func EncodeSegwit(hrp string, version byte, program []byte) (string, error) {
+	if version > 16 || len(program) < 2 || len(program) > 40 {
+		return "", ErrInvalidProgram
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return "", ErrInvalidProgram
+	}
+
+	data := append([]byte{version}, convertBits(program, 8, 5)...)
+	constant := uint32(bech32Const)
+	if version > 0 {
+		constant = bech32mConst
+	}
+	checksum := createChecksum(hrp, data, constant)
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range append(data, checksum...) {
+		sb.WriteByte(charset[d])
+	}
+	return sb.String(), nil
+}
+
+// convertBits regroups the bits of data from groups of from bits into
+// groups of to bits, padding the last group with zeros.
+func convertBits(data []byte, from, to uint) []byte {
+	var out []byte
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<to - 1
+	for _, b := range data {
+		acc = acc<<from | uint32(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(to-bits)&maxv))
+	}
+	return out
+}
+
+func polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func createChecksum(hrp string, data []byte, constant uint32) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ constant
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod >> (5 * (5 - i)) & 31)
+	}
+	return out
+}
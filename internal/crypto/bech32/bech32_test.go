@@ -0,0 +1,48 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestEncodeSegwit(t *testing.T) {
+	// Test vectors from BIP-173 and BIP-350
+	vectors := []struct {
+		hrp     string
+		version byte
+		program string
+		addr    string
+	}{
+		{"bc", 0, "751e76e8199196d454941c45d1b3a323f1433bd6", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"tb", 0, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"},
+		{"bc", 1, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+	}
+	for _, v := range vectors {
+		program, _ := hex.DecodeString(v.program)
+		got, err := EncodeSegwit(v.hrp, v.version, program)
+		if err != nil {
+			t.Fatalf("EncodeSegwit(%s, %d, %s): %v", v.hrp, v.version, v.program, err)
+		}
+		if got != v.addr {
+			t.Errorf("EncodeSegwit(%s, %d, %s) = %s, want %s", v.hrp, v.version, v.program, got, v.addr)
+		}
+	}
+}
+
+func TestEncodeSegwitInvalid(t *testing.T) {
+	tests := []struct {
+		version byte
+		length  int
+	}{
+		{0, 21}, // v0 programs are 20 or 32 bytes
+		{1, 1},
+		{1, 41},
+		{17, 20},
+	}
+	for _, tt := range tests {
+		if _, err := EncodeSegwit("bc", tt.version, make([]byte, tt.length)); !errors.Is(err, ErrInvalidProgram) {
+			t.Errorf("version %d, %d bytes: expected ErrInvalidProgram, got %v", tt.version, tt.length, err)
+		}
+	}
+}
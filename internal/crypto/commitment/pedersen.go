@@ -0,0 +1,262 @@
+package commitment
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// PedersenProofIterations is the number of binary-challenge repetitions in the
+// proof that s and t generate the same group (soundness error 2^-80).
+const PedersenProofIterations = 80
+
+const pedersenProofDomain = "go-cggmp-tss/pedersen/prm/v1"
+
+var one = big.NewInt(1)
+
+// PedersenParams holds ring-Pedersen parameters (N~, s, t) over an auxiliary RSA modulus.
+// A commitment to x with randomness r is C = s^x * t^r mod N~.
+//
+// Proof shows s = t^lambda for a lambda known to the generator, so s lies in the
+// subgroup generated by t and commitments are perfectly hiding.
+type PedersenParams struct {
+	N     *big.Int       // Auxiliary RSA modulus N~
+	S     *big.Int       // s = t^lambda mod N~
+	T     *big.Int       // t = tau^2 mod N~
+	Proof *PedersenProof // Proof that s is in <t>
+}
+
+// PedersenProof proves knowledge of lambda such that s = t^lambda mod N~ (Pi^prm in CGGMP21).
+type PedersenProof struct {
+	A []*big.Int // A_i = t^{a_i} mod N~
+	Z []*big.Int // z_i = a_i + e_i * lambda mod phi
+}
+
+// GenPedersenParams generates fresh ring-Pedersen parameters over a modulus of the
+// given bit length built from two safe primes.
+func GenPedersenParams(bits int) (*PedersenParams, error) {
+	if bits < 256 {
+		return nil, errors.New("pedersen: bits must be at least 256")
+	}
+
+	p, err := safePrime(bits / 2)
+	if err != nil {
+		return nil, err
+	}
+	q, err := safePrime(bits / 2)
+	if err != nil {
+		return nil, err
+	}
+	for p.Cmp(q) == 0 {
+		if q, err = safePrime(bits / 2); err != nil {
+			return nil, err
+		}
+	}
+
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	return NewPedersenParams(n, phi)
+}
+
+// NewPedersenParams derives ring-Pedersen parameters for an existing RSA modulus n.
+// phi must be a multiple of the exponent of Z_n^* (e.g. phi(n) or lcm(p-1, q-1)),
+// which allows reusing a party's Paillier modulus as N~ as in CGGMP21.
+func NewPedersenParams(n, phi *big.Int) (*PedersenParams, error) {
+	if n == nil || phi == nil || n.Sign() <= 0 || phi.Sign() <= 0 {
+		return nil, errors.New("pedersen: invalid modulus")
+	}
+
+	// t = tau^2 mod N~ is a random quadratic residue
+	tau, err := randUnit(n)
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Exp(tau, big.NewInt(2), n)
+
+	// s = t^lambda mod N~
+	lambda, err := rand.Int(rand.Reader, phi)
+	if err != nil {
+		return nil, err
+	}
+	s := new(big.Int).Exp(t, lambda, n)
+
+	proof, err := provePedersen(n, s, t, lambda, phi)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PedersenParams{
+		N:     n,
+		S:     s,
+		T:     t,
+		Proof: proof,
+	}, nil
+}
+
+// Validate checks that the parameters are well-formed and that the proof that s
+// and t generate the same group verifies.
+func (pp *PedersenParams) Validate() error {
+	if pp == nil || pp.N == nil || pp.S == nil || pp.T == nil {
+		return errors.New("pedersen: missing parameters")
+	}
+	if pp.N.Sign() <= 0 || pp.N.Bit(0) == 0 {
+		return errors.New("pedersen: modulus must be odd and positive")
+	}
+	for _, v := range []*big.Int{pp.S, pp.T} {
+		if v.Cmp(one) <= 0 || v.Cmp(pp.N) >= 0 {
+			return errors.New("pedersen: s and t must lie in (1, N~)")
+		}
+		if new(big.Int).GCD(nil, nil, v, pp.N).Cmp(one) != 0 {
+			return errors.New("pedersen: s and t must be units mod N~")
+		}
+	}
+	if pp.S.Cmp(pp.T) == 0 {
+		return errors.New("pedersen: s and t must differ")
+	}
+	if !verifyPedersen(pp.N, pp.S, pp.T, pp.Proof) {
+		return errors.New("pedersen: proof that s is in <t> failed")
+	}
+	return nil
+}
+
+// Commit computes C = s^x * t^r mod N~.
+// x and r may be negative, as is common for the responses of range proofs.
+func (pp *PedersenParams) Commit(x, r *big.Int) (*big.Int, error) {
+	if x == nil || r == nil {
+		return nil, errors.New("pedersen: inputs cannot be nil")
+	}
+	sx := new(big.Int).Exp(pp.S, x, pp.N)
+	tr := new(big.Int).Exp(pp.T, r, pp.N)
+	if sx == nil || tr == nil {
+		return nil, errors.New("pedersen: base is not invertible")
+	}
+	c := sx.Mul(sx, tr)
+	return c.Mod(c, pp.N), nil
+}
+
+// Verify checks that c opens to (x, r).
+func (pp *PedersenParams) Verify(c, x, r *big.Int) bool {
+	if c == nil {
+		return false
+	}
+	expected, err := pp.Commit(x, r)
+	if err != nil {
+		return false
+	}
+	return expected.Cmp(c) == 0
+}
+
+// provePedersen proves knowledge of lambda with s = t^lambda mod n using
+// PedersenProofIterations parallel binary-challenge Schnorr repetitions.
+func provePedersen(n, s, t, lambda, phi *big.Int) (*PedersenProof, error) {
+	m := PedersenProofIterations
+	a := make([]*big.Int, m)
+	A := make([]*big.Int, m)
+	for i := 0; i < m; i++ {
+		ai, err := rand.Int(rand.Reader, phi)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = ai
+		A[i] = new(big.Int).Exp(t, ai, n)
+	}
+
+	e := pedersenChallenge(n, s, t, A)
+
+	Z := make([]*big.Int, m)
+	for i := 0; i < m; i++ {
+		z := new(big.Int).Set(a[i])
+		if e[i] {
+			z.Add(z, lambda)
+		}
+		Z[i] = z.Mod(z, phi)
+	}
+
+	return &PedersenProof{A: A, Z: Z}, nil
+}
+
+// verifyPedersen checks t^{z_i} == A_i * s^{e_i} mod n for all i.
+func verifyPedersen(n, s, t *big.Int, proof *PedersenProof) bool {
+	m := PedersenProofIterations
+	if proof == nil || len(proof.A) != m || len(proof.Z) != m {
+		return false
+	}
+	for i := 0; i < m; i++ {
+		if proof.A[i] == nil || proof.Z[i] == nil {
+			return false
+		}
+		if proof.A[i].Sign() <= 0 || proof.A[i].Cmp(n) >= 0 || proof.Z[i].Sign() < 0 {
+			return false
+		}
+	}
+
+	e := pedersenChallenge(n, s, t, proof.A)
+
+	for i := 0; i < m; i++ {
+		lhs := new(big.Int).Exp(t, proof.Z[i], n)
+		rhs := new(big.Int).Set(proof.A[i])
+		if e[i] {
+			rhs.Mul(rhs, s)
+			rhs.Mod(rhs, n)
+		}
+		if lhs.Cmp(rhs) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pedersenChallenge derives PedersenProofIterations challenge bits from the transcript.
+func pedersenChallenge(n, s, t *big.Int, A []*big.Int) []bool {
+	h := sha256.New()
+	writeField := func(b []byte) {
+		var l [8]byte
+		binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+		h.Write(l[:])
+		h.Write(b)
+	}
+	writeField([]byte(pedersenProofDomain))
+	writeField(n.Bytes())
+	writeField(s.Bytes())
+	writeField(t.Bytes())
+	for _, a := range A {
+		writeField(a.Bytes())
+	}
+	digest := h.Sum(nil)
+
+	bits := make([]bool, PedersenProofIterations)
+	for i := range bits {
+		bits[i] = digest[i/8]>>(uint(i)%8)&1 == 1
+	}
+	return bits
+}
+
+// safePrime returns a prime p = 2q + 1 of the given bit length with q prime.
+func safePrime(bits int) (*big.Int, error) {
+	for {
+		q, err := rand.Prime(rand.Reader, bits-1)
+		if err != nil {
+			return nil, err
+		}
+		p := new(big.Int).Lsh(q, 1)
+		p.Add(p, one)
+		if p.BitLen() == bits && p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// randUnit samples a uniformly random element of Z_n^*.
+func randUnit(n *big.Int) (*big.Int, error) {
+	for {
+		r, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, n).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
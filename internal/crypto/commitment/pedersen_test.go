@@ -0,0 +1,101 @@
+package commitment
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestPedersenParams(t *testing.T) {
+	pp, err := GenPedersenParams(512)
+	if err != nil {
+		t.Fatalf("GenPedersenParams failed: %v", err)
+	}
+	if pp.N.BitLen() != 512 {
+		t.Errorf("Expected 512-bit modulus, got %d", pp.N.BitLen())
+	}
+	if err := pp.Validate(); err != nil {
+		t.Fatalf("Valid parameters rejected: %v", err)
+	}
+
+	// s outside <t>: replacing s breaks the proof
+	bad := *pp
+	bad.S = new(big.Int).Add(pp.S, one)
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted a tampered s")
+	}
+
+	// Degenerate parameters
+	bad = *pp
+	bad.T = big.NewInt(1)
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted t = 1")
+	}
+	bad = *pp
+	bad.S = new(big.Int).Set(pp.T)
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted s = t")
+	}
+	bad = *pp
+	bad.Proof = &PedersenProof{A: pp.Proof.A[:1], Z: pp.Proof.Z[:1]}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted a truncated proof")
+	}
+	bad = *pp
+	bad.Proof = nil
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted a missing proof")
+	}
+}
+
+func TestPedersenCommit(t *testing.T) {
+	pp, err := GenPedersenParams(512)
+	if err != nil {
+		t.Fatalf("GenPedersenParams failed: %v", err)
+	}
+
+	x := big.NewInt(42)
+	r, _ := rand.Int(rand.Reader, pp.N)
+	c, err := pp.Commit(x, r)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Binding: the commitment opens only to (x, r)
+	if !pp.Verify(c, x, r) {
+		t.Fatal("Verify failed for valid opening")
+	}
+	if pp.Verify(c, big.NewInt(43), r) {
+		t.Error("Verify accepted a different x")
+	}
+	if pp.Verify(c, x, new(big.Int).Add(r, one)) {
+		t.Error("Verify accepted a different r")
+	}
+
+	// Hiding: fresh randomness gives a different commitment to the same x
+	r2, _ := rand.Int(rand.Reader, pp.N)
+	c2, err := pp.Commit(x, r2)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if c.Cmp(c2) == 0 {
+		t.Error("Commitments with different randomness are equal")
+	}
+
+	// Homomorphism: C(x1, r1) * C(x2, r2) = C(x1 + x2, r1 + r2)
+	c3, _ := pp.Commit(big.NewInt(8), r2)
+	sum := new(big.Int).Mul(c, c3)
+	sum.Mod(sum, pp.N)
+	if !pp.Verify(sum, big.NewInt(50), new(big.Int).Add(r, r2)) {
+		t.Error("Commitments are not additively homomorphic")
+	}
+
+	// Negative exponents are supported
+	cNeg, err := pp.Commit(big.NewInt(-5), new(big.Int).Neg(r))
+	if err != nil {
+		t.Fatalf("Commit with negative inputs failed: %v", err)
+	}
+	if !pp.Verify(cNeg, big.NewInt(-5), new(big.Int).Neg(r)) {
+		t.Error("Verify failed for negative opening")
+	}
+}
@@ -3,6 +3,7 @@ package curves
 import (
 	"crypto/elliptic"
 	"crypto/rand"
+	"fmt"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
@@ -24,6 +25,47 @@ type Curve interface {
 
 	// Add combines two points
 	Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int)
+
+	// IsOnCurve reports whether (x, y) is a valid, non-identity point
+	IsOnCurve(x, y *big.Int) bool
+
+	// MarshalCompressed encodes a point in SEC 1 compressed form
+	MarshalCompressed(x, y *big.Int) []byte
+
+	// UnmarshalCompressed decodes a SEC 1 compressed point
+	UnmarshalCompressed(b []byte) (*big.Int, *big.Int, error)
+}
+
+// Registered curve names, as used in tss.Parameters.Curve
+const (
+	NameSecp256k1 = "secp256k1"
+	NameP384      = "p384"
+	NameP256      = "secp256r1" // NIST P-256; "p256" is accepted as well
+)
+
+var registry = map[string]func() Curve{
+	NameSecp256k1: NewSecp256k1,
+	NameP384:      NewP384,
+	NameP256:      NewP256,
+	"p256":        NewP256,
+}
+
+// Get returns the curve registered under name.
+// An empty name selects secp256k1 for compatibility with older parameters.
+func Get(name string) (Curve, error) {
+	if name == "" {
+		return NewSecp256k1(), nil
+	}
+	newCurve, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %q", name)
+	}
+	return newCurve(), nil
+}
+
+// ByteSize returns the length in bytes of a field element or scalar of the curve.
+func ByteSize(c Curve) int {
+	return (c.Params().BitSize + 7) / 8
 }
 
 type Secp256k1 struct{}
@@ -54,7 +96,96 @@ func (c *Secp256k1) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
 	return secp256k1.S256().Add(x1, y1, x2, y2)
 }
 
+func (c *Secp256k1) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || (x.Sign() == 0 && y.Sign() == 0) {
+		return false
+	}
+	return secp256k1.S256().IsOnCurve(x, y)
+}
+
+func (c *Secp256k1) MarshalCompressed(x, y *big.Int) []byte {
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(x.Bytes())
+	fy.SetByteSlice(y.Bytes())
+	return secp256k1.NewPublicKey(&fx, &fy).SerializeCompressed()
+}
+
+func (c *Secp256k1) UnmarshalCompressed(b []byte) (*big.Int, *big.Int, error) {
+	pub, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub.X(), pub.Y(), nil
+}
+
 // NewSecp256k1 returns a new instance of the Secp256k1 curve wrapper
 func NewSecp256k1() Curve {
 	return &Secp256k1{}
 }
+
+// nistCurve wraps a NIST curve from the standard library.
+type nistCurve struct {
+	curve elliptic.Curve
+}
+
+func (c *nistCurve) Params() *elliptic.CurveParams {
+	return c.curve.Params()
+}
+
+func (c *nistCurve) NewScalar() (*big.Int, error) {
+	return rand.Int(rand.Reader, c.Params().N)
+}
+
+func (c *nistCurve) ScalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return c.curve.ScalarBaseMult(c.scalarBytes(k))
+}
+
+// ScalarMult computes k * P. P must be on the curve; the standard library
+// panics on invalid points, so callers validate peer input with IsOnCurve first.
+func (c *nistCurve) ScalarMult(Px, Py, k *big.Int) (*big.Int, *big.Int) {
+	return c.curve.ScalarMult(Px, Py, c.scalarBytes(k))
+}
+
+func (c *nistCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return c.curve.Add(x1, y1, x2, y2)
+}
+
+func (c *nistCurve) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return false
+	}
+	return c.curve.IsOnCurve(x, y)
+}
+
+func (c *nistCurve) MarshalCompressed(x, y *big.Int) []byte {
+	return elliptic.MarshalCompressed(c.curve, x, y)
+}
+
+func (c *nistCurve) UnmarshalCompressed(b []byte) (*big.Int, *big.Int, error) {
+	x, y := elliptic.UnmarshalCompressed(c.curve, b)
+	if x == nil {
+		return nil, nil, fmt.Errorf("invalid %s point encoding", c.Params().Name)
+	}
+	return x, y, nil
+}
+
+// scalarBytes reduces k mod N so that negative or oversized scalars are accepted.
+func (c *nistCurve) scalarBytes(k *big.Int) []byte {
+	return new(big.Int).Mod(k, c.Params().N).Bytes()
+}
+
+// P384Curve wraps the NIST P-384 curve from the standard library.
+type P384Curve struct{ nistCurve }
+
+// NewP384 returns a new instance of the P-384 curve wrapper
+func NewP384() Curve {
+	return &P384Curve{nistCurve{elliptic.P384()}}
+}
+
+// P256Curve wraps the NIST P-256 curve (secp256r1) from the standard library.
+type P256Curve struct{ nistCurve }
+
+// NewP256 returns a new instance of the P-256 curve wrapper
+func NewP256() Curve {
+	return &P256Curve{nistCurve{elliptic.P256()}}
+}
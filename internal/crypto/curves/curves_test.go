@@ -0,0 +1,45 @@
+package curves
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	for name, bits := range map[string]int{"": 256, NameSecp256k1: 256, NameP384: 384, NameP256: 256, "p256": 256} {
+		curve, err := Get(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, bits, curve.Params().N.BitLen(), name)
+		assert.Equal(t, bits/8, ByteSize(curve), name)
+	}
+
+	_, err := Get("p521")
+	assert.Error(t, err)
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	for _, curve := range []Curve{NewSecp256k1(), NewP384(), NewP256()} {
+		k, err := curve.NewScalar()
+		require.NoError(t, err)
+		x, y := curve.ScalarBaseMult(k)
+		assert.True(t, curve.IsOnCurve(x, y))
+
+		b := curve.MarshalCompressed(x, y)
+		assert.Len(t, b, ByteSize(curve)+1)
+
+		x2, y2, err := curve.UnmarshalCompressed(b)
+		require.NoError(t, err)
+		assert.Equal(t, 0, x.Cmp(x2))
+		assert.Equal(t, 0, y.Cmp(y2))
+
+		// The identity and off-curve points are rejected
+		assert.False(t, curve.IsOnCurve(big.NewInt(0), big.NewInt(0)))
+		assert.False(t, curve.IsOnCurve(x, new(big.Int).Add(y, big.NewInt(1))))
+
+		_, _, err = curve.UnmarshalCompressed(b[1:])
+		assert.Error(t, err)
+	}
+}
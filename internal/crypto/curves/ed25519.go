@@ -15,7 +15,7 @@ func (c *Ed25519Curve) Name() string {
 
 func (c *Ed25519Curve) Order() *big.Int {
 	// l = 2^252 + 27742317777372353535851937790883648493
-	s, _ := new(big.Int).SetString("72370055773322622139731865630429942408571163593799076060019509382854542509893", 10)
+	s, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
 	return s
 }
 
@@ -38,13 +38,9 @@ func (c *Ed25519Curve) NewScalarFromBigInt(n *big.Int) Scalar {
 	// We need to be careful with endianness. edwards25519 uses little-endian.
 	// big.Int.Bytes() is big-endian.
 	
+	// Reduce first: SetCanonicalBytes only accepts values below the order
+	n = new(big.Int).Mod(n, c.Order())
 	bytes := n.Bytes()
-	// Pad to 32 bytes
-	if len(bytes) > 32 {
-		// Modulo order?
-		n = new(big.Int).Mod(n, c.Order())
-		bytes = n.Bytes()
-	}
 	
 	var buf [32]byte
 	// Reverse bytes for little-endian
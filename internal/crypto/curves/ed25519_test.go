@@ -35,6 +35,21 @@ func TestEd25519Scalar(t *testing.T) {
 	assert.Equal(t, big.NewInt(1), s6.BigInt())
 }
 
+func TestEd25519Order(t *testing.T) {
+	curve := &Ed25519Curve{}
+
+	// l = 2^252 + 27742317777372353535851937790883648493
+	l := new(big.Int).Lsh(big.NewInt(1), 252)
+	c, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	l.Add(l, c)
+	assert.Equal(t, l, curve.Order())
+
+	// Values at or above the order are reduced
+	assert.Equal(t, 0, curve.NewScalarFromBigInt(l).BigInt().Sign())
+	assert.Equal(t, big.NewInt(5), curve.NewScalarFromBigInt(new(big.Int).Add(l, big.NewInt(5))).BigInt())
+	assert.Equal(t, big.NewInt(7), curve.NewScalarFromBigInt(new(big.Int).Add(new(big.Int).Mul(l, big.NewInt(3)), big.NewInt(7))).BigInt())
+}
+
 func TestEd25519Point(t *testing.T) {
 	curve := &Ed25519Curve{}
 	
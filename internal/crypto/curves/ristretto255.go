@@ -0,0 +1,171 @@
+package curves
+
+import (
+	"errors"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// Ristretto255Curve is the prime-order ristretto255 group (RFC 9496) built on
+// Ed25519. Unlike Ed25519 it has no cofactor: every encoding decodes to a
+// single group element, so threshold Schnorr over it needs no small-order or
+// torsion checks.
+//
+// Scalars are the same as for Ed25519 (integers modulo l), so the curve uses
+// *Ed25519Scalar.
+type Ristretto255Curve struct{}
+
+// Field constants from RFC 9496, Section 4.1
+var (
+	ristrettoD              = fieldElementFromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	ristrettoSqrtM1         = fieldElementFromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	ristrettoInvSqrtAMinusD = fieldElementFromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+)
+
+var errInvalidRistrettoEncoding = errors.New("invalid ristretto255 encoding")
+
+func (c *Ristretto255Curve) Name() string {
+	return "ristretto255"
+}
+
+func (c *Ristretto255Curve) Order() *big.Int {
+	return (&Ed25519Curve{}).Order()
+}
+
+func (c *Ristretto255Curve) NewScalar() (Scalar, error) {
+	return (&Ed25519Curve{}).NewScalar()
+}
+
+func (c *Ristretto255Curve) NewScalarFromBigInt(n *big.Int) Scalar {
+	return (&Ed25519Curve{}).NewScalarFromBigInt(n)
+}
+
+func (c *Ristretto255Curve) BasePoint() Point {
+	return &Ristretto255Point{p: edwards25519.NewGeneratorPoint()}
+}
+
+// NewPointFromBytes decodes a 32-byte ristretto255 encoding (RFC 9496,
+// Section 4.3.1). Non-canonical encodings are rejected.
+func (c *Ristretto255Curve) NewPointFromBytes(b []byte) (Point, error) {
+	if len(b) != 32 {
+		return nil, errInvalidRistrettoEncoding
+	}
+	s, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	// s must be canonical and non-negative
+	if string(s.Bytes()) != string(b) || s.IsNegative() == 1 {
+		return nil, errInvalidRistrettoEncoding
+	}
+
+	one := new(field.Element).One()
+	ss := new(field.Element).Square(s)
+	u1 := new(field.Element).Subtract(one, ss)
+	u2 := new(field.Element).Add(one, ss)
+	u2Sqr := new(field.Element).Square(u2)
+
+	// v = -(D * u1^2) - u2^2
+	v := new(field.Element).Square(u1)
+	v.Multiply(v, ristrettoD).Negate(v).Subtract(v, u2Sqr)
+
+	invSqrt, wasSquare := new(field.Element).SqrtRatio(one, new(field.Element).Multiply(v, u2Sqr))
+
+	denX := new(field.Element).Multiply(invSqrt, u2)
+	denY := new(field.Element).Multiply(invSqrt, denX)
+	denY.Multiply(denY, v)
+
+	x := new(field.Element).Multiply(s, denX)
+	x.Add(x, x).Absolute(x)
+	y := new(field.Element).Multiply(u1, denY)
+	t := new(field.Element).Multiply(x, y)
+
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(new(field.Element)) == 1 {
+		return nil, errInvalidRistrettoEncoding
+	}
+	p, err := new(edwards25519.Point).SetExtendedCoordinates(x, y, one, t)
+	if err != nil {
+		return nil, err
+	}
+	return &Ristretto255Point{p: p}, nil
+}
+
+// Ristretto255Point implements Point. It wraps an Edwards point representing
+// its ristretto255 equivalence class.
+type Ristretto255Point struct {
+	p *edwards25519.Point
+}
+
+// Bytes returns the canonical 32-byte encoding (RFC 9496, Section 4.3.2).
+// Equal group elements always have equal encodings.
+func (p *Ristretto255Point) Bytes() []byte {
+	x0, y0, z0, t0 := p.p.ExtendedCoordinates()
+
+	u1 := new(field.Element).Add(z0, y0)
+	u1.Multiply(u1, new(field.Element).Subtract(z0, y0))
+	u2 := new(field.Element).Multiply(x0, y0)
+
+	// Ignore was_square: u1 * u2^2 is always square for a valid point
+	ratio := new(field.Element).Square(u2)
+	ratio.Multiply(ratio, u1)
+	invSqrt, _ := new(field.Element).SqrtRatio(new(field.Element).One(), ratio)
+
+	den1 := new(field.Element).Multiply(invSqrt, u1)
+	den2 := new(field.Element).Multiply(invSqrt, u2)
+	zInv := new(field.Element).Multiply(den1, den2)
+	zInv.Multiply(zInv, t0)
+
+	ix0 := new(field.Element).Multiply(x0, ristrettoSqrtM1)
+	iy0 := new(field.Element).Multiply(y0, ristrettoSqrtM1)
+	enchantedDenominator := new(field.Element).Multiply(den1, ristrettoInvSqrtAMinusD)
+
+	rotate := new(field.Element).Multiply(t0, zInv).IsNegative()
+	x := new(field.Element).Select(iy0, x0, rotate)
+	y := new(field.Element).Select(ix0, y0, rotate)
+	denInv := new(field.Element).Select(enchantedDenominator, den2, rotate)
+
+	negY := new(field.Element).Negate(y)
+	y.Select(negY, y, new(field.Element).Multiply(x, zInv).IsNegative())
+
+	s := new(field.Element).Subtract(z0, y)
+	s.Multiply(s, denInv).Absolute(s)
+	return s.Bytes()
+}
+
+func (p *Ristretto255Point) Add(other Point) Point {
+	o, ok := other.(*Ristretto255Point)
+	if !ok {
+		panic("type mismatch")
+	}
+	res := edwards25519.NewIdentityPoint().Add(p.p, o.p)
+	return &Ristretto255Point{p: res}
+}
+
+func (p *Ristretto255Point) ScalarMult(scalar Scalar) Point {
+	s, ok := scalar.(*Ed25519Scalar)
+	if !ok {
+		panic("type mismatch")
+	}
+	res := edwards25519.NewIdentityPoint().ScalarMult(s.s, p.p)
+	return &Ristretto255Point{p: res}
+}
+
+// fieldElementFromDecimal parses a constant below 2^255-19.
+func fieldElementFromDecimal(s string) *field.Element {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("curves: invalid field constant")
+	}
+	be := n.Bytes()
+	var le [32]byte
+	for i := range be {
+		le[i] = be[len(be)-1-i]
+	}
+	fe, err := new(field.Element).SetBytes(le[:])
+	if err != nil {
+		panic(err)
+	}
+	return fe
+}
@@ -0,0 +1,120 @@
+package curves
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRistretto255Encoding(t *testing.T) {
+	curve := &Ristretto255Curve{}
+	g := curve.BasePoint()
+
+	// RFC 9496, Appendix A.1: encodings of 0*B .. 4*B
+	multiples := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+		"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	}
+	for i, want := range multiples {
+		p := g.ScalarMult(curve.NewScalarFromBigInt(big.NewInt(int64(i))))
+		assert.Equal(t, want, hex.EncodeToString(p.Bytes()), "%d*B", i)
+
+		b, _ := hex.DecodeString(want)
+		decoded, err := curve.NewPointFromBytes(b)
+		require.NoError(t, err, "%d*B", i)
+		assert.Equal(t, b, decoded.Bytes(), "%d*B", i)
+	}
+
+	// Non-canonical and negative field encodings are rejected
+	for _, bad := range []string{
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+	} {
+		b, _ := hex.DecodeString(bad)
+		_, err := curve.NewPointFromBytes(b)
+		assert.Error(t, err, bad)
+	}
+	_, err := curve.NewPointFromBytes(make([]byte, 31))
+	assert.Error(t, err)
+}
+
+func TestRistretto255ThresholdSchnorr(t *testing.T) {
+	curve := &Ristretto255Curve{}
+	l := curve.Order()
+	g := curve.BasePoint()
+	mulG := func(x *big.Int) Point { return g.ScalarMult(curve.NewScalarFromBigInt(x)) }
+	random := func() *big.Int {
+		s, err := curve.NewScalar()
+		require.NoError(t, err)
+		return s.BigInt()
+	}
+
+	// KeyGen: each of 3 parties deals f_j(x) = a_j + c_j*x (threshold 1) and
+	// publishes Feldman commitments A_j = a_j*G, C_j = c_j*G
+	const n = 3
+	shares := make([]*big.Int, n+1) // x_i = sum_j f_j(i), 1-based
+	for i := 1; i <= n; i++ {
+		shares[i] = new(big.Int)
+	}
+	var pub Point
+	for j := 0; j < n; j++ {
+		a, c := random(), random()
+		A, C := mulG(a), mulG(c)
+		for i := 1; i <= n; i++ {
+			f := new(big.Int).Mul(c, big.NewInt(int64(i)))
+			f.Add(f, a).Mod(f, l)
+			// Feldman check: f_j(i)*G == A_j + i*C_j
+			expected := A.Add(C.ScalarMult(curve.NewScalarFromBigInt(big.NewInt(int64(i)))))
+			require.Equal(t, expected.Bytes(), mulG(f).Bytes())
+			shares[i].Add(shares[i], f).Mod(shares[i], l)
+		}
+		if pub == nil {
+			pub = A
+		} else {
+			pub = pub.Add(A)
+		}
+	}
+
+	// Sign with parties 1 and 3: lambda_1 = 3/2, lambda_3 = -1/2
+	signers := []int{1, 3}
+	inv2 := new(big.Int).ModInverse(big.NewInt(2), l)
+	lambdas := []*big.Int{
+		new(big.Int).Mod(new(big.Int).Mul(big.NewInt(3), inv2), l),
+		new(big.Int).Mod(new(big.Int).Neg(inv2), l),
+	}
+	nonces := []*big.Int{random(), random()}
+	R := mulG(nonces[0]).Add(mulG(nonces[1]))
+
+	challenge := func(R, pub Point, msg []byte) *big.Int {
+		h := sha512.New()
+		h.Write(R.Bytes())
+		h.Write(pub.Bytes())
+		h.Write(msg)
+		return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), l)
+	}
+	msg := []byte("ristretto255 threshold schnorr")
+	e := challenge(R, pub, msg)
+
+	// s = sum r_i + e*lambda_i*x_i
+	s := new(big.Int)
+	for i, id := range signers {
+		part := new(big.Int).Mul(e, lambdas[i])
+		part.Mul(part, shares[id]).Add(part, nonces[i])
+		s.Add(s, part).Mod(s, l)
+	}
+
+	// Verify the aggregate: s*G == R + e*X
+	lhs := mulG(s)
+	rhs := R.Add(pub.ScalarMult(curve.NewScalarFromBigInt(e)))
+	assert.Equal(t, rhs.Bytes(), lhs.Bytes())
+
+	e2 := challenge(R, pub, []byte("another message"))
+	assert.NotEqual(t, R.Add(pub.ScalarMult(curve.NewScalarFromBigInt(e2))).Bytes(), lhs.Bytes())
+}
@@ -0,0 +1,139 @@
+// Package keccak implements the Keccak-256 hash function as used by Ethereum.
+//
+// This is the original Keccak submission, which pads with 0x01 instead of
+// the 0x06 of the final SHA3-256 standard, so the two give different
+// digests. It is only used for Ethereum addresses and must not be used as a
+// general-purpose hash.
+package keccak
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// Size is the size of a Keccak-256 checksum in bytes.
+const Size = 32
+
+// BlockSize is the rate of Keccak-256 in bytes: 1600 - 2*256 bits.
+const BlockSize = 136
+
+var (
+	// Round constants of Keccak-f[1600]
+	rc = [24]uint64{
+		0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+		0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+		0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+		0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+		0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+		0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+	}
+
+	// Rotation offsets of the rho step, indexed by lane x + 5*y
+	rotations = [25]int{
+		0, 1, 62, 28, 27,
+		36, 44, 6, 55, 20,
+		3, 10, 43, 25, 39,
+		41, 45, 15, 21, 8,
+		18, 2, 61, 56, 14,
+	}
+)
+
+type digest struct {
+	a   [25]uint64 // State, lane x + 5*y
+	buf [BlockSize]byte
+	n   int // Bytes buffered in buf
+}
+
+// New returns a new hash.Hash computing the Keccak-256 checksum.
+func New() hash.Hash {
+	return new(digest)
+}
+
+// Sum256 returns the Keccak-256 checksum of data.
+func Sum256(data []byte) [Size]byte {
+	var d digest
+	d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+func (d *digest) Reset() {
+	*d = digest{}
+}
+
+func (d *digest) Size() int      { return Size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		k := copy(d.buf[d.n:], p)
+		d.n += k
+		p = p[k:]
+		if d.n == BlockSize {
+			d.absorb()
+			d.n = 0
+		}
+	}
+	return written, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// Pad a copy so the caller can keep writing
+	c := *d
+	clear(c.buf[c.n:])
+	c.buf[c.n] ^= 0x01
+	c.buf[BlockSize-1] ^= 0x80
+	c.absorb()
+
+	var out [Size]byte
+	for i := 0; i < Size/8; i++ {
+		binary.LittleEndian.PutUint64(out[i*8:], c.a[i])
+	}
+	return append(in, out[:]...)
+}
+
+// absorb XORs the buffered block into the state and permutes it.
+func (d *digest) absorb() {
+	for i := 0; i < BlockSize/8; i++ {
+		d.a[i] ^= binary.LittleEndian.Uint64(d.buf[i*8:])
+	}
+	keccakF(&d.a)
+}
+
+// keccakF applies the 24 rounds of Keccak-f[1600] to the state a.
+func keccakF(a *[25]uint64) {
+	var c, d [5]uint64
+	var b [25]uint64
+	for round := 0; round < 24; round++ {
+		// Theta
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for i := 0; i < 25; i++ {
+			a[i] ^= d[i%5]
+		}
+
+		// Rho and pi: lane (x, y) moves to (y, 2x + 3y)
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], rotations[x+5*y])
+			}
+		}
+
+		// Chi
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[y+x] = b[y+x] ^ (^b[y+(x+1)%5] & b[y+(x+2)%5])
+			}
+		}
+
+		// Iota
+		a[0] ^= rc[round]
+	}
+}
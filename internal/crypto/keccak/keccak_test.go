@@ -0,0 +1,48 @@
+package keccak
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+	// Keccak-256 digests as computed by Ethereum clients
+	vectors := []struct {
+		in, out string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{"hello world", "47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad"},
+	}
+	for _, v := range vectors {
+		sum := Sum256([]byte(v.in))
+		if got := hex.EncodeToString(sum[:]); got != v.out {
+			t.Errorf("Keccak256(%q) = %s, want %s", v.in, got, v.out)
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	// Inputs around the block size exercise the padding edge cases
+	for _, n := range []int{0, 1, BlockSize - 1, BlockSize, BlockSize + 1, 3*BlockSize + 5} {
+		in := strings.Repeat("x", n)
+		want := Sum256([]byte(in))
+
+		h := New()
+		for i := 0; i < len(in); i += 7 {
+			end := i + 7
+			if end > len(in) {
+				end = len(in)
+			}
+			h.Write([]byte(in[i:end]))
+		}
+		if got := h.Sum(nil); hex.EncodeToString(got) != hex.EncodeToString(want[:]) {
+			t.Errorf("streamed Keccak256 of %d bytes = %x, want %x", n, got, want)
+		}
+		// Sum does not change the state
+		if got := h.Sum(nil); hex.EncodeToString(got) != hex.EncodeToString(want[:]) {
+			t.Errorf("second Sum of %d bytes differs", n)
+		}
+	}
+}
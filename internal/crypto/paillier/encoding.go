@@ -0,0 +1,84 @@
+package paillier
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Bytes encodes the public key as a length-prefixed field holding N: a
+// 4-byte big-endian length followed by N in big-endian.
+func (pk *PublicKey) Bytes() []byte {
+	return appendInt(nil, pk.N)
+}
+
+// ParsePublicKey decodes a public key encoded by PublicKey.Bytes, with n^2
+// precomputed.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	ints, err := readInts(data, 1)
+	if err != nil {
+		return nil, err
+	}
+	n := ints[0]
+	if n.Cmp(one) <= 0 || n.Bit(0) == 0 {
+		return nil, errors.New("paillier: modulus must be odd and greater than 1")
+	}
+	return NewPublicKey(n), nil
+}
+
+// Bytes encodes the private key as the length-prefixed fields N, Lambda and
+// Mu, each as in PublicKey.Bytes. The encoding holds the secret key.
+func (priv *PrivateKey) Bytes() []byte {
+	buf := appendInt(nil, priv.N)
+	buf = appendInt(buf, priv.Lambda)
+	return appendInt(buf, priv.Mu)
+}
+
+// ParsePrivateKey decodes a private key encoded by PrivateKey.Bytes and
+// checks it with Validate.
+func ParsePrivateKey(data []byte) (*PrivateKey, error) {
+	ints, err := readInts(data, 3)
+	if err != nil {
+		return nil, err
+	}
+	priv := &PrivateKey{
+		PublicKey: *NewPublicKey(ints[0]),
+		Lambda:    ints[1],
+		Mu:        ints[2],
+	}
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+func appendInt(buf []byte, x *big.Int) []byte {
+	var b []byte
+	if x != nil {
+		b = x.Bytes()
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
+
+// readInts reads exactly count length-prefixed integers from data.
+func readInts(data []byte, count int) ([]*big.Int, error) {
+	ints := make([]*big.Int, count)
+	for i := range ints {
+		if len(data) < 4 {
+			return nil, errors.New("paillier: truncated key encoding")
+		}
+		n := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(n) > uint64(len(data)) {
+			return nil, fmt.Errorf("paillier: field length %d exceeds remaining %d bytes", n, len(data))
+		}
+		ints[i] = new(big.Int).SetBytes(data[:n])
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("paillier: %d trailing bytes in key encoding", len(data))
+	}
+	return ints, nil
+}
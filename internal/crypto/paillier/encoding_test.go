@@ -0,0 +1,81 @@
+package paillier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestKeyEncodingRoundTrip(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	msg := big.NewInt(987654321)
+	c, _, err := priv.Encrypt(msg)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	// Decrypt with a reloaded private key
+	reloaded, err := ParsePrivateKey(priv.Bytes())
+	if err != nil {
+		t.Fatalf("ParsePrivateKey failed: %v", err)
+	}
+	got, err := reloaded.Decrypt(c)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if got.Cmp(msg) != 0 {
+		t.Fatalf("Expected %s after reload, got %s", msg, got)
+	}
+	if !bytes.Equal(reloaded.Bytes(), priv.Bytes()) {
+		t.Fatal("Private key encoding did not round-trip")
+	}
+
+	// Encrypt with a reloaded public key
+	pk, err := ParsePublicKey(priv.PublicKey.Bytes())
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+	if pk.N.Cmp(priv.N) != 0 || pk.N2 == nil {
+		t.Fatal("Public key did not round-trip with n^2 precomputed")
+	}
+	c, _, err = pk.Encrypt(msg)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if got, err := priv.Decrypt(c); err != nil || got.Cmp(msg) != 0 {
+		t.Fatalf("Expected %s, got %v, %v", msg, got, err)
+	}
+}
+
+func TestKeyEncodingMalformed(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	data := priv.Bytes()
+
+	if _, err := ParsePrivateKey(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for a truncated private key")
+	}
+	if _, err := ParsePrivateKey(append(data, 0)); err == nil {
+		t.Error("Expected an error for trailing bytes")
+	}
+	tampered := &PrivateKey{PublicKey: priv.PublicKey, Lambda: new(big.Int).Add(priv.Lambda, one), Mu: priv.Mu}
+	if _, err := ParsePrivateKey(tampered.Bytes()); err == nil {
+		t.Error("Expected an error for an inconsistent private key")
+	}
+	if _, err := ParsePublicKey(data); err == nil {
+		t.Error("Expected an error for a private key parsed as public")
+	}
+	if _, err := ParsePublicKey(NewPublicKey(big.NewInt(10)).Bytes()); err == nil {
+		t.Error("Expected an error for an even modulus")
+	}
+	if _, err := ParsePublicKey(nil); err == nil {
+		t.Error("Expected an error for empty input")
+	}
+}
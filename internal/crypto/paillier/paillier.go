@@ -12,6 +12,10 @@ var (
 	one = big.NewInt(1)
 )
 
+// KeyBits is the modulus size of the Paillier keys generated by KeyGen,
+// Refresh and Reshare.
+const KeyBits = 2048
+
 // PublicKey represents a Paillier public key (n).
 type PublicKey struct {
 	N    *big.Int // Modulus n = p * q
@@ -25,32 +29,57 @@ type PrivateKey struct {
 	Mu     *big.Int // modular multiplicative inverse of lambda mod n
 }
 
+// NewPublicKey returns the public key with modulus n, with n^2 precomputed.
+func NewPublicKey(n *big.Int) *PublicKey {
+	pk := &PublicKey{N: n}
+	pk.Precompute()
+	return pk
+}
+
+// Precompute memoizes n^2, so that homomorphic operations on a key that was
+// built or decoded from N alone do not recompute it every time.
+// It modifies the key, so call it before sharing the key between goroutines.
+func (pk *PublicKey) Precompute() {
+	if pk.N != nil && pk.N2 == nil {
+		pk.N2 = new(big.Int).Mul(pk.N, pk.N)
+	}
+}
+
+// n2 returns n^2, computing it if the key was not precomputed.
+func (pk *PublicKey) n2() *big.Int {
+	if pk.N2 != nil {
+		return pk.N2
+	}
+	return new(big.Int).Mul(pk.N, pk.N)
+}
+
 // GenerateKey generates a Paillier key pair with the given bit length for the modulus n.
 // bits must be at least 1024.
 func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
+	return GenerateKeyWithConcurrency(random, bits, 2)
+}
+
+// GenerateKeyWithConcurrency is GenerateKey using at most maxConcurrency
+// goroutines. The primes are searched for concurrently only if maxConcurrency
+// is at least 2 and random is crypto/rand.Reader.
+func GenerateKeyWithConcurrency(random io.Reader, bits, maxConcurrency int) (*PrivateKey, error) {
 	if bits < 1024 {
 		return nil, errors.New("paillier: bits must be at least 1024")
 	}
-
-	// 1. Choose two large prime numbers p and q
-	p, err := rand.Prime(random, bits/2)
-	if err != nil {
-		return nil, err
+	if sk, ok, err := testKey(bits); ok {
+		return sk, err
 	}
 
-	q, err := rand.Prime(random, bits/2)
+	// 1. Choose two large prime numbers p and q
+	p, q, err := generatePrimes(random, bits/2, maxConcurrency >= 2 && random == rand.Reader)
 	if err != nil {
 		return nil, err
 	}
+	return newPrivateKey(p, q)
+}
 
-	// Ensure p != q
-	for p.Cmp(q) == 0 {
-		q, err = rand.Prime(random, bits/2)
-		if err != nil {
-			return nil, err
-		}
-	}
-
+// newPrivateKey returns the key pair for the distinct primes p and q.
+func newPrivateKey(p, q *big.Int) (*PrivateKey, error) {
 	// 2. Compute n = p * q
 	n := new(big.Int).Mul(p, q)
 	n2 := new(big.Int).Mul(n, n)
@@ -79,6 +108,53 @@ func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
 	}, nil
 }
 
+// generatePrimes returns two distinct random primes of the given bit length.
+// With parallel set, p and q are searched for concurrently, which roughly
+// halves the wall-clock time. Only do so for a reader that is safe for
+// concurrent use, such as crypto/rand.Reader: other readers are only ever read
+// from the calling goroutine, one prime after the other.
+func generatePrimes(random io.Reader, bits int, parallel bool) (*big.Int, *big.Int, error) {
+	var p, q *big.Int
+	if parallel {
+		type result struct {
+			prime *big.Int
+			err   error
+		}
+		results := make(chan result, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				prime, err := rand.Prime(random, bits)
+				results <- result{prime, err}
+			}()
+		}
+		first, second := <-results, <-results
+		if first.err != nil {
+			return nil, nil, first.err
+		}
+		if second.err != nil {
+			return nil, nil, second.err
+		}
+		p, q = first.prime, second.prime
+	} else {
+		var err error
+		if p, err = rand.Prime(random, bits); err != nil {
+			return nil, nil, err
+		}
+		if q, err = rand.Prime(random, bits); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Ensure p != q
+	for p.Cmp(q) == 0 {
+		var err error
+		if q, err = rand.Prime(random, bits); err != nil {
+			return nil, nil, err
+		}
+	}
+	return p, q, nil
+}
+
 // Encrypt encrypts a plaintext message m into a ciphertext c.
 // m must be in the range [0, n).
 func (pk *PublicKey) Encrypt(m *big.Int) (*big.Int, *big.Int, error) {
@@ -98,21 +174,7 @@ func (pk *PublicKey) Encrypt(m *big.Int) (*big.Int, *big.Int, error) {
 		r = big.NewInt(1) 
 	}
 
-	// c = (1 + n*m) * r^n mod n^2
-	// Optimization: (1 + n*m) mod n^2 is just (1 + n*m) since m < n
-	
-	// gm = 1 + n*m
-	gm := new(big.Int).Mul(pk.N, m)
-	gm.Add(gm, one)
-	
-	// rn = r^n mod n^2
-	rn := new(big.Int).Exp(r, pk.N, pk.N2)
-
-	// c = gm * rn mod n^2
-	c := new(big.Int).Mul(gm, rn)
-	c.Mod(c, pk.N2)
-
-	return c, r, nil
+	return pk.encrypt(m, r), r, nil
 }
 
 // EncryptWithR encrypts a plaintext message m using a specific randomness r.
@@ -122,23 +184,34 @@ func (pk *PublicKey) EncryptWithR(m, r *big.Int) (*big.Int, error) {
 		return nil, errors.New("paillier: message m must be in range [0, n)")
 	}
 
+	return pk.encrypt(m, r), nil
+}
+
+// encrypt computes c = (1 + n*m) * r^n mod n^2 for m in [0, n).
+// (1 + n*m) needs no reduction since m < n.
+func (pk *PublicKey) encrypt(m, r *big.Int) *big.Int {
 	// gm = 1 + n*m
-	gm := new(big.Int).Mul(pk.N, m)
+	gm := getInt()
+	defer putInt(gm)
+	gm.Mul(pk.N, m)
 	gm.Add(gm, one)
 
 	// rn = r^n mod n^2
-	rn := new(big.Int).Exp(r, pk.N, pk.N2)
+	rn := getInt()
+	defer putInt(rn)
+	rn.Exp(r, pk.N, pk.n2())
 
 	// c = gm * rn mod n^2
-	c := new(big.Int).Mul(gm, rn)
-	c.Mod(c, pk.N2)
-
-	return c, nil
+	prod := getInt()
+	defer putInt(prod)
+	prod.Mul(gm, rn)
+	return new(big.Int).Mod(prod, pk.n2())
 }
 
 // Decrypt decrypts a ciphertext c into a plaintext message m.
 func (priv *PrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
-	if c.Sign() == -1 || c.Cmp(priv.N2) >= 0 {
+	n2 := priv.n2()
+	if c.Sign() == -1 || c.Cmp(n2) >= 0 {
 		return nil, errors.New("paillier: ciphertext c must be in range [0, n^2)")
 	}
 
@@ -146,33 +219,84 @@ func (priv *PrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
 	// where L(x) = (x-1)/n
 
 	// u = c^lambda mod n^2
-	u := new(big.Int).Exp(c, priv.Lambda, priv.N2)
+	u := getInt()
+	defer putInt(u)
+	u.Exp(c, priv.Lambda, n2)
 
 	// L(u) = (u - 1) / n
-	l := new(big.Int).Sub(u, one)
+	l := getInt()
+	defer putInt(l)
+	l.Sub(u, one)
 	l.Div(l, priv.N)
 
 	// m = l * mu mod n
-	m := new(big.Int).Mul(l, priv.Mu)
-	m.Mod(m, priv.N)
+	prod := getInt()
+	defer putInt(prod)
+	prod.Mul(l, priv.Mu)
+	return new(big.Int).Mod(prod, priv.N), nil
+}
+
+// DecryptBlinded decrypts c like Decrypt, but first multiplies c by a fresh
+// encryption of zero, r^n mod n^2. That leaves the plaintext unchanged and
+// makes the base of c^lambda independent of the caller's ciphertext, so an
+// adversary cannot pick bases that make its timing informative. It is base
+// blinding only: big.Int.Exp is not constant-time and lambda is not
+// blinded, so the exponentiation still takes time that depends on lambda.
+// Use it for ciphertexts an adversary may choose.
+func (priv *PrivateKey) DecryptBlinded(c *big.Int) (*big.Int, error) {
+	n2 := priv.n2()
+	if c.Sign() == -1 || c.Cmp(n2) >= 0 {
+		return nil, errors.New("paillier: ciphertext c must be in range [0, n^2)")
+	}
+
+	r, err := rand.Int(rand.Reader, priv.N)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sign() == 0 {
+		r.SetInt64(1)
+	}
 
-	return m, nil
+	// c' = c * r^n mod n^2
+	blind := getInt()
+	defer putInt(blind)
+	blind.Exp(r, priv.N, n2)
+	blind.Mul(blind, c)
+	blind.Mod(blind, n2)
+	return priv.Decrypt(blind)
+}
+
+// Nonce recovers the randomness r of a ciphertext c = (1 + n*m) * r^n mod
+// n^2, so the holder of the key can prove statements about ciphertexts it
+// did not encrypt itself. c mod n = r^n mod n, and n is invertible modulo
+// lambda, so r = (c mod n)^(n^-1 mod lambda) mod n.
+func (priv *PrivateKey) Nonce(c *big.Int) (*big.Int, error) {
+	if c.Sign() == -1 || c.Cmp(priv.n2()) >= 0 {
+		return nil, errors.New("paillier: ciphertext c must be in range [0, n^2)")
+	}
+	e := new(big.Int).ModInverse(priv.N, priv.Lambda)
+	if e == nil {
+		return nil, errors.New("paillier: n is not invertible modulo lambda")
+	}
+	rn := new(big.Int).Mod(c, priv.N)
+	return rn.Exp(rn, e, priv.N), nil
 }
 
 // Add performs homomorphic addition of two ciphertexts.
 // E(m1) + E(m2) = E(m1 + m2)
 // c = c1 * c2 mod n^2
 func (pk *PublicKey) Add(c1, c2 *big.Int) *big.Int {
-	c := new(big.Int).Mul(c1, c2)
-	c.Mod(c, pk.N2)
-	return c
+	prod := getInt()
+	defer putInt(prod)
+	prod.Mul(c1, c2)
+	return new(big.Int).Mod(prod, pk.n2())
 }
 
 // Mul performs homomorphic multiplication of a ciphertext by a scalar.
 // E(m) * k = E(m * k)
 // c = c1^k mod n^2
 func (pk *PublicKey) Mul(c1, k *big.Int) *big.Int {
-	c := new(big.Int).Exp(c1, k, pk.N2)
+	c := new(big.Int).Exp(c1, k, pk.n2())
 	return c
 }
 
@@ -183,25 +307,63 @@ func (pk *PublicKey) EncryptWithNonce(m, r *big.Int) (*big.Int, error) {
 		return nil, errors.New("paillier: message m must be in range [0, n)")
 	}
 	
-	// c = (1 + n*m) * r^n mod n^2
-	
-	gm := new(big.Int).Mul(pk.N, m)
-	gm.Add(gm, one)
-	
-	rn := new(big.Int).Exp(r, pk.N, pk.N2)
-
-	c := new(big.Int).Mul(gm, rn)
-	c.Mod(c, pk.N2)
-
-	return c, nil
+	return pk.encrypt(m, r), nil
 }
 
-// ValidateCiphertext checks if a ciphertext is valid (in range [0, n^2) and coprime to n^2).
+// ValidateCiphertext checks that a ciphertext is in range [0, n^2).
 // Note: Checking coprimality is expensive and usually not strictly required if inputs are trusted or ZKPs are used.
-// Here we just check the range.
+// Use ValidateCiphertextStrict for ciphertexts from untrusted parties.
 func (pk *PublicKey) ValidateCiphertext(c *big.Int) error {
-	if c.Sign() == -1 || c.Cmp(pk.N2) >= 0 {
+	if c.Sign() == -1 || c.Cmp(pk.n2()) >= 0 {
 		return fmt.Errorf("paillier: ciphertext out of range")
 	}
 	return nil
 }
+
+// ValidateCiphertextStrict checks that a ciphertext is in range [0, n^2) and
+// coprime to n, i.e. a unit mod n^2 as every honestly computed ciphertext is.
+// A ciphertext sharing a factor with n decrypts to garbage.
+func (pk *PublicKey) ValidateCiphertextStrict(c *big.Int) error {
+	if err := pk.ValidateCiphertext(c); err != nil {
+		return err
+	}
+	if new(big.Int).GCD(nil, nil, c, pk.N).Cmp(one) != 0 {
+		return fmt.Errorf("paillier: ciphertext not coprime to n")
+	}
+	return nil
+}
+
+// Validate checks that the private key is internally consistent: n^2 is
+// cached correctly, lambda * mu = 1 mod n, and a random message survives an
+// encrypt/decrypt round trip.
+func (priv *PrivateKey) Validate() error {
+	if priv == nil || priv.N == nil || priv.N2 == nil || priv.Lambda == nil || priv.Mu == nil {
+		return errors.New("paillier: incomplete private key")
+	}
+	if priv.N.Cmp(one) <= 0 || priv.N.Bit(0) == 0 {
+		return errors.New("paillier: modulus must be odd and greater than 1")
+	}
+	if new(big.Int).Mul(priv.N, priv.N).Cmp(priv.N2) != 0 {
+		return errors.New("paillier: cached n^2 does not match n")
+	}
+	if new(big.Int).Mod(new(big.Int).Mul(priv.Lambda, priv.Mu), priv.N).Cmp(one) != 0 {
+		return errors.New("paillier: lambda * mu != 1 mod n")
+	}
+
+	m, err := rand.Int(rand.Reader, priv.N)
+	if err != nil {
+		return err
+	}
+	c, _, err := priv.PublicKey.Encrypt(m)
+	if err != nil {
+		return err
+	}
+	got, err := priv.Decrypt(c)
+	if err != nil {
+		return err
+	}
+	if got.Cmp(m) != 0 {
+		return errors.New("paillier: decryption does not invert encryption")
+	}
+	return nil
+}
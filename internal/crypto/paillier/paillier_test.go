@@ -3,6 +3,8 @@ package paillier
 import (
 	"crypto/rand"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -42,6 +44,33 @@ func TestEncryptDecrypt(t *testing.T) {
 	}
 }
 
+func TestDecryptBlinded(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	msg := big.NewInt(123456789)
+	c, _, err := priv.Encrypt(msg)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	// Every call draws a new blind; all of them decrypt to the same plaintext
+	for i := 0; i < 10; i++ {
+		decrypted, err := priv.DecryptBlinded(c)
+		if err != nil {
+			t.Fatalf("DecryptBlinded failed: %v", err)
+		}
+		if msg.Cmp(decrypted) != 0 {
+			t.Fatalf("Blinded decryption %d: expected %s, got %s", i, msg, decrypted)
+		}
+	}
+
+	if _, err := priv.DecryptBlinded(priv.N2); err == nil {
+		t.Fatal("Out-of-range ciphertext decrypted")
+	}
+}
+
 func TestHomomorphicAdd(t *testing.T) {
 	priv, err := GenerateKey(rand.Reader, 1024)
 	if err != nil {
@@ -114,3 +143,342 @@ func TestEncryptWithNonce(t *testing.T) {
 		t.Errorf("Decryption failed. Expected %s, got %s", msg, decrypted)
 	}
 }
+
+func TestNonce(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	c, r, err := priv.Encrypt(big.NewInt(999))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	got, err := priv.Nonce(c)
+	if err != nil {
+		t.Fatalf("Nonce failed: %v", err)
+	}
+	if got.Cmp(r) != 0 {
+		t.Errorf("Nonce = %s, want %s", got, r)
+	}
+
+	// The randomness of a homomorphic sum is the product of the nonces
+	c2, r2, _ := priv.Encrypt(big.NewInt(1))
+	got, err = priv.Nonce(priv.Add(c, c2))
+	if err != nil {
+		t.Fatalf("Nonce failed: %v", err)
+	}
+	want := new(big.Int).Mul(r, r2)
+	if got.Cmp(want.Mod(want, priv.N)) != 0 {
+		t.Errorf("Nonce of sum = %s, want %s", got, want)
+	}
+
+	if _, err := priv.Nonce(priv.N2); err == nil {
+		t.Error("Nonce accepted an out-of-range ciphertext")
+	}
+}
+
+// Reference implementations without pooled scratch values.
+func naiveEncrypt(pk *PublicKey, m, r *big.Int) *big.Int {
+	gm := new(big.Int).Mul(pk.N, m)
+	gm.Add(gm, one)
+	rn := new(big.Int).Exp(r, pk.N, pk.N2)
+	c := new(big.Int).Mul(gm, rn)
+	return c.Mod(c, pk.N2)
+}
+
+func naiveAdd(pk *PublicKey, c1, c2 *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1, c2)
+	return c.Mod(c, pk.N2)
+}
+
+func naiveDecrypt(priv *PrivateKey, c *big.Int) *big.Int {
+	u := new(big.Int).Exp(c, priv.Lambda, priv.N2)
+	l := new(big.Int).Sub(u, one)
+	l.Div(l, priv.N)
+	m := new(big.Int).Mul(l, priv.Mu)
+	return m.Mod(m, priv.N)
+}
+
+func TestPooledMatchesReference(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	pk := &priv.PublicKey
+
+	// Run concurrently so scratch values are shared between goroutines
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				m1, _ := rand.Int(rand.Reader, pk.N)
+				m2, _ := rand.Int(rand.Reader, pk.N)
+				r, _ := rand.Int(rand.Reader, pk.N)
+				r.Add(r, one)
+
+				c1, err := pk.EncryptWithR(m1, r)
+				if err != nil || c1.Cmp(naiveEncrypt(pk, m1, r)) != 0 {
+					errs <- "EncryptWithR differs from reference"
+					return
+				}
+				c2, _, err := pk.Encrypt(m2)
+				if err != nil {
+					errs <- err.Error()
+					return
+				}
+				sum := pk.Add(c1, c2)
+				if sum.Cmp(naiveAdd(pk, c1, c2)) != 0 {
+					errs <- "Add differs from reference"
+					return
+				}
+				got, err := priv.Decrypt(sum)
+				if err != nil || got.Cmp(naiveDecrypt(priv, sum)) != 0 {
+					errs <- "Decrypt differs from reference"
+					return
+				}
+				want := new(big.Int).Add(m1, m2)
+				want.Mod(want, pk.N)
+				if got.Cmp(want) != 0 {
+					errs <- "Decrypt(c1 + c2) != m1 + m2"
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Fatal(e)
+	}
+}
+
+// benchmarkMtA runs the Paillier part of one MtA instance as in sign round 2
+// and 3: the peer encrypts beta, multiplies our ciphertext by its share and
+// adds, then we decrypt.
+func benchmarkMtA(b *testing.B, pooled bool) {
+	priv, err := GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		b.Fatalf("GenerateKey failed: %v", err)
+	}
+	pk := &priv.PublicKey
+	k, _ := rand.Int(rand.Reader, pk.N)
+	gamma, _ := rand.Int(rand.Reader, pk.N)
+	beta, _ := rand.Int(rand.Reader, pk.N)
+	r, _ := rand.Int(rand.Reader, pk.N)
+	r.Add(r, one)
+	encK, _ := pk.EncryptWithR(k, r)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if pooled {
+			encBeta, _ := pk.EncryptWithR(beta, r)
+			c := pk.Add(pk.Mul(encK, gamma), encBeta)
+			if _, err := priv.Decrypt(c); err != nil {
+				b.Fatal(err)
+			}
+		} else {
+			encBeta := naiveEncrypt(pk, beta, r)
+			c := naiveAdd(pk, pk.Mul(encK, gamma), encBeta)
+			naiveDecrypt(priv, c)
+		}
+	}
+}
+
+func BenchmarkMtAPooled(b *testing.B)   { benchmarkMtA(b, true) }
+func BenchmarkMtAUnpooled(b *testing.B) { benchmarkMtA(b, false) }
+
+func TestValidate(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := priv.Validate(); err != nil {
+		t.Fatalf("Valid key rejected: %v", err)
+	}
+
+	bad := *priv
+	bad.Mu = new(big.Int).Add(priv.Mu, big.NewInt(1))
+	if err := bad.Validate(); err == nil {
+		t.Fatal("Key with corrupted mu accepted")
+	}
+	bad = *priv
+	bad.N2 = new(big.Int).Add(priv.N2, big.NewInt(1))
+	if err := bad.Validate(); err == nil {
+		t.Fatal("Key with corrupted n^2 accepted")
+	}
+}
+
+func TestValidateCiphertextStrict(t *testing.T) {
+	p, q, err := generatePrimes(rand.Reader, 512, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := NewPublicKey(new(big.Int).Mul(p, q))
+
+	c, _, err := pk.Encrypt(big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pk.ValidateCiphertextStrict(c); err != nil {
+		t.Fatalf("Honest ciphertext rejected: %v", err)
+	}
+
+	// A ciphertext with the factor p is in range, so only strict validation
+	// catches it
+	bad := new(big.Int).Mod(new(big.Int).Mul(c, p), pk.N2)
+	if err := pk.ValidateCiphertext(bad); err != nil {
+		t.Fatalf("Expected the range check to pass, got %v", err)
+	}
+	if err := pk.ValidateCiphertextStrict(bad); err == nil {
+		t.Fatal("Ciphertext sharing a factor with n accepted")
+	}
+	if err := pk.ValidateCiphertextStrict(big.NewInt(0)); err == nil {
+		t.Fatal("Zero ciphertext accepted")
+	}
+	if err := pk.ValidateCiphertextStrict(pk.N2); err == nil {
+		t.Fatal("Out-of-range ciphertext accepted")
+	}
+}
+
+// serialReader wraps crypto/rand.Reader and fails the test if it is read
+// from two goroutines at once.
+type serialReader struct {
+	t      *testing.T
+	active int32
+}
+
+func (r *serialReader) Read(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&r.active, 0, 1) {
+		r.t.Error("reader used concurrently")
+	}
+	defer atomic.StoreInt32(&r.active, 0)
+	return rand.Reader.Read(p)
+}
+
+func TestGenerateKeyParallel(t *testing.T) {
+	p, q, err := generatePrimes(rand.Reader, 512, true)
+	if err != nil {
+		t.Fatalf("generatePrimes failed: %v", err)
+	}
+	if p.Cmp(q) == 0 || !p.ProbablyPrime(20) || !q.ProbablyPrime(20) || p.BitLen() != 512 || q.BitLen() != 512 {
+		t.Fatal("Expected two distinct 512-bit primes")
+	}
+
+	// GenerateKey searches in parallel with crypto/rand.Reader
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	if err := priv.Validate(); err != nil {
+		t.Fatalf("Parallel-generated key is invalid: %v", err)
+	}
+	msg := big.NewInt(987654321)
+	c, _, err := priv.Encrypt(msg)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	decrypted, err := priv.Decrypt(c)
+	if err != nil || decrypted.Cmp(msg) != 0 {
+		t.Fatalf("Decrypt returned %v, %v; want %v", decrypted, err, msg)
+	}
+}
+
+func TestGenerateKeyWithConcurrency(t *testing.T) {
+	// With a single goroutine the primes are found one after the other
+	priv, err := GenerateKeyWithConcurrency(rand.Reader, 1024, 1)
+	if err != nil {
+		t.Fatalf("GenerateKeyWithConcurrency failed: %v", err)
+	}
+	if err := priv.Validate(); err != nil {
+		t.Fatalf("Generated key is invalid: %v", err)
+	}
+}
+
+func TestGenerateKeyCustomReader(t *testing.T) {
+	// A reader other than crypto/rand.Reader is never shared between goroutines
+	priv, err := GenerateKey(&serialReader{t: t}, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	if err := priv.Validate(); err != nil {
+		t.Fatalf("Generated key is invalid: %v", err)
+	}
+}
+
+func benchmarkGeneratePrimes(b *testing.B, parallel bool) {
+	for i := 0; i < b.N; i++ {
+		// Primes for a 2048-bit modulus, as in keygen
+		if _, _, err := generatePrimes(rand.Reader, 1024, parallel); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGeneratePrimesSequential(b *testing.B) { benchmarkGeneratePrimes(b, false) }
+func BenchmarkGeneratePrimesParallel(b *testing.B)   { benchmarkGeneratePrimes(b, true) }
+
+func TestNewPublicKeyPrecompute(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	pk := NewPublicKey(priv.N)
+	if pk.N2 == nil || pk.N2.Cmp(new(big.Int).Mul(priv.N, priv.N)) != 0 {
+		t.Fatal("NewPublicKey did not precompute N^2")
+	}
+
+	// A key without N2 (e.g. decoded from old save data) must still work.
+	bare := &PublicKey{N: priv.N}
+	m := big.NewInt(42)
+	c, _, err := bare.Encrypt(m)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	c = bare.Add(bare.Mul(c, big.NewInt(2)), c)
+	got, err := priv.Decrypt(c)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if got.Cmp(big.NewInt(126)) != 0 {
+		t.Fatalf("Decrypt = %v, want 126", got)
+	}
+	if bare.N2 != nil {
+		t.Fatal("operations must not modify the key")
+	}
+}
+
+// benchmarkPeerMtA runs the peer side of one MtA instance as in sign round 2
+// (encrypt beta, multiply the received ciphertext, add), with a peer key as
+// loaded from save data or precomputed at sign start.
+func benchmarkPeerMtA(b *testing.B, precompute bool) {
+	priv, err := GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		b.Fatalf("GenerateKey failed: %v", err)
+	}
+	pk := &PublicKey{N: priv.N}
+	if precompute {
+		pk.Precompute()
+	}
+	k, _ := rand.Int(rand.Reader, pk.N)
+	gamma, _ := rand.Int(rand.Reader, pk.N)
+	beta, _ := rand.Int(rand.Reader, pk.N)
+	encK, _, _ := pk.Encrypt(k)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encBeta, _, err := pk.Encrypt(beta)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pk.Add(pk.Mul(encK, gamma), encBeta)
+	}
+}
+
+func BenchmarkRound2MtAPrecomputed(b *testing.B)    { benchmarkPeerMtA(b, true) }
+func BenchmarkRound2MtANotPrecomputed(b *testing.B) { benchmarkPeerMtA(b, false) }
@@ -0,0 +1,121 @@
+//go:build tsstest
+
+package paillier
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Test key source, only built with -tags tsstest: prime generation
+// dominates KeyGen, Refresh and Reshare, so tests draw their Paillier keys
+// from a small set of cached primes instead. Every key is still a distinct
+// product of two of them, of the full bit length the protocols check.
+//
+// The primes are kept in a file under os.TempDir(), so the test binaries of
+// every package share them. Keys built from public, reused primes are
+// worthless outside tests, which is why none of this exists without the tag.
+
+var (
+	testKeysEnabled atomic.Bool
+
+	testPrimesMu sync.Mutex
+	testPrimes   = make(map[int]*primeCache) // By prime bit length
+)
+
+// SetTestKeySource makes GenerateKey and GenerateKeyWithConcurrency build
+// keys from cached primes if enabled, or from fresh ones again if not.
+func SetTestKeySource(enabled bool) {
+	testKeysEnabled.Store(enabled)
+}
+
+// primeCache hands out distinct pairs of cached primes: (0,1), then (0,2),
+// (1,2), then (0,3), (1,3), (2,3) and so on, adding a prime whenever the
+// pairs of the current ones are used up.
+type primeCache struct {
+	bits   int
+	primes []*big.Int
+	i, j   int // Next pair
+	loaded bool
+}
+
+func testKey(bits int) (*PrivateKey, bool, error) {
+	if !testKeysEnabled.Load() {
+		return nil, false, nil
+	}
+	testPrimesMu.Lock()
+	defer testPrimesMu.Unlock()
+	c := testPrimes[bits/2]
+	if c == nil {
+		c = &primeCache{bits: bits / 2, j: 1}
+		testPrimes[bits/2] = c
+	}
+	p, q, err := c.next()
+	if err != nil {
+		return nil, true, err
+	}
+	sk, err := newPrivateKey(p, q)
+	return sk, true, err
+}
+
+func (c *primeCache) next() (*big.Int, *big.Int, error) {
+	if !c.loaded {
+		c.primes = c.load()
+		c.loaded = true
+	}
+	for len(c.primes) <= c.j {
+		prime, err := rand.Prime(rand.Reader, c.bits)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.primes = append(c.primes, prime)
+		c.save(prime)
+	}
+	p, q := c.primes[c.i], c.primes[c.j]
+	if c.i++; c.i == c.j {
+		c.i, c.j = 0, c.j+1
+	}
+	return p, q, nil
+}
+
+func (c *primeCache) path() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("go-cggmp-tss-test-primes-%d", c.bits))
+}
+
+// load reads the cached primes, skipping any line that is not a prime of
+// the right size, e.g. one cut short by a concurrent writer.
+func (c *primeCache) load() []*big.Int {
+	f, err := os.Open(c.path())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var primes []*big.Int
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		p, ok := new(big.Int).SetString(scanner.Text(), 16)
+		if !ok || p.BitLen() != c.bits || seen[scanner.Text()] || !p.ProbablyPrime(20) {
+			continue
+		}
+		seen[scanner.Text()] = true
+		primes = append(primes, p)
+	}
+	return primes
+}
+
+// save appends prime to the cache file; failures only cost speed.
+func (c *primeCache) save(prime *big.Int) {
+	f, err := os.OpenFile(c.path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%x\n", prime)
+}
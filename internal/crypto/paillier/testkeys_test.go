@@ -0,0 +1,56 @@
+//go:build tsstest
+
+package paillier
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestTestKeySource(t *testing.T) {
+	SetTestKeySource(true)
+	defer SetTestKeySource(false)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 6; i++ {
+		sk, err := GenerateKey(rand.Reader, KeyBits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bits := sk.N.BitLen(); bits < KeyBits-1 || bits > KeyBits {
+			t.Fatalf("Modulus is %d bits, want %d", bits, KeyBits)
+		}
+		if seen[sk.N.String()] {
+			t.Fatal("Test key source repeated a key")
+		}
+		seen[sk.N.String()] = true
+
+		m := big.NewInt(42)
+		c, _, err := sk.Encrypt(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := sk.Decrypt(c); err != nil || got.Cmp(m) != 0 {
+			t.Fatalf("Decrypt = %v, %v; want 42", got, err)
+		}
+	}
+}
+
+func BenchmarkGenerateKeyTestKeySource(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "Generated"
+		if enabled {
+			name = "Cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			SetTestKeySource(enabled)
+			defer SetTestKeySource(false)
+			for i := 0; i < b.N; i++ {
+				if _, err := GenerateKey(rand.Reader, KeyBits); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
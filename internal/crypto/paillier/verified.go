@@ -0,0 +1,162 @@
+package paillier
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/blum"
+)
+
+// verifiedKeyAux is the context the Blum proof of a VerifiedKey is bound to.
+var verifiedKeyAux = []byte("go-cggmp-tss/paillier/verified-key/v1")
+
+// VerifiedKey is a Paillier key pair whose modulus is a Blum integer, with
+// the proof of that (see blum.Proof). Keys are generated ahead of time by
+// GenerateVerifiedPool, stored with Bytes and restored with LoadVerifiedKey,
+// which re-checks them.
+type VerifiedKey struct {
+	Key   *PrivateKey
+	Proof *blum.Proof
+}
+
+// GenerateVerifiedPool generates count key pairs with moduli of the given bit
+// length, e.g. during idle time, so that key generation does not have to wait
+// for primes. Every key passes Validate and its proof verifies before it is
+// returned. It stops with ctx.Err() once ctx is done.
+func GenerateVerifiedPool(ctx context.Context, bits, count int) ([]*VerifiedKey, error) {
+	if bits < 1024 {
+		return nil, errors.New("paillier: bits must be at least 1024")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("paillier: negative pool size %d", count)
+	}
+	pool := make([]*VerifiedKey, 0, count)
+	for len(pool) < count {
+		v, err := generateVerifiedKey(ctx, bits)
+		if err != nil {
+			return nil, err
+		}
+		pool = append(pool, v)
+	}
+	return pool, nil
+}
+
+func generateVerifiedKey(ctx context.Context, bits int) (*VerifiedKey, error) {
+	p, err := blumPrime(ctx, bits/2)
+	if err != nil {
+		return nil, err
+	}
+	q, err := blumPrime(ctx, bits/2)
+	if err != nil {
+		return nil, err
+	}
+	for p.Cmp(q) == 0 {
+		if q, err = blumPrime(ctx, bits/2); err != nil {
+			return nil, err
+		}
+	}
+	priv, err := newPrivateKey(p, q)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := blum.Prove(priv.N, priv.Lambda, verifiedKeyAux)
+	if err != nil {
+		return nil, err
+	}
+	v := &VerifiedKey{Key: priv, Proof: proof}
+	if err := v.Verify(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// blumPrime returns a random prime of the given bit length that is 3 mod 4.
+func blumPrime(ctx context.Context, bits int) (*big.Int, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		if p.Bit(1) == 1 {
+			return p, nil
+		}
+	}
+}
+
+// Verify runs the self-test of the key (see PrivateKey.Validate) and checks
+// its Blum proof.
+func (v *VerifiedKey) Verify() error {
+	if v == nil || v.Key == nil {
+		return errors.New("paillier: incomplete verified key")
+	}
+	if err := v.Key.Validate(); err != nil {
+		return err
+	}
+	if !v.Proof.Verify(v.Key.N, verifiedKeyAux) {
+		return errors.New("paillier: Blum proof verification failed")
+	}
+	return nil
+}
+
+// Bytes encodes the key as in PrivateKey.Bytes, followed by the proof as
+// length-prefixed fields: W, then X_i, Z_i and A_i + 2*B_i for every
+// challenge. The encoding holds the secret key.
+func (v *VerifiedKey) Bytes() []byte {
+	buf := v.Key.Bytes()
+	buf = appendInt(buf, v.Proof.W)
+	for i := range v.Proof.X {
+		flags := int64(0)
+		if v.Proof.A[i] {
+			flags |= 1
+		}
+		if v.Proof.B[i] {
+			flags |= 2
+		}
+		buf = appendInt(buf, v.Proof.X[i])
+		buf = appendInt(buf, v.Proof.Z[i])
+		buf = appendInt(buf, big.NewInt(flags))
+	}
+	return buf
+}
+
+// LoadVerifiedKey decodes a key encoded by VerifiedKey.Bytes and checks it
+// with Verify, so a pool read from storage is vetted again before use.
+func LoadVerifiedKey(data []byte) (*VerifiedKey, error) {
+	ints, err := readInts(data, 4+3*blum.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	v := &VerifiedKey{
+		Key: &PrivateKey{
+			PublicKey: *NewPublicKey(ints[0]),
+			Lambda:    ints[1],
+			Mu:        ints[2],
+		},
+		Proof: &blum.Proof{
+			W: ints[3],
+			X: make([]*big.Int, blum.Iterations),
+			A: make([]bool, blum.Iterations),
+			B: make([]bool, blum.Iterations),
+			Z: make([]*big.Int, blum.Iterations),
+		},
+	}
+	for i := 0; i < blum.Iterations; i++ {
+		fields := ints[4+3*i:]
+		flags := fields[2]
+		if flags.Cmp(big.NewInt(3)) > 0 {
+			return nil, fmt.Errorf("paillier: invalid proof flags %s", flags)
+		}
+		v.Proof.X[i], v.Proof.Z[i] = fields[0], fields[1]
+		v.Proof.A[i], v.Proof.B[i] = flags.Bit(0) == 1, flags.Bit(1) == 1
+	}
+	if err := v.Verify(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
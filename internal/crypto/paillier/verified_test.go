@@ -0,0 +1,59 @@
+package paillier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateVerifiedPool(t *testing.T) {
+	pool, err := GenerateVerifiedPool(context.Background(), 1024, 2)
+	if err != nil {
+		t.Fatalf("GenerateVerifiedPool failed: %v", err)
+	}
+	if len(pool) != 2 || pool[0].Key.N.Cmp(pool[1].Key.N) == 0 {
+		t.Fatal("Expected two distinct keys")
+	}
+	for i, v := range pool {
+		if err := v.Verify(); err != nil {
+			t.Fatalf("Key %d: %v", i, err)
+		}
+		if !v.Proof.Verify(v.Key.N, verifiedKeyAux) {
+			t.Fatalf("Key %d: Blum proof rejected", i)
+		}
+
+		data := v.Bytes()
+		loaded, err := LoadVerifiedKey(data)
+		if err != nil {
+			t.Fatalf("Key %d: LoadVerifiedKey failed: %v", i, err)
+		}
+		if loaded.Key.N.Cmp(v.Key.N) != 0 || !bytes.Equal(loaded.Bytes(), data) {
+			t.Fatalf("Key %d did not round-trip", i)
+		}
+	}
+
+	// A proof does not carry over to another key
+	swapped := &VerifiedKey{Key: pool[0].Key, Proof: pool[1].Proof}
+	if _, err := LoadVerifiedKey(swapped.Bytes()); err == nil {
+		t.Fatal("Key loaded with another key's proof")
+	}
+	// Nor does a corrupted proof load
+	corrupted := &VerifiedKey{Key: pool[0].Key, Proof: pool[0].Proof}
+	corrupted.Proof.W = new(big.Int).Add(corrupted.Proof.W, big.NewInt(1))
+	if _, err := LoadVerifiedKey(corrupted.Bytes()); err == nil {
+		t.Fatal("Key loaded with a corrupted proof")
+	}
+	if _, err := LoadVerifiedKey(pool[1].Bytes()[:100]); err == nil {
+		t.Fatal("Truncated key loaded")
+	}
+}
+
+func TestGenerateVerifiedPoolCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GenerateVerifiedPool(ctx, 1024, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package polynomial
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// LagrangeCoefficient computes the Lagrange basis coefficient at x = 0 for myIndex
+// over the set allIndices, modulo the curve order q:
+//
+//	lambda_i = prod_{j != i} x_j / (x_j - x_i) mod q
+//
+// allIndices may include myIndex itself. Returns nil if myIndex is not in allIndices
+// or the indices are not distinct mod q.
+func LagrangeCoefficient(curve curves.Curve, myIndex *big.Int, allIndices []*big.Int) *big.Int {
+	return lagrangeCoefficient(curve.Params().N, myIndex, allIndices)
+}
+
+// LagrangeCoefficientAt generalizes LagrangeCoefficient to the evaluation
+// point at:
+//
+//	lambda_i = prod_{j != i} (at - x_j) / (x_i - x_j) mod q
+//
+// so that f(at) = sum_i lambda_i * f(x_i). It returns nil in the same cases.
+func LagrangeCoefficientAt(curve curves.Curve, at, myIndex *big.Int, allIndices []*big.Int) *big.Int {
+	return lagrangeCoefficientAt(curve.Params().N, at, myIndex, allIndices)
+}
+
+// Interpolate reconstructs f(0) from the points (x, f(x)) over the secp256k1 scalar field.
+// Returns nil if the x coordinates are not distinct.
+func Interpolate(points map[*big.Int]*big.Int) *big.Int {
+	q := curves.NewSecp256k1().Params().N
+
+	indices := make([]*big.Int, 0, len(points))
+	for x := range points {
+		indices = append(indices, x)
+	}
+
+	result := new(big.Int)
+	for x, y := range points {
+		lambda := lagrangeCoefficient(q, x, indices)
+		if lambda == nil {
+			return nil
+		}
+		term := new(big.Int).Mul(y, lambda)
+		result.Add(result, term)
+		result.Mod(result, q)
+	}
+	return result
+}
+
+// LagrangeAt evaluates at x = at the polynomial of degree len(xs)-1
+// through the points (xs[i], ys[i]), modulo the curve order q:
+//
+//	f(at) = sum_i ys[i] * prod_{j != i} (at - x_j) / (x_i - x_j) mod q
+//
+// Interpolating at 0 gives the secret, at a party's index its share.
+// Returns nil if xs and ys differ in length, are empty, or the xs are not
+// distinct mod q.
+func LagrangeAt(curve curves.Curve, xs, ys []*big.Int, at *big.Int) *big.Int {
+	if len(xs) == 0 || len(xs) != len(ys) || at == nil {
+		return nil
+	}
+	q := curve.Params().N
+
+	result := new(big.Int)
+	for i, x := range xs {
+		lambda := lagrangeCoefficientAt(q, at, x, xs)
+		if lambda == nil || ys[i] == nil {
+			return nil
+		}
+		term := new(big.Int).Mul(ys[i], lambda)
+		result.Add(result, term)
+		result.Mod(result, q)
+	}
+	return result
+}
+
+func lagrangeCoefficient(q, myIndex *big.Int, allIndices []*big.Int) *big.Int {
+	return lagrangeCoefficientAt(q, new(big.Int), myIndex, allIndices)
+}
+
+// lagrangeCoefficientAt computes the Lagrange basis coefficient at x = at:
+//
+//	lambda_i = prod_{j != i} (at - x_j) / (x_i - x_j) mod q
+func lagrangeCoefficientAt(q, at, myIndex *big.Int, allIndices []*big.Int) *big.Int {
+	xi := new(big.Int).Mod(myIndex, q)
+
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	found := false
+	seen := make(map[string]bool, len(allIndices))
+
+	for _, idx := range allIndices {
+		xj := new(big.Int).Mod(idx, q)
+		if seen[xj.String()] {
+			return nil // duplicate index
+		}
+		seen[xj.String()] = true
+
+		if xj.Cmp(xi) == 0 {
+			found = true
+			continue
+		}
+
+		// num *= (at - x_j)
+		diff := new(big.Int).Sub(at, xj)
+		diff.Mod(diff, q)
+		num.Mul(num, diff)
+		num.Mod(num, q)
+
+		// den *= (x_i - x_j)
+		diff = new(big.Int).Sub(xi, xj)
+		diff.Mod(diff, q)
+		den.Mul(den, diff)
+		den.Mod(den, q)
+	}
+
+	if !found {
+		return nil
+	}
+
+	denInv := new(big.Int).ModInverse(den, q)
+	if denInv == nil {
+		return nil
+	}
+
+	lambda := new(big.Int).Mul(num, denInv)
+	return lambda.Mod(lambda, q)
+}
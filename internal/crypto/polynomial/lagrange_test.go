@@ -0,0 +1,138 @@
+package polynomial
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+func TestInterpolateSubsets(t *testing.T) {
+	curve := curves.NewSecp256k1()
+	secret := big.NewInt(123456789)
+
+	// Degree 2 (threshold t = 2): any 3 of the 5 shares reconstruct the secret
+	poly, err := New(curve, 2, secret)
+	if err != nil {
+		t.Fatalf("Failed to create polynomial: %v", err)
+	}
+
+	subsets := [][]int64{
+		{1, 2, 3},
+		{1, 3, 5},
+		{2, 4, 5},
+		{3, 4, 5},
+		{1, 2, 3, 4, 5},
+	}
+	for _, subset := range subsets {
+		points := make(map[*big.Int]*big.Int)
+		for _, i := range subset {
+			x := big.NewInt(i)
+			points[x] = poly.Evaluate(x)
+		}
+		got := Interpolate(points)
+		if got == nil || got.Cmp(secret) != 0 {
+			t.Errorf("Subset %v reconstructed %v, expected %v", subset, got, secret)
+		}
+	}
+
+	// Too few shares do not reconstruct the secret
+	points := map[*big.Int]*big.Int{
+		big.NewInt(1): poly.Evaluate(big.NewInt(1)),
+		big.NewInt(4): poly.Evaluate(big.NewInt(4)),
+	}
+	if got := Interpolate(points); got != nil && got.Cmp(secret) == 0 {
+		t.Error("Two shares reconstructed a degree-2 secret")
+	}
+}
+
+func TestLagrangeCoefficient(t *testing.T) {
+	curve := curves.NewSecp256k1()
+	N := curve.Params().N
+	secret := big.NewInt(42)
+
+	poly, err := New(curve, 1, secret)
+	if err != nil {
+		t.Fatalf("Failed to create polynomial: %v", err)
+	}
+
+	// sum(lambda_i * f(x_i)) == f(0)
+	indices := []*big.Int{big.NewInt(2), big.NewInt(7)}
+	sum := new(big.Int)
+	for _, x := range indices {
+		lambda := LagrangeCoefficient(curve, x, indices)
+		if lambda == nil {
+			t.Fatalf("LagrangeCoefficient failed for %v", x)
+		}
+		sum.Add(sum, new(big.Int).Mul(lambda, poly.Evaluate(x)))
+		sum.Mod(sum, N)
+	}
+	if sum.Cmp(secret) != 0 {
+		t.Errorf("Reconstructed %v, expected %v", sum, secret)
+	}
+
+	// Known value: for {1, 2}, lambda_1 = 2 / (2 - 1) = 2 and lambda_2 = 1 / (1 - 2) = -1
+	pair := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	if l := LagrangeCoefficient(curve, big.NewInt(1), pair); l.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("lambda_1 = %v, expected 2", l)
+	}
+	if l := LagrangeCoefficient(curve, big.NewInt(2), pair); l.Cmp(new(big.Int).Sub(N, big.NewInt(1))) != 0 {
+		t.Errorf("lambda_2 = %v, expected N-1", l)
+	}
+
+	// Index not in the set, or duplicate indices
+	if LagrangeCoefficient(curve, big.NewInt(3), pair) != nil {
+		t.Error("Expected nil for index outside the set")
+	}
+	dup := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(2)}
+	if LagrangeCoefficient(curve, big.NewInt(1), dup) != nil {
+		t.Error("Expected nil for duplicate indices")
+	}
+}
+
+func TestLagrangeAt(t *testing.T) {
+	curve := curves.NewSecp256k1()
+	N := curve.Params().N
+
+	// f(x) = 7 + 3x + 5x^2, f(5) = 7 + 15 + 125 = 147
+	f := func(x int64) *big.Int { return big.NewInt(7 + 3*x + 5*x*x) }
+	xs := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	ys := []*big.Int{f(1), f(2), f(3)}
+	if got := LagrangeAt(curve, xs, ys, big.NewInt(5)); got == nil || got.Cmp(big.NewInt(147)) != 0 {
+		t.Errorf("f(5) = %v, expected 147", got)
+	}
+	// At 0 it gives the constant term, at a known point that point's share
+	if got := LagrangeAt(curve, xs, ys, big.NewInt(0)); got == nil || got.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("f(0) = %v, expected 7", got)
+	}
+	if got := LagrangeAt(curve, xs, ys, big.NewInt(2)); got == nil || got.Cmp(f(2)) != 0 {
+		t.Errorf("f(2) = %v, expected %v", got, f(2))
+	}
+
+	// Random polynomial: a share recovered from the other three
+	poly, err := New(curve, 2, big.NewInt(42))
+	if err != nil {
+		t.Fatalf("Failed to create polynomial: %v", err)
+	}
+	xs = []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(4)}
+	ys = []*big.Int{poly.Evaluate(xs[0]), poly.Evaluate(xs[1]), poly.Evaluate(xs[2])}
+	if got := LagrangeAt(curve, xs, ys, big.NewInt(2)); got == nil || got.Cmp(poly.Evaluate(big.NewInt(2))) != 0 {
+		t.Errorf("Recovered share %v, expected %v", got, poly.Evaluate(big.NewInt(2)))
+	}
+	// Points wrap mod q
+	if got := LagrangeAt(curve, xs, ys, new(big.Int).Add(N, big.NewInt(2))); got == nil || got.Cmp(poly.Evaluate(big.NewInt(2))) != 0 {
+		t.Errorf("Evaluation point not reduced mod q: %v", got)
+	}
+
+	// Mismatched lengths, no points, or duplicate xs
+	if LagrangeAt(curve, xs, ys[:2], big.NewInt(5)) != nil {
+		t.Error("Expected nil for mismatched lengths")
+	}
+	if LagrangeAt(curve, nil, nil, big.NewInt(5)) != nil {
+		t.Error("Expected nil for no points")
+	}
+	dup := []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(3)}
+	if LagrangeAt(curve, dup, ys, big.NewInt(5)) != nil {
+		t.Error("Expected nil for duplicate x coordinates")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 )
 
 // Polynomial represents a polynomial f(x) = a_0 + a_1*x + ... + a_t*x^t
@@ -71,3 +72,8 @@ func (p *Polynomial) EvaluateMulti(xs []*big.Int) []*big.Int {
 	}
 	return results
 }
+
+// Zeroize overwrites the coefficients, which include the secret a_0.
+func (p *Polynomial) Zeroize() {
+	zeroize.Ints(p.Coefficients...)
+}
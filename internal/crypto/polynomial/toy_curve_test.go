@@ -0,0 +1,176 @@
+package polynomial
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// toyCurve is y^2 = x^3 + 7 over F_211, a group of prime order 199 small
+// enough to check sharing properties for every secret. The identity is (0, 0).
+type toyCurve struct{}
+
+var toyParams = &elliptic.CurveParams{
+	P:       big.NewInt(211),
+	N:       big.NewInt(199),
+	B:       big.NewInt(7),
+	Gx:      big.NewInt(3),
+	Gy:      big.NewInt(178),
+	BitSize: 8,
+	Name:    "toy211",
+}
+
+var _ curves.Curve = toyCurve{}
+
+func (toyCurve) Params() *elliptic.CurveParams { return toyParams }
+
+func (toyCurve) NewScalar() (*big.Int, error) { return rand.Int(rand.Reader, toyParams.N) }
+
+func (c toyCurve) ScalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return c.ScalarMult(toyParams.Gx, toyParams.Gy, k)
+}
+
+func (c toyCurve) ScalarMult(px, py, k *big.Int) (*big.Int, *big.Int) {
+	rx, ry := new(big.Int), new(big.Int)
+	e := new(big.Int).Mod(k, toyParams.N)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		rx, ry = c.Add(rx, ry, rx, ry)
+		if e.Bit(i) == 1 {
+			rx, ry = c.Add(rx, ry, px, py)
+		}
+	}
+	return rx, ry
+}
+
+func (toyCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	p := toyParams.P
+	if x1.Sign() == 0 && y1.Sign() == 0 {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+	if x2.Sign() == 0 && y2.Sign() == 0 {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		if new(big.Int).Mod(new(big.Int).Add(y1, y2), p).Sign() == 0 {
+			return new(big.Int), new(big.Int)
+		}
+		// (3x^2) / (2y)
+		num := new(big.Int).Mul(big.NewInt(3), new(big.Int).Mul(x1, x1))
+		den := new(big.Int).ModInverse(new(big.Int).Lsh(y1, 1), p)
+		lambda = num.Mul(num, den)
+	} else {
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).ModInverse(new(big.Int).Mod(new(big.Int).Sub(x2, x1), p), p)
+		lambda = num.Mul(num, den)
+	}
+	lambda.Mod(lambda, p)
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, p)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda).Sub(y3, y1).Mod(y3, p)
+	return x3, y3
+}
+
+func (toyCurve) IsOnCurve(x, y *big.Int) bool {
+	p := toyParams.P
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	lhs := new(big.Int).Mod(new(big.Int).Mul(y, y), p)
+	rhs := new(big.Int).Exp(x, big.NewInt(3), p)
+	rhs.Add(rhs, toyParams.B).Mod(rhs, p)
+	return lhs.Cmp(rhs) == 0
+}
+
+func (toyCurve) MarshalCompressed(x, y *big.Int) []byte {
+	return []byte{byte(2 + y.Bit(0)), byte(x.Uint64())}
+}
+
+func (toyCurve) UnmarshalCompressed(b []byte) (*big.Int, *big.Int, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func TestToyCurveOrder(t *testing.T) {
+	c := toyCurve{}
+	x, y := c.ScalarBaseMult(big.NewInt(1))
+	for k := int64(1); k < toyParams.N.Int64(); k++ {
+		if !c.IsOnCurve(x, y) {
+			t.Fatalf("%d*G is not a curve point", k)
+		}
+		x, y = c.Add(x, y, toyParams.Gx, toyParams.Gy)
+	}
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Fatal("N*G is not the identity")
+	}
+}
+
+// TestVSSExhaustive shares every secret of the toy scalar field with a
+// degree-2 Feldman VSS among 5 parties, checks every share against the
+// commitments and reconstructs the secret from every 3-party subset.
+func TestVSSExhaustive(t *testing.T) {
+	// The curve comes in through Parameters.CurveImpl, as in a protocol run
+	params := &tss.Parameters{Curve: "secp256k1", CurveImpl: toyCurve{}}
+	c, err := params.ResolveCurve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Params().Name != toyParams.Name {
+		t.Fatalf("Expected CurveImpl to override the curve name, got %s", c.Params().Name)
+	}
+	const n, degree = 5, 2
+	indices := make([]*big.Int, n)
+	for i := range indices {
+		indices[i] = big.NewInt(int64(i + 1))
+	}
+	var subsets [][]int
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for d := b + 1; d < n; d++ {
+				subsets = append(subsets, []int{a, b, d})
+			}
+		}
+	}
+
+	for s := int64(0); s < toyParams.N.Int64(); s++ {
+		secret := big.NewInt(s)
+		poly, err := New(c, degree, secret)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		commitments := make([]*big.Int, 0, 2*len(poly.Coefficients))
+		for _, coef := range poly.Coefficients {
+			x, y := c.ScalarBaseMult(coef)
+			commitments = append(commitments, x, y)
+		}
+		shares := poly.EvaluateMulti(indices)
+
+		for i, share := range shares {
+			// share*G == sum_k C_k * i^k
+			if !VerifyFeldmanShare(c, indices[i], share, commitments) {
+				t.Fatalf("secret %d: share %d fails the Feldman check", s, i+1)
+			}
+		}
+
+		for _, subset := range subsets {
+			set := make([]*big.Int, len(subset))
+			for j, idx := range subset {
+				set[j] = indices[idx]
+			}
+			got := new(big.Int)
+			for j, idx := range subset {
+				lambda := LagrangeCoefficient(c, set[j], set)
+				got.Add(got, new(big.Int).Mul(lambda, shares[idx]))
+			}
+			got.Mod(got, toyParams.N)
+			if got.Cmp(secret) != 0 {
+				t.Fatalf("secret %d: subset %v reconstructed %s", s, subset, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package polynomial
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// VerifyFeldmanShare checks a Feldman VSS share f(index) against the
+// commitments A_k = a_k * G to the coefficients of f:
+//
+//	share * G == sum_k index^k * A_k
+//
+// commitments holds the coordinates of A_0, ..., A_t in order:
+// X_0, Y_0, X_1, Y_1, .... An empty or odd-length list never verifies.
+func VerifyFeldmanShare(curve curves.Curve, index, share *big.Int, commitments []*big.Int) bool {
+	if share == nil {
+		return false
+	}
+	rhsX, rhsY := EvalCommitment(curve, commitments, index)
+	if rhsX == nil {
+		return false
+	}
+	lhsX, lhsY := curve.ScalarBaseMult(share)
+	return lhsX.Cmp(rhsX) == 0 && lhsY.Cmp(rhsY) == 0
+}
+
+// EvalCommitment returns f(x) * G = sum_k x^k * A_k from the commitments
+// A_k = a_k * G to the coefficients of f, laid out as for VerifyFeldmanShare.
+// It returns nil coordinates for a nil x or an empty, odd-length or
+// incomplete list.
+func EvalCommitment(curve curves.Curve, commitments []*big.Int, x *big.Int) (px, py *big.Int) {
+	if x == nil || len(commitments) == 0 || len(commitments)%2 != 0 {
+		return nil, nil
+	}
+	for _, c := range commitments {
+		if c == nil {
+			return nil, nil
+		}
+	}
+	N := curve.Params().N
+
+	for k := 0; k < len(commitments)/2; k++ {
+		scalar := new(big.Int).Exp(x, big.NewInt(int64(k)), N)
+		termX, termY := curve.ScalarMult(commitments[k*2], commitments[k*2+1], scalar)
+		if k == 0 {
+			px, py = termX, termY
+		} else {
+			px, py = curve.Add(px, py, termX, termY)
+		}
+	}
+	return px, py
+}
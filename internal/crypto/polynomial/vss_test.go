@@ -0,0 +1,126 @@
+package polynomial
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// feldmanCommitments returns X_0, Y_0, X_1, Y_1, ... for A_k = a_k * G.
+func feldmanCommitments(poly *Polynomial) []*big.Int {
+	commitments := make([]*big.Int, 0, 2*len(poly.Coefficients))
+	for _, coef := range poly.Coefficients {
+		x, y := poly.Curve.ScalarBaseMult(coef)
+		commitments = append(commitments, x, y)
+	}
+	return commitments
+}
+
+func TestVerifyFeldmanShare(t *testing.T) {
+	curve := curves.NewSecp256k1()
+	N := curve.Params().N
+	poly, err := New(curve, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to create polynomial: %v", err)
+	}
+	commitments := feldmanCommitments(poly)
+
+	for i := int64(1); i <= 5; i++ {
+		index := big.NewInt(i)
+		share := poly.Evaluate(index)
+		if !VerifyFeldmanShare(curve, index, share, commitments) {
+			t.Fatalf("Valid share %d rejected", i)
+		}
+
+		offByOne := new(big.Int).Add(share, big.NewInt(1))
+		offByOne.Mod(offByOne, N)
+		if VerifyFeldmanShare(curve, index, offByOne, commitments) {
+			t.Fatalf("Share %d + 1 accepted", i)
+		}
+		if VerifyFeldmanShare(curve, big.NewInt(i+1), share, commitments) {
+			t.Fatalf("Share %d accepted at index %d", i, i+1)
+		}
+	}
+
+	share := poly.Evaluate(big.NewInt(1))
+
+	// A commitment to a different coefficient
+	corrupted := append([]*big.Int(nil), commitments...)
+	corrupted[2], corrupted[3] = curve.ScalarBaseMult(big.NewInt(7))
+	if VerifyFeldmanShare(curve, big.NewInt(1), share, corrupted) {
+		t.Fatal("Share accepted against corrupted commitments")
+	}
+
+	// A missing top coefficient
+	if VerifyFeldmanShare(curve, big.NewInt(1), share, commitments[:4]) {
+		t.Fatal("Share accepted against truncated commitments")
+	}
+
+	malformed := map[string][]*big.Int{
+		"empty":       nil,
+		"odd length":  commitments[:5],
+		"nil element": {commitments[0], nil},
+	}
+	for name, c := range malformed {
+		if VerifyFeldmanShare(curve, big.NewInt(1), share, c) {
+			t.Fatalf("%s: share accepted", name)
+		}
+	}
+}
+
+func TestEvalCommitment(t *testing.T) {
+	for _, curve := range []curves.Curve{curves.NewSecp256k1(), curves.NewP384()} {
+		// f(x) = 3 + 5x + 7x^2
+		poly := &Polynomial{Coefficients: []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)}, Curve: curve}
+		commitments := feldmanCommitments(poly)
+		for _, x := range []int64{0, 1, 2, 10} {
+			wantX, wantY := curve.ScalarBaseMult(big.NewInt(3 + 5*x + 7*x*x))
+			gotX, gotY := EvalCommitment(curve, commitments, big.NewInt(x))
+			if gotX == nil || gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+				t.Fatalf("%s: commitment evaluated at %d is not f(%d) * G", curve.Params().Name, x, x)
+			}
+		}
+
+		random, err := New(curve, 3, nil)
+		if err != nil {
+			t.Fatalf("Failed to create polynomial: %v", err)
+		}
+		x := big.NewInt(42)
+		wantX, wantY := curve.ScalarBaseMult(random.Evaluate(x))
+		gotX, gotY := EvalCommitment(curve, feldmanCommitments(random), x)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("%s: commitment to a random polynomial evaluated incorrectly", curve.Params().Name)
+		}
+
+		if px, py := EvalCommitment(curve, commitments[:3], x); px != nil || py != nil {
+			t.Fatalf("%s: odd-length commitments evaluated", curve.Params().Name)
+		}
+		if px, _ := EvalCommitment(curve, commitments, nil); px != nil {
+			t.Fatalf("%s: nil x evaluated", curve.Params().Name)
+		}
+	}
+}
+
+func FuzzVerifyFeldmanShare(f *testing.F) {
+	curve := curves.NewSecp256k1()
+	poly, err := New(curve, 1, nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	commitments := feldmanCommitments(poly)
+
+	f.Add([]byte{1}, poly.Evaluate(big.NewInt(1)).Bytes())
+	f.Add([]byte{2}, []byte{0})
+	f.Add([]byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, indexBytes, shareBytes []byte) {
+		index := new(big.Int).SetBytes(indexBytes)
+		share := new(big.Int).SetBytes(shareBytes)
+		// Only f(index) verifies
+		want := new(big.Int).Mod(share, curve.Params().N).Cmp(poly.Evaluate(index)) == 0
+		if got := VerifyFeldmanShare(curve, index, share, commitments); got != want {
+			t.Fatalf("VerifyFeldmanShare(%s, %s) = %v, want %v", index, share, got, want)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+// Package zeroize overwrites secret values in memory once they are no longer
+// needed. The garbage collector frees memory without clearing it, so secrets
+// would otherwise stay readable until the memory is reused.
+package zeroize
+
+import "math/big"
+
+// Int overwrites the words backing n, including unused capacity, and sets n
+// to zero. It is a no-op for nil.
+func Int(n *big.Int) {
+	if n == nil {
+		return
+	}
+	words := n.Bits()
+	clear(words[:cap(words)])
+	n.SetInt64(0)
+}
+
+// Ints calls Int on each element of ns.
+func Ints(ns ...*big.Int) {
+	for _, n := range ns {
+		Int(n)
+	}
+}
+
+// Bytes overwrites b with zeros.
+func Bytes(b []byte) {
+	clear(b)
+}
+
+// Entries zeroes and deletes the given keys of m, a protocol's per-session
+// scratch data. Values may be *big.Int, []*big.Int, map[string]*big.Int,
+// []byte, or implement Zeroize(); others are only deleted.
+func Entries(m map[string]interface{}, keys ...string) {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case *big.Int:
+			Int(v)
+		case []*big.Int:
+			Ints(v...)
+		case map[string]*big.Int:
+			for _, n := range v {
+				Int(n)
+			}
+		case []byte:
+			Bytes(v)
+		case interface{ Zeroize() }:
+			v.Zeroize()
+		}
+		delete(m, k)
+	}
+}
@@ -0,0 +1,48 @@
+package zeroize
+
+import (
+	"math/big"
+	"testing"
+)
+
+type zeroizer struct{ called bool }
+
+func (z *zeroizer) Zeroize() { z.called = true }
+
+func TestInt(t *testing.T) {
+	n, _ := new(big.Int).SetString("123456789abcdef0123456789abcdef", 16)
+	words := n.Bits()
+	Int(n)
+	if n.Sign() != 0 {
+		t.Fatalf("Int left %v", n)
+	}
+	for i, w := range words[:cap(words)] {
+		if w != 0 {
+			t.Fatalf("word %d not cleared", i)
+		}
+	}
+	Int(nil)
+}
+
+func TestEntries(t *testing.T) {
+	secret := big.NewInt(42)
+	share := big.NewInt(7)
+	decommit := []byte{1, 2, 3}
+	z := &zeroizer{}
+	public := big.NewInt(9)
+	m := map[string]interface{}{
+		"ki":       secret,
+		"betas":    map[string]*big.Int{"2": share},
+		"decommit": decommit,
+		"poly":     z,
+		"r":        public,
+	}
+
+	Entries(m, "ki", "betas", "decommit", "poly", "missing")
+	if secret.Sign() != 0 || share.Sign() != 0 || decommit[0] != 0 || !z.called {
+		t.Fatal("secret entries not zeroed")
+	}
+	if len(m) != 1 || public.Int64() != 9 {
+		t.Fatalf("expected only r to remain untouched, got %v", m)
+	}
+}
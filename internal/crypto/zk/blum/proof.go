@@ -0,0 +1,176 @@
+package blum
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	one      = big.NewInt(1)
+	minusOne = big.NewInt(-1)
+)
+
+// Iterations is the number of challenges in a proof; a modulus that is not a
+// Blum integer passes each one with probability at most 1/2.
+const Iterations = 80
+
+// proofDomain separates the challenges of this proof from other hashes.
+const proofDomain = "go-cggmp-tss/zk/blum/v1"
+
+// Proof shows that a Paillier modulus N is a Blum integer, N = p*q with
+// p = q = 3 mod 4, and that gcd(N, phi(N)) = 1. It is the non-interactive
+// Pi^mod of CGGMP21 (Figure 16): for each challenge y_i the prover gives an
+// N-th root Z_i of y_i, and a fourth root X_i of (-1)^A_i * W^B_i * y_i, where
+// W has Jacobi symbol -1. Only for a Blum integer is one of those four values
+// always a fourth power.
+type Proof struct {
+	W *big.Int   // Jacobi(W, N) = -1
+	X []*big.Int // X_i^4 = (-1)^A_i * W^B_i * y_i mod N
+	A []bool
+	B []bool
+	Z []*big.Int // Z_i^N = y_i mod N
+}
+
+// Prove computes the proof for the modulus N with Carmichael function lambda
+// (lambda(N) = lcm(p-1, q-1), the Lambda of a Paillier private key), bound to
+// aux. It takes plain integers so that the paillier package can prove its own
+// keys.
+func Prove(N, lambda *big.Int, aux []byte) (*Proof, error) {
+	if N == nil || lambda == nil || N.Cmp(one) <= 0 || N.Bit(0) == 0 {
+		return nil, errors.New("blum: invalid modulus")
+	}
+	// The squares mod a Blum integer form a group of odd exponent lambda/2, on
+	// which squaring is a bijection: a fourth root of a square y is
+	// y^e with e = ((m+1)/2)^2 mod m, m = lambda/2.
+	if lambda.Bit(0) != 0 || lambda.Bit(1) != 1 {
+		return nil, errors.New("blum: modulus is not a Blum integer")
+	}
+	m := new(big.Int).Rsh(lambda, 1)
+	e := new(big.Int).Add(m, one)
+	e.Rsh(e, 1)
+	e.Mul(e, e).Mod(e, m)
+	// y^lambda = 1 for every unit y, so y^(N^-1 mod lambda) is its N-th root
+	d := new(big.Int).ModInverse(N, lambda)
+	if d == nil {
+		return nil, errors.New("blum: N is not invertible mod lambda")
+	}
+
+	w, err := nonResidue(N)
+	if err != nil {
+		return nil, err
+	}
+	p := &Proof{
+		W: w,
+		X: make([]*big.Int, Iterations),
+		A: make([]bool, Iterations),
+		B: make([]bool, Iterations),
+		Z: make([]*big.Int, Iterations),
+	}
+	for i, y := range challenges(N, w, aux) {
+		if new(big.Int).GCD(nil, nil, y, N).Cmp(one) != 0 {
+			return nil, errors.New("blum: challenge is not a unit")
+		}
+		p.Z[i] = new(big.Int).Exp(y, d, N)
+
+		found := false
+		for _, ab := range [4][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			v := adjust(N, w, y, ab[0], ab[1])
+			// v is a square iff v^m = 1
+			if new(big.Int).Exp(v, m, N).Cmp(one) == 0 {
+				p.X[i] = new(big.Int).Exp(v, e, N)
+				p.A[i], p.B[i] = ab[0], ab[1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("blum: modulus is not a Blum integer")
+		}
+	}
+	return p, nil
+}
+
+// Verify checks the proof for the modulus N and the context aux.
+func (p *Proof) Verify(N *big.Int, aux []byte) bool {
+	if p == nil || N == nil || N.Cmp(one) <= 0 || N.Bit(0) == 0 || N.ProbablyPrime(20) {
+		return false
+	}
+	if len(p.X) != Iterations || len(p.A) != Iterations || len(p.B) != Iterations || len(p.Z) != Iterations {
+		return false
+	}
+	if !inRange(p.W, N) || big.Jacobi(p.W, N) != -1 {
+		return false
+	}
+	four := big.NewInt(4)
+	for i, y := range challenges(N, p.W, aux) {
+		if !inRange(p.X[i], N) || !inRange(p.Z[i], N) {
+			return false
+		}
+		if new(big.Int).Exp(p.Z[i], N, N).Cmp(y) != 0 {
+			return false
+		}
+		if new(big.Int).Exp(p.X[i], four, N).Cmp(adjust(N, p.W, y, p.A[i], p.B[i])) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// adjust returns (-1)^a * w^b * y mod N.
+func adjust(N, w, y *big.Int, a, b bool) *big.Int {
+	v := new(big.Int).Set(y)
+	if a {
+		v.Mul(v, minusOne)
+	}
+	if b {
+		v.Mul(v, w)
+	}
+	return v.Mod(v, N)
+}
+
+func inRange(x, N *big.Int) bool {
+	return x != nil && x.Sign() > 0 && x.Cmp(N) < 0
+}
+
+// nonResidue returns a random w in Z_N with Jacobi symbol -1.
+func nonResidue(N *big.Int) (*big.Int, error) {
+	for {
+		w, err := rand.Int(rand.Reader, N)
+		if err != nil {
+			return nil, err
+		}
+		if w.Sign() > 0 && big.Jacobi(w, N) == -1 {
+			return w, nil
+		}
+	}
+}
+
+// challenges derives Iterations elements of Z_N from N, w and aux. Each is
+// hashed to 128 bits more than N and reduced, so the bias mod N is negligible.
+func challenges(N, w *big.Int, aux []byte) []*big.Int {
+	size := (N.BitLen()+7)/8 + 16
+	ys := make([]*big.Int, Iterations)
+	for i := range ys {
+		var buf []byte
+		for block := uint32(0); len(buf) < size; block++ {
+			h := sha256.New()
+			writeField := func(b []byte) {
+				var l [8]byte
+				binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+				h.Write(l[:])
+				h.Write(b)
+			}
+			writeField([]byte(proofDomain))
+			writeField(N.Bytes())
+			writeField(w.Bytes())
+			writeField(aux)
+			writeField(binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(i)), block))
+			buf = h.Sum(buf)
+		}
+		ys[i] = new(big.Int).Mod(new(big.Int).SetBytes(buf[:size]), N)
+	}
+	return ys
+}
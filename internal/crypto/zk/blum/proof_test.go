@@ -0,0 +1,78 @@
+package blum
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// testModulus returns N = p*q and lambda(N) for 512-bit primes p and q, both
+// 3 mod 4 if blum is set and both 1 mod 4 otherwise.
+func testModulus(t *testing.T, blum bool) (*big.Int, *big.Int) {
+	t.Helper()
+	want := uint(3)
+	if !blum {
+		want = 1
+	}
+	prime := func() *big.Int {
+		for {
+			p, err := rand.Prime(rand.Reader, 512)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Bits()[0]&3 == big.Word(want) {
+				return p
+			}
+		}
+	}
+	p, q := prime(), prime()
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	gcd := new(big.Int).GCD(nil, nil, pMinus1, qMinus1)
+	lambda := new(big.Int).Mul(pMinus1, qMinus1)
+	return new(big.Int).Mul(p, q), lambda.Div(lambda, gcd)
+}
+
+func TestProof(t *testing.T) {
+	N, lambda := testModulus(t, true)
+	aux := []byte("pool/key-1")
+
+	proof, err := Prove(N, lambda, aux)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	if !proof.Verify(N, aux) {
+		t.Fatal("Valid proof rejected")
+	}
+
+	// Bound to its context and modulus
+	if proof.Verify(N, []byte("pool/key-2")) {
+		t.Fatal("Proof verified under another context")
+	}
+	otherN, _ := testModulus(t, true)
+	if proof.Verify(otherN, aux) {
+		t.Fatal("Proof verified for another modulus")
+	}
+
+	// Tampered proofs fail
+	proof.A[5] = !proof.A[5]
+	if proof.Verify(N, aux) {
+		t.Fatal("Proof with a flipped sign verified")
+	}
+	proof.A[5] = !proof.A[5]
+	proof.Z[7] = new(big.Int).Add(proof.Z[7], one)
+	if proof.Verify(N, aux) {
+		t.Fatal("Proof with a tampered N-th root verified")
+	}
+	proof.Z = proof.Z[:Iterations-1]
+	if proof.Verify(N, aux) {
+		t.Fatal("Short proof verified")
+	}
+}
+
+func TestProveRejectsNonBlum(t *testing.T) {
+	N, lambda := testModulus(t, false)
+	if _, err := Prove(N, lambda, nil); err == nil {
+		t.Fatal("Proved a modulus with primes 1 mod 4")
+	}
+}
@@ -0,0 +1,212 @@
+package logstar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+var (
+	one = big.NewInt(1)
+)
+
+// Proof represents the ZK Proof that the prover knows x, rho such that:
+// 1. C = E(x, rho)  under the prover's Paillier key
+// 2. X = x * B      for a public base point B
+//
+// This is a simplified version of the Pi^{log*} proof from CGGMP21, without
+// the ring-Pedersen commitments.
+type Proof struct {
+	// Commitments
+	A      *big.Int // A = E(alpha, r) mod N^2
+	Yx, Yy *big.Int // Y = alpha * B
+
+	// Responses
+	Z1 *big.Int // z1 = alpha + e * x
+	Z2 *big.Int // z2 = r * rho^e mod N
+}
+
+// Prove generates a ZK Proof for the statement (C, B, X).
+// Inputs:
+// - curve: The curve B and X live on; its order q bounds x
+// - pk: The prover's Paillier PK, under which C was encrypted
+// - C: Ciphertext E(x, rho)
+// - x, rho: The plaintext and randomness of C
+// - Bx, By: The base point B
+// - Xx, Xy: X = x * B
+// - sessionID: The session the proof is bound to; the verifier must supply the same one
+func Prove(
+	curve curves.Curve,
+	pk *paillier.PublicKey,
+	C *big.Int,
+	x, rho *big.Int,
+	Bx, By, Xx, Xy *big.Int,
+	sessionID []byte,
+) (*Proof, error) {
+	if curve == nil || pk == nil || C == nil || x == nil || rho == nil || Bx == nil || By == nil || Xx == nil || Xy == nil {
+		return nil, errors.New("logstar: inputs cannot be nil")
+	}
+
+	N := pk.N
+	q := curve.Params().N
+
+	if x.Sign() < 0 || x.Cmp(q) >= 0 {
+		return nil, errors.New("logstar: x out of range")
+	}
+
+	// 1. Generate randoms
+	// alpha in [0, q^3) so that z1 = alpha + e*x statistically hides x
+	// r in Z_N^*
+	alpha, err := rand.Int(rand.Reader, alphaBound(q))
+	if err != nil {
+		return nil, err
+	}
+	r, err := randUnit(N)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Compute Commitments
+	A, err := pk.EncryptWithNonce(alpha, r)
+	if err != nil {
+		return nil, err
+	}
+	Yx, Yy := curve.ScalarMult(Bx, By, alpha)
+
+	// 3. Compute Challenge e = H(sid, N, C, B, X, A, Y)
+	e := challenge(curve, sessionID, N, C, Bx, By, Xx, Xy, A, Yx, Yy)
+
+	// 4. Compute Responses
+	// z1 = alpha + e * x (over the integers)
+	z1 := new(big.Int).Mul(e, x)
+	z1.Add(z1, alpha)
+
+	// z2 = r * rho^e mod N
+	z2 := new(big.Int).Exp(rho, e, N)
+	z2.Mul(z2, r)
+	z2.Mod(z2, N)
+
+	return &Proof{
+		A:  A,
+		Yx: Yx,
+		Yy: Yy,
+		Z1: z1,
+		Z2: z2,
+	}, nil
+}
+
+// Verify checks the proof for the given session.
+func (p *Proof) Verify(
+	curve curves.Curve,
+	pk *paillier.PublicKey,
+	C *big.Int,
+	Bx, By, Xx, Xy *big.Int,
+	sessionID []byte,
+) bool {
+	if p == nil || curve == nil || pk == nil || C == nil {
+		return false
+	}
+	if p.A == nil || p.Z1 == nil || p.Z2 == nil {
+		return false
+	}
+	if !curve.IsOnCurve(Bx, By) || !curve.IsOnCurve(Xx, Xy) || !curve.IsOnCurve(p.Yx, p.Yy) {
+		return false
+	}
+
+	N := pk.N
+	N2 := pk.N2
+	if N2 == nil {
+		N2 = new(big.Int).Mul(N, N)
+	}
+	q := curve.Params().N
+
+	// 0. Range checks
+	// z1 must lie in [0, q^3 + q^2), the honest range of alpha + e*x.
+	if p.Z1.Sign() < 0 || p.Z1.Cmp(responseBound(q)) >= 0 {
+		return false
+	}
+	// z2 in Z_N^*
+	if p.Z2.Sign() <= 0 || p.Z2.Cmp(N) >= 0 || new(big.Int).GCD(nil, nil, p.Z2, N).Cmp(one) != 0 {
+		return false
+	}
+	for _, c := range []*big.Int{C, p.A} {
+		if c.Sign() <= 0 || pk.ValidateCiphertext(c) != nil {
+			return false
+		}
+	}
+
+	// 1. Recompute challenge e
+	e := challenge(curve, sessionID, N, C, Bx, By, Xx, Xy, p.A, p.Yx, p.Yy)
+
+	// 2. Check 1: E(z1, z2) ?= A * C^e mod N^2
+	lhs, err := pk.EncryptWithNonce(p.Z1, p.Z2)
+	if err != nil {
+		return false
+	}
+	rhs := new(big.Int).Exp(C, e, N2)
+	rhs.Mul(rhs, p.A)
+	rhs.Mod(rhs, N2)
+	if lhs.Cmp(rhs) != 0 {
+		return false
+	}
+
+	// 3. Check 2: z1 * B ?= Y + e * X
+	zBx, zBy := curve.ScalarMult(Bx, By, new(big.Int).Mod(p.Z1, q))
+	eXx, eXy := curve.ScalarMult(Xx, Xy, e)
+	rhsX, rhsY := curve.Add(p.Yx, p.Yy, eXx, eXy)
+
+	return zBx.Cmp(rhsX) == 0 && zBy.Cmp(rhsY) == 0
+}
+
+// alphaBound returns q^3, the exclusive upper bound for the masking value alpha.
+func alphaBound(q *big.Int) *big.Int {
+	return new(big.Int).Exp(q, big.NewInt(3), nil)
+}
+
+// responseBound returns q^3 + q^2, the exclusive upper bound for an honest
+// z1 = alpha + e*x with alpha < q^3 and e, x < q.
+func responseBound(q *big.Int) *big.Int {
+	b := new(big.Int).Mul(q, q)
+	return b.Add(b, alphaBound(q))
+}
+
+// challenge computes e = H(sid, N, C, B, X, A, Y) mod q, with the session ID
+// length-prefixed and point coordinates padded to the curve's field size.
+func challenge(curve curves.Curve, sessionID []byte, N, C, Bx, By, Xx, Xy, A, Yx, Yy *big.Int) *big.Int {
+	size := curves.ByteSize(curve)
+	h := sha256.New()
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(sessionID)))
+	h.Write(l[:])
+	h.Write(sessionID)
+	h.Write(N.Bytes())
+	h.Write(C.Bytes())
+	for _, v := range []*big.Int{Bx, By, Xx, Xy} {
+		h.Write(v.FillBytes(make([]byte, size)))
+	}
+	h.Write(A.Bytes())
+	h.Write(Yx.FillBytes(make([]byte, size)))
+	h.Write(Yy.FillBytes(make([]byte, size)))
+
+	hash := h.Sum(nil)
+	e := new(big.Int).SetBytes(hash)
+	return e.Mod(e, curve.Params().N)
+}
+
+// randUnit samples a uniformly random element of Z_N^*.
+func randUnit(N *big.Int) (*big.Int, error) {
+	for {
+		r, err := rand.Int(rand.Reader, N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, N).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
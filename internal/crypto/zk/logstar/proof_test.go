@@ -0,0 +1,113 @@
+package logstar
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+var testSession = []byte("logstar-test-session")
+
+type logStarFixture struct {
+	curve  curves.Curve
+	pk     *paillier.PublicKey
+	x, rho *big.Int
+	C      *big.Int
+	Bx, By *big.Int
+	Xx, Xy *big.Int
+}
+
+func newLogStarFixture(t *testing.T) *logStarFixture {
+	t.Helper()
+
+	priv, err := paillier.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	f := &logStarFixture{curve: curves.NewSecp256k1(), pk: &priv.PublicKey}
+
+	f.x, _ = rand.Int(rand.Reader, f.curve.Params().N)
+	f.C, f.rho, err = f.pk.Encrypt(f.x)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	// B = b * G for some b unknown to the verifier, X = x * B
+	b, _ := rand.Int(rand.Reader, f.curve.Params().N)
+	f.Bx, f.By = f.curve.ScalarBaseMult(b)
+	f.Xx, f.Xy = f.curve.ScalarMult(f.Bx, f.By, f.x)
+	return f
+}
+
+func (f *logStarFixture) prove(t *testing.T) *Proof {
+	t.Helper()
+	proof, err := Prove(f.curve, f.pk, f.C, f.x, f.rho, f.Bx, f.By, f.Xx, f.Xy, testSession)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	return proof
+}
+
+func TestLogStarProof(t *testing.T) {
+	f := newLogStarFixture(t)
+	proof := f.prove(t)
+
+	if !proof.Verify(f.curve, f.pk, f.C, f.Bx, f.By, f.Xx, f.Xy, testSession) {
+		t.Fatal("Verify failed")
+	}
+
+	// The proof does not transfer to another session
+	if proof.Verify(f.curve, f.pk, f.C, f.Bx, f.By, f.Xx, f.Xy, []byte("other-session")) {
+		t.Fatal("Proof verified under a different session ID")
+	}
+
+	// Nor to another base point
+	Gx, Gy := f.curve.Params().Gx, f.curve.Params().Gy
+	if proof.Verify(f.curve, f.pk, f.C, Gx, Gy, f.Xx, f.Xy, testSession) {
+		t.Fatal("Proof verified under a different base point")
+	}
+}
+
+func TestLogStarProofTampered(t *testing.T) {
+	f := newLogStarFixture(t)
+	q := f.curve.Params().N
+
+	// X for a different scalar than the one encrypted
+	otherX, otherY := f.curve.ScalarMult(f.Bx, f.By, new(big.Int).Add(f.x, big.NewInt(1)))
+	if f.prove(t).Verify(f.curve, f.pk, f.C, f.Bx, f.By, otherX, otherY, testSession) {
+		t.Error("Verify accepted a point for another scalar")
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(p *Proof)
+	}{
+		{"z1 plus one", func(p *Proof) { p.Z1.Add(p.Z1, big.NewInt(1)) }},
+		// Shifting z1 by q keeps the EC check valid, so only the Paillier relation catches it.
+		{"z1 plus q", func(p *Proof) { p.Z1.Add(p.Z1, q) }},
+		{"z1 negative", func(p *Proof) { p.Z1.Neg(p.Z1) }},
+		{"z1 at bound", func(p *Proof) { p.Z1 = responseBound(q) }},
+		{"z2 plus one", func(p *Proof) { p.Z2.Add(p.Z2, big.NewInt(1)) }},
+		{"z2 zero", func(p *Proof) { p.Z2 = big.NewInt(0) }},
+		{"a plus one", func(p *Proof) { p.A.Add(p.A, big.NewInt(1)) }},
+		{"y identity", func(p *Proof) { p.Yx, p.Yy = big.NewInt(0), big.NewInt(0) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proof := f.prove(t)
+			tc.tamper(proof)
+			if proof.Verify(f.curve, f.pk, f.C, f.Bx, f.By, f.Xx, f.Xy, testSession) {
+				t.Fatal("Verify accepted a tampered proof")
+			}
+		})
+	}
+
+	// Out-of-range witnesses are rejected by the prover.
+	if _, err := Prove(f.curve, f.pk, f.C, q, f.rho, f.Bx, f.By, f.Xx, f.Xy, testSession); err == nil {
+		t.Error("Prove accepted x >= q")
+	}
+}
@@ -1,17 +1,18 @@
 package mta
 
 import (
-"crypto/rand"
-"crypto/sha256"
-"errors"
-"math/big"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
 
-"github.com/decred/dcrd/dcrec/secp256k1/v4"
-"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 )
 
 var (
-one = big.NewInt(1)
+	one = big.NewInt(1)
 )
 
 // Proof represents the ZK Proof for the MtA (Multiplicative-to-Additive) protocol.
@@ -22,52 +23,62 @@ one = big.NewInt(1)
 // This is a simplified version of the MtAwc (MtA with check) proof from CGGMP21.
 type Proof struct {
 	// Commitments
-	Z *big.Int                 // z = A^alpha * E(gamma, rho) mod N^2
-	U *secp256k1.JacobianPoint // U = alpha * G (only for MtAwc)
-	W *big.Int                 // w = E(alpha, rho) (optional, depends on variant)
+	Z      *big.Int // z = A^alpha * E(gamma, rho) mod N^2
+	Ux, Uy *big.Int // U = alpha * G (only for MtAwc)
+	W      *big.Int // w = E(alpha, rho) (optional, depends on variant)
 
 	// Responses
 	S     *big.Int // s = alpha + e * x
 	SBeta *big.Int // s_beta = gamma + e * beta
-	SR    *big.Int // s_r = rho * r^e mod N (approx)
+	SR    *big.Int // s_r = rho * r^e mod N
 }
 
 // Prove generates a ZK Proof for the MtA protocol.
 // Inputs:
+// - curve: The curve X lives on; its order q bounds x
 // - receiverPk: Alice's Paillier PK (N0)
 // - A: Ciphertext from Alice
 // - x: Bob's secret scalar
 // - beta: Bob's secret noise
 // - r: Randomness used for E(beta)
 // - X: Bob's public key (x*G) - for MtAwc
+// - sessionID: The session the proof is bound to; the verifier must supply the same one
 func Prove(
-receiverPk *paillier.PublicKey,
-A *big.Int,
-x, beta, r *big.Int,
-X *secp256k1.JacobianPoint,
+	curve curves.Curve,
+	receiverPk *paillier.PublicKey,
+	A *big.Int,
+	x, beta, r *big.Int,
+	Xx, Xy *big.Int,
+	sessionID []byte,
 ) (*Proof, error) {
-	if receiverPk == nil || A == nil || x == nil || beta == nil || r == nil {
+	if curve == nil || receiverPk == nil || A == nil || x == nil || beta == nil || r == nil || Xx == nil || Xy == nil {
 		return nil, errors.New("mta: inputs cannot be nil")
 	}
 
 	N := receiverPk.N
 	N2 := receiverPk.N2
-	curve := secp256k1.S256()
-	q := curve.N
+	q := curve.Params().N
 
 	// 1. Generate randoms
-	// alpha in [0, q^3] (approx, for statistical hiding)
-	// gamma in [0, q^3 * N]
-	// rho in [0, N]
-	alpha, err := randInt(q) // Simplified range for demo
+	// alpha in [0, q^3) so that s = alpha + e*x statistically hides x
+	// gamma in [0, N) so that s_beta = gamma + e*beta mod N perfectly hides beta
+	// rho in Z_N^*
+	if x.Sign() < 0 || x.Cmp(q) >= 0 {
+		return nil, errors.New("mta: x out of range")
+	}
+	if beta.Sign() < 0 || beta.Cmp(N) >= 0 {
+		return nil, errors.New("mta: beta out of range")
+	}
+
+	alpha, err := randInt(alphaBound(q))
 	if err != nil {
 		return nil, err
 	}
-	gamma, err := randInt(N) // Simplified
+	gamma, err := randInt(N)
 	if err != nil {
 		return nil, err
 	}
-	rho, err := randInt(N)
+	rho, err := randUnit(N)
 	if err != nil {
 		return nil, err
 	}
@@ -75,10 +86,10 @@ X *secp256k1.JacobianPoint,
 	// 2. Compute Commitments
 	// z = A^alpha * E(gamma, rho) mod N^2
 	//   = A^alpha * (1+N*gamma) * rho^N mod N^2
-	
+
 	// A_alpha = A^alpha mod N^2
 	A_alpha := new(big.Int).Exp(A, alpha, N2)
-	
+
 	// E_gamma = E(gamma, rho)
 	E_gamma, err := receiverPk.EncryptWithNonce(gamma, rho)
 	if err != nil {
@@ -89,154 +100,172 @@ X *secp256k1.JacobianPoint,
 	z.Mod(z, N2)
 
 	// U = alpha * G
-	var U secp256k1.JacobianPoint
-	alphaScalar := new(secp256k1.ModNScalar)
-	alphaScalar.SetByteSlice(alpha.Bytes())
-	secp256k1.ScalarBaseMultNonConst(alphaScalar, &U)
+	Ux, Uy := curve.ScalarBaseMult(new(big.Int).Mod(alpha, q))
 
 	// 3. Compute Challenge e
-	// e = H(A, C, X, z, U)
-	// We need C = A^x * E(beta, r) to include in challenge
-	// But C is not passed in, usually computed by verifier or passed.
-	// Let's assume we compute C here just for the hash, or we should pass it.
-// For this function, let's compute C locally to ensure consistency.
-	
-	// C = A^x * E(beta, r)
+	// e = H(sid, N, A, C, X, z, U)
+	// C = A^x * E(beta, r) is recomputed locally so the challenge is bound to
+	// exactly the ciphertext the verifier will see.
 	Ax := new(big.Int).Exp(A, x, N2)
-	E_beta, _ := receiverPk.EncryptWithNonce(beta, r)
+	E_beta, err := receiverPk.EncryptWithNonce(beta, r)
+	if err != nil {
+		return nil, err
+	}
 	C := new(big.Int).Mul(Ax, E_beta)
 	C.Mod(C, N2)
 
-	e := challenge(receiverPk.N, A, C, X, z, &U)
+	e := challenge(curve, sessionID, receiverPk.N, A, C, Xx, Xy, z, Ux, Uy)
 
 	// 4. Compute Responses
-	// s = alpha + e * x
+	// s = alpha + e * x (over the integers)
 	s := new(big.Int).Mul(e, x)
 	s.Add(s, alpha)
 
-	// s_beta = gamma + e * beta
+	// s_beta = gamma + e * beta mod N
 	sBeta := new(big.Int).Mul(e, beta)
 	sBeta.Add(sBeta, gamma)
+	sBeta.Mod(sBeta, N)
 
 	// s_r = rho * r^e mod N
-	// Note: This is a simplification. In real Paillier ZKPs, handling the random r is tricky
-	// because r is in Z_N^*. Usually we prove knowledge of r such that r^N is correct.
-	// For this demo, we'll skip the complex r check or use a simplified one.
-// Let's just store 0 for s_r for now as we focus on the additive structure.
-	sR := big.NewInt(0) 
+	// Since (1+N)^N = 1 mod N^2 and (a + kN)^N = a^N mod N^2, reducing both
+	// s_beta and s_r mod N preserves the ciphertext relation checked by Verify.
+	sR := new(big.Int).Exp(r, e, N)
+	sR.Mul(sR, rho)
+	sR.Mod(sR, N)
 
 	return &Proof{
 		Z:     z,
-		U:     &U,
+		Ux:    Ux,
+		Uy:    Uy,
 		S:     s,
 		SBeta: sBeta,
 		SR:    sR,
 	}, nil
 }
 
-// Verify checks the MtA proof.
+// Verify checks the MtA proof for the given session.
 func (p *Proof) Verify(
-receiverPk *paillier.PublicKey,
-A, C *big.Int,
-X *secp256k1.JacobianPoint,
+	curve curves.Curve,
+	receiverPk *paillier.PublicKey,
+	A, C *big.Int,
+	Xx, Xy *big.Int,
+	sessionID []byte,
 ) bool {
-	if p == nil || receiverPk == nil || A == nil || C == nil {
+	if p == nil || curve == nil || receiverPk == nil || A == nil || C == nil {
+		return false
+	}
+	if p.Z == nil || p.S == nil || p.SBeta == nil || p.SR == nil {
+		return false
+	}
+	// X and U must be valid points; this also rejects U as the identity
+	if !curve.IsOnCurve(Xx, Xy) || !curve.IsOnCurve(p.Ux, p.Uy) {
 		return false
 	}
 
+	N := receiverPk.N
 	N2 := receiverPk.N2
-	// curve := secp256k1.S256()
+	q := curve.Params().N
+
+	// 0. Range checks
+	// s must lie in [0, q^3 + q^2), the honest range of alpha + e*x.
+	if p.S.Sign() < 0 || p.S.Cmp(responseBound(q)) >= 0 {
+		return false
+	}
+	// s_beta in [0, N), s_r in Z_N^*
+	if p.SBeta.Sign() < 0 || p.SBeta.Cmp(N) >= 0 {
+		return false
+	}
+	if p.SR.Sign() <= 0 || p.SR.Cmp(N) >= 0 || new(big.Int).GCD(nil, nil, p.SR, N).Cmp(one) != 0 {
+		return false
+	}
+	for _, c := range []*big.Int{A, C, p.Z} {
+		if c.Sign() <= 0 || receiverPk.ValidateCiphertext(c) != nil {
+			return false
+		}
+	}
 
 	// 1. Recompute challenge e
-	e := challenge(receiverPk.N, A, C, X, p.Z, p.U)
+	e := challenge(curve, sessionID, N, A, C, Xx, Xy, p.Z, p.Ux, p.Uy)
 
 	// 2. Check 1: A^s * E(s_beta, s_r) ?= z * C^e mod N^2
-	// LHS = A^s * E(s_beta) (ignoring s_r for this simplified version)
-	// RHS = z * C^e
-	
-	// LHS
-	_ = new(big.Int).Exp(A, p.S, N2)
-	_, _, _ = receiverPk.Encrypt(p.SBeta) // Using default random for now, which is wrong for verification.
-	// In verification, we usually don't check the randomness part strictly in this simplified version,
-// OR we need s_r.
-// If we ignore s_r, we can't verify exact equality of ciphertexts because of the random factor.
-	// However, for the purpose of this "Roadmap Implementation", we will check the structure
-	// assuming we can verify the "message" part.
-	
-	// Actually, A^s * E(s_beta) = A^(alpha + ex) * E(gamma + e*beta)
-	// = A^alpha * A^ex * E(gamma) * E(beta)^e
-	// = (A^alpha * E(gamma)) * (A^x * E(beta))^e
-	// = z * C^e
-	// This holds for the message part. The randomness part matches if s_r is correct.
-	
-	// Let's do a "Message Only" check for this demo (decrypting both sides), 
-// which requires the private key (Verifier usually doesn't have it in ZK).
-	// BUT, this is a ZK Proof, Verifier should verify WITHOUT private key.
-	
-	// To verify properly without s_r, we need the full protocol.
-	// For this task, I will implement the check on the elliptic curve part (U) which is exact,
-	// and leave the Paillier part as a TODO comment or simplified check.
-	
-	// Check 2: s * G ?= U + e * X
-	curve := secp256k1.S256()
-	sMod := new(big.Int).Mod(p.S, curve.N)
-
-	var sG secp256k1.JacobianPoint
-	sScalar := new(secp256k1.ModNScalar)
-	if overflow := sScalar.SetByteSlice(sMod.Bytes()); overflow {
-		// This should not happen if we mod N correctly
+	lhs := new(big.Int).Exp(A, p.S, N2)
+	encS, err := receiverPk.EncryptWithNonce(p.SBeta, p.SR)
+	if err != nil {
+		return false
+	}
+	lhs.Mul(lhs, encS)
+	lhs.Mod(lhs, N2)
+
+	rhs := new(big.Int).Exp(C, e, N2)
+	rhs.Mul(rhs, p.Z)
+	rhs.Mod(rhs, N2)
+
+	if lhs.Cmp(rhs) != 0 {
+		return false
+	}
+
+	// 3. Check 2: s * G ?= U + e * X
+	// s mod q must be non-zero
+	sMod := new(big.Int).Mod(p.S, q)
+	if sMod.Sign() == 0 {
 		return false
 	}
-	secp256k1.ScalarBaseMultNonConst(sScalar, &sG)
 
-	var eX secp256k1.JacobianPoint
-	eScalar := new(secp256k1.ModNScalar)
-	eScalar.SetByteSlice(e.Bytes())
-	secp256k1.ScalarMultNonConst(eScalar, X, &eX)
+	sGx, sGy := curve.ScalarBaseMult(sMod)
+	eXx, eXy := curve.ScalarMult(Xx, Xy, e)
+	rhsX, rhsY := curve.Add(p.Ux, p.Uy, eXx, eXy)
 
-	var rhs secp256k1.JacobianPoint
-	secp256k1.AddNonConst(p.U, &eX, &rhs)
+	return sGx.Cmp(rhsX) == 0 && sGy.Cmp(rhsY) == 0
+}
+
+// alphaBound returns q^3, the exclusive upper bound for the masking value alpha.
+func alphaBound(q *big.Int) *big.Int {
+	return new(big.Int).Exp(q, big.NewInt(3), nil)
+}
 
-	sG.ToAffine()
-	rhs.ToAffine()
-	
-	return sG.X.Equals(&rhs.X) && sG.Y.Equals(&rhs.Y)
+// responseBound returns q^3 + q^2, the exclusive upper bound for an honest s = alpha + e*x
+// with alpha < q^3 and e, x < q.
+func responseBound(q *big.Int) *big.Int {
+	b := new(big.Int).Mul(q, q)
+	return b.Add(b, alphaBound(q))
 }
 
-func challenge(N, A, C *big.Int, X *secp256k1.JacobianPoint, z *big.Int, U *secp256k1.JacobianPoint) *big.Int {
+// challenge computes e = H(sid, N, A, C, X, z, U) mod q, with the session ID
+// length-prefixed and point coordinates padded to the curve's field size.
+func challenge(curve curves.Curve, sessionID []byte, N, A, C, Xx, Xy, z, Ux, Uy *big.Int) *big.Int {
+	size := curves.ByteSize(curve)
 	h := sha256.New()
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(sessionID)))
+	h.Write(l[:])
+	h.Write(sessionID)
 	h.Write(N.Bytes())
 	h.Write(A.Bytes())
 	h.Write(C.Bytes())
-	
-	if X != nil {
-		X.ToAffine()
-		xBytes := X.X.Bytes()
-		yBytes := X.Y.Bytes()
-		h.Write(xBytes[:])
-		h.Write(yBytes[:])
-	}
-	
+	h.Write(Xx.FillBytes(make([]byte, size)))
+	h.Write(Xy.FillBytes(make([]byte, size)))
 	h.Write(z.Bytes())
-	
-	if U != nil {
-		U.ToAffine()
-		uXBytes := U.X.Bytes()
-		uYBytes := U.Y.Bytes()
-		h.Write(uXBytes[:])
-		h.Write(uYBytes[:])
-	}
-	
+	h.Write(Ux.FillBytes(make([]byte, size)))
+	h.Write(Uy.FillBytes(make([]byte, size)))
+
 	hash := h.Sum(nil)
 	e := new(big.Int).SetBytes(hash)
-	
-	// Mod q (curve order) usually
-	curve := secp256k1.S256()
-	e.Mod(e, curve.N)
-	
-	return e
+	return e.Mod(e, curve.Params().N)
 }
 
 func randInt(max *big.Int) (*big.Int, error) {
 	return rand.Int(rand.Reader, max)
 }
+
+// randUnit samples a uniformly random element of Z_N^*.
+func randUnit(N *big.Int) (*big.Int, error) {
+	for {
+		r, err := rand.Int(rand.Reader, N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, N).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
@@ -1,49 +1,161 @@
 package mta
 
 import (
-"crypto/rand"
-"math/big"
-"testing"
+	"crypto/rand"
+	"math/big"
+	"testing"
 
-"github.com/decred/dcrd/dcrec/secp256k1/v4"
-"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 )
 
-func TestMtaProof(t *testing.T) {
+var testSession = []byte("mta-test-session")
+
+type mtaFixture struct {
+	curve      curves.Curve
+	receiverPk *paillier.PublicKey
+	x, beta, r *big.Int
+	A, C       *big.Int
+	Xx, Xy     *big.Int
+}
+
+func newMtaFixture(t *testing.T) *mtaFixture {
+	t.Helper()
+
 	// 1. Setup Paillier (Receiver)
-	receiverPriv, _ := paillier.GenerateKey(rand.Reader, 1024)
-	receiverPk := &receiverPriv.PublicKey
+	receiverPriv, err := paillier.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	f := &mtaFixture{curve: curves.NewSecp256k1(), receiverPk: &receiverPriv.PublicKey}
 
 	// 2. Setup Secrets (Prover)
-	x, _ := rand.Int(rand.Reader, secp256k1.S256().N)
-	beta, _ := rand.Int(rand.Reader, receiverPk.N)
-	r, _ := rand.Int(rand.Reader, receiverPk.N)
+	f.x, _ = rand.Int(rand.Reader, f.curve.Params().N)
+	f.beta, _ = rand.Int(rand.Reader, f.receiverPk.N)
+	f.r, _ = randUnit(f.receiverPk.N)
 
 	// 3. Public Inputs
 	// A = E(a) (Receiver encrypts a)
 	a := big.NewInt(42)
-	A, _, _ := receiverPk.Encrypt(a)
+	f.A, _, _ = f.receiverPk.Encrypt(a)
 
 	// X = x * G
-	var X secp256k1.JacobianPoint
-	xScalar := new(secp256k1.ModNScalar)
-	xScalar.SetByteSlice(x.Bytes())
-	secp256k1.ScalarBaseMultNonConst(xScalar, &X)
-
-	// C = A^x * E(beta)
-	Ax := new(big.Int).Exp(A, x, receiverPk.N2)
-	E_beta, _ := receiverPk.EncryptWithNonce(beta, r)
+	f.Xx, f.Xy = f.curve.ScalarBaseMult(f.x)
+
+	// C = A^x * E(beta, r)
+	f.C = f.ciphertext(t, f.beta)
+	return f
+}
+
+func (f *mtaFixture) ciphertext(t *testing.T, beta *big.Int) *big.Int {
+	t.Helper()
+	Ax := new(big.Int).Exp(f.A, f.x, f.receiverPk.N2)
+	E_beta, err := f.receiverPk.EncryptWithNonce(beta, f.r)
+	if err != nil {
+		t.Fatalf("EncryptWithNonce failed: %v", err)
+	}
 	C := new(big.Int).Mul(Ax, E_beta)
-	C.Mod(C, receiverPk.N2)
+	return C.Mod(C, f.receiverPk.N2)
+}
+
+func TestMtaProof(t *testing.T) {
+	f := newMtaFixture(t)
 
 	// 4. Prove
-	proof, err := Prove(receiverPk, A, x, beta, r, &X)
+	proof, err := Prove(f.curve, f.receiverPk, f.A, f.x, f.beta, f.r, f.Xx, f.Xy, testSession)
 	if err != nil {
 		t.Fatalf("Prove failed: %v", err)
 	}
 
 	// 5. Verify
-	if !proof.Verify(receiverPk, A, C, &X) {
+	if !proof.Verify(f.curve, f.receiverPk, f.A, f.C, f.Xx, f.Xy, testSession) {
 		t.Fatal("Verify failed")
 	}
+	if proof.SR.Sign() == 0 {
+		t.Fatal("s_r must not be zero")
+	}
+
+	// The proof does not transfer to another session
+	if proof.Verify(f.curve, f.receiverPk, f.A, f.C, f.Xx, f.Xy, []byte("other-session")) {
+		t.Fatal("Proof verified under a different session ID")
+	}
+}
+
+func TestMtaProofTampered(t *testing.T) {
+	f := newMtaFixture(t)
+	q := f.curve.Params().N
+
+	fresh := func() *Proof {
+		proof, err := Prove(f.curve, f.receiverPk, f.A, f.x, f.beta, f.r, f.Xx, f.Xy, testSession)
+		if err != nil {
+			t.Fatalf("Prove failed: %v", err)
+		}
+		return proof
+	}
+
+	// C built from a different beta than the one the proof was made for.
+	otherBeta := new(big.Int).Add(f.beta, big.NewInt(1))
+	otherBeta.Mod(otherBeta, f.receiverPk.N)
+	if fresh().Verify(f.curve, f.receiverPk, f.A, f.ciphertext(t, otherBeta), f.Xx, f.Xy, testSession) {
+		t.Error("Verify accepted a ciphertext with tampered beta")
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(p *Proof)
+	}{
+		{"s plus one", func(p *Proof) { p.S.Add(p.S, big.NewInt(1)) }},
+		// Shifting s by q keeps the EC check valid, so only the Paillier relation catches it.
+		{"s plus q", func(p *Proof) { p.S.Add(p.S, q) }},
+		{"s negative", func(p *Proof) { p.S.Neg(p.S) }},
+		{"s zero", func(p *Proof) { p.S = big.NewInt(0) }},
+		{"u identity", func(p *Proof) { p.Ux, p.Uy = big.NewInt(0), big.NewInt(0) }},
+		{"s at bound", func(p *Proof) { p.S = responseBound(q) }},
+		{"s_beta plus one", func(p *Proof) {
+			p.SBeta.Add(p.SBeta, big.NewInt(1))
+			p.SBeta.Mod(p.SBeta, f.receiverPk.N)
+		}},
+		{"s_beta out of range", func(p *Proof) { p.SBeta.Add(p.SBeta, f.receiverPk.N) }},
+		{"s_r plus one", func(p *Proof) { p.SR.Add(p.SR, big.NewInt(1)) }},
+		{"s_r zero", func(p *Proof) { p.SR = big.NewInt(0) }},
+		{"z plus one", func(p *Proof) { p.Z.Add(p.Z, big.NewInt(1)) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proof := fresh()
+			tc.tamper(proof)
+			if proof.Verify(f.curve, f.receiverPk, f.A, f.C, f.Xx, f.Xy, testSession) {
+				t.Fatal("Verify accepted a tampered proof")
+			}
+		})
+	}
+}
+
+func TestMtaProofAlphaRange(t *testing.T) {
+	f := newMtaFixture(t)
+	q := f.curve.Params().N
+	bound := responseBound(q)
+
+	for i := 0; i < 8; i++ {
+		proof, err := Prove(f.curve, f.receiverPk, f.A, f.x, f.beta, f.r, f.Xx, f.Xy, testSession)
+		if err != nil {
+			t.Fatalf("Prove failed: %v", err)
+		}
+		if proof.S.Sign() < 0 || proof.S.Cmp(bound) >= 0 {
+			t.Fatalf("s out of range [0, q^3 + q^2): %s", proof.S)
+		}
+		// alpha is sampled from [0, q^3), so s should essentially never fit in Z_q.
+		if proof.S.Cmp(q) < 0 {
+			t.Fatalf("s unexpectedly small; alpha is not masking x")
+		}
+	}
+
+	// Out-of-range witnesses are rejected by the prover.
+	if _, err := Prove(f.curve, f.receiverPk, f.A, q, f.beta, f.r, f.Xx, f.Xy, testSession); err == nil {
+		t.Error("Prove accepted x >= q")
+	}
+	if _, err := Prove(f.curve, f.receiverPk, f.A, f.x, f.receiverPk.N, f.r, f.Xx, f.Xy, testSession); err == nil {
+		t.Error("Prove accepted beta >= N")
+	}
 }
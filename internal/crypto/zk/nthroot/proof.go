@@ -0,0 +1,95 @@
+package nthroot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+var (
+	one = big.NewInt(1)
+)
+
+// Iterations is the number of challenges in a proof.
+const Iterations = 16
+
+// proofDomain separates the challenges of this proof from other hashes.
+const proofDomain = "go-cggmp-tss/zk/nthroot/v1"
+
+// Proof shows possession of the Paillier private key for a modulus N: it
+// gives the N-th roots mod N of Iterations challenges derived from N and the
+// proof context. Taking N-th roots requires the factorization of N (with
+// gcd(N, phi(N)) = 1), so a party cannot claim a modulus it did not generate.
+//
+// This is the non-interactive proof of a Paillier modulus of Hazay et al.,
+// not the full Pi^mod of CGGMP21: it does not show that N is a Blum integer.
+type Proof struct {
+	Roots []*big.Int // Roots[i]^N = y_i mod N
+}
+
+// Prove computes the proof for the key sk, bound to aux (see schnorr.Aux).
+func Prove(sk *paillier.PrivateKey, aux []byte) (*Proof, error) {
+	if sk == nil || sk.N == nil || sk.Lambda == nil {
+		return nil, errors.New("nthroot: incomplete private key")
+	}
+	// y^lambda = 1 for every unit y, so y^(N^-1 mod lambda) is its N-th root
+	d := new(big.Int).ModInverse(sk.N, sk.Lambda)
+	if d == nil {
+		return nil, errors.New("nthroot: N is not invertible mod lambda")
+	}
+	ys := challenges(sk.N, aux)
+	roots := make([]*big.Int, len(ys))
+	for i, y := range ys {
+		if new(big.Int).GCD(nil, nil, y, sk.N).Cmp(one) != 0 {
+			return nil, errors.New("nthroot: challenge is not a unit")
+		}
+		roots[i] = new(big.Int).Exp(y, d, sk.N)
+	}
+	return &Proof{Roots: roots}, nil
+}
+
+// Verify checks the proof for the modulus N and the context aux.
+func (p *Proof) Verify(N *big.Int, aux []byte) bool {
+	if p == nil || N == nil || N.Cmp(one) <= 0 || N.Bit(0) == 0 || len(p.Roots) != Iterations {
+		return false
+	}
+	for i, y := range challenges(N, aux) {
+		x := p.Roots[i]
+		if x == nil || x.Sign() <= 0 || x.Cmp(N) >= 0 {
+			return false
+		}
+		if new(big.Int).Exp(x, N, N).Cmp(y) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// challenges derives Iterations elements of Z_N from N and aux. Each is hashed
+// to 128 bits more than N and reduced, so the bias mod N is negligible.
+func challenges(N *big.Int, aux []byte) []*big.Int {
+	size := (N.BitLen()+7)/8 + 16
+	ys := make([]*big.Int, Iterations)
+	for i := range ys {
+		var buf []byte
+		for block := uint32(0); len(buf) < size; block++ {
+			h := sha256.New()
+			writeField := func(b []byte) {
+				var l [8]byte
+				binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+				h.Write(l[:])
+				h.Write(b)
+			}
+			writeField([]byte(proofDomain))
+			writeField(N.Bytes())
+			writeField(aux)
+			writeField(binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(i)), block))
+			buf = h.Sum(buf)
+		}
+		ys[i] = new(big.Int).Mod(new(big.Int).SetBytes(buf[:size]), N)
+	}
+	return ys
+}
@@ -0,0 +1,47 @@
+package nthroot
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+func TestProof(t *testing.T) {
+	sk, err := paillier.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	aux := []byte("session/party-1")
+
+	proof, err := Prove(sk, aux)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	if !proof.Verify(sk.N, aux) {
+		t.Fatal("Valid proof rejected")
+	}
+
+	// Bound to its context and modulus
+	if proof.Verify(sk.N, []byte("session/party-2")) {
+		t.Fatal("Proof verified under another context")
+	}
+	other, err := paillier.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	if proof.Verify(other.N, aux) {
+		t.Fatal("Proof verified for another modulus")
+	}
+
+	// Tampered roots fail
+	proof.Roots[3] = new(big.Int).Add(proof.Roots[3], big.NewInt(1))
+	if proof.Verify(sk.N, aux) {
+		t.Fatal("Tampered proof verified")
+	}
+	proof.Roots = proof.Roots[:Iterations-1]
+	if proof.Verify(sk.N, aux) {
+		t.Fatal("Short proof verified")
+	}
+}
@@ -3,6 +3,7 @@ package range_proof
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"math/big"
 
@@ -13,11 +14,21 @@ var (
 	one = big.NewInt(1)
 )
 
+const (
+	// ChallengeBits is the bit length of the Fiat-Shamir challenge e.
+	ChallengeBits = 128
+	// SlackBits is the statistical hiding parameter for the masking value alpha.
+	SlackBits = 80
+
+	challengeDomain = "go-cggmp-tss/zk/range/v1"
+)
+
 // Proof represents a Zero-Knowledge Range Proof.
 // It proves that a value x encrypted in a Paillier ciphertext C is within a specific range [0, 2^bits].
 //
 // Note: This is a simplified implementation structure for the roadmap milestone.
 // A full implementation (e.g., Bulletproofs or specialized Paillier range proofs) would be significantly more complex.
+// As with all range proofs of this shape, soundness only guarantees x < 2^(bits+ChallengeBits+SlackBits+1).
 type Proof struct {
 	// Commitments
 	A *big.Int // Commitment to the value
@@ -30,13 +41,28 @@ type Proof struct {
 
 // Prove generates a Range Proof for the value x encrypted in C.
 // C = E(x, r)
-func Prove(pk *paillier.PublicKey, C *big.Int, x *big.Int, r *big.Int, bits int) (*Proof, error) {
+// The proof is bound to sessionID, which the verifier must supply as well.
+func Prove(pk *paillier.PublicKey, C *big.Int, x *big.Int, r *big.Int, bits int, sessionID []byte) (*Proof, error) {
 	if pk == nil || C == nil || x == nil || r == nil {
 		return nil, errors.New("range: inputs cannot be nil")
 	}
+	if bits <= 0 {
+		return nil, errors.New("range: bits must be positive")
+	}
+	if x.Sign() < 0 || x.BitLen() > bits {
+		return nil, errors.New("range: x out of range [0, 2^bits)")
+	}
+	if responseBound(bits).Cmp(pk.N) >= 0 {
+		return nil, errors.New("range: bits too large for Paillier modulus")
+	}
+	return prove(pk, C, x, r, bits, sessionID)
+}
 
+// prove computes the proof without checking the range of x.
+func prove(pk *paillier.PublicKey, C *big.Int, x *big.Int, r *big.Int, bits int, sessionID []byte) (*Proof, error) {
 	// 1. Generate random blinding factors
-	alpha, err := randInt(pk.N)
+	// alpha in [0, 2^(bits+ChallengeBits+SlackBits)) statistically hides e*x
+	alpha, err := randInt(new(big.Int).Lsh(one, uint(bits+ChallengeBits+SlackBits)))
 	if err != nil {
 		return nil, err
 	}
@@ -58,15 +84,15 @@ func Prove(pk *paillier.PublicKey, C *big.Int, x *big.Int, r *big.Int, bits int)
 		return nil, err
 	}
 
-	// 3. Compute challenge e = H(pk, C, A, S)
-	e := challenge(pk.N, C, A, S)
+	// 3. Compute challenge e = H(tag, sid, bits, pk, C, A, S)
+	e := challenge(sessionID, bits, pk.N, C, A, S)
 
 	// 4. Compute responses
 	// z1 = alpha + e * x
 	z1 := new(big.Int).Mul(e, x)
 	z1.Add(z1, alpha)
 
-	// z2 = rho * r^e mod N (Approximation for Paillier randomness)
+	// z2 = rho * r^e mod N
 	// For Paillier, randomness combines multiplicatively: r' = rho * r^e mod N
 	z2 := new(big.Int).Exp(r, e, pk.N)
 	z2.Mul(z2, rho)
@@ -81,17 +107,26 @@ func Prove(pk *paillier.PublicKey, C *big.Int, x *big.Int, r *big.Int, bits int)
 }
 
 // Verify verifies the Range Proof.
-func (p *Proof) Verify(pk *paillier.PublicKey, C *big.Int, bits int) bool {
-	if p == nil || pk == nil || C == nil {
+func (p *Proof) Verify(pk *paillier.PublicKey, C *big.Int, bits int, sessionID []byte) bool {
+	if p == nil || pk == nil || C == nil || bits <= 0 {
+		return false
+	}
+	if p.A == nil || p.S == nil || p.Z1 == nil || p.Z2 == nil {
+		return false
+	}
+
+	// 1. Range check on z1
+	// An honest z1 = alpha + e*x is below 2^(bits+ChallengeBits+SlackBits+1).
+	if p.Z1.Sign() < 0 || p.Z1.Cmp(responseBound(bits)) >= 0 {
 		return false
 	}
 
-	// 1. Recompute challenge e = H(pk, C, A, S)
-	e := challenge(pk.N, C, p.A, p.S)
+	// 2. Recompute challenge e = H(tag, sid, bits, pk, C, A, S)
+	e := challenge(sessionID, bits, pk.N, C, p.A, p.S)
 
-	// 2. Verify encryption relation
+	// 3. Verify encryption relation
 	// E(z1, z2) ?= A * C^e mod N^2
-	
+
 	// LHS = E(z1, z2)
 	lhs, err := pk.EncryptWithR(p.Z1, p.Z2)
 	if err != nil {
@@ -106,16 +141,32 @@ func (p *Proof) Verify(pk *paillier.PublicKey, C *big.Int, bits int) bool {
 	return lhs.Cmp(rhs) == 0
 }
 
+// responseBound returns 2^(bits+ChallengeBits+SlackBits+1), the exclusive upper bound for z1.
+func responseBound(bits int) *big.Int {
+	return new(big.Int).Lsh(one, uint(bits+ChallengeBits+SlackBits+1))
+}
+
 func randInt(max *big.Int) (*big.Int, error) {
 	return rand.Int(rand.Reader, max)
 }
 
-func challenge(n *big.Int, values ...*big.Int) *big.Int {
+// challenge derives e in [0, 2^ChallengeBits) from a domain-separated,
+// length-prefixed transcript.
+func challenge(sessionID []byte, bits int, n *big.Int, values ...*big.Int) *big.Int {
 	h := sha256.New()
-	h.Write(n.Bytes())
+	writeField := func(b []byte) {
+		var l [8]byte
+		binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+		h.Write(l[:])
+		h.Write(b)
+	}
+	writeField([]byte(challengeDomain))
+	writeField(sessionID)
+	writeField(big.NewInt(int64(bits)).Bytes())
+	writeField(n.Bytes())
 	for _, v := range values {
-		h.Write(v.Bytes())
+		writeField(v.Bytes())
 	}
 	bytes := h.Sum(nil)
-	return new(big.Int).SetBytes(bytes)
+	return new(big.Int).SetBytes(bytes[:ChallengeBits/8])
 }
@@ -28,13 +28,78 @@ func TestRangeProof(t *testing.T) {
 	}
 
 	// 3. Generate Proof
-	proof, err := Prove(pk, C, x, r, 256)
+	sid := []byte("session-1")
+	proof, err := Prove(pk, C, x, r, 256, sid)
 	if err != nil {
 		t.Fatalf("Prove failed: %v", err)
 	}
 
 	// 4. Verify Proof
-	if !proof.Verify(pk, C, 256) {
+	if !proof.Verify(pk, C, 256, sid) {
 		t.Fatal("Verify failed")
 	}
+
+	// The proof is bound to the session and bit length.
+	if proof.Verify(pk, C, 256, []byte("session-2")) {
+		t.Fatal("Verify accepted proof under a different session ID")
+	}
+	if proof.Verify(pk, C, 128, sid) {
+		t.Fatal("Verify accepted proof under a different bit length")
+	}
+}
+
+func TestRangeProofOutOfRange(t *testing.T) {
+	sk, err := paillier.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := &sk.PublicKey
+	sid := []byte("session-1")
+	const bits = 256
+
+	r, err := rand.Int(rand.Reader, pk.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The honest prover refuses x >= 2^bits.
+	x := new(big.Int).Lsh(one, bits)
+	C, err := pk.EncryptWithR(x, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Prove(pk, C, x, r, bits, sid); err == nil {
+		t.Fatal("Prove accepted x = 2^bits")
+	}
+
+	// A cheating prover skipping the check is caught by the z1 bound.
+	for _, shift := range []uint{bits + ChallengeBits + SlackBits + 1, bits + 2*ChallengeBits + SlackBits} {
+		x := new(big.Int).Lsh(one, shift)
+		C, err := pk.EncryptWithR(x, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := prove(pk, C, x, r, bits, sid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proof.Verify(pk, C, bits, sid) {
+			t.Fatalf("Verify accepted x = 2^%d for bits = %d", shift, bits)
+		}
+	}
+
+	// Tampered z1 is rejected.
+	x = big.NewInt(42)
+	C, err = pk.EncryptWithR(x, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := Prove(pk, C, x, r, bits, sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Z1 = responseBound(bits)
+	if proof.Verify(pk, C, bits, sid) {
+		t.Fatal("Verify accepted z1 at the bound")
+	}
 }
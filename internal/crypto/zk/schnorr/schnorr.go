@@ -3,6 +3,7 @@ package schnorr
 import (
 crand "crypto/rand"
 "crypto/sha256"
+"encoding/binary"
 "errors"
 "math/big"
 
@@ -17,8 +18,9 @@ type Proof struct {
 }
 
 // Prove generates a Schnorr proof for the secret x, public key X = x*G.
-// It uses the provided unique session ID (sid) and other context to bind the proof.
-func Prove(x *big.Int, X *secp256k1.JacobianPoint) (*Proof, error) {
+// aux is mixed into the challenge to bind the proof to its context; use Aux to
+// build it from the session ID and the prover's party ID.
+func Prove(x *big.Int, X *secp256k1.JacobianPoint, aux []byte) (*Proof, error) {
 	if x == nil || X == nil {
 		return nil, errors.New("schnorr: inputs cannot be nil")
 	}
@@ -38,8 +40,8 @@ func Prove(x *big.Int, X *secp256k1.JacobianPoint) (*Proof, error) {
 	kScalar.SetByteSlice(k.Bytes())
 	secp256k1.ScalarBaseMultNonConst(kScalar, &R)
 
-	// 3. Compute challenge e = H(X, R)
-	e := challenge(X, &R)
+	// 3. Compute challenge e = H(X, R, aux)
+	e := challenge(X, &R, aux)
 
 	// 4. Compute s = k + e * x mod n
 	s := new(big.Int).Mul(e, x)
@@ -52,8 +54,9 @@ func Prove(x *big.Int, X *secp256k1.JacobianPoint) (*Proof, error) {
 	}, nil
 }
 
-// Verify checks the validity of the Schnorr proof for public key X.
-func (p *Proof) Verify(X *secp256k1.JacobianPoint) bool {
+// Verify checks the validity of the Schnorr proof for public key X under the
+// same aux context that was passed to Prove.
+func (p *Proof) Verify(X *secp256k1.JacobianPoint, aux []byte) bool {
 	if p == nil || p.R == nil || p.S == nil || X == nil {
 		return false
 	}
@@ -61,13 +64,18 @@ func (p *Proof) Verify(X *secp256k1.JacobianPoint) bool {
 	curve := secp256k1.S256()
 	n := curve.N
 
-	// Check if s is in [0, n-1]
-	if p.S.Sign() < 0 || p.S.Cmp(n) >= 0 {
+	// Check if s is in [1, n-1]
+	if p.S.Sign() <= 0 || p.S.Cmp(n) >= 0 {
 		return false
 	}
 
-	// 1. Compute challenge e = H(X, R)
-	e := challenge(X, p.R)
+	// Reject the identity as commitment
+	if isIdentity(p.R) {
+		return false
+	}
+
+	// 1. Compute challenge e = H(X, R, aux)
+	e := challenge(X, p.R, aux)
 
 	// 2. Verify R = s*G - e*X
 	// Equivalent to checking s*G = R + e*X
@@ -95,8 +103,19 @@ func (p *Proof) Verify(X *secp256k1.JacobianPoint) bool {
 	return lhs.X.Equals(&rhs.X) && lhs.Y.Equals(&rhs.Y)
 }
 
-// challenge computes H(X, R) mod n
-func challenge(X, R *secp256k1.JacobianPoint) *big.Int {
+// Aux builds the auxiliary proof context from the session ID and the prover's party ID.
+// Both fields are length-prefixed so distinct contexts never collide.
+func Aux(sessionID []byte, partyID string) []byte {
+	aux := make([]byte, 0, 16+len(sessionID)+len(partyID))
+	aux = binary.BigEndian.AppendUint64(aux, uint64(len(sessionID)))
+	aux = append(aux, sessionID...)
+	aux = binary.BigEndian.AppendUint64(aux, uint64(len(partyID)))
+	aux = append(aux, partyID...)
+	return aux
+}
+
+// challenge computes H(X, R, aux) mod n
+func challenge(X, R *secp256k1.JacobianPoint, aux []byte) *big.Int {
 	curve := secp256k1.S256()
 	
 	// Serialize points
@@ -112,6 +131,7 @@ func challenge(X, R *secp256k1.JacobianPoint) *big.Int {
 	h.Write(X.Y.Bytes()[:])
 	h.Write(R.X.Bytes()[:])
 	h.Write(R.Y.Bytes()[:])
+	h.Write(aux)
 	
 	hashBytes := h.Sum(nil)
 	
@@ -120,6 +140,14 @@ func challenge(X, R *secp256k1.JacobianPoint) *big.Int {
 	return e
 }
 
+// isIdentity reports whether P is the point at infinity.
+func isIdentity(P *secp256k1.JacobianPoint) bool {
+	if P.Z.IsZero() {
+		return true
+	}
+	return P.X.IsZero() && P.Y.IsZero()
+}
+
 // randInt generates a random integer in [0, max)
 func randInt(max *big.Int) (*big.Int, error) {
 	return crand.Int(crand.Reader, max)
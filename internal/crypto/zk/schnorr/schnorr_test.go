@@ -6,6 +6,7 @@ import (
 "testing"
 
 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 )
 
 func TestSchnorrProof(t *testing.T) {
@@ -25,13 +26,14 @@ func TestSchnorrProof(t *testing.T) {
 	secp256k1.ScalarBaseMultNonConst(xScalar, &X)
 
 	// 3. Generate Proof
-	proof, err := Prove(x, &X)
+	aux := Aux([]byte("session"), "1")
+	proof, err := Prove(x, &X, aux)
 	if err != nil {
 		t.Fatalf("Prove failed: %v", err)
 	}
 
 	// 4. Verify Proof
-	if !proof.Verify(&X) {
+	if !proof.Verify(&X, aux) {
 		t.Fatal("Verify failed for valid proof")
 	}
 }
@@ -50,12 +52,13 @@ func TestSchnorrProofInvalid(t *testing.T) {
 	secp256k1.ScalarBaseMultNonConst(xScalar, &X)
 
 	// 3. Generate Proof
-	proof, _ := Prove(x, &X)
+	aux := Aux([]byte("session"), "1")
+	proof, _ := Prove(x, &X, aux)
 
 	// 4. Tamper with the proof
 	// Case A: Modify s
 	proof.S.Add(proof.S, big.NewInt(1))
-	if proof.Verify(&X) {
+	if proof.Verify(&X, aux) {
 		t.Fatal("Verify passed for tampered s")
 	}
 
@@ -67,7 +70,90 @@ secp256k1.DoubleNonConst(proof.R, proof.R)
 // Restore s (it was modified in Case A)
 proof.S.Sub(proof.S, big.NewInt(1))
 
-if proof.Verify(&X) {
+if proof.Verify(&X, aux) {
 t.Fatal("Verify passed for tampered R")
 }
 }
+
+func TestSchnorrProofAuxBinding(t *testing.T) {
+	x, _ := rand.Int(rand.Reader, secp256k1.S256().N)
+
+	var X secp256k1.JacobianPoint
+	xScalar := new(secp256k1.ModNScalar)
+	xScalar.SetByteSlice(x.Bytes())
+	secp256k1.ScalarBaseMultNonConst(xScalar, &X)
+
+	auxA := Aux([]byte("session-A"), "1")
+	proof, err := Prove(x, &X, auxA)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	if !proof.Verify(&X, auxA) {
+		t.Fatal("Verify failed under session A")
+	}
+
+	// Replaying the proof in session B or as another party must fail
+	if proof.Verify(&X, Aux([]byte("session-B"), "1")) {
+		t.Fatal("Verify passed under session B's aux")
+	}
+	if proof.Verify(&X, Aux([]byte("session-A"), "2")) {
+		t.Fatal("Verify passed under a different party ID")
+	}
+	// Field boundaries are unambiguous
+	if proof.Verify(&X, Aux([]byte("session-A1"), "")) {
+		t.Fatal("Verify passed under an ambiguous aux encoding")
+	}
+}
+
+func TestSchnorrProofZeroS(t *testing.T) {
+	x, _ := rand.Int(rand.Reader, secp256k1.S256().N)
+
+	var X secp256k1.JacobianPoint
+	xScalar := new(secp256k1.ModNScalar)
+	xScalar.SetByteSlice(x.Bytes())
+	secp256k1.ScalarBaseMultNonConst(xScalar, &X)
+	aux := Aux([]byte("session"), "1")
+
+	// S == 0 is rejected
+	proof, _ := Prove(x, &X, aux)
+	proof.S = big.NewInt(0)
+	if proof.Verify(&X, aux) {
+		t.Fatal("Verify passed for S == 0")
+	}
+
+	// R == identity is rejected
+	var identity secp256k1.JacobianPoint
+	proof, _ = Prove(x, &X, aux)
+	proof.R = &identity
+	if proof.Verify(&X, aux) {
+		t.Fatal("Verify passed for R == identity")
+	}
+}
+
+func TestCurveProof(t *testing.T) {
+	for _, curve := range []curves.Curve{curves.NewSecp256k1(), curves.NewP384()} {
+		x, _ := curve.NewScalar()
+		Xx, Xy := curve.ScalarBaseMult(x)
+		aux := Aux([]byte("session"), "1")
+
+		proof, err := ProveOnCurve(curve, x, Xx, Xy, aux)
+		if err != nil {
+			t.Fatalf("ProveOnCurve failed: %v", err)
+		}
+		if !proof.Verify(curve, Xx, Xy, aux) {
+			t.Fatalf("Verify failed on %d-bit curve", curve.Params().BitSize)
+		}
+		if proof.Verify(curve, Xx, Xy, Aux([]byte("session"), "2")) {
+			t.Fatal("Verify passed under a different aux")
+		}
+
+		// Off-curve X and a zero response are rejected
+		if proof.Verify(curve, Xx, new(big.Int).Add(Xy, big.NewInt(1)), aux) {
+			t.Fatal("Verify passed for an off-curve X")
+		}
+		proof.S = big.NewInt(0)
+		if proof.Verify(curve, Xx, Xy, aux) {
+			t.Fatal("Verify passed for S == 0")
+		}
+	}
+}
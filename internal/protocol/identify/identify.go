@@ -1,10 +1,14 @@
 package identify
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/nthroot"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
@@ -14,9 +18,15 @@ import (
 // A party can use this to prove they possess a valid secret key share.
 type IdentifyProof struct {
 	PartyID    string
+	SessionID  []byte // Session the proof is bound to
 	Proof      *schnorr.Proof
 	PublicKeyX *big.Int
 	PublicKeyY *big.Int
+
+	// Proof of possession of the Paillier private key for PaillierN, set if
+	// the prover's Parameters.IdentifyPaillierKey is
+	PaillierN     *big.Int
+	PaillierProof *nthroot.Proof
 }
 
 // NewIdentifyProof generates a ZK proof that the party owns their secret key share.
@@ -44,20 +54,37 @@ func NewIdentifyProof(params *tss.Parameters, keyData *keygen.LocalPartySaveData
 	Xi_jac.Z.SetInt(1)
 
 	// Generate Schnorr proof: proves knowledge of x_i such that X_i = x_i * G
-	proof, err := schnorr.Prove(keyData.Xi, &Xi_jac)
+	// The proof is bound to the session and our party ID so it cannot be replayed
+	proof, err := schnorr.Prove(keyData.Xi, &Xi_jac, schnorr.Aux(params.SessionID, params.PartyID.ID()))
 	if err != nil {
 		return nil, err
 	}
 
-	return &IdentifyProof{
+	out := &IdentifyProof{
 		PartyID:    params.PartyID.ID(),
+		SessionID:  params.SessionID,
 		Proof:      proof,
 		PublicKeyX: keyData.XiX,
 		PublicKeyY: keyData.XiY,
-	}, nil
+	}
+
+	// Optionally prove that we also hold the Paillier key peers encrypt to
+	if params.IdentifyPaillierKey {
+		if keyData.PaillierSk == nil {
+			return nil, errors.New("identify: missing Paillier private key")
+		}
+		out.PaillierN = keyData.PaillierSk.N
+		out.PaillierProof, err = nthroot.Prove(keyData.PaillierSk, schnorr.Aux(params.SessionID, params.PartyID.ID()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
 }
 
-// VerifyIdentifyProof checks if the provided proof is valid for the claimed public key share.
+// VerifyIdentifyProof checks if the provided proof is valid for the claimed public key share,
+// party ID and session ID, and, if it includes one, the proof of possession of
+// the Paillier key PaillierN.
 func VerifyIdentifyProof(proof *IdentifyProof) bool {
 	if proof == nil || proof.Proof == nil {
 		return false
@@ -67,6 +94,12 @@ func VerifyIdentifyProof(proof *IdentifyProof) bool {
 		return false
 	}
 
+	if proof.PaillierN != nil || proof.PaillierProof != nil {
+		if !proof.PaillierProof.Verify(proof.PaillierN, schnorr.Aux(proof.SessionID, proof.PartyID)) {
+			return false
+		}
+	}
+
 	// Reconstruct the public key share as a Jacobian point
 	var Xi_jac secp256k1.JacobianPoint
 	var Xi_x_field, Xi_y_field secp256k1.FieldVal
@@ -76,16 +109,18 @@ func VerifyIdentifyProof(proof *IdentifyProof) bool {
 	Xi_jac.Y = Xi_y_field
 	Xi_jac.Z.SetInt(1)
 
-	return proof.Proof.Verify(&Xi_jac)
+	return proof.Proof.Verify(&Xi_jac, schnorr.Aux(proof.SessionID, proof.PartyID))
 }
 
 // IdentifySession enables multi-party identification verification.
 // Each party broadcasts their proof, and all parties verify each other.
 type IdentifySession struct {
-	params      *tss.Parameters
-	myProof     *IdentifyProof
-	peerProofs  map[string]*IdentifyProof
-	peerPubKeys map[string]struct{ X, Y *big.Int }
+	params        *tss.Parameters
+	myProof       *IdentifyProof
+	peerProofs    map[string]*IdentifyProof
+	peerPubKeys   map[string]struct{ X, Y *big.Int }
+	peerPaillierN map[string]*big.Int // Paillier moduli from KeyGen, by party ID
+	failed        map[string]error    // Why the last proof of a peer was rejected, by party ID
 }
 
 // NewIdentifySession creates a new identification session.
@@ -99,17 +134,67 @@ func NewIdentifySession(params *tss.Parameters, keyData *keygen.LocalPartySaveDa
 	// In a real scenario, these would come from the keygen output or a trusted source
 	peerPubKeys := make(map[string]struct{ X, Y *big.Int })
 
+	peerPaillierN := make(map[string]*big.Int, len(keyData.PeerPaillierPks))
+	for id, pk := range keyData.PeerPaillierPks {
+		if pk != nil {
+			peerPaillierN[id] = pk.N
+		}
+	}
+
 	return &IdentifySession{
-		params:      params,
-		myProof:     proof,
-		peerProofs:  make(map[string]*IdentifyProof),
-		peerPubKeys: peerPubKeys,
+		params:        params,
+		myProof:       proof,
+		peerProofs:    make(map[string]*IdentifyProof),
+		peerPubKeys:   peerPubKeys,
+		peerPaillierN: peerPaillierN,
+		failed:        make(map[string]error),
 	}, proof, nil
 }
 
+// NewIdentifySessionFromSaveData creates an identification session seeded
+// with the public key shares of every peer from the KeyGen output
+// (PeerXiX, PeerXiY), so peer proofs can be added without passing the
+// expected shares. It fails if the key data lacks the share of a member of
+// params.Parties.
+func NewIdentifySessionFromSaveData(params *tss.Parameters, keyData *keygen.LocalPartySaveData) (*IdentifySession, *IdentifyProof, error) {
+	s, proof, err := NewIdentifySession(params, keyData)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range params.Parties {
+		id := p.ID()
+		if id == params.PartyID.ID() {
+			continue
+		}
+		x, y := keyData.PeerXiX[id], keyData.PeerXiY[id]
+		if x == nil || y == nil {
+			return nil, nil, fmt.Errorf("%w: no public key share for party %s in key data", tss.ErrInvalidParameters, id)
+		}
+		s.peerPubKeys[id] = struct{ X, Y *big.Int }{x, y}
+	}
+	return s, proof, nil
+}
+
 // AddPeerProof adds and verifies a proof from another party.
 // expectedX, expectedY are the expected public key share coordinates for this party.
+// They may be nil for a session from NewIdentifySessionFromSaveData, which
+// then checks the share recorded by KeyGen.
+// A rejected proof from a member of params.Parties counts as a failure of
+// that peer (see Report and FailedPeers) until it sends a valid one.
 func (s *IdentifySession) AddPeerProof(proof *IdentifyProof, expectedX, expectedY *big.Int) error {
+	err := s.addPeerProof(proof, expectedX, expectedY)
+	if proof == nil || proof.PartyID == s.params.PartyID.ID() || !s.isPeer(proof.PartyID) {
+		return err
+	}
+	if err != nil {
+		s.failed[proof.PartyID] = err
+	} else {
+		delete(s.failed, proof.PartyID)
+	}
+	return err
+}
+
+func (s *IdentifySession) addPeerProof(proof *IdentifyProof, expectedX, expectedY *big.Int) error {
 	if proof == nil {
 		return errors.New("identify: proof cannot be nil")
 	}
@@ -118,13 +203,35 @@ func (s *IdentifySession) AddPeerProof(proof *IdentifyProof, expectedX, expected
 		return errors.New("identify: cannot add own proof as peer proof")
 	}
 
+	if !bytes.Equal(proof.SessionID, s.params.SessionID) {
+		return errors.New("identify: session ID mismatch")
+	}
+
 	// Verify the public key matches expected
+	if expectedX == nil && expectedY == nil && len(s.peerPubKeys) > 0 {
+		share, ok := s.peerPubKeys[proof.PartyID]
+		if !ok {
+			return fmt.Errorf("identify: unknown party %s", proof.PartyID)
+		}
+		expectedX, expectedY = share.X, share.Y
+	}
 	if expectedX != nil && expectedY != nil {
 		if proof.PublicKeyX.Cmp(expectedX) != 0 || proof.PublicKeyY.Cmp(expectedY) != 0 {
 			return errors.New("identify: public key mismatch")
 		}
 	}
 
+	// With IdentifyPaillierKey, the peer must prove possession of the Paillier
+	// key it used in KeyGen
+	if s.params.IdentifyPaillierKey {
+		if proof.PaillierProof == nil {
+			return errors.New("identify: missing Paillier key proof")
+		}
+		if n, ok := s.peerPaillierN[proof.PartyID]; ok && (proof.PaillierN == nil || proof.PaillierN.Cmp(n) != 0) {
+			return errors.New("identify: Paillier key mismatch")
+		}
+	}
+
 	// Verify the ZK proof
 	if !VerifyIdentifyProof(proof) {
 		return errors.New("identify: proof verification failed")
@@ -139,3 +246,38 @@ func (s *IdentifySession) IsComplete() bool {
 	expectedCount := len(s.params.Parties) - 1 // Exclude self
 	return len(s.peerProofs) >= expectedCount
 }
+
+// Report returns, for every other member of params.Parties, whether it has
+// been verified: true once its proof was accepted, false if its proof was
+// rejected or has not been added yet.
+func (s *IdentifySession) Report() map[string]bool {
+	report := make(map[string]bool, len(s.params.Parties))
+	for _, p := range s.params.Parties {
+		if id := p.ID(); id != s.params.PartyID.ID() {
+			_, ok := s.peerProofs[id]
+			report[id] = ok
+		}
+	}
+	return report
+}
+
+// FailedPeers returns the IDs of the peers whose last proof was rejected,
+// in sorted order. Peers that have not sent a proof are not listed.
+func (s *IdentifySession) FailedPeers() []string {
+	failed := make([]string, 0, len(s.failed))
+	for id := range s.failed {
+		failed = append(failed, id)
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// isPeer reports whether id is a member of params.Parties.
+func (s *IdentifySession) isPeer(id string) bool {
+	for _, p := range s.params.Parties {
+		if p.ID() == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package identify
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -166,6 +167,162 @@ func TestIdentifyProof(t *testing.T) {
 		}
 	})
 
+	t.Run("SessionFromSaveData", func(t *testing.T) {
+		paramsFor := func(i int) *tss.Parameters {
+			return &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-identify-keygen"),
+			}
+		}
+		sessions := make([]*IdentifySession, 3)
+		proofs := make([]*IdentifyProof, 3)
+		for i := 0; i < 3; i++ {
+			session, proof, err := NewIdentifySessionFromSaveData(paramsFor(i), keyData[i])
+			if err != nil {
+				t.Fatalf("Failed to create identify session for party %d: %v", i, err)
+			}
+			sessions[i] = session
+			proofs[i] = proof
+		}
+
+		// The expected shares come from the KeyGen output alone
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				if i == j {
+					continue
+				}
+				if err := sessions[i].AddPeerProof(proofs[j], nil, nil); err != nil {
+					t.Fatalf("Party %d failed to verify proof from party %d: %v", i, j, err)
+				}
+			}
+			if !sessions[i].IsComplete() {
+				t.Fatalf("Session %d not complete", i)
+			}
+		}
+
+		// A proof presented under another party's ID does not match its share
+		impostor := *proofs[1]
+		impostor.PartyID = parties[2].ID()
+		if err := sessions[0].AddPeerProof(&impostor, nil, nil); err == nil {
+			t.Fatal("Proof accepted against another party's share")
+		}
+
+		// Key data missing a peer's share cannot seed a session
+		partial := *keyData[0]
+		partial.PeerXiX = map[string]*big.Int{parties[1].ID(): keyData[0].PeerXiX[parties[1].ID()]}
+		if _, _, err := NewIdentifySessionFromSaveData(paramsFor(0), &partial); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+		}
+	})
+
+	t.Run("PaillierKeyProof", func(t *testing.T) {
+		paramsFor := func(i int) *tss.Parameters {
+			return &tss.Parameters{
+				PartyID:             parties[i],
+				Parties:             parties,
+				Threshold:           1,
+				Curve:               "secp256k1",
+				SessionID:           []byte("test-session-identify"),
+				IdentifyPaillierKey: true,
+			}
+		}
+		verifier, _, err := NewIdentifySession(paramsFor(1), keyData[1])
+		if err != nil {
+			t.Fatalf("Failed to create identify session: %v", err)
+		}
+
+		proof, err := NewIdentifyProof(paramsFor(0), keyData[0])
+		if err != nil {
+			t.Fatalf("Failed to create identify proof: %v", err)
+		}
+		if proof.PaillierProof == nil || proof.PaillierN.Cmp(keyData[0].PaillierPk.N) != 0 {
+			t.Fatal("Expected a proof for the party's Paillier key")
+		}
+		if err := verifier.AddPeerProof(proof, keyData[0].XiX, keyData[0].XiY); err != nil {
+			t.Fatalf("Valid extended proof rejected: %v", err)
+		}
+
+		// Party 3 claims party 1's Paillier key, which it cannot prove
+		cheat, err := NewIdentifyProof(paramsFor(2), keyData[2])
+		if err != nil {
+			t.Fatalf("Failed to create identify proof: %v", err)
+		}
+		cheat.PaillierN = keyData[0].PaillierPk.N
+		if VerifyIdentifyProof(cheat) {
+			t.Fatal("Proof for a Paillier key the party does not own verified")
+		}
+		if err := verifier.AddPeerProof(cheat, keyData[2].XiX, keyData[2].XiY); err == nil {
+			t.Fatal("Session accepted a claimed Paillier key")
+		}
+
+		// Without the Paillier part the extended identification fails
+		plain, err := NewIdentifyProof(&tss.Parameters{PartyID: parties[2], Parties: parties, SessionID: []byte("test-session-identify")}, keyData[2])
+		if err != nil {
+			t.Fatalf("Failed to create identify proof: %v", err)
+		}
+		if err := verifier.AddPeerProof(plain, keyData[2].XiX, keyData[2].XiY); err == nil {
+			t.Fatal("Session accepted a proof without the Paillier part")
+		}
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		newParams := func(i int) *tss.Parameters {
+			return &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-report"),
+			}
+		}
+		session, _, err := NewIdentifySessionFromSaveData(newParams(0), keyData[0])
+		if err != nil {
+			t.Fatalf("Failed to create identify session: %v", err)
+		}
+		good, err := NewIdentifyProof(newParams(1), keyData[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad, err := NewIdentifyProof(newParams(2), keyData[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad.Proof.S.Add(bad.Proof.S, big.NewInt(1))
+
+		if err := session.AddPeerProof(good, nil, nil); err != nil {
+			t.Fatalf("Valid proof rejected: %v", err)
+		}
+		if err := session.AddPeerProof(bad, nil, nil); err == nil {
+			t.Fatal("Tampered proof accepted")
+		}
+
+		report := session.Report()
+		if len(report) != 2 || !report["2"] || report["3"] {
+			t.Fatalf("Expected report map[2:true 3:false], got %v", report)
+		}
+		if failed := session.FailedPeers(); len(failed) != 1 || failed[0] != "3" {
+			t.Fatalf("Expected failed peers [3], got %v", failed)
+		}
+		if session.IsComplete() {
+			t.Fatal("Session with a failed peer is complete")
+		}
+
+		// A valid proof clears the failure
+		bad.Proof.S.Sub(bad.Proof.S, big.NewInt(1))
+		if err := session.AddPeerProof(bad, nil, nil); err != nil {
+			t.Fatalf("Restored proof rejected: %v", err)
+		}
+		if failed := session.FailedPeers(); len(failed) != 0 {
+			t.Fatalf("Expected no failed peers, got %v", failed)
+		}
+		if report := session.Report(); !report["2"] || !report["3"] {
+			t.Fatalf("Expected every peer verified, got %v", report)
+		}
+	})
+
 	t.Run("InvalidProofRejected", func(t *testing.T) {
 		params := &tss.Parameters{
 			PartyID:   parties[0],
@@ -0,0 +1,11 @@
+//go:build tsstest
+
+package identify
+
+import "github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+
+// With -tags tsstest, Paillier keys come from cached primes (see
+// paillier.SetTestKeySource).
+func init() {
+	paillier.SetTestKeySource(true)
+}
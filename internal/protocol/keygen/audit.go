@@ -0,0 +1,90 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+// PaillierAudit is the public Paillier setup of one committee member, for
+// verifiers outside the committee.
+type PaillierAudit struct {
+	N *big.Int // Paillier modulus
+
+	// Ring-Pedersen parameters over N, with the proof (Pi^prm) that s lies in
+	// the group generated by t. Nil if the key data does not hold them, e.g.
+	// after a one-round KeyGen.
+	Pedersen *commitment.PedersenParams
+}
+
+// ExportPaillierSetup returns the Paillier setup of every committee member
+// known to saveData, the local party included, keyed by PartyID.ID(). The
+// result holds no secrets and shares no memory with saveData.
+//
+// KeyGen does not yet prove that N is a Blum integer (Pi^mod of CGGMP21), so
+// the setup carries no such proof; PaillierAudit.Verify re-checks the proofs
+// there are.
+func ExportPaillierSetup(saveData *LocalPartySaveData) map[string]*PaillierAudit {
+	out := make(map[string]*PaillierAudit)
+	if saveData == nil {
+		return out
+	}
+	add := func(id string, pk *paillier.PublicKey, pedersen *commitment.PedersenParams) {
+		if pk == nil || pk.N == nil {
+			return
+		}
+		out[id] = &PaillierAudit{N: copyInt(pk.N), Pedersen: copyPedersen(pedersen)}
+	}
+	for id, pk := range saveData.PeerPaillierPks {
+		add(id, pk, saveData.PeerPedersenParams[id])
+	}
+	if saveData.LocalPartyID != nil {
+		add(saveData.LocalPartyID.ID(), saveData.PaillierPk, saveData.PedersenParams)
+	}
+	return out
+}
+
+// Verify re-checks the setup: N is an odd modulus of the size signers accept
+// (paillier.KeyBits, or one bit shorter), and the ring-Pedersen parameters
+// are built over N with a valid proof, as KeyGen Round 4 checks.
+func (a *PaillierAudit) Verify() error {
+	if a == nil || a.N == nil {
+		return errors.New("paillier audit: missing modulus")
+	}
+	if bits := a.N.BitLen(); a.N.Bit(0) == 0 || bits < paillier.KeyBits-1 || bits > paillier.KeyBits {
+		return fmt.Errorf("paillier audit: modulus must be odd with %d bits", paillier.KeyBits)
+	}
+	if a.Pedersen == nil {
+		return errors.New("paillier audit: no ring-Pedersen proof")
+	}
+	if a.Pedersen.N == nil || a.Pedersen.N.Cmp(a.N) != 0 {
+		return errors.New("paillier audit: pedersen modulus does not match paillier modulus")
+	}
+	if err := a.Pedersen.Validate(); err != nil {
+		return fmt.Errorf("paillier audit: %w", err)
+	}
+	return nil
+}
+
+func copyPedersen(pp *commitment.PedersenParams) *commitment.PedersenParams {
+	if pp == nil {
+		return nil
+	}
+	out := &commitment.PedersenParams{N: copyInt(pp.N), S: copyInt(pp.S), T: copyInt(pp.T)}
+	if pp.Proof != nil {
+		out.Proof = &commitment.PedersenProof{
+			A: make([]*big.Int, len(pp.Proof.A)),
+			Z: make([]*big.Int, len(pp.Proof.Z)),
+		}
+		for i, v := range pp.Proof.A {
+			out.Proof.A[i] = copyInt(v)
+		}
+		for i, v := range pp.Proof.Z {
+			out.Proof.Z[i] = copyInt(v)
+		}
+	}
+	return out
+}
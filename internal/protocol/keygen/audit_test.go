@@ -0,0 +1,84 @@
+package keygen
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestExportPaillierSetup(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-audit"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+	results := make([]*LocalPartySaveData, 3)
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+		results[i] = data
+	}
+
+	// An external verifier only gets the serialized setup
+	data, err := json.Marshal(ExportPaillierSetup(results[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var setup map[string]*PaillierAudit
+	if err := json.Unmarshal(data, &setup); err != nil {
+		t.Fatal(err)
+	}
+	if len(setup) != len(parties) {
+		t.Fatalf("Exported %d setups, want %d", len(setup), len(parties))
+	}
+	for _, d := range results {
+		id := d.LocalPartyID.ID()
+		audit := setup[id]
+		if audit == nil || audit.N.Cmp(d.PaillierPk.N) != 0 {
+			t.Fatalf("Wrong Paillier modulus for %s", id)
+		}
+		if err := audit.Verify(); err != nil {
+			t.Fatalf("Setup of %s does not verify: %v", id, err)
+		}
+	}
+
+	// The export shares no memory with the key data
+	exported := ExportPaillierSetup(results[0])
+	exported["2"].Pedersen.Proof.Z[0].SetInt64(1)
+	if results[0].PeerPedersenParams["2"].Proof.Z[0].Cmp(big.NewInt(1)) == 0 {
+		t.Fatal("ExportPaillierSetup aliases the key data")
+	}
+
+	// A tampered proof or a modulus swapped for another party's is caught
+	if err := exported["2"].Verify(); err == nil {
+		t.Fatal("Tampered proof verified")
+	}
+	swapped := setup["3"]
+	swapped.N = setup["1"].N
+	if err := swapped.Verify(); err == nil {
+		t.Fatal("Pedersen parameters verified for another modulus")
+	}
+}
@@ -0,0 +1,280 @@
+package keygen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// BatchKeyGenResult holds the result of a batch keygen.
+type BatchKeyGenResult struct {
+	Keys []*LocalPartySaveData // One independent key per instance
+}
+
+// batchMessageType is the Type() of every message of a batch keygen session.
+const batchMessageType = "KeyGenBatch"
+
+// BatchPayload carries the messages of every instance of a batch for one
+// recipient: Payloads[i] is the payload instance i sent, all of the same
+// Type.
+type BatchPayload struct {
+	Type     string
+	Payloads [][]byte
+}
+
+// NewBatchStateMachine creates a state machine that generates count
+// independent keys in one session. Its result is a *BatchKeyGenResult.
+//
+// The session runs one keygen instance per key in lockstep, each with its own
+// secret and VSS polynomial. In every round the messages of all instances to
+// the same recipient travel as one message, so the batch needs as many
+// messages as a single keygen. All instances share one Paillier key, which
+// is generated once (or restored from params.PaillierCheckpointData and
+// handed to params.PaillierCheckpoint as in NewStateMachine).
+func NewBatchStateMachine(params *tss.Parameters, count int) (tss.StateMachine, []tss.Message, error) {
+	b, out, err := NewBatch(params, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tss.Wrap(b.params)(b, out, nil)
+}
+
+// batchState multiplexes one keygen state per key over a single session.
+type batchState struct {
+	params *tss.Parameters
+
+	instances []tss.StateMachine // One per key
+	sessions  [][]byte           // Session ID of each instance
+}
+
+// NewBatch creates a batch keygen session, without the message
+// authentication and diagnostics wrappers that NewBatchStateMachine applies
+// from params.
+func NewBatch(params *tss.Parameters, count int) (*batchState, []tss.Message, error) {
+	if count <= 0 {
+		return nil, nil, fmt.Errorf("%w: batch of %d keys", tss.ErrInvalidParameters, count)
+	}
+	params, err := params.Canonical()
+	if err != nil {
+		return nil, nil, err
+	}
+	curve, err := params.ResolveCurve()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// One Paillier key for the whole batch; every instance restores it from
+	// the same checkpoint instead of generating its own
+	shared := &state{params: params}
+	paillierSk, err := shared.paillierKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	checkpoint := encodePaillierCheckpoint(paillierSk)
+
+	b := &batchState{
+		params:    params,
+		instances: make([]tss.StateMachine, count),
+		sessions:  make([][]byte, count),
+	}
+	outs := make([][]tss.Message, count)
+	for i := 0; i < count; i++ {
+		instanceParams := *params
+		instanceParams.SessionID = tss.DeriveSessionID(fmt.Sprintf("keygen-batch/%d", i), params.Parties, params.SessionID)
+		instanceParams.PaillierCheckpoint = nil
+		instanceParams.PaillierCheckpointData = checkpoint
+		b.sessions[i] = instanceParams.SessionID
+
+		s := &state{
+			params: &instanceParams,
+			curve:  curve,
+			round:  1,
+			saveData: &LocalPartySaveData{
+				LocalPartyID: params.PartyID,
+			},
+			tempData:     make(map[string]interface{}),
+			receivedMsgs: make(map[string][]tss.Message),
+		}
+		if params.OneRoundKeyGen {
+			b.instances[i], outs[i], err = s.round1Direct()
+		} else {
+			b.instances[i], outs[i], err = s.round1()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	out, err := b.pack(outs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, out, nil
+}
+
+// Update unpacks a batch message into one message per instance and feeds
+// them to the instances.
+func (b *batchState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	// Messages replayed from another session are never processed
+	if err := b.params.CheckSession(msg); err != nil {
+		return b, nil, err
+	}
+	if b.params.IsOwnMessage(msg) {
+		return b, nil, nil
+	}
+	if msg.Type() != batchMessageType {
+		return nil, nil, tss.NewBlame(msg.From(), fmt.Sprintf("unexpected %s message in batch keygen", msg.Type()), tss.ErrInvalidMsg)
+	}
+
+	var payload BatchPayload
+	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+		return nil, nil, tss.NewBlame(msg.From(), "malformed batch message", err)
+	}
+	if len(payload.Payloads) != len(b.instances) {
+		return nil, nil, tss.NewBlame(msg.From(), fmt.Sprintf("batch message has %d payloads, expected %d", len(payload.Payloads), len(b.instances)), tss.ErrInvalidMsg)
+	}
+
+	outs := make([][]tss.Message, len(b.instances))
+	for i, sm := range b.instances {
+		inner := &KeyGenMessage{
+			FromParty:  msg.From(),
+			ToParties:  msg.To(),
+			IsBcast:    msg.IsBroadcast(),
+			Data:       payload.Payloads[i],
+			TypeString: payload.Type,
+			RoundNum:   msg.RoundNumber(),
+			Session:    b.sessions[i],
+		}
+		next, out, err := sm.Update(inner)
+		if err != nil {
+			// The instances can no longer stay in lockstep
+			return &abortedState{err: err}, nil, err
+		}
+		if next != nil {
+			b.instances[i] = next
+		}
+		outs[i] = out
+	}
+
+	out, err := b.pack(outs)
+	if err != nil {
+		return &abortedState{err: err}, nil, err
+	}
+	if b.instances[0].Result() == nil {
+		return b, out, nil
+	}
+	return b.finish(out)
+}
+
+// finish collects the keys once every instance is done.
+func (b *batchState) finish(out []tss.Message) (tss.StateMachine, []tss.Message, error) {
+	keys := make([]*LocalPartySaveData, len(b.instances))
+	for i, sm := range b.instances {
+		data, ok := sm.Result().(*LocalPartySaveData)
+		if !ok {
+			err := fmt.Errorf("batch keygen instance %d did not finish", i)
+			return &abortedState{err: err}, nil, err
+		}
+		keys[i] = data
+	}
+	return &batchFinishedState{keys: keys}, out, nil
+}
+
+// pack merges the outgoing messages of all instances into one message per
+// recipient. The instances run in lockstep, so each must have produced
+// messages of the same types for the same recipients.
+func (b *batchState) pack(outs [][]tss.Message) ([]tss.Message, error) {
+	batches := make(map[string]*BatchPayload)
+	var order []tss.Message // First instance's message for each key
+	for i, out := range outs {
+		if len(out) != len(outs[0]) {
+			return nil, fmt.Errorf("batch instance %d sent %d messages, instance 0 sent %d", i, len(out), len(outs[0]))
+		}
+		for _, m := range out {
+			key := batchKey(m)
+			payload, ok := batches[key]
+			if !ok {
+				if i != 0 {
+					return nil, fmt.Errorf("batch instance %d sent an unmatched %s message", i, m.Type())
+				}
+				payload = &BatchPayload{Type: m.Type(), Payloads: make([][]byte, len(outs))}
+				batches[key] = payload
+				order = append(order, m)
+			}
+			if payload.Payloads[i] != nil {
+				return nil, fmt.Errorf("batch instance %d sent two %s messages to the same recipients", i, m.Type())
+			}
+			payload.Payloads[i] = m.Payload()
+		}
+	}
+
+	packed := make([]tss.Message, 0, len(order))
+	for _, m := range order {
+		data, err := json.Marshal(batches[batchKey(m)])
+		if err != nil {
+			return nil, err
+		}
+		packed = append(packed, &KeyGenMessage{
+			FromParty:  b.params.PartyID,
+			ToParties:  m.To(),
+			IsBcast:    m.IsBroadcast(),
+			Data:       data,
+			TypeString: batchMessageType,
+			RoundNum:   m.RoundNumber(),
+			Session:    b.params.SessionID,
+		})
+	}
+	return packed, nil
+}
+
+// batchKey identifies a message by type, round and recipients.
+func batchKey(m tss.Message) string {
+	to := make([]string, len(m.To()))
+	for i, p := range m.To() {
+		to[i] = p.ID()
+	}
+	sort.Strings(to)
+	return fmt.Sprintf("%s|%d|%t|%s", m.Type(), m.RoundNumber(), m.IsBroadcast(), strings.Join(to, ","))
+}
+
+// Result returns nil while batch keygen is in progress.
+func (b *batchState) Result() interface{} {
+	return nil
+}
+
+// Details returns a string describing the current state.
+func (b *batchState) Details() string {
+	return fmt.Sprintf("Batch KeyGen (%d keys): %s", len(b.instances), b.instances[0].Details())
+}
+
+// WaitingFor returns the peers whose messages for the current round are
+// still outstanding. All instances wait for the same messages.
+func (b *batchState) WaitingFor() []tss.PartyID {
+	return tss.WaitingFor(b.instances[0])
+}
+
+// RemainingForRound returns how many messages the current round still
+// needs. Every message carries all instances, so this is the count of one
+// instance.
+func (b *batchState) RemainingForRound() int {
+	return tss.RemainingForRound(b.instances[0])
+}
+
+// batchFinishedState represents the completed batch keygen state.
+type batchFinishedState struct {
+	keys []*LocalPartySaveData
+}
+
+func (b *batchFinishedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (b *batchFinishedState) Result() interface{} {
+	return &BatchKeyGenResult{Keys: b.keys}
+}
+
+func (b *batchFinishedState) Details() string {
+	return "Batch KeyGen Finished"
+}
@@ -0,0 +1,331 @@
+package keygen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// ErrUnsupportedVersion is returned by UnmarshalLocalPartySaveData for key
+// data written by a newer version of this package.
+var ErrUnsupportedVersion = errors.New("unsupported key data version")
+
+// saveDataVersion is the first byte of MarshalBinary output.
+const saveDataVersion byte = 1
+
+// MarshalBinary encodes the key data, secrets included, as a version byte
+// followed by length-prefixed fields. Version 1 holds, in order:
+//
+//	LocalPartyID (ID, Moniker, Key)
+//	ECDSAPubX, ECDSAPubY, ShareID, ShareIDs
+//	Paillier N, Lambda, Mu, PeerPaillierPks
+//	PedersenParams, PeerPedersenParams
+//	Ui, Xi, XiX, XiY, PeerXiX, PeerXiY, PublicKeyX, PublicKeyY
+//	Epoch, Mode, Threshold
+//
+// A new field gets a new version that appends it to the list, and
+// UnmarshalLocalPartySaveData keeps reading every older version, leaving the
+// fields it lacks at their zero value.
+func (d *LocalPartySaveData) MarshalBinary() ([]byte, error) {
+	if d.Threshold < 0 {
+		return nil, fmt.Errorf("%w: negative threshold", tss.ErrInvalidParameters)
+	}
+	w := &binaryWriter{buf: []byte{saveDataVersion}}
+
+	w.optional(d.LocalPartyID != nil, func(w *binaryWriter) {
+		w.bytes([]byte(d.LocalPartyID.ID()))
+		w.bytes([]byte(d.LocalPartyID.Moniker()))
+		w.bytes(d.LocalPartyID.Key())
+	})
+	w.int(d.ECDSAPubX)
+	w.int(d.ECDSAPubY)
+	w.int(d.ShareID)
+	w.intMap(d.ShareIDs)
+
+	var n, lambda, mu *big.Int
+	if d.PaillierPk != nil {
+		n = d.PaillierPk.N
+	}
+	if d.PaillierSk != nil {
+		n, lambda, mu = d.PaillierSk.N, d.PaillierSk.Lambda, d.PaillierSk.Mu
+	}
+	w.int(n)
+	w.int(lambda)
+	w.int(mu)
+	peerN := make(map[string]*big.Int, len(d.PeerPaillierPks))
+	for id, pk := range d.PeerPaillierPks {
+		if pk != nil {
+			peerN[id] = pk.N
+		}
+	}
+	w.optional(d.PeerPaillierPks != nil, func(w *binaryWriter) { writeEntries(w, peerN, w.int) })
+
+	w.pedersen(d.PedersenParams)
+	w.optional(d.PeerPedersenParams != nil, func(w *binaryWriter) { writeEntries(w, d.PeerPedersenParams, w.pedersen) })
+
+	w.int(d.Ui)
+	w.int(d.Xi)
+	w.int(d.XiX)
+	w.int(d.XiY)
+	w.intMap(d.PeerXiX)
+	w.intMap(d.PeerXiY)
+	w.int(d.PublicKeyX)
+	w.int(d.PublicKeyY)
+
+	w.uint(d.Epoch)
+	w.bytes([]byte(d.Mode))
+	w.uint(uint64(d.Threshold))
+	return w.buf, nil
+}
+
+// UnmarshalLocalPartySaveData decodes key data written by MarshalBinary, of
+// this or any older version. LocalPartyID is decoded as a *tss.BasicPartyID.
+func UnmarshalLocalPartySaveData(data []byte) (*LocalPartySaveData, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty key data", tss.ErrInvalidParameters)
+	}
+	version := data[0]
+	if version == 0 || version > saveDataVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedVersion, version)
+	}
+
+	r := &binaryReader{rest: data[1:]}
+	d := &LocalPartySaveData{}
+	r.optional(func(r *binaryReader) {
+		d.LocalPartyID = &tss.BasicPartyID{
+			IDVal:      string(r.bytes()),
+			MonikerVal: string(r.bytes()),
+			KeyVal:     r.bytes(),
+		}
+	})
+	d.ECDSAPubX = r.int()
+	d.ECDSAPubY = r.int()
+	d.ShareID = r.int()
+	d.ShareIDs = r.intMap()
+
+	n, lambda, mu := r.int(), r.int(), r.int()
+	if n != nil {
+		if lambda != nil || mu != nil {
+			d.PaillierSk = &paillier.PrivateKey{PublicKey: *paillier.NewPublicKey(n), Lambda: lambda, Mu: mu}
+			d.PaillierPk = &d.PaillierSk.PublicKey
+		} else {
+			d.PaillierPk = paillier.NewPublicKey(n)
+		}
+	}
+	r.optional(func(r *binaryReader) {
+		d.PeerPaillierPks = make(map[string]*paillier.PublicKey)
+		r.entries(func(id string) {
+			if n := r.int(); n != nil {
+				d.PeerPaillierPks[id] = paillier.NewPublicKey(n)
+			}
+		})
+	})
+
+	d.PedersenParams = r.pedersen()
+	r.optional(func(r *binaryReader) {
+		d.PeerPedersenParams = make(map[string]*commitment.PedersenParams)
+		r.entries(func(id string) { d.PeerPedersenParams[id] = r.pedersen() })
+	})
+
+	d.Ui = r.int()
+	d.Xi = r.int()
+	d.XiX = r.int()
+	d.XiY = r.int()
+	d.PeerXiX = r.intMap()
+	d.PeerXiY = r.intMap()
+	d.PublicKeyX = r.int()
+	d.PublicKeyY = r.int()
+
+	d.Epoch = r.uint()
+	d.Mode = string(r.bytes())
+	threshold := r.uint()
+	if r.err == nil && threshold > uint64(^uint32(0)) {
+		r.err = fmt.Errorf("threshold %d out of range", threshold)
+	}
+	d.Threshold = int(threshold)
+
+	// Fields added by later versions are read here, guarded by version
+
+	if r.err == nil && len(r.rest) != 0 {
+		r.err = fmt.Errorf("%d trailing bytes", len(r.rest))
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: key data version %d: %v", tss.ErrInvalidParameters, version, r.err)
+	}
+	return d, nil
+}
+
+// binaryWriter appends length-prefixed fields to buf. Optional values are a
+// field that is empty when the value is absent and otherwise holds a 1 byte
+// followed by the value, so a zero integer or an empty map round-trips.
+type binaryWriter struct {
+	buf []byte
+}
+
+func (w *binaryWriter) bytes(b []byte) {
+	w.buf = appendField(w.buf, b)
+}
+
+func (w *binaryWriter) uint(v uint64) {
+	w.bytes(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (w *binaryWriter) int(x *big.Int) {
+	if x == nil {
+		w.bytes(nil)
+		return
+	}
+	w.bytes(append([]byte{1}, x.Bytes()...))
+}
+
+// optional writes the fields f writes as one optional field.
+func (w *binaryWriter) optional(present bool, f func(w *binaryWriter)) {
+	if !present {
+		w.bytes(nil)
+		return
+	}
+	inner := &binaryWriter{buf: []byte{1}}
+	f(inner)
+	w.bytes(inner.buf)
+}
+
+func (w *binaryWriter) intMap(m map[string]*big.Int) {
+	w.optional(m != nil, func(w *binaryWriter) { writeEntries(w, m, w.int) })
+}
+
+func (w *binaryWriter) pedersen(pp *commitment.PedersenParams) {
+	w.optional(pp != nil, func(w *binaryWriter) {
+		w.int(pp.N)
+		w.int(pp.S)
+		w.int(pp.T)
+		w.optional(pp.Proof != nil, func(w *binaryWriter) {
+			w.intList(pp.Proof.A)
+			w.intList(pp.Proof.Z)
+		})
+	})
+}
+
+func (w *binaryWriter) intList(xs []*big.Int) {
+	w.optional(true, func(w *binaryWriter) {
+		for _, x := range xs {
+			w.int(x)
+		}
+	})
+}
+
+// writeEntries writes the entries of m as key and value fields, sorted by
+// key.
+func writeEntries[V any](w *binaryWriter, m map[string]V, value func(V)) {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		w.bytes([]byte(id))
+		value(m[id])
+	}
+}
+
+// binaryReader reads the fields binaryWriter writes. The first error sticks:
+// later reads return zero values.
+type binaryReader struct {
+	rest []byte
+	err  error
+}
+
+func (r *binaryReader) bytes() []byte {
+	if r.err != nil {
+		return nil
+	}
+	var field []byte
+	field, r.rest, r.err = readField(r.rest)
+	return field
+}
+
+func (r *binaryReader) uint() uint64 {
+	field := r.bytes()
+	if r.err != nil {
+		return 0
+	}
+	if len(field) != 8 {
+		r.err = fmt.Errorf("integer field of %d bytes", len(field))
+		return 0
+	}
+	return binary.BigEndian.Uint64(field)
+}
+
+func (r *binaryReader) int() *big.Int {
+	field := r.bytes()
+	if r.err != nil || len(field) == 0 {
+		return nil
+	}
+	if field[0] != 1 {
+		r.err = errors.New("malformed integer")
+		return nil
+	}
+	return new(big.Int).SetBytes(field[1:])
+}
+
+// optional reads an optional field and, if present, parses its contents with
+// f, which must consume all of them.
+func (r *binaryReader) optional(f func(r *binaryReader)) {
+	field := r.bytes()
+	if r.err != nil || len(field) == 0 {
+		return
+	}
+	if field[0] != 1 {
+		r.err = errors.New("malformed optional field")
+		return
+	}
+	inner := &binaryReader{rest: field[1:]}
+	f(inner)
+	if inner.err == nil && len(inner.rest) != 0 {
+		inner.err = fmt.Errorf("%d trailing bytes in field", len(inner.rest))
+	}
+	r.err = inner.err
+}
+
+// entries calls value with the key of each entry, for it to read the value.
+func (r *binaryReader) entries(value func(id string)) {
+	for r.err == nil && len(r.rest) > 0 {
+		value(string(r.bytes()))
+	}
+}
+
+func (r *binaryReader) intMap() map[string]*big.Int {
+	var m map[string]*big.Int
+	r.optional(func(r *binaryReader) {
+		m = make(map[string]*big.Int)
+		r.entries(func(id string) { m[id] = r.int() })
+	})
+	return m
+}
+
+func (r *binaryReader) pedersen() *commitment.PedersenParams {
+	var pp *commitment.PedersenParams
+	r.optional(func(r *binaryReader) {
+		pp = &commitment.PedersenParams{N: r.int(), S: r.int(), T: r.int()}
+		r.optional(func(r *binaryReader) {
+			pp.Proof = &commitment.PedersenProof{A: r.intList(), Z: r.intList()}
+		})
+	})
+	return pp
+}
+
+func (r *binaryReader) intList() []*big.Int {
+	var xs []*big.Int
+	r.optional(func(r *binaryReader) {
+		xs = []*big.Int{}
+		for r.err == nil && len(r.rest) > 0 {
+			xs = append(xs, r.int())
+		}
+	})
+	return xs
+}
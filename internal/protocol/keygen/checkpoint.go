@@ -0,0 +1,75 @@
+package keygen
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// paillierCheckpointVersion prefixes the checkpoint encoding, so a format
+// change is detected instead of misparsed.
+const paillierCheckpointVersion = "go-cggmp-tss/keygen/paillier-checkpoint/v1"
+
+// paillierKey returns the Paillier key for Round 1: the one restored from
+// params.PaillierCheckpointData if set, otherwise a freshly generated key,
+// which is handed to params.PaillierCheckpoint before the round continues.
+func (s *state) paillierKey() (*paillier.PrivateKey, error) {
+	if s.params.PaillierCheckpointData != nil {
+		return decodePaillierCheckpoint(s.params.PaillierCheckpointData)
+	}
+
+	sk, err := paillier.GenerateKeyWithConcurrency(rand.Reader, paillier.KeyBits, s.params.Concurrency())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate paillier key: %w", err)
+	}
+	if s.params.PaillierCheckpoint != nil {
+		s.params.PaillierCheckpoint(encodePaillierCheckpoint(sk))
+	}
+	return sk, nil
+}
+
+// encodePaillierCheckpoint serializes the private key as length-prefixed
+// fields: version || N || lambda || mu.
+func encodePaillierCheckpoint(sk *paillier.PrivateKey) []byte {
+	var buf []byte
+	buf = appendField(buf, []byte(paillierCheckpointVersion))
+	buf = appendField(buf, sk.N.Bytes())
+	buf = appendField(buf, sk.Lambda.Bytes())
+	buf = appendField(buf, sk.Mu.Bytes())
+	return buf
+}
+
+// decodePaillierCheckpoint parses and validates a checkpoint written by
+// encodePaillierCheckpoint.
+func decodePaillierCheckpoint(data []byte) (*paillier.PrivateKey, error) {
+	fields := make([][]byte, 4)
+	rest := data
+	for i := range fields {
+		var err error
+		fields[i], rest, err = readField(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: paillier checkpoint: %v", tss.ErrInvalidParameters, err)
+		}
+	}
+	if len(rest) != 0 || !bytes.Equal(fields[0], []byte(paillierCheckpointVersion)) {
+		return nil, fmt.Errorf("%w: unrecognized paillier checkpoint", tss.ErrInvalidParameters)
+	}
+
+	sk := &paillier.PrivateKey{
+		PublicKey: *paillier.NewPublicKey(new(big.Int).SetBytes(fields[1])),
+		Lambda:    new(big.Int).SetBytes(fields[2]),
+		Mu:        new(big.Int).SetBytes(fields[3]),
+	}
+	// Signers accept KeyBits-1 bits as well, see sign.checkPaillierSize
+	if bits := sk.N.BitLen(); bits < paillier.KeyBits-1 || bits > paillier.KeyBits {
+		return nil, fmt.Errorf("%w: paillier checkpoint modulus is %d bits, want %d", tss.ErrInvalidParameters, bits, paillier.KeyBits)
+	}
+	if err := sk.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: paillier checkpoint: %v", tss.ErrInvalidParameters, err)
+	}
+	return sk, nil
+}
@@ -0,0 +1,55 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCommitteeMismatch is returned by SameCommittee when shares come from
+// different ceremonies.
+var ErrCommitteeMismatch = errors.New("shares belong to different committees")
+
+// SameCommittee checks that all shares come from the same KeyGen ceremony:
+// the same group public key, the same committee indices and the same epoch
+// (see LocalPartySaveData.Epoch). It catches operators mixing up save data
+// from different keygens, or from before and after a Refresh.
+func SameCommittee(shares ...*LocalPartySaveData) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("%w: no shares given", ErrCommitteeMismatch)
+	}
+	for i, share := range shares {
+		if share == nil || share.PublicKeyX == nil || share.PublicKeyY == nil {
+			return fmt.Errorf("%w: share %d is missing its public key", ErrCommitteeMismatch, i)
+		}
+		if share.LocalPartyID != nil && len(share.ShareIDs) > 0 {
+			idx, ok := share.ShareIDs[share.LocalPartyID.ID()]
+			if !ok {
+				return fmt.Errorf("%w: share %d: party %s is not in its own committee", ErrCommitteeMismatch, i, share.LocalPartyID.ID())
+			}
+			if share.ShareID != nil && share.ShareID.Cmp(idx) != 0 {
+				return fmt.Errorf("%w: share %d: share index does not match committee", ErrCommitteeMismatch, i)
+			}
+		}
+	}
+
+	first := shares[0]
+	for i, share := range shares[1:] {
+		i++
+		if share.PublicKeyX.Cmp(first.PublicKeyX) != 0 || share.PublicKeyY.Cmp(first.PublicKeyY) != 0 {
+			return fmt.Errorf("%w: share %d has a different public key", ErrCommitteeMismatch, i)
+		}
+		if share.Epoch != first.Epoch {
+			return fmt.Errorf("%w: share %d is from epoch %d, share 0 from epoch %d", ErrCommitteeMismatch, i, share.Epoch, first.Epoch)
+		}
+		if len(share.ShareIDs) != len(first.ShareIDs) {
+			return fmt.Errorf("%w: share %d has %d committee members, share 0 has %d", ErrCommitteeMismatch, i, len(share.ShareIDs), len(first.ShareIDs))
+		}
+		for id, idx := range first.ShareIDs {
+			other, ok := share.ShareIDs[id]
+			if !ok || other.Cmp(idx) != 0 {
+				return fmt.Errorf("%w: share %d has a different index for party %s", ErrCommitteeMismatch, i, id)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package keygen
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSameCommittee(t *testing.T) {
+	a := runDirectKeyGen(t, make([]tss.Logger, 3))
+	b := runDirectKeyGen(t, make([]tss.Logger, 3))
+
+	if err := SameCommittee(a...); err != nil {
+		t.Fatalf("Shares of one keygen rejected: %v", err)
+	}
+	if err := SameCommittee(a[0], a[2]); err != nil {
+		t.Fatalf("Signing subset rejected: %v", err)
+	}
+
+	// Shares of two keygens with the same parties
+	if err := SameCommittee(a[0], b[1]); !errors.Is(err, ErrCommitteeMismatch) {
+		t.Fatalf("Expected ErrCommitteeMismatch for mixed keygens, got %v", err)
+	}
+
+	// Same key, but one share is from a later epoch
+	refreshed := *a[1]
+	refreshed.Epoch++
+	if err := SameCommittee(a[0], &refreshed); !errors.Is(err, ErrCommitteeMismatch) {
+		t.Fatalf("Expected ErrCommitteeMismatch for mixed epochs, got %v", err)
+	}
+
+	// Same key, but a different committee layout
+	reindexed := *a[1]
+	reindexed.ShareIDs = map[string]*big.Int{"1": big.NewInt(1), "2": big.NewInt(2), "4": big.NewInt(3)}
+	if err := SameCommittee(a[0], &reindexed); !errors.Is(err, ErrCommitteeMismatch) {
+		t.Fatalf("Expected ErrCommitteeMismatch for different indices, got %v", err)
+	}
+
+	if err := SameCommittee(); !errors.Is(err, ErrCommitteeMismatch) {
+		t.Fatalf("Expected ErrCommitteeMismatch for no shares, got %v", err)
+	}
+}
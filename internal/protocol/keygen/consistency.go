@@ -0,0 +1,108 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// ErrInconsistentShares is returned by VerifyConsistency when the public key
+// shares do not reconstruct the group public key.
+var ErrInconsistentShares = errors.New("public key shares do not match the public key")
+
+// VerifyConsistency checks that the public key shares X_j, keyed by
+// PartyID.ID(), reconstruct the group public key:
+//
+//	sum_j lambda_j * X_j == X
+//
+// with lambda_j the Lagrange coefficients at 0 over the shares' indices
+// (ShareIDs). The local X_i is added if peerShares lacks it. A nil
+// peerShares checks the shares recorded by KeyGen (PeerXiX, PeerXiY).
+//
+// The shares must come from a qualified set, i.e. at least t+1 parties: fewer
+// shares do not determine X and fail the check. This is a sanity check for
+// operators after KeyGen; Round 4 already checks each X_j against the VSS
+// commitments.
+func (d *LocalPartySaveData) VerifyConsistency(peerShares map[string]tss.PublicKey) error {
+	if d.PublicKeyX == nil || d.PublicKeyY == nil {
+		return fmt.Errorf("%w: key data has no public key", tss.ErrInvalidParameters)
+	}
+	curve, err := d.curve()
+	if err != nil {
+		return err
+	}
+
+	shares := make(map[string]tss.PublicKey, len(peerShares)+1)
+	if peerShares == nil {
+		for id, x := range d.PeerXiX {
+			shares[id] = tss.PublicKey{X: x, Y: d.PeerXiY[id]}
+		}
+	} else {
+		for id, share := range peerShares {
+			shares[id] = share
+		}
+	}
+	if d.LocalPartyID != nil && d.XiX != nil {
+		if _, ok := shares[d.LocalPartyID.ID()]; !ok {
+			shares[d.LocalPartyID.ID()] = tss.PublicKey{X: d.XiX, Y: d.XiY}
+		}
+	}
+	if len(shares) == 0 {
+		return fmt.Errorf("%w: no public key shares", tss.ErrInvalidParameters)
+	}
+
+	ids := make([]string, 0, len(shares))
+	indices := make([]*big.Int, 0, len(shares))
+	for id := range shares {
+		idx, ok := d.ShareIDs[id]
+		if !ok {
+			return fmt.Errorf("%w: party %s is not in the committee", tss.ErrInvalidParameters, id)
+		}
+		ids = append(ids, id)
+		indices = append(indices, idx)
+	}
+	sort.Strings(ids)
+
+	var sumX, sumY *big.Int
+	for _, id := range ids {
+		share := shares[id]
+		if share.X == nil || share.Y == nil || !curve.IsOnCurve(share.X, share.Y) {
+			return fmt.Errorf("%w: share of party %s is not a curve point", ErrInconsistentShares, id)
+		}
+		lambda := polynomial.LagrangeCoefficient(curve, d.ShareIDs[id], indices)
+		if lambda == nil {
+			return fmt.Errorf("%w: duplicate share indices", tss.ErrInvalidParameters)
+		}
+		x, y := curve.ScalarMult(share.X, share.Y, lambda)
+		if sumX == nil {
+			sumX, sumY = x, y
+		} else {
+			sumX, sumY = curve.Add(sumX, sumY, x, y)
+		}
+	}
+
+	if sumX.Cmp(d.PublicKeyX) != 0 || sumY.Cmp(d.PublicKeyY) != 0 {
+		return fmt.Errorf("%w: %d shares interpolate to a different point", ErrInconsistentShares, len(shares))
+	}
+	return nil
+}
+
+// curve returns the registered curve the group public key lies on. Key data
+// does not record its curve.
+func (d *LocalPartySaveData) curve() (curves.Curve, error) {
+	for _, name := range []string{curves.NameSecp256k1, curves.NameP384, curves.NameP256} {
+		curve, err := curves.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if curve.IsOnCurve(d.PublicKeyX, d.PublicKeyY) {
+			return curve, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: public key is not on a supported curve", tss.ErrInvalidParameters)
+}
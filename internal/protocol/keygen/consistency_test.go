@@ -0,0 +1,47 @@
+package keygen
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestVerifyConsistency(t *testing.T) {
+	results := runDirectKeyGen(t, make([]tss.Logger, 3))
+
+	// The shares recorded by KeyGen
+	for i, d := range results {
+		if err := d.VerifyConsistency(nil); err != nil {
+			t.Fatalf("Party %d: %v", i, err)
+		}
+	}
+
+	// Any t+1 shares, the local one included implicitly
+	shares := map[string]tss.PublicKey{
+		"3": {X: results[2].XiX, Y: results[2].XiY},
+	}
+	if err := results[0].VerifyConsistency(shares); err != nil {
+		t.Fatalf("Shares of 1 and 3: %v", err)
+	}
+
+	// One share does not determine the key
+	if err := results[0].VerifyConsistency(map[string]tss.PublicKey{}); !errors.Is(err, ErrInconsistentShares) {
+		t.Fatalf("Expected ErrInconsistentShares for a single share, got %v", err)
+	}
+
+	// A wrong share is caught
+	x := results[1].XiX
+	y := new(big.Int).Sub(curves.NewSecp256k1().Params().P, results[1].XiY) // -X_2
+	shares["2"] = tss.PublicKey{X: x, Y: y}
+	if err := results[0].VerifyConsistency(shares); !errors.Is(err, ErrInconsistentShares) {
+		t.Fatalf("Expected ErrInconsistentShares for a wrong share, got %v", err)
+	}
+
+	shares = map[string]tss.PublicKey{"4": {X: x, Y: y}}
+	if err := results[0].VerifyConsistency(shares); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a party outside the committee, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package keygen
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// lengthPrefixSize is the size of the big-endian length that precedes each field.
+const lengthPrefixSize = 4
+
+// appendField appends b to buf as a length-prefixed field: a 4-byte big-endian
+// length followed by the bytes themselves.
+func appendField(buf, b []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
+
+// readField reads one length-prefixed field from data and returns it along with
+// the remaining bytes.
+func readField(data []byte) ([]byte, []byte, error) {
+	if len(data) < lengthPrefixSize {
+		return nil, nil, fmt.Errorf("truncated field length")
+	}
+	n := binary.BigEndian.Uint32(data[:lengthPrefixSize])
+	data = data[lengthPrefixSize:]
+	if uint64(n) > uint64(len(data)) {
+		return nil, nil, fmt.Errorf("field length %d exceeds remaining %d bytes", n, len(data))
+	}
+	return data[:n], data[n:], nil
+}
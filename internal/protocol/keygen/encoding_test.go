@@ -0,0 +1,80 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
+)
+
+func TestCommitDataPaillier3072(t *testing.T) {
+	sk, err := paillier.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatalf("Failed to generate paillier key: %v", err)
+	}
+	if sk.N.BitLen() <= 2048 {
+		t.Fatalf("Expected a modulus wider than 2048 bits, got %d", sk.N.BitLen())
+	}
+
+	for _, curve := range []curves.Curve{curves.NewSecp256k1(), curves.NewP384()} {
+		threshold := 2
+		poly, err := polynomial.New(curve, threshold, nil)
+		if err != nil {
+			t.Fatalf("Failed to generate polynomial: %v", err)
+		}
+		var vss []*big.Int
+		for _, coeff := range poly.Coefficients {
+			x, y := curve.ScalarBaseMult(coeff)
+			vss = append(vss, x, y)
+		}
+
+		data := serializeCommitData(curve, sk.N, vss)
+		n, parsed, err := parseCommitData(curve, data, threshold)
+		if err != nil {
+			t.Fatalf("parseCommitData failed: %v", err)
+		}
+		if n.Cmp(sk.N) != 0 {
+			t.Fatal("Paillier N did not round-trip")
+		}
+		for i := range vss {
+			if parsed[i].Cmp(vss[i]) != 0 {
+				t.Fatalf("VSS coordinate %d did not round-trip", i)
+			}
+		}
+
+		// Truncated, extended and wrong-degree payloads are rejected
+		if _, _, err := parseCommitData(curve, data[:len(data)-1], threshold); err == nil {
+			t.Error("Expected error for truncated data")
+		}
+		if _, _, err := parseCommitData(curve, append(data, 0), threshold); err == nil {
+			t.Error("Expected error for trailing data")
+		}
+		if _, _, err := parseCommitData(curve, data, threshold-1); err == nil {
+			t.Error("Expected error for wrong threshold")
+		}
+	}
+}
+
+func TestReadField(t *testing.T) {
+	buf := appendField(nil, []byte("abc"))
+	buf = appendField(buf, nil)
+
+	field, rest, err := readField(buf)
+	if err != nil || string(field) != "abc" {
+		t.Fatalf("Unexpected first field %q: %v", field, err)
+	}
+	field, rest, err = readField(rest)
+	if err != nil || len(field) != 0 || len(rest) != 0 {
+		t.Fatalf("Unexpected second field %q (rest %d): %v", field, len(rest), err)
+	}
+
+	if _, _, err := readField([]byte{0, 0}); err == nil {
+		t.Error("Expected error for truncated length")
+	}
+	if _, _, err := readField([]byte{0, 0, 0, 5, 'a'}); err == nil {
+		t.Error("Expected error for oversized length")
+	}
+}
@@ -13,9 +13,15 @@ import (
 func FuzzRound3Decommit(f *testing.F) {
 	// Seed corpus
 	f.Add([]byte("short"))
-	f.Add(make([]byte, 256))    // correct length for N
-	f.Add(make([]byte, 256+64)) // correct length for N + 1 point
-	f.Add(make([]byte, 1000))   // long
+	f.Add(make([]byte, 256))  // zero-length fields
+	f.Add(make([]byte, 1000)) // long
+
+	// Well-formed field layout: salt || N || 4 coordinates
+	seed := appendField(make([]byte, 32), make([]byte, 256))
+	for i := 0; i < 4; i++ {
+		seed = appendField(seed, make([]byte, 32))
+	}
+	f.Add(seed)
 
 	f.Fuzz(func(t *testing.T, data []byte) {
 		// 1. Setup minimal state for round3
@@ -58,7 +64,7 @@ func FuzzRound3Decommit(f *testing.F) {
 		// We use sha256 as in internal/crypto/commitment
 		hash := sha256.New()
 		hash.Write(salt)
-		hash.Write(msgData)
+		hash.Write(bindCommitData(params.SessionID, p2.ID(), msgData))
 		comm := hash.Sum(nil)
 
 		peerCommitments := map[string][]byte{
@@ -73,6 +79,7 @@ func FuzzRound3Decommit(f *testing.F) {
 			Data:       data, // payload = salt || msgData
 			TypeString: "KeyGenRound2_Decommit",
 			RoundNum:   2,
+			Session:    params.SessionID,
 		}
 
 		s.receivedMsgs[p2.ID()] = []tss.Message{decommitMsg}
@@ -84,6 +91,7 @@ func FuzzRound3Decommit(f *testing.F) {
 			Data:       []byte("dummy-share"),
 			TypeString: "KeyGenRound2_Share",
 			RoundNum:   2,
+			Session:    params.SessionID,
 		}
 		s.receivedMsgs[p2.ID()] = append(s.receivedMsgs[p2.ID()], shareMsg)
 
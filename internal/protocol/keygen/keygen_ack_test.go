@@ -0,0 +1,130 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// routeAll delivers every pending message to its recipients and returns the new outputs.
+func routeAll(t *testing.T, parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Message) [][]tss.Message {
+	t.Helper()
+	var allMsgs []tss.Message
+	for _, msgs := range outMsgs {
+		allMsgs = append(allMsgs, msgs...)
+	}
+	next := make([][]tss.Message, len(parties))
+	for i := range parties {
+		for _, msg := range allMsgs {
+			if msg.From().ID() == parties[i].ID() || !isFor(msg, parties[i]) {
+				continue
+			}
+			sm, out, err := sms[i].Update(msg)
+			if err != nil {
+				t.Fatalf("Party %d failed: %v", i, err)
+			}
+			sms[i] = sm
+			next[i] = append(next[i], out...)
+		}
+	}
+	return next
+}
+
+func isFor(msg tss.Message, party tss.PartyID) bool {
+	if msg.IsBroadcast() {
+		return true
+	}
+	for _, dest := range msg.To() {
+		if dest.ID() == party.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestKeyGenAck(t *testing.T) {
+	for _, oneRound := range []bool{false, true} {
+		parties := []tss.PartyID{
+			&MockPartyID{id: "1"},
+			&MockPartyID{id: "2"},
+			&MockPartyID{id: "3"},
+		}
+		sms := make([]tss.StateMachine, 3)
+		outMsgs := make([][]tss.Message, 3)
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:        parties[i],
+				Parties:        parties,
+				Threshold:      1,
+				Curve:          "secp256k1",
+				SessionID:      []byte("test-session-ack"),
+				OneRoundKeyGen: oneRound,
+				KeyGenAck:      true,
+			}
+			var err error
+			sms[i], outMsgs[i], err = NewStateMachine(params)
+			if err != nil {
+				t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+			}
+		}
+
+		// Run the regular rounds; every party must now be waiting for ACKs
+		rounds := 3
+		if oneRound {
+			rounds = 1
+		}
+		for r := 0; r < rounds; r++ {
+			outMsgs = routeAll(t, parties, sms, outMsgs)
+		}
+		for i := range parties {
+			if sms[i].Result() != nil {
+				t.Fatalf("Party %d finished before receiving ACKs (%s)", i, sms[i].Details())
+			}
+			if len(outMsgs[i]) != 1 || outMsgs[i][0].Type() != "KeyGenAck" {
+				t.Fatalf("Party %d did not broadcast an ACK", i)
+			}
+		}
+
+		// Party 1 receives the ACK from party 2 only: still not finished
+		sm, _, err := sms[0].Update(outMsgs[1][0])
+		if err != nil {
+			t.Fatalf("Party 0 failed to process ACK: %v", err)
+		}
+		sms[0] = sm
+		if sms[0].Result() != nil {
+			t.Fatal("Party 0 finished with an ACK missing")
+		}
+
+		// The last ACK completes the ceremony
+		sm, _, err = sms[0].Update(outMsgs[2][0])
+		if err != nil {
+			t.Fatalf("Party 0 failed to process ACK: %v", err)
+		}
+		sms[0] = sm
+		if sms[0].Result() == nil {
+			t.Fatal("Party 0 did not finish after all ACKs")
+		}
+
+		// Everyone else finishes too, agreeing on the same public key
+		acks := [][]tss.Message{outMsgs[0], outMsgs[1], outMsgs[2]}
+		for i := 1; i < 3; i++ {
+			for j := range parties {
+				if j == i {
+					continue
+				}
+				sm, _, err := sms[i].Update(acks[j][0])
+				if err != nil {
+					t.Fatalf("Party %d failed to process ACK: %v", i, err)
+				}
+				sms[i] = sm
+			}
+			res, ok := sms[i].Result().(*LocalPartySaveData)
+			if !ok {
+				t.Fatalf("Party %d did not finish after all ACKs", i)
+			}
+			if res.PublicKeyX.Cmp(sms[0].Result().(*LocalPartySaveData).PublicKeyX) != 0 {
+				t.Fatalf("Party %d finished with a different public key", i)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package keygen
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// identityPartyID is a PartyID backed by an Ed25519 identity key.
+type identityPartyID struct {
+	id  string
+	pub ed25519.PublicKey
+}
+
+func (p *identityPartyID) ID() string      { return p.id }
+func (p *identityPartyID) Moniker() string { return p.id }
+func (p *identityPartyID) Key() []byte     { return p.pub }
+
+func TestKeyGenAuthenticated(t *testing.T) {
+	parties := make([]tss.PartyID, 3)
+	keys := make([]ed25519.PrivateKey, 3)
+	for i := range parties {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parties[i] = &identityPartyID{id: string(rune('1' + i)), pub: pub}
+		keys[i] = priv
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:    parties[i],
+			Parties:    parties,
+			Threshold:  1,
+			Curve:      "secp256k1",
+			SessionID:  []byte("test-session-auth"),
+			SigningKey: keys[i],
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Party 3 re-signs party 2's commitment and claims it came from party 2
+	orig := outMsgs[1][0].(*tss.SignedMessage)
+	forged := tss.NewSignedMessage(orig.Message)
+	if err := forged.Sign(keys[2]); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := sms[0].Update(forged)
+	var blame *tss.Blame
+	if !errors.Is(err, tss.ErrInvalidSignature) || !errors.As(err, &blame) || blame.PartyID.ID() != "2" {
+		t.Fatalf("Expected forged message to be rejected, got %v", err)
+	}
+
+	// Genuine signed traffic completes the ceremony
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*LocalPartySaveData); !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestBatchKeyGenInvalidCount(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("test-session-batch"),
+	}
+	for _, count := range []int{0, -1} {
+		if _, _, err := NewBatchStateMachine(params, count); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("count %d: expected ErrInvalidParameters, got %v", count, err)
+		}
+	}
+}
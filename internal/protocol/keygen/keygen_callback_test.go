@@ -0,0 +1,76 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenRoundCallback(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	type call struct {
+		round int
+		out   []tss.Message
+	}
+	calls := make([][]call, len(parties))
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		i := i
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-callback"),
+			OnRoundComplete: func(round int, out []tss.Message) {
+				calls[i] = append(calls[i], call{round, out})
+			},
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	sent := make([][][]tss.Message, len(parties))
+	for i := range parties {
+		sent[i] = append(sent[i], outMsgs[i])
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+		for i := range parties {
+			if len(outMsgs[i]) > 0 {
+				sent[i] = append(sent[i], outMsgs[i])
+			}
+		}
+	}
+
+	// Rounds 1-3 send messages; round 4 only finishes
+	for i := range parties {
+		if sms[i].Result() == nil {
+			t.Fatalf("Party %d did not finish", i)
+		}
+		if len(calls[i]) != 3 || len(sent[i]) != 3 {
+			t.Fatalf("Party %d: expected 3 callbacks for 3 sending rounds, got %d", i, len(calls[i]))
+		}
+		for r, c := range calls[i] {
+			if c.round != r+1 {
+				t.Fatalf("Party %d: callback %d reported round %d", i, r, c.round)
+			}
+			if len(c.out) != len(sent[i][r]) {
+				t.Fatalf("Party %d round %d: callback got %d messages, Update returned %d", i, c.round, len(c.out), len(sent[i][r]))
+			}
+			for k := range c.out {
+				if c.out[k] != sent[i][r][k] {
+					t.Fatalf("Party %d round %d: callback message %d differs from Update's", i, c.round, k)
+				}
+			}
+		}
+	}
+}
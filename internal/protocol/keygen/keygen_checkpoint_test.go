@@ -0,0 +1,103 @@
+package keygen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenPaillierCheckpoint(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	newParams := func(i int, sessionID string) *tss.Parameters {
+		return &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte(sessionID),
+		}
+	}
+
+	// Party 1 checkpoints its Paillier key in Round 1, then crashes
+	var checkpoints [][]byte
+	params := newParams(0, "test-session-crashed")
+	params.PaillierCheckpoint = func(data []byte) {
+		checkpoints = append(checkpoints, data)
+	}
+	if _, _, err := NewStateMachine(params); err != nil {
+		t.Fatalf("Failed to create state machine: %v", err)
+	}
+	if len(checkpoints) != 1 {
+		t.Fatalf("Expected one checkpoint, got %d", len(checkpoints))
+	}
+
+	// The ceremony restarts; party 1 reuses the checkpointed key
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := newParams(i, "test-session-restarted")
+		if i == 0 {
+			params.PaillierCheckpointData = checkpoints[0]
+			params.PaillierCheckpoint = func(data []byte) {
+				t.Fatal("Checkpoint written for a restored key")
+			}
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+
+	restored, err := decodePaillierCheckpoint(checkpoints[0])
+	if err != nil {
+		t.Fatalf("decodePaillierCheckpoint: %v", err)
+	}
+	data, ok := sms[0].Result().(*LocalPartySaveData)
+	if !ok {
+		t.Fatalf("Party 1 did not finish (%s)", sms[0].Details())
+	}
+	if data.PaillierPk.N.Cmp(restored.N) != 0 || data.PaillierSk.Lambda.Cmp(restored.Lambda) != 0 {
+		t.Fatal("Party 1 generated a new Paillier key instead of reusing the checkpoint")
+	}
+	for i := 1; i < 3; i++ {
+		peer := sms[i].Result().(*LocalPartySaveData)
+		if peer.PeerPaillierPks[parties[0].ID()].N.Cmp(restored.N) != 0 {
+			t.Fatalf("Party %d does not hold the restored Paillier key of party 1", i+1)
+		}
+	}
+}
+
+func TestKeyGenInvalidPaillierCheckpoint(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	for _, data := range [][]byte{
+		{},
+		[]byte("not a checkpoint"),
+		appendField(nil, []byte(paillierCheckpointVersion)),
+	} {
+		params := &tss.Parameters{
+			PartyID:                parties[0],
+			Parties:                parties,
+			Threshold:              1,
+			Curve:                  "secp256k1",
+			SessionID:              []byte("test-session-checkpoint"),
+			PaillierCheckpointData: data,
+		}
+		_, _, err := NewStateMachine(params)
+		if !errors.Is(err, tss.ErrInvalidParameters) || !strings.Contains(err.Error(), "checkpoint") {
+			t.Fatalf("Expected ErrInvalidParameters for checkpoint %x, got %v", data, err)
+		}
+	}
+}
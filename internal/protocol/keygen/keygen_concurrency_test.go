@@ -0,0 +1,46 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenMaxConcurrency(t *testing.T) {
+	if (&tss.Parameters{}).Concurrency() < 1 {
+		t.Fatal("Expected the default concurrency to be at least 1")
+	}
+
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	c := tss.NewLocalCoordinator()
+	for _, p := range parties {
+		params := &tss.Parameters{
+			PartyID:        p,
+			Parties:        parties,
+			Threshold:      1,
+			SessionID:      []byte("test-session-concurrency"),
+			MaxConcurrency: 1,
+		}
+		if params.Concurrency() != 1 {
+			t.Fatalf("Expected concurrency 1, got %d", params.Concurrency())
+		}
+		sm, out, err := NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %s: %v", p.ID(), err)
+		}
+		c.Add(p.ID(), sm, out)
+	}
+	results, err := c.Run()
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	for id, result := range results {
+		if _, ok := result.(*LocalPartySaveData); !ok {
+			t.Fatalf("Party %s returned %T", id, result)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenDuplicateRound1(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-duplicate"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Every round-1 commitment is retransmitted once
+	for i := range outMsgs {
+		outMsgs[i] = append(outMsgs[i], outMsgs[i]...)
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+
+	var pubX string
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+		if i == 0 {
+			pubX = data.PublicKeyX.String()
+		} else if data.PublicKeyX.String() != pubX {
+			t.Fatalf("Party %d derived a different public key", i)
+		}
+	}
+}
+
+func TestKeyGenConflictingRound1(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-conflict"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Party 1 is still in round 1 (party 3 has not spoken) when party 2
+	// sends a second, different commitment
+	msg := outMsgs[1][0].(*KeyGenMessage)
+	if _, _, err := sms[0].Update(msg); err != nil {
+		t.Fatalf("First delivery failed: %v", err)
+	}
+	forged := *msg
+	forged.Data = append([]byte{}, msg.Data...)
+	forged.Data[len(forged.Data)-1] ^= 0x01
+	_, _, err := sms[0].Update(&forged)
+
+	var blame *tss.Blame
+	if !errors.As(err, &blame) || blame.PartyID.ID() != "2" {
+		t.Fatalf("Expected equivocation blame on party 2, got %v", err)
+	}
+}
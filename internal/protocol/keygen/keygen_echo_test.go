@@ -0,0 +1,112 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenEchoBroadcast(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	newParams := func(i int) *tss.Parameters {
+		return &tss.Parameters{
+			PartyID:       parties[i],
+			Parties:       parties,
+			Threshold:     1,
+			Curve:         "secp256k1",
+			SessionID:     []byte("test-session-echo"),
+			EchoBroadcast: true,
+		}
+	}
+
+	t.Run("Honest", func(t *testing.T) {
+		coord := tss.NewLocalCoordinator()
+		for i := range parties {
+			sm, out, err := NewStateMachine(newParams(i))
+			if err != nil {
+				t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+			}
+			coord.Add(parties[i].ID(), sm, out)
+		}
+		if _, err := coord.Run(); err != nil {
+			t.Fatalf("KeyGen failed: %v", err)
+		}
+	})
+
+	t.Run("EquivocatedCommitment", func(t *testing.T) {
+		sms := make([]tss.StateMachine, len(parties))
+		outMsgs := make([][]tss.Message, len(parties))
+		for i := range parties {
+			var err error
+			sms[i], outMsgs[i], err = NewStateMachine(newParams(i))
+			if err != nil {
+				t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+			}
+		}
+
+		// Party 3 sends party 2 a different round 1 commitment than party 1
+		orig := outMsgs[2][0].(*KeyGenMessage)
+		forged := *orig
+		forged.Data = append([]byte(nil), orig.Data...)
+		forged.Data[0] ^= 1
+
+		var err error
+		if sms[0], _, err = sms[0].Update(orig); err != nil {
+			t.Fatalf("Party 1 rejected the commitment: %v", err)
+		}
+		var echoes []tss.Message
+		if sms[1], echoes, err = sms[1].Update(&forged); err != nil {
+			t.Fatalf("Party 2 rejected the commitment: %v", err)
+		}
+		var echoed bool
+		for _, msg := range echoes {
+			if msg.Type() != tss.EchoMessageType {
+				continue
+			}
+			echoed = true
+			_, _, err = sms[0].Update(msg)
+		}
+		if !echoed {
+			t.Fatal("Party 2 did not echo the commitment")
+		}
+		var blame *tss.Blame
+		if !errors.Is(err, tss.ErrEquivocation) || errors.As(err, &blame) {
+			t.Fatalf("Expected an unblamed equivocation, got %v", err)
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		var echoed bool
+		coord := tss.NewLocalCoordinator()
+		for i := range parties {
+			params := newParams(i)
+			params.OnRoundComplete = func(round int, out []tss.Message) {
+				for _, msg := range out {
+					echoed = echoed || msg.Type() == tss.EchoMessageType
+				}
+			}
+			sm, out, err := NewBatchStateMachine(params, 2)
+			if err != nil {
+				t.Fatalf("Failed to create batch state machine for party %d: %v", i, err)
+			}
+			coord.Add(parties[i].ID(), sm, out)
+		}
+		results, err := coord.Run()
+		if err != nil {
+			t.Fatalf("Batch KeyGen failed: %v", err)
+		}
+		if !echoed {
+			t.Fatal("Batch KeyGen sent no echoes")
+		}
+		for id, res := range results {
+			if keys := res.(*BatchKeyGenResult).Keys; len(keys) != 2 {
+				t.Fatalf("Party %s got %d keys, expected 2", id, len(keys))
+			}
+		}
+	})
+}
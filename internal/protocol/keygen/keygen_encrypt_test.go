@@ -0,0 +1,142 @@
+package keygen
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// encryptingPartyID is an identityPartyID with a separate X25519 encryption
+// key.
+type encryptingPartyID struct {
+	identityPartyID
+	enc []byte
+}
+
+func (p *encryptingPartyID) EncryptionKey() []byte { return p.enc }
+
+func TestKeyGenEncryptedShares(t *testing.T) {
+	run := func(t *testing.T, distinct bool) {
+		parties := make([]tss.PartyID, 3)
+		signingKeys := make([]ed25519.PrivateKey, 3)
+		encKeys := make([]*ecdh.PrivateKey, 3)
+		for i := range parties {
+			pub, priv, err := ed25519.GenerateKey(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := identityPartyID{id: string(rune('1' + i)), pub: pub}
+			signingKeys[i] = priv
+			if !distinct {
+				parties[i] = &id
+				continue
+			}
+			encKeys[i], err = ecdh.X25519().GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parties[i] = &encryptingPartyID{identityPartyID: id, enc: encKeys[i].PublicKey().Bytes()}
+		}
+
+		coord := tss.NewLocalCoordinator()
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:       parties[i],
+				Parties:       parties,
+				Threshold:     1,
+				Curve:         "secp256k1",
+				SessionID:     []byte("test-session-encrypt"),
+				SigningKey:    signingKeys[i],
+				EncryptShares: true,
+			}
+			if distinct {
+				params.EncryptionKey = encKeys[i].Bytes()
+			}
+			sm, out, err := NewStateMachine(params)
+			if err != nil {
+				t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+			}
+			coord.Add(parties[i].ID(), sm, out)
+		}
+		results, err := coord.Run()
+		if err != nil {
+			t.Fatalf("KeyGen failed: %v", err)
+		}
+		first := results["1"].(*LocalPartySaveData)
+		for id, res := range results {
+			data := res.(*LocalPartySaveData)
+			if data.PublicKeyX.Cmp(first.PublicKeyX) != 0 || data.PublicKeyY.Cmp(first.PublicKeyY) != 0 {
+				t.Fatalf("Party %s derived a different public key", id)
+			}
+		}
+	}
+
+	t.Run("DistinctEncryptionKeys", func(t *testing.T) { run(t, true) })
+	t.Run("IdentityKeyFallback", func(t *testing.T) { run(t, false) })
+
+	t.Run("SharesAreEncrypted", func(t *testing.T) {
+		parties := make([]tss.PartyID, 2)
+		signingKeys := make([]ed25519.PrivateKey, 2)
+		for i := range parties {
+			pub, priv, err := ed25519.GenerateKey(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parties[i] = &identityPartyID{id: string(rune('1' + i)), pub: pub}
+			signingKeys[i] = priv
+		}
+		params := &tss.Parameters{
+			PartyID:        parties[0],
+			Parties:        parties,
+			Threshold:      1,
+			Curve:          "secp256k1",
+			SessionID:      []byte("test-session-encrypt"),
+			SigningKey:     signingKeys[0],
+			EncryptShares:  true,
+			OneRoundKeyGen: true,
+		}
+		_, out, err := NewStateMachine(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var share tss.Message
+		for _, msg := range out {
+			if msg.Type() == "KeyGen1Round_Direct_Share" {
+				share = msg
+			}
+		}
+		if share == nil {
+			t.Fatal("No share message sent")
+		}
+		if len(share.Payload()) <= 32+16 {
+			t.Fatalf("Share payload of %d bytes is not encrypted", len(share.Payload()))
+		}
+		// Only the recipient can decrypt it
+		peer := *params
+		peer.PartyID, peer.SigningKey = parties[1], signingKeys[1]
+		if _, err := peer.DecryptShare(parties[0], share.Payload()); err != nil {
+			t.Fatalf("Recipient cannot decrypt its share: %v", err)
+		}
+		if _, err := params.DecryptShare(parties[0], share.Payload()); !errors.Is(err, tss.ErrDecryptShare) {
+			t.Fatalf("Expected ErrDecryptShare for another key, got %v", err)
+		}
+	})
+
+	t.Run("MissingEncryptionKey", func(t *testing.T) {
+		parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+		params := &tss.Parameters{
+			PartyID:       parties[0],
+			Parties:       parties,
+			Threshold:     1,
+			SessionID:     []byte("test-session-encrypt"),
+			EncryptShares: true,
+		}
+		if _, _, err := NewStateMachine(params); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+		}
+	})
+}
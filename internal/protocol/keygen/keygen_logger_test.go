@@ -0,0 +1,103 @@
+package keygen
+
+import (
+	"io"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// recordingLogger keeps every log call for inspection.
+type recordingLogger struct {
+	entries [][]interface{}
+}
+
+func (l *recordingLogger) record(msg string, keyvals []interface{}) {
+	l.entries = append(l.entries, append([]interface{}{msg}, keyvals...))
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.record(msg, keyvals) }
+func (l *recordingLogger) Info(msg string, keyvals ...interface{})  { l.record(msg, keyvals) }
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{})  { l.record(msg, keyvals) }
+
+// runDirectKeyGen runs a 2-of-3 one-round keygen with the given loggers.
+func runDirectKeyGen(t *testing.T, loggers []tss.Logger) []*LocalPartySaveData {
+	t.Helper()
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:        parties[i],
+			Parties:        parties,
+			Threshold:      1,
+			Curve:          "secp256k1",
+			SessionID:      []byte("test-session-logger"),
+			OneRoundKeyGen: true,
+			Logger:         loggers[i],
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	routeAll(t, parties, sms, outMsgs)
+
+	results := make([]*LocalPartySaveData, len(parties))
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish keygen", i)
+		}
+		results[i] = data
+	}
+	return results
+}
+
+func TestKeyGenDefaultLoggerSilent(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	runDirectKeyGen(t, make([]tss.Logger, 3))
+
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("Expected no output with the default logger, got %q", out)
+	}
+}
+
+func TestKeyGenLoggerNoSecrets(t *testing.T) {
+	loggers := []*recordingLogger{{}, {}, {}}
+	results := runDirectKeyGen(t, []tss.Logger{loggers[0], loggers[1], loggers[2]})
+
+	for i, l := range loggers {
+		if len(l.entries) == 0 {
+			t.Fatalf("Party %d: expected debug output", i)
+		}
+		for _, entry := range l.entries {
+			for _, v := range entry {
+				// Protocol values are never logged, so no big integers at all
+				if _, ok := v.(*big.Int); ok {
+					t.Fatalf("Party %d logged a big.Int: %v (Ui %v)", i, entry, results[i].Ui)
+				}
+			}
+		}
+	}
+}
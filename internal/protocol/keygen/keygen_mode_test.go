@@ -0,0 +1,54 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenMode(t *testing.T) {
+	for _, tc := range []struct {
+		oneRound bool
+		rounds   int
+		want     string
+	}{
+		{oneRound: false, rounds: 3, want: ModeStandard},
+		{oneRound: true, rounds: 1, want: ModeOneRound},
+	} {
+		parties := []tss.PartyID{
+			&MockPartyID{id: "1"},
+			&MockPartyID{id: "2"},
+			&MockPartyID{id: "3"},
+		}
+		sms := make([]tss.StateMachine, 3)
+		outMsgs := make([][]tss.Message, 3)
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:        parties[i],
+				Parties:        parties,
+				Threshold:      1,
+				Curve:          "secp256k1",
+				SessionID:      []byte("test-session-mode"),
+				OneRoundKeyGen: tc.oneRound,
+			}
+			var err error
+			sms[i], outMsgs[i], err = NewStateMachine(params)
+			if err != nil {
+				t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+			}
+		}
+		for r := 0; r < tc.rounds; r++ {
+			outMsgs = routeAll(t, parties, sms, outMsgs)
+		}
+
+		for i, sm := range sms {
+			data, ok := sm.Result().(*LocalPartySaveData)
+			if !ok {
+				t.Fatalf("Party %d did not finish (%s)", i, sm.Details())
+			}
+			if data.Mode != tc.want {
+				t.Errorf("Party %d: mode %q, want %q", i, data.Mode, tc.want)
+			}
+		}
+	}
+}
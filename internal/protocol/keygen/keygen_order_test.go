@@ -0,0 +1,204 @@
+package keygen
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenShuffledParties(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	parties := []tss.PartyID{p1, p2, p3}
+
+	// Every node lists the committee in a different order
+	orders := [][]tss.PartyID{
+		{p1, p2, p3},
+		{p3, p1, p2},
+		{p2, p3, p1},
+	}
+
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   orders[i],
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-order"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+
+	var first *LocalPartySaveData
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+		if first == nil {
+			first = data
+			continue
+		}
+		if data.PublicKeyX.Cmp(first.PublicKeyX) != 0 || data.PublicKeyY.Cmp(first.PublicKeyY) != 0 {
+			t.Fatalf("Party %d derived a different public key", i)
+		}
+		for id, x := range first.ShareIDs {
+			if data.ShareIDs[id] == nil || data.ShareIDs[id].Cmp(x) != 0 {
+				t.Fatalf("Party %d disagrees on the share index of %s", i, id)
+			}
+		}
+	}
+
+	// The caller's slice is left as it was
+	if orders[1][0] != p3 {
+		t.Fatal("NewStateMachine reordered the caller's Parties")
+	}
+}
+
+func TestKeyGenNonNumericPartyIDs(t *testing.T) {
+	// Share indices come from committee positions, not from parsing IDs
+	parties := []tss.PartyID{
+		&MockPartyID{id: "carol"},
+		&MockPartyID{id: "alice"},
+		&MockPartyID{id: "bob"},
+	}
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-names"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+
+	results := make([]*LocalPartySaveData, len(parties))
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+		results[i] = data
+	}
+	if idx := results[0].ShareID; idx.Int64() != 3 {
+		t.Fatalf("Expected carol to hold share index 3, got %v", idx)
+	}
+
+	// Any t+1 shares, interpolated at their indices, yield the secret key
+	curve := curves.NewSecp256k1()
+	signers := []*LocalPartySaveData{results[0], results[2]}
+	xs := []*big.Int{signers[0].ShareID, signers[1].ShareID}
+	secret := new(big.Int)
+	for _, d := range signers {
+		lambda := polynomial.LagrangeCoefficient(curve, d.ShareID, xs)
+		secret.Add(secret, new(big.Int).Mul(lambda, d.Xi))
+	}
+	secret.Mod(secret, curve.Params().N)
+	x, y := curve.ScalarBaseMult(secret)
+	if x.Cmp(results[0].PublicKeyX) != 0 || y.Cmp(results[0].PublicKeyY) != 0 {
+		t.Fatal("Shares do not reconstruct the public key")
+	}
+}
+
+func TestKeyGenLocalPartyNotInCommittee(t *testing.T) {
+	params := &tss.Parameters{
+		PartyID:   &MockPartyID{id: "4"},
+		Parties:   []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}},
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("test-session-order"),
+	}
+	if _, _, err := NewStateMachine(params); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+	}
+}
+
+// TestKeyGenEarlyMessages holds back every message to party 1 until the
+// others are stuck waiting for it, then delivers them latest round first.
+// Party 1 must keep the early messages until it reaches their round.
+func TestKeyGenEarlyMessages(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}}
+	sms := make([]tss.StateMachine, len(parties))
+	inbox := make([][]tss.Message, len(parties))
+	post := func(msgs []tss.Message) {
+		for _, msg := range msgs {
+			for j, p := range parties {
+				if msg.From().ID() != p.ID() && isFor(msg, p) {
+					inbox[j] = append(inbox[j], msg)
+				}
+			}
+		}
+	}
+	deliver := func(i int) {
+		msgs := inbox[i]
+		inbox[i] = nil
+		for _, msg := range msgs {
+			sm, out, err := sms[i].Update(msg)
+			if err != nil {
+				t.Fatalf("Party %d failed: %v", i, err)
+			}
+			sms[i] = sm
+			post(out)
+		}
+	}
+
+	for i := range parties {
+		sm, out, err := NewStateMachine(&tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-early"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+		sms[i] = sm
+		post(out)
+	}
+
+	for k := 0; k < 3; k++ {
+		deliver(1)
+		deliver(2)
+	}
+	held := inbox[0]
+	if held[len(held)-1].RoundNumber() < 2 {
+		t.Fatal("Expected party 1 to hold messages of a later round")
+	}
+	for l, r := 0, len(held)-1; l < r; l, r = l+1, r-1 {
+		held[l], held[r] = held[r], held[l]
+	}
+
+	for k := 0; k < 4; k++ {
+		for i := range parties {
+			deliver(i)
+		}
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*LocalPartySaveData); !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+	}
+}
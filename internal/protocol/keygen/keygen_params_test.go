@@ -0,0 +1,39 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestNewStateMachineInvalidParameters(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	tests := []struct {
+		name      string
+		partyID   tss.PartyID
+		parties   []tss.PartyID
+		threshold int
+	}{
+		{"no parties", p1, nil, 0},
+		{"threshold too large", p1, []tss.PartyID{p1, p2, p3}, 3},
+		{"negative threshold", p1, []tss.PartyID{p1, p2, p3}, -1},
+		{"duplicate party IDs", p1, []tss.PartyID{p1, p2, &MockPartyID{id: "1"}}, 1},
+		{"local party missing", &MockPartyID{id: "4"}, []tss.PartyID{p1, p2, p3}, 1},
+		{"no local party", nil, []tss.PartyID{p1, p2, p3}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:   tt.partyID,
+				Parties:   tt.parties,
+				Threshold: tt.threshold,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-params"),
+			}
+			if _, _, err := NewStateMachine(params); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+}
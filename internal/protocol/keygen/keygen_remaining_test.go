@@ -0,0 +1,74 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenRemainingForRound(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-remaining"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Round 1: one commitment from each of the two peers
+	if got := tss.RemainingForRound(sms[0]); got != 2 {
+		t.Fatalf("Round 1: expected 2 remaining, got %d", got)
+	}
+	outMsgs = routeAll(t, parties, sms, outMsgs)
+	if sms[0].Details() != "KeyGen Round 2" {
+		t.Fatalf("Expected round 2, got %s", sms[0].Details())
+	}
+
+	// Round 2: a decommitment and a share from each peer. Every message
+	// delivered to party 1 brings the count down by one.
+	want := 4
+	if got := tss.RemainingForRound(sms[0]); got != want {
+		t.Fatalf("Round 2: expected %d remaining, got %d", want, got)
+	}
+	for _, msgs := range outMsgs[1:] {
+		for _, msg := range msgs {
+			if !isFor(msg, parties[0]) {
+				continue
+			}
+			next, _, err := sms[0].Update(msg)
+			if err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			sms[0] = next
+			want--
+			if want == 0 {
+				break
+			}
+			if got := tss.RemainingForRound(sms[0]); got != want {
+				t.Fatalf("Round 2: expected %d remaining, got %d", want, got)
+			}
+		}
+	}
+	if want != 0 || sms[0].Details() != "KeyGen Round 3" {
+		t.Fatalf("Expected round 3 after all messages, got %s with %d outstanding", sms[0].Details(), want)
+	}
+
+	// Round 3: one Schnorr proof from each peer
+	if got := tss.RemainingForRound(sms[0]); got != 2 {
+		t.Fatalf("Round 3: expected 2 remaining, got %d", got)
+	}
+}
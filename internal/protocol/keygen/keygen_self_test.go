@@ -0,0 +1,68 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenSelfCollision(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+
+	// Two nodes configured as party 1 in one committee list
+	dup := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   []tss.PartyID{parties[0], parties[1], &MockPartyID{id: "1"}},
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("test-session-self"),
+	}
+	if _, _, err := NewStateMachine(dup); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for duplicate party IDs, got %v", err)
+	}
+
+	// Two nodes that both believe they are party 1 in a valid committee
+	var own []tss.Message
+	newParty1 := func() (tss.StateMachine, []tss.Message) {
+		params := &tss.Parameters{
+			PartyID:      parties[0],
+			Parties:      parties,
+			Threshold:    1,
+			Curve:        "secp256k1",
+			SessionID:    []byte("test-session-self"),
+			OnOwnMessage: func(msg tss.Message) { own = append(own, msg) },
+		}
+		sm, out, err := NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine: %v", err)
+		}
+		return sm, out
+	}
+	sm, _ := newParty1()
+	_, twinOut := newParty1()
+
+	for _, msg := range twinOut {
+		next, out, err := sm.Update(msg)
+		if err != nil {
+			t.Fatalf("Message from the twin failed: %v", err)
+		}
+		if next == nil {
+			t.Fatal("Message from the twin ended the session")
+		}
+		sm = next
+		if len(out) != 0 {
+			t.Fatal("Message from the twin advanced the round")
+		}
+	}
+	if len(own) != len(twinOut) {
+		t.Fatalf("OnOwnMessage saw %d messages, want %d", len(own), len(twinOut))
+	}
+	if waiting := tss.WaitingFor(sm); len(waiting) != 2 {
+		t.Fatalf("Still waiting for %v, want parties 2 and 3", partyIDs(waiting))
+	}
+}
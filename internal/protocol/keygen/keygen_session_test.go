@@ -0,0 +1,63 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenRejectsOtherSession(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	start := func(i int, session string) (tss.StateMachine, []tss.Message) {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte(session),
+		}
+		sm, out, err := NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine: %v", err)
+		}
+		return sm, out
+	}
+
+	// Party 2's genuine round-1 commitment from session A...
+	_, outA := start(1, "session-A")
+	// ...is replayed into session B
+	smB, _ := start(0, "session-B")
+
+	next, _, err := smB.Update(outA[0])
+	if !errors.Is(err, tss.ErrInvalidMsg) {
+		t.Fatalf("Expected replayed message to be rejected, got %v", err)
+	}
+	// The replay is dropped without ending session B
+	if next == nil {
+		t.Fatal("Replayed message ended the session")
+	}
+	_, outB := start(1, "session-B")
+	if _, _, err := next.Update(outB[0]); err != nil {
+		t.Fatalf("Genuine message rejected after the replay: %v", err)
+	}
+
+	// Rewriting the session header does not help either: the commitment is
+	// bound to the session it was made in, so it cannot be opened in another
+	data := []byte("decommitted data")
+	comm, err := commitment.New(bindCommitData([]byte("session-A"), "2", data))
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if !commitment.Verify(comm.C, comm.D, bindCommitData([]byte("session-A"), "2", data)) {
+		t.Fatal("Commitment does not open in its own session")
+	}
+	if commitment.Verify(comm.C, comm.D, bindCommitData([]byte("session-B"), "2", data)) {
+		t.Fatal("Commitment from session A opened in session B")
+	}
+}
@@ -86,6 +86,7 @@ func TestRound2Transition(t *testing.T) {
 		Data:       []byte("commitment_from_2"),
 		TypeString: "KeyGenRound1",
 		RoundNum:   1,
+		Session:    params.SessionID,
 	}
 	msg3 := &KeyGenMessage{
 		FromParty:  p3,
@@ -93,6 +94,7 @@ func TestRound2Transition(t *testing.T) {
 		Data:       []byte("commitment_from_3"),
 		TypeString: "KeyGenRound1",
 		RoundNum:   1,
+		Session:    params.SessionID,
 	}
 
 	// Update with msg2
@@ -258,6 +260,17 @@ func TestE2E(t *testing.T) {
 		if data.PublicKeyX == nil {
 			t.Errorf("Party %d has no public key", i)
 		}
+		if err := data.PedersenParams.Validate(); err != nil {
+			t.Errorf("Party %d has invalid pedersen params: %v", i, err)
+		}
+		if len(data.PeerPedersenParams) != 2 {
+			t.Errorf("Party %d has %d peer pedersen params, expected 2", i, len(data.PeerPedersenParams))
+		}
+		for id, pp := range data.PeerPedersenParams {
+			if pp.N.Cmp(data.PeerPaillierPks[id].N) != 0 {
+				t.Errorf("Party %d: pedersen modulus of %s does not match its paillier key", i, id)
+			}
+		}
 		t.Logf("Party %d finished. PubKey: (%s, %s)", i, data.PublicKeyX, data.PublicKeyY)
 	}
 	
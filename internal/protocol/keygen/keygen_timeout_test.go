@@ -0,0 +1,88 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func partyIDs(parties []tss.PartyID) []string {
+	ids := make([]string, len(parties))
+	for i, p := range parties {
+		ids[i] = p.ID()
+	}
+	return ids
+}
+
+func TestKeyGenWaitingFor(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-waiting"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Party 1 has heard from nobody yet
+	if got := partyIDs(tss.WaitingFor(sms[0])); len(got) != 2 || got[0] != "2" || got[1] != "3" {
+		t.Fatalf("Round 1: expected to wait for [2 3], got %v", got)
+	}
+
+	// Party 2's commitment arrives, party 3 stalls
+	timed := tss.WithTimeout(sms[0])
+	for _, msg := range outMsgs[1] {
+		if _, _, err := timed.Update(msg); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+	if got := partyIDs(timed.WaitingFor()); len(got) != 1 || got[0] != "3" {
+		t.Fatalf("Round 1: expected to wait for [3], got %v", got)
+	}
+
+	if err := timed.CheckTimeout(); err != nil {
+		t.Fatalf("No deadline set, got %v", err)
+	}
+	timed.SetDeadline(time.Now().Add(time.Hour))
+	if err := timed.CheckTimeout(); err != nil {
+		t.Fatalf("Deadline not reached, got %v", err)
+	}
+	timed.SetDeadline(time.Now().Add(-time.Second))
+	err := timed.CheckTimeout()
+	if !errors.Is(err, tss.ErrRoundTimeout) {
+		t.Fatalf("Expected ErrRoundTimeout, got %v", err)
+	}
+	var timeout *tss.TimeoutError
+	if !errors.As(err, &timeout) || len(timeout.Missing) != 1 || timeout.Missing[0].ID() != "3" {
+		t.Fatalf("Expected party 3 as non-responder, got %v", err)
+	}
+
+	// Once party 3 delivers, the round completes and nobody is missing
+	for _, msg := range outMsgs[2] {
+		if _, _, err := timed.Update(msg); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+	if timed.Details() != "KeyGen Round 2" {
+		t.Fatalf("Expected round 2, got %s", timed.Details())
+	}
+	// Round 2 needs a broadcast and a share from each peer
+	if got := partyIDs(timed.WaitingFor()); len(got) != 2 {
+		t.Fatalf("Round 2: expected to wait for both peers, got %v", got)
+	}
+}
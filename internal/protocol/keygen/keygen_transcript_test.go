@@ -0,0 +1,113 @@
+package keygen
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenTranscript(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	transcripts := make([]*bytes.Buffer, len(parties))
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		transcripts[i] = new(bytes.Buffer)
+		params := &tss.Parameters{
+			PartyID:    parties[i],
+			Parties:    parties,
+			Threshold:  1,
+			Curve:      "secp256k1",
+			SessionID:  []byte("test-session-transcript"),
+			Transcript: transcripts[i],
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+
+	// Track what every party sent and received so the transcript can be checked against it
+	sent := make([][]tss.Message, len(parties))
+	received := make([][]tss.Message, len(parties))
+	for i := range parties {
+		sent[i] = append(sent[i], outMsgs[i]...)
+	}
+	for r := 0; r < 3; r++ {
+		var allMsgs []tss.Message
+		for _, msgs := range outMsgs {
+			allMsgs = append(allMsgs, msgs...)
+		}
+		for i := range parties {
+			for _, msg := range allMsgs {
+				if msg.From().ID() != parties[i].ID() && isFor(msg, parties[i]) {
+					received[i] = append(received[i], msg)
+				}
+			}
+		}
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+		for i := range parties {
+			sent[i] = append(sent[i], outMsgs[i]...)
+		}
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*LocalPartySaveData); !ok {
+			t.Fatalf("Party %d did not finish keygen", i)
+		}
+	}
+
+	for i := range parties {
+		entries, err := tss.ParseTranscript(transcripts[i])
+		if err != nil {
+			t.Fatalf("Party %d: failed to parse transcript: %v", i, err)
+		}
+
+		var in, out []tss.TranscriptEntry
+		for _, e := range entries {
+			if e.Party != parties[i].ID() {
+				t.Errorf("Party %d: entry recorded for party %q", i, e.Party)
+			}
+			switch e.Direction {
+			case tss.TranscriptIn:
+				in = append(in, e)
+			case tss.TranscriptOut:
+				out = append(out, e)
+			default:
+				t.Fatalf("Party %d: unexpected direction %q", i, e.Direction)
+			}
+		}
+
+		checkEntries(t, "out", out, sent[i])
+		checkEntries(t, "in", in, received[i])
+	}
+}
+
+func checkEntries(t *testing.T, direction string, entries []tss.TranscriptEntry, msgs []tss.Message) {
+	t.Helper()
+	if len(entries) != len(msgs) {
+		t.Fatalf("%s: expected %d transcript entries, got %d", direction, len(msgs), len(entries))
+	}
+	for j, msg := range msgs {
+		e := entries[j]
+		if e.Type != msg.Type() || e.Round != msg.RoundNumber() || e.From != msg.From().ID() || e.Broadcast != msg.IsBroadcast() {
+			t.Errorf("%s entry %d does not match message %s (round %d)", direction, j, msg.Type(), msg.RoundNumber())
+		}
+		if len(e.To) != len(msg.To()) {
+			t.Errorf("%s entry %d: expected %d recipients, got %d", direction, j, len(msg.To()), len(e.To))
+		}
+		payload, err := e.PayloadBytes()
+		if err != nil {
+			t.Fatalf("%s entry %d: invalid payload hex: %v", direction, j, err)
+		}
+		if !bytes.Equal(payload, msg.Payload()) {
+			t.Errorf("%s entry %d: payload mismatch (%s...)", direction, j, hex.EncodeToString(payload)[:16])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package keygen
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestKeyGenZeroize(t *testing.T) {
+	results := runDirectKeyGen(t, make([]tss.Logger, 3))
+	data := results[0]
+
+	// The polynomial is zeroized on completion; u_i must survive it
+	if data.Ui == nil || data.Ui.Sign() == 0 {
+		t.Fatal("u_i was cleared along with the polynomial")
+	}
+
+	data.Zeroize()
+	if data.Xi.Sign() != 0 || data.Ui.Sign() != 0 {
+		t.Fatal("key share not zeroed")
+	}
+	if data.PaillierSk.Lambda.Sign() != 0 || data.PaillierSk.Mu.Sign() != 0 {
+		t.Fatal("Paillier private key not zeroed")
+	}
+	if data.PublicKeyX.Sign() == 0 {
+		t.Fatal("public values must be kept")
+	}
+}
+
+func TestKeyGenZeroizesSecretsOnAbort(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i, p := range parties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-abort"),
+		}
+		var err error
+		if sms[i], outMsgs[i], err = NewStateMachine(params); err != nil {
+			t.Fatalf("Failed to create state machine: %v", err)
+		}
+	}
+
+	// Party 2's commitment will not open, which aborts Round 3
+	commit := outMsgs[1][0].(*KeyGenMessage)
+	commit.Data = append([]byte(nil), commit.Data...)
+	commit.Data[0] ^= 1
+	outMsgs = routeAll(t, parties, sms, outMsgs)
+
+	s := sms[0].(*state)
+	if s.tempData["polynomial"] == nil {
+		t.Fatal("no polynomial to zeroize")
+	}
+	var err error
+deliver:
+	for _, msgs := range outMsgs[1:] {
+		for _, msg := range msgs {
+			if !isFor(msg, parties[0]) {
+				continue
+			}
+			if sms[0], _, err = sms[0].Update(msg); err != nil {
+				break deliver
+			}
+		}
+	}
+	if err == nil {
+		t.Fatal("Expected the session to abort")
+	}
+	if _, ok := s.tempData["polynomial"]; ok {
+		t.Fatal("polynomial survived the abort")
+	}
+}
@@ -0,0 +1,89 @@
+package keygen
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// PublicKeyData is a view-only export of a party's key data: everything the
+// committee knows publicly, and no secret material.
+type PublicKeyData struct {
+	// The global public key X
+	PublicKeyX *big.Int
+	PublicKeyY *big.Int
+
+	// x-coordinates of every committee member's share, keyed by PartyID.ID()
+	Indices map[string]*big.Int
+
+	// The public key share X_j of every committee member whose share is
+	// known, including the local party, keyed by PartyID.ID()
+	PublicSharesX map[string]*big.Int
+	PublicSharesY map[string]*big.Int
+
+	// Paillier public keys of every committee member, keyed by PartyID.ID()
+	PaillierPks map[string]*paillier.PublicKey
+
+	Epoch uint64
+}
+
+// ExportPublic returns the public part of the key data, safe to hand to a
+// view-only holder. Secret fields (Xi, Ui, PaillierSk, ...) are never copied,
+// and the result shares no memory with the key data.
+func (d *LocalPartySaveData) ExportPublic() *PublicKeyData {
+	out := &PublicKeyData{
+		PublicKeyX:    copyInt(d.PublicKeyX),
+		PublicKeyY:    copyInt(d.PublicKeyY),
+		Indices:       make(map[string]*big.Int, len(d.ShareIDs)),
+		PublicSharesX: make(map[string]*big.Int),
+		PublicSharesY: make(map[string]*big.Int),
+		PaillierPks:   make(map[string]*paillier.PublicKey),
+		Epoch:         d.Epoch,
+	}
+	for id, x := range d.ShareIDs {
+		out.Indices[id] = copyInt(x)
+	}
+	for id, x := range d.PeerXiX {
+		out.PublicSharesX[id] = copyInt(x)
+		out.PublicSharesY[id] = copyInt(d.PeerXiY[id])
+	}
+	for id, pk := range d.PeerPaillierPks {
+		out.PaillierPks[id] = copyPaillierPk(pk)
+	}
+
+	if d.LocalPartyID != nil {
+		id := d.LocalPartyID.ID()
+		if d.XiX != nil {
+			out.PublicSharesX[id] = copyInt(d.XiX)
+			out.PublicSharesY[id] = copyInt(d.XiY)
+		}
+		if d.PaillierPk != nil {
+			out.PaillierPks[id] = copyPaillierPk(d.PaillierPk)
+		}
+	}
+	return out
+}
+
+// GroupPublicKey returns a copy of the group public key X, or nil if the key
+// data does not hold one.
+func (d *LocalPartySaveData) GroupPublicKey() *tss.PublicKey {
+	if d.PublicKeyX == nil || d.PublicKeyY == nil {
+		return nil
+	}
+	return &tss.PublicKey{X: copyInt(d.PublicKeyX), Y: copyInt(d.PublicKeyY)}
+}
+
+func copyInt(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
+
+func copyPaillierPk(pk *paillier.PublicKey) *paillier.PublicKey {
+	if pk == nil {
+		return nil
+	}
+	return &paillier.PublicKey{N: copyInt(pk.N), N2: copyInt(pk.N2)}
+}
@@ -0,0 +1,136 @@
+package keygen
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// checkPublicShares verifies that every party's export agrees with each
+// party's own X_i.
+func checkPublicShares(t *testing.T, results []*LocalPartySaveData) {
+	t.Helper()
+	for i, d := range results {
+		pub := d.ExportPublic()
+		if pub.PublicKeyX.Cmp(d.PublicKeyX) != 0 || pub.PublicKeyY.Cmp(d.PublicKeyY) != 0 {
+			t.Fatalf("Party %d exported a different public key", i)
+		}
+		if len(pub.Indices) != len(results) || len(pub.PublicSharesX) != len(results) || len(pub.PaillierPks) != len(results) {
+			t.Fatalf("Party %d export is missing committee members", i)
+		}
+		for _, peer := range results {
+			id := peer.LocalPartyID.ID()
+			if pub.PublicSharesX[id].Cmp(peer.XiX) != 0 || pub.PublicSharesY[id].Cmp(peer.XiY) != 0 {
+				t.Fatalf("Party %d has a wrong public share for %s", i, id)
+			}
+			if pub.Indices[id].Cmp(peer.ShareID) != 0 {
+				t.Fatalf("Party %d has a wrong index for %s", i, id)
+			}
+			if pub.PaillierPks[id].N.Cmp(peer.PaillierPk.N) != 0 {
+				t.Fatalf("Party %d has a wrong Paillier key for %s", i, id)
+			}
+		}
+	}
+}
+
+func TestExportPublic(t *testing.T) {
+	results := runDirectKeyGen(t, make([]tss.Logger, 3))
+	checkPublicShares(t, results)
+
+	// The export shares no memory with the key data
+	pub := results[0].ExportPublic()
+	pub.PublicKeyX.SetInt64(1)
+	pub.Indices["1"].SetInt64(42)
+	if results[0].PublicKeyX.Cmp(big.NewInt(1)) == 0 || results[0].ShareIDs["1"].Int64() == 42 {
+		t.Fatal("ExportPublic aliases the key data")
+	}
+}
+
+func TestExportPublicStandardKeyGen(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-export"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create state machine for party %d: %v", i, err)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		outMsgs = routeAll(t, parties, sms, outMsgs)
+	}
+	results := make([]*LocalPartySaveData, 3)
+	for i := range parties {
+		data, ok := sms[i].Result().(*LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Party %d did not finish (%s)", i, sms[i].Details())
+		}
+		results[i] = data
+	}
+	checkPublicShares(t, results)
+}
+
+func TestPublicKeyDataHasNoSecrets(t *testing.T) {
+	forbiddenNames := map[string]bool{"Xi": true, "Ui": true, "PaillierSk": true}
+	forbiddenTypes := []reflect.Type{reflect.TypeOf(paillier.PrivateKey{})}
+
+	seen := make(map[reflect.Type]bool)
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		for _, f := range forbiddenTypes {
+			if typ == f {
+				t.Errorf("%s has secret-bearing type %s", path, typ)
+			}
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] || typ == reflect.TypeOf(big.Int{}) {
+			return
+		}
+		seen[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if forbiddenNames[f.Name] {
+				t.Errorf("%s.%s is a secret field", path, f.Name)
+			}
+			walk(f.Type, path+"."+f.Name)
+		}
+	}
+	walk(reflect.TypeOf(PublicKeyData{}), "PublicKeyData")
+}
+
+func TestGroupPublicKey(t *testing.T) {
+	results := runDirectKeyGen(t, make([]tss.Logger, 3))
+	pk := results[0].GroupPublicKey()
+	if !pk.Equal(results[0].PublicKeyX, results[0].PublicKeyY) {
+		t.Fatal("GroupPublicKey differs from the key data")
+	}
+	parsed, err := tss.ParsePublicKey(pk.SerializeCompressed())
+	if err != nil || !parsed.Equal(pk.X, pk.Y) {
+		t.Fatalf("Compressed group key does not round-trip: %v", err)
+	}
+
+	pk.X.SetInt64(1)
+	if results[0].PublicKeyX.Cmp(big.NewInt(1)) == 0 {
+		t.Fatal("GroupPublicKey aliases the key data")
+	}
+	if (&LocalPartySaveData{}).GroupPublicKey() != nil {
+		t.Fatal("Expected nil for key data without a public key")
+	}
+}
@@ -1,40 +1,48 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"fmt"
 	"math/big"
 
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
-	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 // round1 executes the logic for the first round of the KeyGen protocol.
 func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
-	// 1. Generate Paillier Key Pair
-	// Using 2048 bits as a standard security parameter
-	paillierSk, err := paillier.GenerateKey(rand.Reader, 2048)
+	// 1. Generate Paillier Key Pair (or restore it from a checkpoint)
+	paillierSk, err := s.paillierKey()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate paillier key: %w", err)
+		return nil, nil, err
 	}
 
 	// Save keys to state
 	s.saveData.PaillierSk = paillierSk
 	s.saveData.PaillierPk = &paillierSk.PublicKey
+	s.saveData.Mode = ModeStandard
+	s.saveData.Threshold = s.params.Threshold
+
+	// Derive ring-Pedersen parameters over the Paillier modulus; they are
+	// broadcast with a well-formedness proof in Round 3
+	pedersen, err := commitment.NewPedersenParams(paillierSk.N, paillierSk.Lambda)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate pedersen params: %w", err)
+	}
+	s.saveData.PedersenParams = pedersen
 
 	// 2. Generate VSS Polynomial
 	// Degree t = threshold
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	poly, err := polynomial.New(curve, s.params.Threshold, nil) // nil secret -> random u_i
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate polynomial: %w", err)
 	}
 
 	// Save our secret share (u_i = poly.Coefficients[0])
-	s.saveData.Ui = poly.Coefficients[0]
+	// Copied, since the polynomial is zeroized once KeyGen completes
+	s.saveData.Ui = new(big.Int).Set(poly.Coefficients[0])
 	s.tempData["polynomial"] = poly
 
 	// 3. Calculate VSS Commitments (Feldman VSS)
@@ -49,16 +57,14 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 
 	// 4. Create Commitment
 	// We commit to (PaillierPK, VSS_Commitments)
-	// Serialize data for commitment
-	// Format: PaillierN || VSS_X0 || VSS_Y0 || ...
-	var commitData []byte
-	commitData = append(commitData, paillierSk.PublicKey.N.Bytes()...)
-	for _, coord := range vssCommitments {
-		commitData = append(commitData, coord.Bytes()...)
-	}
+	// Serialize data for commitment using the same encoding as the Round 2 decommitment
+	// Format: len|PaillierN || len|VSS_X0 || len|VSS_Y0 || ...
+	commitData := serializeCommitData(curve, paillierSk.PublicKey.N, vssCommitments)
 
-	// Create commitment: C = Hash(salt, data)
-	comm, err := commitment.New(commitData)
+	// Create commitment: C = Hash(salt, sid, i, data)
+	// Binding the session and sender stops a commitment from being replayed
+	// into another ceremony or attributed to another party
+	comm, err := commitment.New(bindCommitData(s.params.SessionID, s.params.PartyID.ID(), commitData))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create commitment: %w", err)
 	}
@@ -75,7 +81,66 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 		Data:        comm.C,
 		TypeString:  "KeyGenRound1",
 		RoundNum:    1,
+		Session:    s.params.SessionID,
 	}
 
 	return s, []tss.Message{msg}, nil
 }
+
+// serializeCommitData encodes (PaillierN, VSS_Commitments) as a sequence of
+// length-prefixed fields, so any Paillier modulus size round-trips unchanged.
+// Coordinates are padded to the curve's field size to keep the encoding canonical.
+func serializeCommitData(curve curves.Curve, paillierN *big.Int, vssCommitments []*big.Int) []byte {
+	size := curves.ByteSize(curve)
+	data := appendField(nil, paillierN.Bytes())
+	for _, coord := range vssCommitments {
+		data = appendField(data, coord.FillBytes(make([]byte, size)))
+	}
+	return data
+}
+
+// bindCommitData prefixes commitment data with the session ID and the
+// committing party's ID. Only data is sent in the decommitment; the verifier
+// supplies the session and sender itself.
+func bindCommitData(sessionID []byte, partyID string, data []byte) []byte {
+	bound := appendField(nil, sessionID)
+	bound = appendField(bound, []byte(partyID))
+	return append(bound, data...)
+}
+
+// parseCommitData decodes the output of serializeCommitData for a polynomial of
+// degree threshold and checks that every VSS commitment is a point on the curve.
+func parseCommitData(curve curves.Curve, data []byte, threshold int) (*big.Int, []*big.Int, error) {
+	nBytes, rest, err := readField(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Paillier N: %w", err)
+	}
+	paillierN := new(big.Int).SetBytes(nBytes)
+	if paillierN.Sign() == 0 {
+		return nil, nil, fmt.Errorf("empty Paillier N")
+	}
+
+	size := curves.ByteSize(curve)
+	vssPoly := make([]*big.Int, (threshold+1)*2)
+	for i := range vssPoly {
+		var coord []byte
+		coord, rest, err = readField(rest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid vss coordinate %d: %w", i, err)
+		}
+		if len(coord) != size {
+			return nil, nil, fmt.Errorf("vss coordinate %d has length %d, expected %d", i, len(coord), size)
+		}
+		vssPoly[i] = new(big.Int).SetBytes(coord)
+	}
+	if len(rest) != 0 {
+		return nil, nil, fmt.Errorf("%d trailing bytes after vss commitments", len(rest))
+	}
+
+	for k := 0; k <= threshold; k++ {
+		if !curve.IsOnCurve(vssPoly[k*2], vssPoly[k*2+1]) {
+			return nil, nil, fmt.Errorf("vss commitment %d is not on curve", k)
+		}
+	}
+	return paillierN, vssPoly, nil
+}
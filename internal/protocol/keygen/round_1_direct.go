@@ -1,12 +1,9 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"fmt"
 	"math/big"
 
-	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
-	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -14,70 +11,46 @@ import (
 // round1Direct executes the logic for the first round of the 1-Round KeyGen optimization.
 // In this mode, we skip the commitment round and directly broadcast keys and commitments.
 func (s *state) round1Direct() (tss.StateMachine, []tss.Message, error) {
-	// 1. Generate Paillier Key Pair
-	paillierSk, err := paillier.GenerateKey(rand.Reader, 2048)
+	// 1. Generate Paillier Key Pair (or restore it from a checkpoint)
+	paillierSk, err := s.paillierKey()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate paillier key: %w", err)
+		return nil, nil, err
 	}
 
 	// Save keys to state
 	s.saveData.PaillierSk = paillierSk
 	s.saveData.PaillierPk = &paillierSk.PublicKey
+	s.saveData.Mode = ModeOneRound
+	s.saveData.Threshold = s.params.Threshold
 
 	// 2. Generate VSS Polynomial
 	// Degree t = threshold
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	poly, err := polynomial.New(curve, s.params.Threshold, nil) // nil secret -> random u_i
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate polynomial: %w", err)
 	}
 
 	// Save our secret share (u_i = poly.Coefficients[0])
-	s.saveData.Ui = poly.Coefficients[0]
+	// Copied, since the polynomial is zeroized once KeyGen completes
+	s.saveData.Ui = new(big.Int).Set(poly.Coefficients[0])
 	s.tempData["polynomial"] = poly
 
 	// 3. Calculate VSS Commitments (Feldman VSS)
 	// C_k = a_k * G
 	vssCommitments := make([]*big.Int, len(poly.Coefficients)*2) // Store as (x, y) pairs flattened
 	for i, coeff := range poly.Coefficients {
-		fmt.Printf("DEBUG: Sender %s Coeff[%d] = %s\n", s.params.PartyID.ID(), i, coeff.String())
 		x, y := curve.ScalarBaseMult(coeff)
 		vssCommitments[i*2] = x
 		vssCommitments[i*2+1] = y
 	}
 	s.tempData["vss_commitments"] = vssCommitments
 
-	fmt.Printf("DEBUG: Sender %s generated VSS C0=(%s, %s)\n", s.params.PartyID.ID(), vssCommitments[0].String(), vssCommitments[1].String())
-	if len(vssCommitments) > 2 {
-		fmt.Printf("DEBUG: Sender %s generated VSS C1=(%s, %s)\n", s.params.PartyID.ID(), vssCommitments[2].String(), vssCommitments[3].String())
-	}
+	s.params.Log().Debug("generated vss commitments", "party", s.params.PartyID.ID(), "degree", len(poly.Coefficients)-1)
 
 	// 4. Prepare Broadcast Payload (PaillierPK || VSS_Commitments)
 	// Same serialization as Round 2 Decommit, but without Salt.
-	var payload []byte
-
-	// Pad Paillier N to 256 bytes (2048 bits)
-	nBytes := paillierSk.PublicKey.N.Bytes()
-	paddedN := make([]byte, 256)
-	if len(nBytes) > 256 {
-		// Just copy suffix if too long
-		copy(paddedN, nBytes[len(nBytes)-256:])
-	} else {
-		copy(paddedN[256-len(nBytes):], nBytes)
-	}
-	payload = append(payload, paddedN...)
-
-	for _, coord := range vssCommitments {
-		// Pad to 32 bytes (256 bits)
-		cBytes := coord.Bytes()
-		paddedC := make([]byte, 32)
-		if len(cBytes) > 32 {
-			copy(paddedC, cBytes[len(cBytes)-32:])
-		} else {
-			copy(paddedC[32-len(cBytes):], cBytes)
-		}
-		payload = append(payload, paddedC...)
-	}
+	payload := serializeCommitData(curve, paillierSk.PublicKey.N, vssCommitments)
 
 	outMsgs := []tss.Message{}
 
@@ -89,6 +62,7 @@ func (s *state) round1Direct() (tss.StateMachine, []tss.Message, error) {
 		Data:       payload,
 		TypeString: "KeyGen1Round_Direct_Broadcast", // Distinguishes from Round 1 Commit
 		RoundNum:   1,
+		Session:    s.params.SessionID,
 	}
 	outMsgs = append(outMsgs, bcastMsg)
 
@@ -102,14 +76,19 @@ func (s *state) round1Direct() (tss.StateMachine, []tss.Message, error) {
 		// Calculate x = index + 1
 		x := big.NewInt(int64(i + 1))
 		share := poly.Evaluate(x)
+		data, err := s.sealShare(peer, share)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		p2pMsg := &KeyGenMessage{
 			FromParty:  s.params.PartyID,
 			ToParties:  []tss.PartyID{peer},
 			IsBcast:    false,
-			Data:       share.Bytes(),
+			Data:       data,
 			TypeString: "KeyGen1Round_Direct_Share",
 			RoundNum:   1, // It's still Round 1 in this protocol
+			Session:    s.params.SessionID,
 		}
 		outMsgs = append(outMsgs, p2pMsg)
 	}
@@ -40,37 +40,9 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		return nil, nil, fmt.Errorf("missing vss commitments")
 	}
 
-	// Re-serialize data
 	// Re-serialize data
 	// Use fixed-width fields to avoid parsing ambiguity
-	var decommitData []byte
-
-	// Pad Paillier N to 256 bytes (2048 bits)
-	nBytes := paillierPk.N.Bytes()
-	if len(nBytes) > 256 {
-		// Should not happen for 2048-bit keys, but handle gracefully or error?
-		// For now, take suffix or just append.
-		// Realistically, if it's > 256 bytes, we have a bigger config issue.
-		// But let's assume it fits.
-	}
-	paddedN := make([]byte, 256)
-	// Right-align the bytes (BigEndian)
-	copy(paddedN[256-len(nBytes):], nBytes)
-	decommitData = append(decommitData, paddedN...)
-
-	for _, coord := range vssCommitments {
-		// Pad to 32 bytes (256 bits)
-		cBytes := coord.Bytes()
-		paddedC := make([]byte, 32)
-		if len(cBytes) > 32 {
-			// This can happen if mod N is close to 2^256? secp256k1 order is < 2^256.
-			// Just copy suffix if too long (unlikely for valid field elements)
-			copy(paddedC, cBytes[len(cBytes)-32:])
-		} else {
-			copy(paddedC[32-len(cBytes):], cBytes)
-		}
-		decommitData = append(decommitData, paddedC...)
-	}
+	decommitData := serializeCommitData(s.curve, paillierPk.N, vssCommitments)
 
 	// Payload: Salt || Data
 	// To make parsing easier, we might want a proper serialization format (e.g. Protobuf or length-prefixed).
@@ -86,6 +58,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		Data:       payload,
 		TypeString: "KeyGenRound2_Decommit",
 		RoundNum:   2,
+		Session:    s.params.SessionID,
 	}
 	outMsgs = append(outMsgs, broadcastMsg)
 
@@ -101,18 +74,23 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		}
 
 		// Calculate x = index + 1 (using 1-based index for polynomial evaluation)
-		// We assume s.params.Parties is sorted and consistent across all parties.
+		// s.params.Parties is in canonical order (see tss.SortParties).
 		x := big.NewInt(int64(i + 1))
 		share := poly.Evaluate(x)
 
-		// Payload: Share (big.Int bytes)
+		// Payload: Share (big.Int bytes), encrypted with EncryptShares
+		data, err := s.sealShare(peer, share)
+		if err != nil {
+			return nil, nil, err
+		}
 		p2pMsg := &KeyGenMessage{
 			FromParty:  s.params.PartyID,
 			ToParties:  []tss.PartyID{peer},
 			IsBcast:    false,
-			Data:       share.Bytes(),
+			Data:       data,
 			TypeString: "KeyGenRound2_Share",
 			RoundNum:   2,
+			Session:    s.params.SessionID,
 		}
 		outMsgs = append(outMsgs, p2pMsg)
 	}
@@ -120,6 +98,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 	// 3. Update State
 	newState := &state{
 		params:       s.params,
+		curve:        s.curve,
 		round:        2,
 		saveData:     s.saveData,
 		tempData:     s.tempData,
@@ -128,3 +107,26 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 
 	return newState, outMsgs, nil
 }
+
+// sealShare returns the payload carrying share to peer: the share bytes,
+// encrypted to the peer's encryption key if EncryptShares is set.
+func (s *state) sealShare(peer tss.PartyID, share *big.Int) ([]byte, error) {
+	if !s.params.EncryptShares {
+		return share.Bytes(), nil
+	}
+	return s.params.EncryptShare(peer, share.Bytes())
+}
+
+// openShare returns the share carried by msg (see sealShare). A share that
+// does not decrypt is blamed on its sender.
+func (s *state) openShare(msg tss.Message) (*big.Int, error) {
+	data := msg.Payload()
+	if s.params.EncryptShares {
+		var err error
+		data, err = s.params.DecryptShare(msg.From(), data)
+		if err != nil {
+			return nil, tss.NewBlame(msg.From(), "undecryptable vss share", err)
+		}
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -21,8 +21,7 @@ func (s *state) round2Direct() (tss.StateMachine, []tss.Message, error) {
 	curve := poly.Curve
 
 	// Prepare to calculate x_i
-	myIdx := new(big.Int)
-	myIdx.SetString(s.params.PartyID.ID(), 10)
+	myIdx := ShareIndices(s.params.Parties)[s.params.PartyID.ID()]
 
 	// x_i starts with our own share F_i(i)
 	xi := poly.Evaluate(myIdx)
@@ -58,60 +57,26 @@ func (s *state) round2Direct() (tss.StateMachine, []tss.Message, error) {
 		}
 
 		// 1. Process Broadcast Data (PaillierPK || VSS_Commitments)
-		data := bcastMsg.Payload()
-		if len(data) < 256 {
-			return nil, nil, fmt.Errorf("data too short for Paillier N from %s", id)
-		}
-
-		paillierNBytes := data[:256]
-		paillierN := new(big.Int).SetBytes(paillierNBytes)
-		peerPk := &paillier.PublicKey{N: paillierN, N2: new(big.Int).Mul(paillierN, paillierN)}
-		s.saveData.PeerPaillierPks[id] = peerPk
-
-		vssData := data[256:]
 		t := s.params.Threshold
-		expectedLen := (t + 1) * 64
-		if len(vssData) != expectedLen {
-			return nil, nil, fmt.Errorf("vss data length mismatch from %s: expected %d, got %d", id, expectedLen, len(vssData))
-		}
-
-		vssPoly := make([]*big.Int, (t+1)*2)
-		for k := 0; k <= t; k++ {
-			xBytes := vssData[k*64 : k*64+32]
-			yBytes := vssData[k*64+32 : (k+1)*64]
-			vssPoly[k*2] = new(big.Int).SetBytes(xBytes)
-			vssPoly[k*2+1] = new(big.Int).SetBytes(yBytes)
+		paillierN, vssPoly, err := parseCommitData(curve, bcastMsg.Payload(), t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid commitment data from %s: %w", id, err)
 		}
+		peerPk := paillier.NewPublicKey(paillierN)
+		s.saveData.PeerPaillierPks[id] = peerPk
 
-		fmt.Printf("DEBUG: Receiver %s parsed VSS from %s: C0=(%s, %s)\n", s.params.PartyID.ID(), id, vssPoly[0].String(), vssPoly[1].String())
-		if len(vssPoly) > 2 {
-			fmt.Printf("DEBUG: Receiver %s parsed VSS from %s: C1=(%s, %s)\n", s.params.PartyID.ID(), id, vssPoly[2].String(), vssPoly[3].String())
-		}
+		s.params.Log().Debug("parsed vss commitments", "party", s.params.PartyID.ID(), "from", id)
 
 		allVss[id] = vssPoly
 
 		// 2. Verify Share
-		share := new(big.Int).SetBytes(shareMsg.Payload())
-
-		// LHS: share * G
-		lhsX, lhsY := curve.ScalarBaseMult(share)
-
-		// RHS: sum( A_j,k * i^k )
-		var rhsX, rhsY *big.Int
-		for k := 0; k <= t; k++ {
-			akX := vssPoly[k*2]
-			akY := vssPoly[k*2+1]
-			scalar := new(big.Int).Exp(myIdx, big.NewInt(int64(k)), curve.Params().N)
-			termX, termY := curve.ScalarMult(akX, akY, scalar)
-
-			if k == 0 {
-				rhsX, rhsY = termX, termY
-			} else {
-				rhsX, rhsY = curve.Add(rhsX, rhsY, termX, termY)
-			}
+		share, err := s.openShare(shareMsg)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if lhsX.Cmp(rhsX) != 0 || lhsY.Cmp(rhsY) != 0 {
+		// share * G = sum( A_j,k * i^k )
+		if !polynomial.VerifyFeldmanShare(curve, myIdx, share, vssPoly) {
 			return nil, nil, tss.NewBlame(shareMsg.From(), "vss share verification failed", nil)
 		}
 
@@ -139,12 +104,29 @@ func (s *state) round2Direct() (tss.StateMachine, []tss.Message, error) {
 
 	// Save data
 	s.saveData.Xi = xi
+	s.saveData.ShareIDs = ShareIndices(s.params.Parties)
+	s.saveData.ShareID = s.saveData.ShareIDs[s.params.PartyID.ID()]
 	s.saveData.XiX = Xi_x
 	s.saveData.XiY = Xi_y
 	s.saveData.PublicKeyX = X_x
 	s.saveData.PublicKeyY = X_y
-	// We also save allVss if needed for future
-	// s.tempData["all_vss"] = allVss // Not strict require for result
+
+	// Peers' public key shares follow from the verified VSS commitments
+	s.saveData.PeerXiX = make(map[string]*big.Int)
+	s.saveData.PeerXiY = make(map[string]*big.Int)
+	for id, x := range s.saveData.ShareIDs {
+		if id == s.params.PartyID.ID() {
+			continue
+		}
+		s.saveData.PeerXiX[id], s.saveData.PeerXiY[id] = publicShare(curve, allVss, x, s.params.Threshold)
+	}
+
+	s.zeroizeSecrets()
+
+	// Optionally wait for every party to confirm before finishing
+	if s.params.KeyGenAck {
+		return s.roundAck(2)
+	}
 
 	// Return finished state
 	return &finishedState{data: s.saveData}, nil, nil
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
@@ -18,6 +17,8 @@ type Round3Payload struct {
 	XiY    []byte // Y coordinate of X_i
 	ProofR []byte // Serialized R point of Schnorr proof
 	ProofS []byte // Scalar s of Schnorr proof
+
+	Pedersen *commitment.PedersenParams // Ring-Pedersen parameters over our Paillier modulus
 }
 
 func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
@@ -29,9 +30,8 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	// Initialize x_i with our own share u_{i->i}
 	// x_i = sum_j F_j(i)
 	// We need to calculate F_i(i) first.
-	// My index is s.params.PartyID
-	myIdx := new(big.Int)
-	myIdx.SetString(s.params.PartyID.ID(), 10)
+	// My index is my 1-based position in the canonical committee
+	myIdx := ShareIndices(s.params.Parties)[s.params.PartyID.ID()]
 
 	xi := poly.Evaluate(myIdx)
 
@@ -78,80 +78,36 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 
 		// Verify against Round 1 Commitment
 		comm := peerCommitments[id]
-		if !commitment.Verify(comm, salt, data) {
+		if !commitment.Verify(comm, salt, bindCommitData(s.params.SessionID, id, data)) {
 			return nil, nil, tss.NewBlame(decommitMsg.From(), "commitment verification failed", nil)
 		}
 
 		// 1b. Parse Data
-		// Format: PaillierN (variable) || VSS_X0 || VSS_Y0 || ...
-		// We assume Paillier N is 256 bytes.
-		if len(data) < 256 {
-			return nil, nil, fmt.Errorf("data too short for Paillier N from %s", id)
+		// Format: length-prefixed PaillierN, VSS_X0, VSS_Y0, ...
+		t := s.params.Threshold
+		paillierN, vssPoly, err := parseCommitData(curve, data, t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid commitment data from %s: %w", id, err)
 		}
-		paillierNBytes := data[:256]
-		paillierN := new(big.Int).SetBytes(paillierNBytes)
-		peerPk := &paillier.PublicKey{N: paillierN, N2: new(big.Int).Mul(paillierN, paillierN)}
+		peerPk := paillier.NewPublicKey(paillierN)
 
 		if s.saveData.PeerPaillierPks == nil {
 			s.saveData.PeerPaillierPks = make(map[string]*paillier.PublicKey)
 		}
 		s.saveData.PeerPaillierPks[id] = peerPk
 
-		vssData := data[256:] // Skip Paillier N
-
-		// Parse VSS Commitments (A_j,0 ... A_j,t)
-		// t = threshold
-		t := s.params.Threshold
-		expectedLen := (t + 1) * 64 // 32 bytes for X, 32 bytes for Y
-		if len(vssData) != expectedLen {
-			return nil, nil, fmt.Errorf("vss data length mismatch from %s: expected %d, got %d", id, expectedLen, len(vssData))
-		}
-
-		vssPoly := make([]*big.Int, (t+1)*2)
-		for k := 0; k <= t; k++ {
-			xBytes := vssData[k*64 : k*64+32]
-			yBytes := vssData[k*64+32 : (k+1)*64]
-			vssPoly[k*2] = new(big.Int).SetBytes(xBytes)
-			vssPoly[k*2+1] = new(big.Int).SetBytes(yBytes)
-		}
 		allVss[id] = vssPoly
 
 		// 1c. Verify Share
-		share := new(big.Int).SetBytes(shareMsg.Payload())
-
-		// Verify: share * G = sum( (index)^k * A_j,k )
-		// My index (i) is s.params.PartyID (we need numeric index)
-		// We assume PartyID.ID() is "1", "2", etc. or we map them.
-		// In `keygen_test.go` we used "1", "2".
-		// Let's parse ID to int.
-		myIdx := new(big.Int)
-		myIdx.SetString(s.params.PartyID.ID(), 10)
-
-		// LHS: share * G
-		lhsX, lhsY := curve.ScalarBaseMult(share)
-
-		// RHS: sum
-		var rhsX, rhsY *big.Int
-
-		for k := 0; k <= t; k++ {
-			// term = A_j,k * (myIdx^k)
-			akX := vssPoly[k*2]
-			akY := vssPoly[k*2+1]
-
-			// scalar = myIdx^k
-			scalar := new(big.Int).Exp(myIdx, big.NewInt(int64(k)), curve.Params().N)
-
-			// term = scalar * A_j,k
-			termX, termY := curve.ScalarMult(akX, akY, scalar)
-
-			if k == 0 {
-				rhsX, rhsY = termX, termY
-			} else {
-				rhsX, rhsY = curve.Add(rhsX, rhsY, termX, termY)
-			}
+		share, err := s.openShare(shareMsg)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if lhsX.Cmp(rhsX) != 0 || lhsY.Cmp(rhsY) != 0 {
+		// Verify: share * G = sum( (index)^k * A_j,k )
+		// My index (i) is my 1-based position in the canonical committee
+		myIdx := ShareIndices(s.params.Parties)[s.params.PartyID.ID()]
+		if !polynomial.VerifyFeldmanShare(curve, myIdx, share, vssPoly) {
 			return nil, nil, tss.NewBlame(shareMsg.From(), "vss share verification failed", nil)
 		}
 
@@ -167,31 +123,22 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	// We prove we know x_i such that X_i = x_i * G
 	Xi_x, Xi_y := curve.ScalarBaseMult(xi)
 
-	// Convert to Jacobian for ZK lib
-	var Xi_jac secp256k1.JacobianPoint
-	var Xi_x_field, Xi_y_field secp256k1.FieldVal
-	Xi_x_field.SetByteSlice(Xi_x.Bytes())
-	Xi_y_field.SetByteSlice(Xi_y.Bytes())
-	Xi_jac.X = Xi_x_field
-	Xi_jac.Y = Xi_y_field
-	Xi_jac.Z.SetInt(1)
-
-	proof, err := schnorr.Prove(xi, &Xi_jac)
+	proof, err := schnorr.ProveOnCurve(curve, xi, Xi_x, Xi_y, schnorr.Aux(s.params.SessionID, s.params.PartyID.ID()))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate schnorr proof: %w", err)
 	}
 
 	// 3. Broadcast Proof
 	// Serialize Proof
-	proof.R.ToAffine()
-	pub := secp256k1.NewPublicKey(&proof.R.X, &proof.R.Y)
-	R_bytes := pub.SerializeCompressed()
+	R_bytes := curve.MarshalCompressed(proof.Rx, proof.Ry)
 
 	payload := Round3Payload{
 		XiX:    Xi_x.Bytes(),
 		XiY:    Xi_y.Bytes(),
 		ProofR: R_bytes,
 		ProofS: proof.S.Bytes(),
+
+		Pedersen: s.saveData.PedersenParams,
 	}
 
 	data, err := json.Marshal(payload)
@@ -206,10 +153,13 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		Data:       data,
 		TypeString: "KeyGenRound3_Proof",
 		RoundNum:   3,
+		Session:    s.params.SessionID,
 	}
 
 	// Save data for next round
 	s.saveData.Xi = xi
+	s.saveData.ShareIDs = ShareIndices(s.params.Parties)
+	s.saveData.ShareID = s.saveData.ShareIDs[s.params.PartyID.ID()]
 	s.saveData.XiX = Xi_x
 	s.saveData.XiY = Xi_y
 	s.saveData.PublicKeyX = X_x
@@ -219,6 +169,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	// Clear received messages
 	newState := &state{
 		params:       s.params,
+		curve:        s.curve,
 		round:        3,
 		saveData:     s.saveData,
 		tempData:     s.tempData,
@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	// 1. Process Round 3 Messages (Schnorr Proofs)
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	allVss, _ := s.tempData["all_vss"].(map[string][]*big.Int)
+	s.saveData.PeerXiX = make(map[string]*big.Int)
+	s.saveData.PeerXiY = make(map[string]*big.Int)
 
 	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 {
@@ -27,36 +30,40 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 			return nil, nil, fmt.Errorf("failed to unmarshal round 3 payload from %s: %w", id, err)
 		}
 
+		// 1a. Verify ring-Pedersen parameters
+		// They must be well-formed and built over the Paillier modulus committed in Round 1
+		if err := payload.Pedersen.Validate(); err != nil {
+			return nil, nil, tss.NewBlame(msg.From(), "invalid pedersen parameters", err)
+		}
+		peerPk := s.saveData.PeerPaillierPks[id]
+		if peerPk == nil || payload.Pedersen.N.Cmp(peerPk.N) != 0 {
+			return nil, nil, tss.NewBlame(msg.From(), "pedersen modulus does not match paillier key", nil)
+		}
+		if s.saveData.PeerPedersenParams == nil {
+			s.saveData.PeerPedersenParams = make(map[string]*commitment.PedersenParams)
+		}
+		s.saveData.PeerPedersenParams[id] = payload.Pedersen
+
 		// 2. Verify Schnorr Proof
 		// Reconstruct X_j point
 		Xj_x := new(big.Int).SetBytes(payload.XiX)
 		Xj_y := new(big.Int).SetBytes(payload.XiY)
 		
-		// Convert to Jacobian
-		var Xj_jac secp256k1.JacobianPoint
-		var Xj_x_field, Xj_y_field secp256k1.FieldVal
-		Xj_x_field.SetByteSlice(Xj_x.Bytes())
-		Xj_y_field.SetByteSlice(Xj_y.Bytes())
-		Xj_jac.X = Xj_x_field
-		Xj_jac.Y = Xj_y_field
-		Xj_jac.Z.SetInt(1)
-
 		// Reconstruct Proof
 		// R
-		pubKey, err := secp256k1.ParsePubKey(payload.ProofR)
+		Rx, Ry, err := curve.UnmarshalCompressed(payload.ProofR)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to parse R point from %s: %w", id, err)
 		}
-		
-		var R_jac_recovered secp256k1.JacobianPoint
-		pubKey.AsJacobian(&R_jac_recovered)
-		
-		proof := &schnorr.Proof{
-			R: &R_jac_recovered,
-			S: new(big.Int).SetBytes(payload.ProofS),
+
+		proof := &schnorr.CurveProof{
+			Rx: Rx,
+			Ry: Ry,
+			S:  new(big.Int).SetBytes(payload.ProofS),
 		}
-		
-		if !proof.Verify(&Xj_jac) {
+
+		// Verify also rejects an X_j that is not on the curve
+		if !proof.Verify(curve, Xj_x, Xj_y, schnorr.Aux(s.params.SessionID, id)) {
 			return nil, nil, tss.NewBlame(msg.From(), "schnorr proof verification failed", nil)
 		}
 
@@ -64,49 +71,45 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 		// X_j should be sum_k (Eval(A_k, j+1))
 		// j is the ID of the sender of this message
 		
-		// Parse j's ID to int
-		jIdx := new(big.Int)
-		jIdx.SetString(id, 10)
-		
-		// Calculate expected X_j
-		var expectedX, expectedY *big.Int
-		
-		// Iterate over all parties k (including self)
-		for _, vss := range allVss {
-			// Evaluate polynomial A_k at x = jIdx
-			// A_k is defined by vss (points)
-			// val = sum_m (A_k,m * jIdx^m)
-			
-			var termSumX, termSumY *big.Int
-			t := s.params.Threshold
-			
-			for m := 0; m <= t; m++ {
-				akX := vss[m*2]
-				akY := vss[m*2+1]
-				
-				scalar := new(big.Int).Exp(jIdx, big.NewInt(int64(m)), curve.Params().N)
-				tx, ty := curve.ScalarMult(akX, akY, scalar)
-				
-				if m == 0 {
-					termSumX, termSumY = tx, ty
-				} else {
-					termSumX, termSumY = curve.Add(termSumX, termSumY, tx, ty)
-				}
-			}
-			
-			// Add to total sum
-			if expectedX == nil {
-				expectedX, expectedY = termSumX, termSumY
-			} else {
-				expectedX, expectedY = curve.Add(expectedX, expectedY, termSumX, termSumY)
-			}
+		// j's index is its 1-based position in the canonical committee
+		jIdx := s.saveData.ShareIDs[id]
+		if jIdx == nil {
+			return nil, nil, tss.NewBlame(msg.From(), "sender is not in the committee", nil)
 		}
 		
+		// Calculate expected X_j
+		expectedX, expectedY := publicShare(curve, allVss, jIdx, s.params.Threshold)
+
 		if Xj_x.Cmp(expectedX) != 0 || Xj_y.Cmp(expectedY) != 0 {
 			return nil, nil, tss.NewBlame(msg.From(), "public key share mismatch", nil)
 		}
+		s.saveData.PeerXiX[id] = Xj_x
+		s.saveData.PeerXiY[id] = Xj_y
+	}
+
+	s.zeroizeSecrets()
+
+	// Optionally wait for every party to confirm before finishing
+	if s.params.KeyGenAck {
+		return s.roundAck(4)
 	}
 
 	// Protocol Finished!
 	return &finishedState{data: s.saveData}, nil, nil
 }
+
+// publicShare returns X_j = sum_k A_k(x), the public key share at index x,
+// from every party's VSS commitments A_k (flattened x,y coefficient points).
+func publicShare(curve curves.Curve, allVss map[string][]*big.Int, x *big.Int, t int) (*big.Int, *big.Int) {
+	var sumX, sumY *big.Int
+	for _, vss := range allVss {
+		// A_k(x) = sum_m (A_k,m * x^m)
+		tx, ty := polynomial.EvalCommitment(curve, vss[:2*(t+1)], x)
+		if sumX == nil {
+			sumX, sumY = tx, ty
+		} else {
+			sumX, sumY = curve.Add(sumX, sumY, tx, ty)
+		}
+	}
+	return sumX, sumY
+}
@@ -0,0 +1,74 @@
+package keygen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// AckPayload confirms that the sender completed KeyGen successfully.
+// It carries the resulting public key so the committee can check it agrees on the outcome.
+type AckPayload struct {
+	PublicKeyX []byte
+	PublicKeyY []byte
+}
+
+// roundAck broadcasts our ACK and waits for every peer's ACK before finishing.
+// It is only used when params.KeyGenAck is set.
+func (s *state) roundAck(round int) (tss.StateMachine, []tss.Message, error) {
+	payload := AckPayload{
+		PublicKeyX: s.saveData.PublicKeyX.Bytes(),
+		PublicKeyY: s.saveData.PublicKeyY.Bytes(),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := &KeyGenMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  nil,
+		IsBcast:    true,
+		Data:       data,
+		TypeString: "KeyGenAck",
+		RoundNum:   uint32(round),
+		Session:    s.params.SessionID,
+	}
+
+	newState := &state{
+		params:       s.params,
+		curve:        s.curve,
+		round:        round,
+		saveData:     s.saveData,
+		tempData:     s.tempData,
+		receivedMsgs: make(map[string][]tss.Message),
+	}
+
+	return newState, []tss.Message{msg}, nil
+}
+
+// finishAck checks all peers acknowledged the same public key and finishes the protocol.
+func (s *state) finishAck() (tss.StateMachine, []tss.Message, error) {
+	for id, msgs := range s.receivedMsgs {
+		if len(msgs) == 0 {
+			continue
+		}
+		msg := msgs[0]
+
+		var payload AckPayload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal ack from %s: %w", id, err)
+		}
+
+		x := new(big.Int).SetBytes(payload.PublicKeyX)
+		y := new(big.Int).SetBytes(payload.PublicKeyY)
+		if x.Cmp(s.saveData.PublicKeyX) != 0 || y.Cmp(s.saveData.PublicKeyY) != 0 {
+			return nil, nil, tss.NewBlame(msg.From(), "acknowledged public key mismatch", nil)
+		}
+	}
+
+	// Protocol Finished!
+	return &finishedState{data: s.saveData}, nil, nil
+}
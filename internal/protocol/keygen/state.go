@@ -3,11 +3,14 @@ package keygen
 import (
 	"fmt"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 type state struct {
 	params *tss.Parameters
+	curve  curves.Curve // Resolved from params.Curve
 
 	// Current round number (1-based)
 	round int
@@ -21,13 +24,28 @@ type state struct {
 	// Messages received in the current round
 	// Map: PartyID.ID() -> []Message
 	receivedMsgs map[string][]tss.Message
+
+	// Messages of the previous round, kept to recognise late retransmissions
+	previousMsgs map[string][]tss.Message
+
+	// Messages received for a future round, replayed once we get there
+	pending []tss.Message
 }
 
 // NewStateMachine initializes a new KeyGen state machine.
 // It immediately executes Round 1 logic to generate the first set of messages.
 func NewStateMachine(params *tss.Parameters) (tss.StateMachine, []tss.Message, error) {
+	params, err := params.Canonical()
+	if err != nil {
+		return nil, nil, err
+	}
+	curve, err := params.ResolveCurve()
+	if err != nil {
+		return nil, nil, err
+	}
 	s := &state{
 		params: params,
+		curve:  curve,
 		round:  1,
 		saveData: &LocalPartySaveData{
 			LocalPartyID: params.PartyID,
@@ -38,64 +56,63 @@ func NewStateMachine(params *tss.Parameters) (tss.StateMachine, []tss.Message, e
 
 	// Check initialization logic
 	if params.OneRoundKeyGen {
-		return s.round1Direct()
+		return tss.Wrap(params)(s.round1Direct())
 	}
 
-	return s.round1()
+	return tss.Wrap(params)(s.round1())
 }
 
 func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	// Messages replayed from another session are never processed
+	if err := s.params.CheckSession(msg); err != nil {
+		return s, nil, err
+	}
+
+	// A retransmission of the previous round may arrive after we moved on
+	if msg.RoundNumber()+1 == uint32(s.round) && s.previousMsgs != nil {
+		duplicate, err := tss.CheckDuplicate(s.previousMsgs[msg.From().ID()], msg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if duplicate {
+			return s, nil, nil
+		}
+	}
+
+	// Early message: keep it until we reach its round
+	if msg.RoundNumber() > uint32(s.round) {
+		s.pending = append(s.pending, msg)
+		return s, nil, nil
+	}
+
 	// Validate message round
 	if msg.RoundNumber() != uint32(s.round) {
 		return nil, nil, fmt.Errorf("received message for round %d, expected %d", msg.RoundNumber(), s.round)
 	}
 
 	// Validate sender
-	senderID := msg.From().ID()
-	if senderID == s.params.PartyID.ID() {
-		return nil, nil, nil // Ignore own messages if looped back
+	if s.params.IsOwnMessage(msg) {
+		return s, nil, nil // Ignore own messages if looped back
 	}
+	senderID := msg.From().ID()
 
 	// Store message
 	if s.receivedMsgs == nil {
 		s.receivedMsgs = make(map[string][]tss.Message)
 	}
 
-	// Check for duplicates (simple check based on type)
-	for _, existing := range s.receivedMsgs[senderID] {
-		if existing.Type() == msg.Type() {
-			return nil, nil, fmt.Errorf("duplicate message type %s from party %s", msg.Type(), senderID)
-		}
+	// Exact retransmissions are ignored, conflicting ones are equivocation
+	duplicate, err := tss.CheckDuplicate(s.receivedMsgs[senderID], msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if duplicate {
+		return s, nil, nil
 	}
 
 	s.receivedMsgs[senderID] = append(s.receivedMsgs[senderID], msg)
 
-	// Check if we have received all expected messages from all other parties
-	// Check if we have received all expected messages from all other parties
-	// Standard:
-	// Round 1: 1 Broadcast per peer
-	// Round 2: 1 Broadcast + 1 P2P per peer
-	// Round 3: 1 Broadcast per peer
-
-	// OneRoundKeyGen:
-	// Round 1: 1 Broadcast + 1 P2P per peer
-
-	expectedCount := 0
-	if s.params.OneRoundKeyGen {
-		switch s.round {
-		case 1:
-			expectedCount = 2 // Broadcast + Share
-		}
-	} else {
-		switch s.round {
-		case 1:
-			expectedCount = 1
-		case 2:
-			expectedCount = 2
-		case 3:
-			expectedCount = 1
-		}
-	}
+	expectedCount := s.expectedPerPeer()
 
 	// Check if all peers have sent enough messages
 	// We need to hear from ALL n-1 peers
@@ -110,7 +127,65 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 	}
 
 	// Round complete, transition to next round
-	return s.nextRound()
+	pending := s.pending
+	s.pending = nil
+	next, out, err := s.nextRound()
+	if err != nil {
+		// The session is over, so nothing needs the secrets any more
+		s.zeroizeSecrets()
+		return &abortedState{err: err}, nil, err
+	}
+	if ns, ok := next.(*state); ok {
+		ns.previousMsgs = s.receivedMsgs
+	}
+	return tss.Replay(next, out, pending)
+}
+
+// expectedPerPeer returns how many messages each peer sends in the current round.
+//
+// Standard:
+// Round 1: 1 Broadcast per peer
+// Round 2: 1 Broadcast + 1 P2P per peer
+// Round 3: 1 Broadcast per peer
+//
+// OneRoundKeyGen:
+// Round 1: 1 Broadcast + 1 P2P per peer
+//
+// KeyGenAck adds a final round with 1 Broadcast (ACK) per peer:
+// Round 4 (standard) or Round 2 (OneRoundKeyGen)
+func (s *state) expectedPerPeer() int {
+	if s.params.OneRoundKeyGen {
+		switch s.round {
+		case 1:
+			return 2 // Broadcast + Share
+		case 2:
+			return 1 // ACK
+		}
+		return 0
+	}
+	switch s.round {
+	case 1:
+		return 1
+	case 2:
+		return 2
+	case 3:
+		return 1
+	case 4:
+		return 1 // ACK
+	}
+	return 0
+}
+
+// WaitingFor returns the peers whose messages for the current round are
+// still outstanding.
+func (s *state) WaitingFor() []tss.PartyID {
+	return tss.MissingSenders(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// RemainingForRound returns how many messages the peers still owe for the
+// current round.
+func (s *state) RemainingForRound() int {
+	return tss.RemainingMessages(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
 }
 
 func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
@@ -118,6 +193,11 @@ func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
 		switch s.round {
 		case 1:
 			return s.round2Direct()
+		case 2:
+			if s.params.KeyGenAck {
+				return s.finishAck()
+			}
+			return nil, nil, fmt.Errorf("unknown round %d in direct mode", s.round)
 		// No further rounds
 		default:
 			return nil, nil, fmt.Errorf("unknown round %d in direct mode", s.round)
@@ -131,6 +211,11 @@ func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
 		return s.round3()
 	case 3:
 		return s.round4()
+	case 4:
+		if s.params.KeyGenAck {
+			return s.finishAck()
+		}
+		return nil, nil, fmt.Errorf("unknown round %d", s.round)
 	default:
 		return nil, nil, fmt.Errorf("unknown round %d", s.round)
 	}
@@ -161,3 +246,31 @@ func (s *finishedState) Result() interface{} {
 func (s *finishedState) Details() string {
 	return "KeyGen Finished"
 }
+
+// abortedState is the terminal state after a fatal error in a round. Late
+// messages are rejected with tss.ErrProtocolDone instead of reaching a state
+// that can no longer make progress.
+type abortedState struct {
+	err error
+}
+
+func (s *abortedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (s *abortedState) Result() interface{} {
+	return nil
+}
+
+func (s *abortedState) Details() string {
+	return fmt.Sprintf("KeyGen Aborted: %v", s.err)
+}
+
+// secretTempData lists the tempData entries holding secrets: the polynomial, whose constant term is u_i.
+var secretTempData = []string{"polynomial"}
+
+// zeroizeSecrets overwrites and drops the secret tempData entries once the
+// rounds that need them are over, or the session aborted.
+func (s *state) zeroizeSecrets() {
+	zeroize.Entries(s.tempData, secretTempData...)
+}
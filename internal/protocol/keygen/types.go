@@ -3,10 +3,21 @@ package keygen
 import (
 	"math/big"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
+// Keygen modes recorded in LocalPartySaveData.Mode
+const (
+	// ModeStandard is the 4-round protocol with commit-then-reveal of the
+	// VSS commitments.
+	ModeStandard = "standard"
+	// ModeOneRound is the single-round direct protocol (OneRoundKeyGen).
+	ModeOneRound = "one-round"
+)
+
 // LocalPartySaveData contains the final result of the KeyGen protocol
 // that needs to be persisted by the local party.
 type LocalPartySaveData struct {
@@ -21,11 +32,20 @@ type LocalPartySaveData struct {
 	ShareID *big.Int
 	// Xi removed (duplicate)
 
+	// x-coordinates of every committee member's share, keyed by PartyID.ID().
+	// Signers look up their original indices here, so any t+1 subset can sign.
+	ShareIDs map[string]*big.Int
+
 	// Paillier Keys
 	PaillierSk *paillier.PrivateKey
 	PaillierPk *paillier.PublicKey
 	PeerPaillierPks map[string]*paillier.PublicKey
 
+	// Ring-Pedersen parameters (N~, s, t) over our Paillier modulus,
+	// used as the auxiliary setup for range/affine proofs
+	PedersenParams     *commitment.PedersenParams
+	PeerPedersenParams map[string]*commitment.PedersenParams
+
 	// Our share of the secret key (u_i)
 	// This is the constant term of our polynomial F_i(x)
 	Ui *big.Int
@@ -36,9 +56,62 @@ type LocalPartySaveData struct {
 	XiX *big.Int
 	XiY *big.Int
 
+	// The public key shares X_j of the other parties, keyed by PartyID.ID().
+	// Set by KeyGen, Refresh and Reshare; nil for key data that does not
+	// know them.
+	PeerXiX map[string]*big.Int
+	PeerXiY map[string]*big.Int
+
 	// The global public key X = sum(A_{j,0})
 	PublicKeyX *big.Int
 	PublicKeyY *big.Int
+
+	// Epoch counts the Refresh and Reshare runs since KeyGen (which yields 0).
+	// Shares only combine with shares of the same epoch.
+	Epoch uint64
+
+	// Mode is the keygen protocol that produced the key (ModeStandard or
+	// ModeOneRound). Refresh keeps it.
+	Mode string
+
+	// Threshold is the degree t of the sharing: any t+1 committee members can
+	// sign. Set by KeyGen, Refresh and Reshare.
+	Threshold int
+}
+
+// Zeroize overwrites the secret values (the key share x_i, u_i and the
+// Paillier private key) once the key data is no longer needed, e.g. after it
+// has been persisted or replaced by a Refresh. The key data is unusable
+// afterwards.
+func (d *LocalPartySaveData) Zeroize() {
+	zeroize.Ints(d.Xi, d.Ui)
+	if d.PaillierSk != nil {
+		zeroize.Ints(d.PaillierSk.Lambda, d.PaillierSk.Mu)
+	}
+}
+
+// ShareIndices returns the x-coordinate of each party's share, keyed by PartyID.ID().
+// Shares are evaluated at the party's 1-based position in the committee.
+func ShareIndices(parties []tss.PartyID) map[string]*big.Int {
+	indices := make(map[string]*big.Int, len(parties))
+	for i, p := range parties {
+		indices[p.ID()] = big.NewInt(int64(i + 1))
+	}
+	return indices
+}
+
+// Message types emitted by keygen, for tss.DecodeMessage
+func init() {
+	tss.RegisterMessageType("keygen",
+		"KeyGenRound1",
+		"KeyGenRound2_Decommit",
+		"KeyGenRound2_Share",
+		"KeyGenRound3_Proof",
+		"KeyGen1Round_Direct_Broadcast",
+		"KeyGen1Round_Direct_Share",
+		"KeyGenAck",
+		"KeyGenBatch",
+	)
 }
 
 // KeyGenMessage is a concrete implementation of tss.Message for KeyGen
@@ -49,6 +122,7 @@ type KeyGenMessage struct {
 	Data        []byte
 	TypeString  string
 	RoundNum    uint32
+	Session     []byte // Session the message belongs to
 }
 
 func (m *KeyGenMessage) Type() string {
@@ -74,3 +148,7 @@ func (m *KeyGenMessage) Payload() []byte {
 func (m *KeyGenMessage) RoundNumber() uint32 {
 	return m.RoundNum
 }
+
+func (m *KeyGenMessage) SessionID() []byte {
+	return m.Session
+}
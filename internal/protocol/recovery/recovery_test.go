@@ -0,0 +1,310 @@
+package recovery
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+type MockPartyID struct {
+	id string
+}
+
+func (m *MockPartyID) ID() string      { return m.id }
+func (m *MockPartyID) Moniker() string { return m.id }
+func (m *MockPartyID) Key() []byte     { return []byte(m.id) }
+
+// TestRecoveryE2E runs a 2-of-3 KeyGen, drops party 3's share, recovers it
+// from parties 1 and 2 and signs with parties 1 and 3.
+func TestRecoveryE2E(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties)
+
+	// Party 3 loses its share but keeps the rest of its key data
+	lost := *keyData["3"]
+	lost.Xi = nil
+
+	sms := make(map[string]tss.StateMachine)
+	out := make(map[string][]tss.Message)
+	for _, p := range parties {
+		data := keyData[p.ID()]
+		if p.ID() == "3" {
+			data = &lost
+		}
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-recovery"),
+		}
+		sm, msgs, err := NewStateMachine(params, data, parties[2])
+		if err != nil {
+			t.Fatalf("Failed to create recovery state machine for %s: %v", p.ID(), err)
+		}
+		sms[p.ID()] = sm
+		out[p.ID()] = msgs
+	}
+	for r := 1; r <= 2; r++ {
+		sms, out = routeByID(t, sms, out)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		res, ok := sms[id].Result().(*HelperResult)
+		if !ok || res.LostPartyID != "3" {
+			t.Fatalf("Expected HelperResult for helper %s, got %v", id, sms[id].Result())
+		}
+	}
+	recovered, ok := sms["3"].Result().(*keygen.LocalPartySaveData)
+	if !ok {
+		t.Fatalf("Expected recovered key data, got %T", sms["3"].Result())
+	}
+	if recovered.Xi.Cmp(keyData["3"].Xi) != 0 {
+		t.Fatal("Recovered share differs from the original")
+	}
+
+	// The recovered share signs together with party 1
+	signers := []tss.PartyID{parties[0], parties[2]}
+	signData := map[string]*keygen.LocalPartySaveData{"1": keyData["1"], "3": recovered}
+	hash := sha256.Sum256([]byte("recovered share"))
+	signSMs := make(map[string]tss.StateMachine)
+	signOut := make(map[string][]tss.Message)
+	for _, p := range signers {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   signers,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-recovery-sign"),
+		}
+		sm, msgs, err := sign.NewStateMachine(params, signData[p.ID()], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine for %s: %v", p.ID(), err)
+		}
+		signSMs[p.ID()] = sm
+		signOut[p.ID()] = msgs
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOut = routeByID(t, signSMs, signOut)
+	}
+	for _, p := range signers {
+		if _, ok := signSMs[p.ID()].Result().(*sign.Signature); !ok {
+			t.Fatalf("Expected *sign.Signature for party %s, got %T", p.ID(), signSMs[p.ID()].Result())
+		}
+	}
+}
+
+// TestRecoveryBadPiece checks that a helper dealing a piece that does not
+// match its commitment is blamed.
+func TestRecoveryBadPiece(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties)
+
+	params := func(p tss.PartyID) *tss.Parameters {
+		return &tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-recovery-bad"),
+		}
+	}
+	_, out1, err := NewStateMachine(params(parties[0]), keyData["1"], parties[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm2, _, err := NewStateMachine(params(parties[1]), keyData["2"], parties[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range out1 {
+		if msg.Type() == "RecoveryRound1_Share" {
+			msg.(*RecoveryMessage).Data = []byte(`{"Share":1}`)
+		}
+	}
+	for _, msg := range out1 {
+		if !msg.IsBroadcast() && msg.To()[0].ID() != "2" {
+			continue
+		}
+		if sm2, _, err = sm2.Update(msg); err != nil {
+			break
+		}
+	}
+	var blame *tss.Blame
+	if !errors.As(err, &blame) || blame.PartyID.ID() != "1" {
+		t.Fatalf("Expected party 1 to be blamed, got %v", err)
+	}
+}
+
+// TestRecoveryEchoBroadcast recovers party 3's share with echo broadcasts
+// enabled.
+func TestRecoveryEchoBroadcast(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties)
+	lost := *keyData["3"]
+	lost.Xi = nil
+
+	coord := tss.NewLocalCoordinator()
+	for _, p := range parties {
+		data := keyData[p.ID()]
+		if p.ID() == "3" {
+			data = &lost
+		}
+		params := &tss.Parameters{
+			PartyID:       p,
+			Parties:       parties,
+			Threshold:     1,
+			Curve:         "secp256k1",
+			SessionID:     []byte("test-session-recovery-echo"),
+			EchoBroadcast: true,
+		}
+		sm, msgs, err := NewStateMachine(params, data, parties[2])
+		if err != nil {
+			t.Fatalf("Failed to create recovery state machine for %s: %v", p.ID(), err)
+		}
+		coord.Add(p.ID(), sm, msgs)
+	}
+	results, err := coord.Run()
+	if err != nil {
+		t.Fatalf("Recovery failed: %v", err)
+	}
+	recovered, ok := results["3"].(*keygen.LocalPartySaveData)
+	if !ok || recovered.Xi.Cmp(keyData["3"].Xi) != 0 {
+		t.Fatalf("Expected the original share to be recovered, got %v", results["3"])
+	}
+}
+
+func TestRecoveryInvalidParameters(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	keyData := &keygen.LocalPartySaveData{
+		LocalPartyID: p1,
+		ShareIDs:     keygen.ShareIndices([]tss.PartyID{p1, p2, p3}),
+	}
+	tests := []struct {
+		name      string
+		parties   []tss.PartyID
+		threshold int
+		lost      tss.PartyID
+	}{
+		{"too few helpers", []tss.PartyID{p1, p2, p3}, 2, p3},
+		{"lost party not in session", []tss.PartyID{p1, p2}, 1, p3},
+		{"missing lost party", []tss.PartyID{p1, p2, p3}, 1, nil},
+		{"helper without share", []tss.PartyID{p1, p2, p3}, 1, p3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:   p1,
+				Parties:   tt.parties,
+				Threshold: tt.threshold,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-recovery-params"),
+			}
+			if _, _, err := NewStateMachine(params, keyData, tt.lost); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+}
+
+func runKeyGen(t *testing.T, parties []tss.PartyID) map[string]*keygen.LocalPartySaveData {
+	t.Helper()
+
+	sms := make(map[string]tss.StateMachine)
+	out := make(map[string][]tss.Message)
+	for _, p := range parties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-keygen"),
+		}
+		sm, msgs, err := keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine for %s: %v", p.ID(), err)
+		}
+		sms[p.ID()] = sm
+		out[p.ID()] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		sms, out = routeByID(t, sms, out)
+	}
+
+	keyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, p := range parties {
+		data, ok := sms[p.ID()].Result().(*keygen.LocalPartySaveData)
+		if !ok {
+			t.Fatalf("KeyGen failed for party %s", p.ID())
+		}
+		keyData[p.ID()] = data
+	}
+	return keyData
+}
+
+// routeByID delivers all pending messages to the addressed state machines,
+// iterating parties in sorted order, and returns the newly produced messages.
+func routeByID(t *testing.T, sms map[string]tss.StateMachine, pending map[string][]tss.Message) (map[string]tss.StateMachine, map[string][]tss.Message) {
+	t.Helper()
+
+	allMsgs := []tss.Message{}
+	for _, msgs := range pending {
+		allMsgs = append(allMsgs, msgs...)
+	}
+
+	ids := make([]string, 0, len(sms))
+	for id := range sms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	newOut := make(map[string][]tss.Message)
+	for _, id := range ids {
+		for _, msg := range allMsgs {
+			if msg.From().ID() == id {
+				continue
+			}
+			if !msg.IsBroadcast() {
+				found := false
+				for _, dest := range msg.To() {
+					if dest.ID() == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					continue
+				}
+			}
+
+			next, out, err := sms[id].Update(msg)
+			if err != nil {
+				t.Fatalf("Party %s failed at round %d processing msg from %s: %v", id, msg.RoundNumber(), msg.From().ID(), err)
+			}
+			if next == nil {
+				t.Fatalf("Party %s Update returned nil next state", id)
+			}
+			sms[id] = next
+			newOut[id] = append(newOut[id], out...)
+		}
+	}
+	return sms, newOut
+}
@@ -0,0 +1,122 @@
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
+	// The lost party has nothing to contribute; it waits for the
+	// helpers' commitments
+	if s.isLostParty {
+		return s, nil, nil
+	}
+
+	// 1. Our contribution to the lost share: c_i = lambda_i * x_i
+	q := s.curve.Params().N
+	contribution := new(big.Int).Mul(s.lambdas[s.params.PartyID.ID()], s.keyData.Xi)
+	contribution.Mod(contribution, q)
+
+	// 2. Split it into one random piece per helper, sum_j d_{i->j} = c_i, so
+	// the lost party never sees c_i itself
+	pieces := make(map[string]*big.Int, len(s.helpers))
+	rest := contribution
+	for i, h := range s.helpers {
+		if i == len(s.helpers)-1 {
+			pieces[h.ID()] = rest
+			break
+		}
+		d, err := s.curve.NewScalar()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate piece: %w", err)
+		}
+		pieces[h.ID()] = d
+		rest = new(big.Int).Sub(rest, d)
+		rest.Mod(rest, q)
+	}
+	s.tempData["pieces"] = pieces
+
+	// 3. Broadcast D_{i->j} = d_{i->j} * G for every piece
+	commitPayload := Round1CommitPayload{Commitments: make(map[string][]byte, len(pieces))}
+	for id, d := range pieces {
+		Dx, Dy := s.curve.ScalarBaseMult(d)
+		commitPayload.Commitments[id] = s.curve.MarshalCompressed(Dx, Dy)
+	}
+	commitBytes, err := json.Marshal(commitPayload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal commitments: %w", err)
+	}
+
+	outMsgs := []tss.Message{&RecoveryMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  nil,
+		IsBcast:    true,
+		Data:       commitBytes,
+		TypeString: "RecoveryRound1_Commit",
+		RoundNum:   1,
+		Session:    s.params.SessionID,
+	}}
+
+	// 4. Send each other helper its piece
+	for _, h := range s.helpers {
+		if h.ID() == s.params.PartyID.ID() {
+			continue
+		}
+		shareBytes, err := json.Marshal(Round1SharePayload{Share: pieces[h.ID()]})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal piece: %w", err)
+		}
+		outMsgs = append(outMsgs, &RecoveryMessage{
+			FromParty:  s.params.PartyID,
+			ToParties:  []tss.PartyID{h},
+			IsBcast:    false,
+			Data:       shareBytes,
+			TypeString: "RecoveryRound1_Share",
+			RoundNum:   1,
+			Session:    s.params.SessionID,
+		})
+	}
+
+	return s, outMsgs, nil
+}
+
+// verifyCommitments parses the commitments broadcast by a helper and checks
+// that they add up to its contribution, sum_j D_{h->j} = lambda_h * X_h, when
+// its public share X_h is known. It returns the commitments keyed by the
+// receiving helper's ID.
+func (s *state) verifyCommitments(msg tss.Message) (map[string][2]*big.Int, error) {
+	var payload Round1CommitPayload
+	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+		return nil, tss.NewBlame(msg.From(), "malformed commitments", tss.ErrInvalidMsg)
+	}
+	if len(payload.Commitments) != len(s.helpers) {
+		return nil, tss.NewBlame(msg.From(), "wrong number of commitments", tss.ErrInvalidMsg)
+	}
+
+	points := make(map[string][2]*big.Int, len(s.helpers))
+	var sumX, sumY *big.Int
+	for _, h := range s.helpers {
+		Dx, Dy, err := s.curve.UnmarshalCompressed(payload.Commitments[h.ID()])
+		if err != nil {
+			return nil, tss.NewBlame(msg.From(), "invalid commitment for "+h.ID(), tss.ErrInvalidMsg)
+		}
+		points[h.ID()] = [2]*big.Int{Dx, Dy}
+		if sumX == nil {
+			sumX, sumY = Dx, Dy
+		} else {
+			sumX, sumY = s.curve.Add(sumX, sumY, Dx, Dy)
+		}
+	}
+
+	id := msg.From().ID()
+	if Xx, Xy := s.keyData.PeerXiX[id], s.keyData.PeerXiY[id]; Xx != nil && Xy != nil {
+		Cx, Cy := s.curve.ScalarMult(Xx, Xy, s.lambdas[id])
+		if Cx.Cmp(sumX) != 0 || Cy.Cmp(sumY) != 0 {
+			return nil, tss.NewBlame(msg.From(), "commitments do not match public share", tss.ErrInvalidMsg)
+		}
+	}
+	return points, nil
+}
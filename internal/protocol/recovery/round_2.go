@@ -0,0 +1,152 @@
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// round2 is run by the helpers: check the pieces dealt to us against their
+// commitments and send their sum to the lost party.
+func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
+	pieces, ok := s.tempData["pieces"].(map[string]*big.Int)
+	if !ok {
+		return nil, nil, fmt.Errorf("missing pieces")
+	}
+
+	myID := s.params.PartyID.ID()
+	q := s.curve.Params().N
+	sum := new(big.Int).Set(pieces[myID])
+	s.tempData["sum"] = sum
+
+	for _, h := range s.helpers {
+		if h.ID() == myID {
+			continue
+		}
+		var commitMsg, shareMsg tss.Message
+		for _, m := range s.receivedMsgs[h.ID()] {
+			switch m.Type() {
+			case "RecoveryRound1_Commit":
+				commitMsg = m
+			case "RecoveryRound1_Share":
+				shareMsg = m
+			}
+		}
+		if commitMsg == nil || shareMsg == nil {
+			return nil, nil, tss.NewBlame(h, "missing commitment or piece", tss.ErrInvalidMsg)
+		}
+
+		commitments, err := s.verifyCommitments(commitMsg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var payload Round1SharePayload
+		if err := json.Unmarshal(shareMsg.Payload(), &payload); err != nil || payload.Share == nil {
+			return nil, nil, tss.NewBlame(h, "malformed piece", tss.ErrInvalidMsg)
+		}
+		d := payload.Share
+		if d.Sign() < 0 || d.Cmp(q) >= 0 {
+			return nil, nil, tss.NewBlame(h, "piece out of range", tss.ErrInvalidMsg)
+		}
+		Dx, Dy := s.curve.ScalarBaseMult(d)
+		if Dx.Cmp(commitments[myID][0]) != 0 || Dy.Cmp(commitments[myID][1]) != 0 {
+			return nil, nil, tss.NewBlame(h, "piece does not match commitment", tss.ErrInvalidMsg)
+		}
+
+		sum.Add(sum, d)
+		sum.Mod(sum, q)
+	}
+
+	sumBytes, err := json.Marshal(Round2SumPayload{Sum: sum})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal sum: %w", err)
+	}
+	msg := &RecoveryMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  []tss.PartyID{s.lostParty},
+		IsBcast:    false,
+		Data:       sumBytes,
+		TypeString: "RecoveryRound2_Sum",
+		RoundNum:   2,
+		Session:    s.params.SessionID,
+	}
+
+	// The sum is on its way; nothing secret is needed any more
+	s.zeroizeSecrets()
+
+	return &finishedState{result: &HelperResult{LostPartyID: s.lostParty.ID()}}, []tss.Message{msg}, nil
+}
+
+// round2Lost is run by the lost party once every helper has committed to
+// its pieces. It records, for each helper j, the point sum_h D_{h->j} that
+// j's sum must match.
+func (s *state) round2Lost() (tss.StateMachine, []tss.Message, error) {
+	expectedX := make(map[string]*big.Int, len(s.helpers))
+	expectedY := make(map[string]*big.Int, len(s.helpers))
+
+	for _, h := range s.helpers {
+		msgs := s.receivedMsgs[h.ID()]
+		if len(msgs) == 0 || msgs[0].Type() != "RecoveryRound1_Commit" {
+			return nil, nil, tss.NewBlame(h, "missing commitments", tss.ErrInvalidMsg)
+		}
+		commitments, err := s.verifyCommitments(msgs[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, D := range commitments {
+			if expectedX[id] == nil {
+				expectedX[id], expectedY[id] = D[0], D[1]
+			} else {
+				expectedX[id], expectedY[id] = s.curve.Add(expectedX[id], expectedY[id], D[0], D[1])
+			}
+		}
+	}
+	s.tempData["expected_x"] = expectedX
+	s.tempData["expected_y"] = expectedY
+
+	s.receivedMsgs = make(map[string][]tss.Message)
+	s.round = 2
+	return s, nil, nil
+}
+
+// finalize is run by the lost party: x_i = sum_j s_j, where each helper's sum
+// s_j is checked against the commitments and the result against the stored
+// public share X_i.
+func (s *state) finalize() (tss.StateMachine, []tss.Message, error) {
+	expectedX, _ := s.tempData["expected_x"].(map[string]*big.Int)
+	expectedY, _ := s.tempData["expected_y"].(map[string]*big.Int)
+
+	q := s.curve.Params().N
+	xi := new(big.Int)
+	for _, h := range s.helpers {
+		msgs := s.receivedMsgs[h.ID()]
+		if len(msgs) == 0 {
+			return nil, nil, tss.NewBlame(h, "missing sum", tss.ErrInvalidMsg)
+		}
+		var payload Round2SumPayload
+		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil || payload.Sum == nil {
+			return nil, nil, tss.NewBlame(h, "malformed sum", tss.ErrInvalidMsg)
+		}
+		if payload.Sum.Sign() < 0 || payload.Sum.Cmp(q) >= 0 {
+			return nil, nil, tss.NewBlame(h, "sum out of range", tss.ErrInvalidMsg)
+		}
+		Sx, Sy := s.curve.ScalarBaseMult(payload.Sum)
+		if Sx.Cmp(expectedX[h.ID()]) != 0 || Sy.Cmp(expectedY[h.ID()]) != 0 {
+			return nil, nil, tss.NewBlame(h, "sum does not match commitments", tss.ErrInvalidMsg)
+		}
+		xi.Add(xi, payload.Sum)
+		xi.Mod(xi, q)
+	}
+
+	Xx, Xy := s.curve.ScalarBaseMult(xi)
+	if Xx.Cmp(s.keyData.XiX) != 0 || Xy.Cmp(s.keyData.XiY) != 0 {
+		return nil, nil, fmt.Errorf("%w: recovered share does not match the stored public share", tss.ErrInvalidMsg)
+	}
+
+	recovered := *s.keyData
+	recovered.Xi = xi
+	return &finishedState{result: &recovered}, nil, nil
+}
@@ -0,0 +1,268 @@
+package recovery
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+type state struct {
+	params  *tss.Parameters
+	curve   curves.Curve // Resolved from params.Curve
+	keyData *keygen.LocalPartySaveData
+
+	lostParty tss.PartyID
+	helpers   []tss.PartyID // params.Parties without the lost party, in canonical order
+
+	// lambdas maps each helper's ID to its Lagrange coefficient at the lost
+	// party's index, over the helpers' indices
+	lambdas map[string]*big.Int
+
+	round        int
+	tempData     map[string]interface{}
+	receivedMsgs map[string][]tss.Message
+	pending      []tss.Message // Messages for a future round, replayed once we get there
+
+	isLostParty bool
+}
+
+// NewStateMachine initializes a share recovery state machine. The helpers,
+// params.Parties without lostPartyID (at least Threshold+1 of them), jointly
+// evaluate their shares at the lost party's index and hand it the result
+// x_lost = sum_h lambda_h * x_h in additively blinded pieces, so no single
+// helper's share is revealed.
+//
+// Helpers pass their key data in keyData. The lost party runs the same state
+// machine with its own key data, whose Xi is ignored: the Paillier keys and
+// public shares it still holds are kept, and the recovered Xi is only
+// accepted if it matches the stored public share X_i. Its Result is the key
+// data with Xi restored; a helper's Result is a *HelperResult.
+func NewStateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData, lostPartyID tss.PartyID) (tss.StateMachine, []tss.Message, error) {
+	if params == nil || lostPartyID == nil {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	params, err := params.Canonical()
+	if err != nil {
+		return nil, nil, err
+	}
+	curve, err := params.ResolveCurve()
+	if err != nil {
+		return nil, nil, err
+	}
+	if keyData == nil {
+		return nil, nil, fmt.Errorf("%w: missing key data", tss.ErrInvalidParameters)
+	}
+
+	var helpers []tss.PartyID
+	lostInParties := false
+	for _, p := range params.Parties {
+		if p.ID() == lostPartyID.ID() {
+			lostInParties = true
+			continue
+		}
+		helpers = append(helpers, p)
+	}
+	if !lostInParties {
+		return nil, nil, fmt.Errorf("%w: lost party %s is not in Parties", tss.ErrInvalidParameters, lostPartyID.ID())
+	}
+	if len(helpers) < params.Threshold+1 {
+		return nil, nil, fmt.Errorf("%w: threshold %d needs %d helpers, have %d", tss.ErrInvalidParameters, params.Threshold, params.Threshold+1, len(helpers))
+	}
+
+	// Shares are evaluated at the indices assigned by KeyGen, not at the
+	// positions in this session's Parties
+	lostIdx, ok := keyData.ShareIDs[lostPartyID.ID()]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: no share index for lost party %s", tss.ErrInvalidParameters, lostPartyID.ID())
+	}
+	indices := make([]*big.Int, 0, len(helpers))
+	for _, h := range helpers {
+		idx, ok := keyData.ShareIDs[h.ID()]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: no share index for helper %s", tss.ErrInvalidParameters, h.ID())
+		}
+		indices = append(indices, idx)
+	}
+	lambdas := make(map[string]*big.Int, len(helpers))
+	for i, h := range helpers {
+		lambda := polynomial.LagrangeCoefficientAt(curve, lostIdx, indices[i], indices)
+		if lambda == nil {
+			return nil, nil, fmt.Errorf("%w: share indices are not distinct", tss.ErrInvalidParameters)
+		}
+		lambdas[h.ID()] = lambda
+	}
+
+	isLost := params.PartyID.ID() == lostPartyID.ID()
+	if isLost && (keyData.XiX == nil || keyData.XiY == nil) {
+		return nil, nil, fmt.Errorf("%w: lost party has no public share to verify against", tss.ErrInvalidParameters)
+	}
+	if !isLost && keyData.Xi == nil {
+		return nil, nil, fmt.Errorf("%w: helper %s has no secret share", tss.ErrInvalidParameters, params.PartyID.ID())
+	}
+
+	s := &state{
+		params:       params,
+		curve:        curve,
+		keyData:      keyData,
+		lostParty:    lostPartyID,
+		helpers:      helpers,
+		lambdas:      lambdas,
+		round:        1,
+		tempData:     make(map[string]interface{}),
+		receivedMsgs: make(map[string][]tss.Message),
+		isLostParty:  isLost,
+	}
+
+	return tss.Wrap(params)(s.round1())
+}
+
+func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	// Messages replayed from another session are never processed
+	if err := s.params.CheckSession(msg); err != nil {
+		return s, nil, err
+	}
+
+	switch {
+	case msg.RoundNumber() < uint32(s.round):
+		// Late message for a round we already completed
+		return s, nil, nil
+	case msg.RoundNumber() > uint32(s.round):
+		// Early message: keep it until we reach its round
+		s.pending = append(s.pending, msg)
+		return s, nil, nil
+	}
+
+	if s.params.IsOwnMessage(msg) {
+		return s, nil, nil
+	}
+	senderID := msg.From().ID()
+	// Only helpers send anything
+	if senderID == s.lostParty.ID() {
+		return nil, nil, tss.NewBlame(msg.From(), "lost party sent a recovery message", tss.ErrInvalidMsg)
+	}
+
+	// Exact retransmissions are ignored, conflicting ones are equivocation
+	duplicate, err := tss.CheckDuplicate(s.receivedMsgs[senderID], msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if duplicate {
+		return s, nil, nil
+	}
+
+	s.receivedMsgs[senderID] = append(s.receivedMsgs[senderID], msg)
+
+	if len(s.WaitingFor()) > 0 {
+		return s, nil, nil
+	}
+	return s.advance()
+}
+
+// WaitingFor returns the helpers whose messages for the current round are
+// still outstanding. In round 1 helpers expect a commitment and a piece from
+// every other helper, the lost party only the commitments; in round 2 the
+// lost party expects a sum from every helper.
+func (s *state) WaitingFor() []tss.PartyID {
+	return tss.MissingSenders(s.helpers, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// RemainingForRound returns how many messages the helpers still owe for the
+// current round.
+func (s *state) RemainingForRound() int {
+	return tss.RemainingMessages(s.helpers, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// expectedPerPeer returns how many messages each helper sends us in the
+// current round.
+func (s *state) expectedPerPeer() int {
+	if s.round == 1 && !s.isLostParty {
+		return 2 // Commit + Share
+	}
+	return 1
+}
+
+// advance moves to the next round and replays messages that arrived early.
+func (s *state) advance() (tss.StateMachine, []tss.Message, error) {
+	pending := s.pending
+	s.pending = nil
+	next, out, err := s.nextRound()
+	if err != nil {
+		s.zeroizeSecrets()
+		return &abortedState{err: err}, nil, err
+	}
+	return tss.Replay(next, out, pending)
+}
+
+func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
+	switch {
+	case s.round == 1 && s.isLostParty:
+		return s.round2Lost()
+	case s.round == 1:
+		return s.round2()
+	case s.round == 2 && s.isLostParty:
+		return s.finalize()
+	default:
+		return nil, nil, fmt.Errorf("unknown round %d", s.round)
+	}
+}
+
+func (s *state) Result() interface{} {
+	return nil
+}
+
+func (s *state) Details() string {
+	return fmt.Sprintf("Recovery Round %d", s.round)
+}
+
+// finishedState is the terminal state after a successful run.
+type finishedState struct {
+	result interface{}
+}
+
+func (s *finishedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return s, nil, nil
+}
+
+// Result returns the recovered key data for the lost party and a
+// *HelperResult for helpers.
+func (s *finishedState) Result() interface{} {
+	return s.result
+}
+
+func (s *finishedState) Details() string {
+	return "Recovery Finished"
+}
+
+// abortedState is the terminal state after a fatal error in a round. Late
+// messages are rejected with tss.ErrProtocolDone instead of reaching a state
+// that can no longer make progress.
+type abortedState struct {
+	err error
+}
+
+func (s *abortedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (s *abortedState) Result() interface{} {
+	return nil
+}
+
+func (s *abortedState) Details() string {
+	return fmt.Sprintf("Recovery Aborted: %v", s.err)
+}
+
+// secretTempData lists the tempData entries holding secrets: the pieces a
+// helper dealt (its own included) and the sum it sends to the lost party.
+var secretTempData = []string{"pieces", "sum"}
+
+// zeroizeSecrets overwrites and drops the secret tempData entries once the
+// rounds that need them are over.
+func (s *state) zeroizeSecrets() {
+	zeroize.Entries(s.tempData, secretTempData...)
+}
@@ -0,0 +1,79 @@
+package recovery
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// Message types emitted by recovery, for tss.DecodeMessage
+func init() {
+	tss.RegisterMessageType("recovery",
+		"RecoveryRound1_Commit",
+		"RecoveryRound1_Share",
+		"RecoveryRound2_Sum",
+	)
+}
+
+// Round1CommitPayload is broadcast by each helper h: the commitments
+// D_{h->j} = d_{h->j} * G to the pieces of lambda_h * x_h it dealt, keyed by
+// the receiving helper's PartyID.ID().
+type Round1CommitPayload struct {
+	Commitments map[string][]byte // Compressed points
+}
+
+// Round1SharePayload carries the piece d_{h->j} to helper j.
+type Round1SharePayload struct {
+	Share *big.Int
+}
+
+// Round2SumPayload carries helper j's sum of the pieces it received,
+// sum_h d_{h->j}, to the recovering party.
+type Round2SumPayload struct {
+	Sum *big.Int
+}
+
+// HelperResult is the Result of a helper's state machine once it has sent
+// its sum to the recovering party.
+type HelperResult struct {
+	LostPartyID string
+}
+
+// RecoveryMessage is the concrete message type for share recovery.
+type RecoveryMessage struct {
+	FromParty  tss.PartyID
+	ToParties  []tss.PartyID
+	IsBcast    bool
+	Data       []byte
+	TypeString string
+	RoundNum   uint32
+	Session    []byte // Session the message belongs to
+}
+
+func (m *RecoveryMessage) Type() string {
+	return m.TypeString
+}
+
+func (m *RecoveryMessage) From() tss.PartyID {
+	return m.FromParty
+}
+
+func (m *RecoveryMessage) To() []tss.PartyID {
+	return m.ToParties
+}
+
+func (m *RecoveryMessage) IsBroadcast() bool {
+	return m.IsBcast
+}
+
+func (m *RecoveryMessage) Payload() []byte {
+	return m.Data
+}
+
+func (m *RecoveryMessage) RoundNumber() uint32 {
+	return m.RoundNum
+}
+
+func (m *RecoveryMessage) SessionID() []byte {
+	return m.Session
+}
@@ -1,6 +1,8 @@
 package refresh
 
 import (
+	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
@@ -144,4 +146,63 @@ func TestRefreshE2E(t *testing.T) {
 			t.Fatalf("Paillier Key did not change for party %d", i)
 		}
 	}
+
+	// Every honest refresh result passes the post-refresh check
+	for i := 0; i < 3; i++ {
+		if err := VerifyResult(keyData[i], newKeyData[i]); err != nil {
+			t.Fatalf("VerifyResult rejected party %d: %v", i, err)
+		}
+	}
+
+	// Corrupted results are caught before the new share is trusted
+	corrupt := func(f func(d *keygen.LocalPartySaveData)) *keygen.LocalPartySaveData {
+		d := *newKeyData[0]
+		f(&d)
+		return &d
+	}
+	cases := map[string]*keygen.LocalPartySaveData{
+		"secret share": corrupt(func(d *keygen.LocalPartySaveData) { d.Xi = new(big.Int).Add(d.Xi, big.NewInt(1)) }),
+		"public key":   corrupt(func(d *keygen.LocalPartySaveData) { d.PublicKeyX, d.PublicKeyY = d.XiX, d.XiY }),
+		"paillier key": corrupt(func(d *keygen.LocalPartySaveData) {
+			sk := *d.PaillierSk
+			sk.Mu = new(big.Int).Add(sk.Mu, big.NewInt(1))
+			d.PaillierSk = &sk
+		}),
+	}
+	for name, d := range cases {
+		if err := VerifyResult(keyData[0], d); !errors.Is(err, ErrInvalidRefresh) {
+			t.Fatalf("Corrupted %s: expected ErrInvalidRefresh, got %v", name, err)
+		}
+	}
+}
+
+func TestRefreshInvalidParameters(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	keyData := &keygen.LocalPartySaveData{LocalPartyID: p1}
+	tests := []struct {
+		name      string
+		partyID   tss.PartyID
+		parties   []tss.PartyID
+		threshold int
+		keyData   *keygen.LocalPartySaveData
+	}{
+		{"threshold too large", p1, []tss.PartyID{p1, p2, p3}, 3, keyData},
+		{"duplicate party IDs", p1, []tss.PartyID{p1, p2, &MockPartyID{id: "1"}}, 1, keyData},
+		{"local party missing", &MockPartyID{id: "4"}, []tss.PartyID{p1, p2, p3}, 1, keyData},
+		{"missing key data", p1, []tss.PartyID{p1, p2, p3}, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:   tt.partyID,
+				Parties:   tt.parties,
+				Threshold: tt.threshold,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-params"),
+			}
+			if _, _, err := NewStateMachine(params, tt.keyData); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
 }
@@ -15,7 +15,7 @@ import (
 
 func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 	// 1. Generate New Paillier Key Pair
-	paillierSk, err := paillier.GenerateKey(rand.Reader, 2048)
+	paillierSk, err := paillier.GenerateKeyWithConcurrency(rand.Reader, paillier.KeyBits, s.params.Concurrency())
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate paillier key: %w", err)
 	}
@@ -74,6 +74,7 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 		Data:        comm.C,
 		TypeString:  "RefreshRound1",
 		RoundNum:    1,
+		Session:    s.params.SessionID,
 	}
 
 	return s, []tss.Message{msg}, nil
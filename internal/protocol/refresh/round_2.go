@@ -59,6 +59,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		Data:        payload,
 		TypeString:  "RefreshRound2_Decommit",
 		RoundNum:    2,
+		Session:    s.params.SessionID,
 	}
 	outMsgs = append(outMsgs, broadcastMsg)
 
@@ -83,6 +84,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 			Data:        share.Bytes(),
 			TypeString:  "RefreshRound2_Share",
 			RoundNum:    2,
+			Session:    s.params.SessionID,
 		}
 		outMsgs = append(outMsgs, p2pMsg)
 	}
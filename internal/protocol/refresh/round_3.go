@@ -10,6 +10,7 @@ import (
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
@@ -28,8 +29,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	N := curve.Params().N
 
 	// Initialize sum of shares with our own share of 0
-	myIdx := new(big.Int)
-	myIdx.SetString(s.params.PartyID.ID(), 10)
+	myIdx := keygen.ShareIndices(s.params.Parties)[s.params.PartyID.ID()]
 	
 	shareSum := poly.Evaluate(myIdx)
 	
@@ -76,11 +76,11 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		}
 		var cData CommitData
 		if err := json.Unmarshal(data, &cData); err != nil {
-			return nil, nil, fmt.Errorf("failed to unmarshal commit data from %s: %w", id, err)
+			return nil, nil, tss.MalformedPayload(decommitMsg, err)
 		}
 		
 		paillierN := new(big.Int).SetBytes(cData.PaillierN)
-		peerPk := &paillier.PublicKey{N: paillierN, N2: new(big.Int).Mul(paillierN, paillierN)}
+		peerPk := paillier.NewPublicKey(paillierN)
 		
 		if s.saveData.PeerPaillierPks == nil {
 			s.saveData.PeerPaillierPks = make(map[string]*paillier.PublicKey)
@@ -94,27 +94,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		
 		// Verify share against VSS commitments
 		// share * G == sum(A_k * i^k)
-		
-		shareG_x, shareG_y := curve.ScalarBaseMult(share)
-		
-		var rhsX, rhsY *big.Int
-		for k := 0; k < len(cData.VSS)/2; k++ {
-			akX := cData.VSS[k*2]
-			akY := cData.VSS[k*2+1]
-			
-			// i^k
-			scalar := new(big.Int).Exp(myIdx, big.NewInt(int64(k)), N)
-			
-			termX, termY := curve.ScalarMult(akX, akY, scalar)
-			
-			if k == 0 {
-				rhsX, rhsY = termX, termY
-			} else {
-				rhsX, rhsY = curve.Add(rhsX, rhsY, termX, termY)
-			}
-		}
-		
-		if shareG_x.Cmp(rhsX) != 0 || shareG_y.Cmp(rhsY) != 0 {
+		if !polynomial.VerifyFeldmanShare(curve, myIdx, share, cData.VSS) {
 			return nil, nil, tss.NewBlame(shareMsg.From(), "vss share verification failed", nil)
 		}
 		
@@ -147,7 +127,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	Xi_jac.Y = fy
 	Xi_jac.Z.SetInt(1)
 	
-	proof, err := schnorr.Prove(xiNew, &Xi_jac)
+	proof, err := schnorr.Prove(xiNew, &Xi_jac, schnorr.Aux(s.params.SessionID, s.params.PartyID.ID()))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate schnorr proof: %w", err)
 	}
@@ -175,6 +155,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		Data:        data,
 		TypeString:  "RefreshRound3",
 		RoundNum:    3,
+		Session:    s.params.SessionID,
 	}
 	
 	s.receivedMsgs = make(map[string][]tss.Message)
@@ -7,19 +7,21 @@ import (
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
+	// The dealing polynomial is no longer needed
+	s.zeroizeSecrets()
+
 	curve := curves.NewSecp256k1()
-	N := curve.Params().N
 	
 	// Map PartyID to index (x coordinate)
-	partyIndices := make(map[string]*big.Int)
-	for i, p := range s.params.Parties {
-		partyIndices[p.ID()] = big.NewInt(int64(i + 1))
-	}
+	partyIndices := keygen.ShareIndices(s.params.Parties)
+	s.saveData.ShareIDs = partyIndices
 
 	// Collect all X_j (including own)
 	allXiX := make(map[string]*big.Int)
@@ -28,6 +30,8 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	// Own
 	allXiX[s.params.PartyID.ID()] = s.saveData.XiX
 	allXiY[s.params.PartyID.ID()] = s.saveData.XiY
+	s.saveData.PeerXiX = make(map[string]*big.Int)
+	s.saveData.PeerXiY = make(map[string]*big.Int)
 	
 	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 { continue }
@@ -35,7 +39,7 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 		
 		var payload Round3Payload
 		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
-			return nil, nil, err
+			return nil, nil, tss.MalformedPayload(msg, err)
 		}
 		
 		// Verify Schnorr Proof
@@ -61,12 +65,14 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 			S: new(big.Int).SetBytes(payload.ProofS),
 		}
 		
-		if !proof.Verify(&Xj_jac) {
+		if !proof.Verify(&Xj_jac, schnorr.Aux(s.params.SessionID, id)) {
 			return nil, nil, tss.NewBlame(msg.From(), "schnorr proof verification failed", nil)
 		}
 		
 		allXiX[id] = Xj_x
 		allXiY[id] = Xj_y
+		s.saveData.PeerXiX[id] = Xj_x
+		s.saveData.PeerXiY[id] = Xj_y
 	}
 	
 	// Compute X = sum(lambda_j * X_j)
@@ -77,34 +83,21 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	// But Refresh usually involves all parties (n-out-of-n for resharing, or same committee).
 	// Here we assume all parties in s.params.Parties participated.
 	
+	allIndices := make([]*big.Int, 0, len(s.params.Parties))
+	for _, p := range s.params.Parties {
+		allIndices = append(allIndices, partyIndices[p.ID()])
+	}
+
 	for _, p := range s.params.Parties {
 		id := p.ID()
 		xj := partyIndices[id]
 		
 		// Calculate lambda_j (Lagrange coefficient at x=0)
-		num := big.NewInt(1)
-		den := big.NewInt(1)
-		
-		for _, op := range s.params.Parties {
-			oid := op.ID()
-			if id == oid { continue }
-			ox := partyIndices[oid]
-			
-			// num *= ox
-			num.Mul(num, ox)
-			num.Mod(num, N)
-			
-			// den *= (ox - xj)
-			diff := new(big.Int).Sub(ox, xj)
-			diff.Mod(diff, N)
-			den.Mul(den, diff)
-			den.Mod(den, N)
+		lambda := polynomial.LagrangeCoefficient(curve, xj, allIndices)
+		if lambda == nil {
+			return nil, nil, fmt.Errorf("failed to compute lagrange coefficient for %s", id)
 		}
 		
-		denInv := new(big.Int).ModInverse(den, N)
-		lambda := new(big.Int).Mul(num, denInv)
-		lambda.Mod(lambda, N)
-		
 		// term = lambda * X_j
 		tx, ty := curve.ScalarMult(allXiX[id], allXiY[id], lambda)
 		
@@ -3,6 +3,7 @@ package refresh
 import (
 	"fmt"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -15,10 +16,18 @@ type state struct {
 	saveData     *keygen.LocalPartySaveData
 	tempData     map[string]interface{}
 	receivedMsgs map[string][]tss.Message
+	pending      []tss.Message // Messages for a future round, replayed once we get there
 }
 
 // NewStateMachine initializes a new Key Refresh state machine.
 func NewStateMachine(params *tss.Parameters, oldKeyData *keygen.LocalPartySaveData) (tss.StateMachine, []tss.Message, error) {
+	params, err := params.Canonical()
+	if err != nil {
+		return nil, nil, err
+	}
+	if oldKeyData == nil {
+		return nil, nil, fmt.Errorf("%w: missing key data", tss.ErrInvalidParameters)
+	}
 	s := &state{
 		params:     params,
 		oldKeyData: oldKeyData,
@@ -30,66 +39,54 @@ func NewStateMachine(params *tss.Parameters, oldKeyData *keygen.LocalPartySaveDa
 			ECDSAPubY:  oldKeyData.ECDSAPubY,
 			PublicKeyX: oldKeyData.PublicKeyX,
 			PublicKeyY: oldKeyData.PublicKeyY,
+			Epoch:      oldKeyData.Epoch + 1,
+			Mode:       oldKeyData.Mode,
+			Threshold:  params.Threshold,
 		},
 		tempData:     make(map[string]interface{}),
 		receivedMsgs: make(map[string][]tss.Message),
 	}
 
-	return s.round1()
+	return tss.Wrap(params)(s.round1())
 }
 
 func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
-	if msg.RoundNumber() != uint32(s.round) {
-		return nil, nil, fmt.Errorf("received message for round %d, expected %d", msg.RoundNumber(), s.round)
+	// Messages replayed from another session are never processed
+	if err := s.params.CheckSession(msg); err != nil {
+		return s, nil, err
 	}
 
-	senderID := msg.From().ID()
-	if senderID == s.params.PartyID.ID() {
-		return nil, nil, nil
+	switch {
+	case msg.RoundNumber() < uint32(s.round):
+		// Late message for a round we already completed
+		return s, nil, nil
+	case msg.RoundNumber() > uint32(s.round):
+		// Early message: keep it until we reach its round
+		s.pending = append(s.pending, msg)
+		return s, nil, nil
+	}
+
+	if s.params.IsOwnMessage(msg) {
+		return s, nil, nil
 	}
+	senderID := msg.From().ID()
 
 	if s.receivedMsgs == nil {
 		s.receivedMsgs = make(map[string][]tss.Message)
 	}
 
-	for _, existing := range s.receivedMsgs[senderID] {
-		if existing.Type() == msg.Type() {
-			return nil, nil, fmt.Errorf("duplicate message type %s from party %s", msg.Type(), senderID)
-		}
+	// Exact retransmissions are ignored, conflicting ones are equivocation
+	duplicate, err := tss.CheckDuplicate(s.receivedMsgs[senderID], msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if duplicate {
+		return s, nil, nil
 	}
 
 	s.receivedMsgs[senderID] = append(s.receivedMsgs[senderID], msg)
 
-	// Check completion
-	// Round 1: 1 Broadcast (Commitment)
-	// Round 2: 1 Broadcast (Decommit) + 1 P2P (Share)
-	// Round 3: 1 Broadcast (Proof)
-	// Round 4: 1 Broadcast (Schnorr)
-	
-	expectedCount := 0
-	switch s.round {
-	case 1:
-		expectedCount = 1
-	case 2:
-		expectedCount = 2
-	case 3:
-		expectedCount = 1 // VSS Verify? No, in KeyGen Round 3 is VSS Verify.
-		// Wait, KeyGen Round 3 receives Decommitments?
-		// Let's check KeyGen structure.
-	}
-	
-	// In KeyGen:
-	// Round 1: Broadcast Commit
-	// Round 2: Broadcast Decommit + P2P Share
-	// Round 3: Broadcast Paillier Proof (Wait, KeyGen Round 3 is VSS Verify?)
-	// Let's check KeyGen Round 3.
-	
-	// Assuming same structure as KeyGen for now.
-	if s.round == 3 {
-		expectedCount = 1
-	} else if s.round == 4 {
-		expectedCount = 1
-	}
+	expectedCount := s.expectedPerPeer()
 
 	if len(s.receivedMsgs) < len(s.params.Parties)-1 {
 		return s, nil, nil
@@ -101,7 +98,48 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 		}
 	}
 
-	return s.nextRound()
+	return s.advance()
+}
+
+// expectedPerPeer returns how many messages each peer sends in the current round.
+// Round 1: 1 Broadcast (Commitment)
+// Round 2: 1 Broadcast (Decommit) + 1 P2P (Share)
+// Round 3: 1 Broadcast (Schnorr proof of the new X_i)
+func (s *state) expectedPerPeer() int {
+	switch s.round {
+	case 1:
+		return 1
+	case 2:
+		return 2
+	case 3:
+		return 1
+	}
+	return 0
+}
+
+// WaitingFor returns the peers whose messages for the current round are
+// still outstanding.
+func (s *state) WaitingFor() []tss.PartyID {
+	return tss.MissingSenders(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// RemainingForRound returns how many messages the peers still owe for the
+// current round.
+func (s *state) RemainingForRound() int {
+	return tss.RemainingMessages(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// advance moves to the next round and replays messages that arrived early.
+func (s *state) advance() (tss.StateMachine, []tss.Message, error) {
+	pending := s.pending
+	s.pending = nil
+	next, out, err := s.nextRound()
+	if err != nil {
+		// The session is over, so nothing needs the secrets any more
+		s.zeroizeSecrets()
+		return &abortedState{err: err}, nil, err
+	}
+	return tss.Replay(next, out, pending)
 }
 
 func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
@@ -141,3 +179,31 @@ func (s *finishedState) Result() interface{} {
 func (s *finishedState) Details() string {
 	return "Refresh Finished"
 }
+
+// abortedState is the terminal state after a fatal error in a round. Late
+// messages are rejected with tss.ErrProtocolDone instead of reaching a state
+// that can no longer make progress.
+type abortedState struct {
+	err error
+}
+
+func (s *abortedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (s *abortedState) Result() interface{} {
+	return nil
+}
+
+func (s *abortedState) Details() string {
+	return fmt.Sprintf("Refresh Aborted: %v", s.err)
+}
+
+// secretTempData lists the tempData entries holding secrets: the re-sharing polynomial.
+var secretTempData = []string{"polynomial"}
+
+// zeroizeSecrets overwrites and drops the secret tempData entries once the
+// rounds that need them are over, or the session aborted.
+func (s *state) zeroizeSecrets() {
+	zeroize.Entries(s.tempData, secretTempData...)
+}
@@ -4,6 +4,16 @@ import (
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
+// Message types emitted by refresh, for tss.DecodeMessage
+func init() {
+	tss.RegisterMessageType("refresh",
+		"RefreshRound1",
+		"RefreshRound2_Decommit",
+		"RefreshRound2_Share",
+		"RefreshRound3",
+	)
+}
+
 // RefreshMessage is the concrete message type for Key Refresh.
 type RefreshMessage struct {
 	FromParty   tss.PartyID
@@ -12,6 +22,7 @@ type RefreshMessage struct {
 	Data        []byte
 	TypeString  string
 	RoundNum    uint32
+	Session     []byte // Session the message belongs to
 }
 
 func (m *RefreshMessage) Type() string {
@@ -37,3 +48,7 @@ func (m *RefreshMessage) Payload() []byte {
 func (m *RefreshMessage) RoundNumber() uint32 {
 	return m.RoundNum
 }
+
+func (m *RefreshMessage) SessionID() []byte {
+	return m.Session
+}
@@ -0,0 +1,52 @@
+package refresh
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+)
+
+// ErrInvalidRefresh is returned by VerifyResult when refreshed key data is
+// inconsistent with the share it replaces.
+var ErrInvalidRefresh = errors.New("invalid refresh result")
+
+// VerifyResult checks a party's refreshed key data against its previous share
+// before the new share is trusted:
+//   - the group public key and the share index are unchanged,
+//   - the new secret share matches its public share (Xi*G == XiX, XiY),
+//   - the new Paillier key pair is well-formed.
+func VerifyResult(oldData, newData *keygen.LocalPartySaveData) error {
+	if oldData == nil || newData == nil {
+		return fmt.Errorf("%w: missing key data", ErrInvalidRefresh)
+	}
+	if newData.PublicKeyX == nil || newData.PublicKeyY == nil ||
+		oldData.PublicKeyX == nil || oldData.PublicKeyY == nil ||
+		newData.PublicKeyX.Cmp(oldData.PublicKeyX) != 0 || newData.PublicKeyY.Cmp(oldData.PublicKeyY) != 0 {
+		return fmt.Errorf("%w: group public key changed", ErrInvalidRefresh)
+	}
+	if oldData.ShareID != nil && (newData.ShareID == nil || newData.ShareID.Cmp(oldData.ShareID) != 0) {
+		return fmt.Errorf("%w: share index changed", ErrInvalidRefresh)
+	}
+
+	curve := curves.NewSecp256k1()
+	if newData.Xi == nil || newData.Xi.Sign() <= 0 || newData.Xi.Cmp(curve.Params().N) >= 0 {
+		return fmt.Errorf("%w: secret share out of range", ErrInvalidRefresh)
+	}
+	x, y := curve.ScalarBaseMult(newData.Xi)
+	if newData.XiX == nil || newData.XiY == nil || x.Cmp(newData.XiX) != 0 || y.Cmp(newData.XiY) != 0 {
+		return fmt.Errorf("%w: secret share does not match public share", ErrInvalidRefresh)
+	}
+
+	if newData.PaillierSk == nil || newData.PaillierPk == nil {
+		return fmt.Errorf("%w: missing Paillier key", ErrInvalidRefresh)
+	}
+	if newData.PaillierPk.N == nil || newData.PaillierPk.N.Cmp(newData.PaillierSk.N) != 0 {
+		return fmt.Errorf("%w: Paillier public key does not match private key", ErrInvalidRefresh)
+	}
+	if err := newData.PaillierSk.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRefresh, err)
+	}
+	return nil
+}
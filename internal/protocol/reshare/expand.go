@@ -0,0 +1,48 @@
+package reshare
+
+import (
+	"fmt"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// Expand starts a Reshare that turns a 2-party key into a threshold key of a
+// larger committee, e.g. 2-of-2 into 2-of-3, keeping the group public key.
+// oldParams describes the 2-party committee and params the new one, which must
+// keep both original parties, add at least one, and need at least two
+// signers (Threshold >= 1). oldKeyData is required for the two original
+// parties and ignored for joining ones.
+//
+// Expand only checks this growth pattern; the protocol run is that of
+// NewStateMachine.
+func Expand(oldParams, params *tss.Parameters, oldKeyData *keygen.LocalPartySaveData) (tss.StateMachine, []tss.Message, error) {
+	if params == nil || oldParams == nil {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	if len(oldParams.Parties) != 2 {
+		return nil, nil, fmt.Errorf("%w: expand needs a 2-party committee, have %d parties", tss.ErrInvalidParameters, len(oldParams.Parties))
+	}
+	if len(params.Parties) <= len(oldParams.Parties) {
+		return nil, nil, fmt.Errorf("%w: expand needs a new committee of more than 2 parties, have %d", tss.ErrInvalidParameters, len(params.Parties))
+	}
+	if params.Threshold < 1 {
+		return nil, nil, fmt.Errorf("%w: expanded key must need at least 2 signers, threshold is %d", tss.ErrInvalidParameters, params.Threshold)
+	}
+	for _, old := range oldParams.Parties {
+		if old == nil {
+			return nil, nil, fmt.Errorf("%w: nil party in old committee", tss.ErrInvalidParameters)
+		}
+		kept := false
+		for _, p := range params.Parties {
+			if p != nil && p.ID() == old.ID() {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			return nil, nil, fmt.Errorf("%w: original party %s is not in the new committee", tss.ErrInvalidParameters, old.ID())
+		}
+	}
+	return NewStateMachine(params, oldParams, oldKeyData)
+}
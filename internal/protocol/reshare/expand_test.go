@@ -0,0 +1,143 @@
+package reshare
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestExpand(t *testing.T) {
+	// Scenario: 2-of-2 (t=1) on {1,2} becomes 2-of-3 (t'=1) on {1,2,3}.
+	allParties := make(map[string]tss.PartyID)
+	for _, id := range []string{"1", "2", "3"} {
+		allParties[id] = &MockPartyID{id: id}
+	}
+	oldParties := []tss.PartyID{allParties["1"], allParties["2"]}
+	newParties := []tss.PartyID{allParties["1"], allParties["2"], allParties["3"]}
+
+	// 1. KeyGen on the 2-party committee
+	keygenSMs := make(map[string]tss.StateMachine)
+	outMsgs := make(map[string][]tss.Message)
+	for _, p := range oldParties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   oldParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-keygen-expand"),
+		}
+		sm, msgs, err := keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine for %s: %v", p.ID(), err)
+		}
+		keygenSMs[p.ID()] = sm
+		outMsgs[p.ID()] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		keygenSMs, outMsgs = routeByID(t, keygenSMs, outMsgs)
+	}
+	oldKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, p := range oldParties {
+		res, ok := keygenSMs[p.ID()].Result().(*keygen.LocalPartySaveData)
+		if !ok {
+			t.Fatalf("KeyGen failed for party %s", p.ID())
+		}
+		oldKeyData[p.ID()] = res
+	}
+
+	// 2. Expand to three parties
+	oldParams := &tss.Parameters{Parties: oldParties, Threshold: 1, Curve: "secp256k1"}
+	expandSMs := make(map[string]tss.StateMachine)
+	expandOutMsgs := make(map[string][]tss.Message)
+	for _, p := range newParties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   newParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-expand"),
+		}
+		sm, msgs, err := Expand(oldParams, params, oldKeyData[p.ID()])
+		if err != nil {
+			t.Fatalf("Failed to create expand SM for %s: %v", p.ID(), err)
+		}
+		expandSMs[p.ID()] = sm
+		expandOutMsgs[p.ID()] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		expandSMs, expandOutMsgs = routeByID(t, expandSMs, expandOutMsgs)
+	}
+	newKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, p := range newParties {
+		data, ok := expandSMs[p.ID()].Result().(*keygen.LocalPartySaveData)
+		if !ok {
+			t.Fatalf("Expand failed for party %s", p.ID())
+		}
+		if data.PublicKeyX.Cmp(oldKeyData["1"].PublicKeyX) != 0 || data.PublicKeyY.Cmp(oldKeyData["1"].PublicKeyY) != 0 {
+			t.Fatalf("Public Key changed for party %s", p.ID())
+		}
+		newKeyData[p.ID()] = data
+	}
+
+	// 3. The new party and one original party sign without the other
+	signers := []tss.PartyID{allParties["1"], allParties["3"]}
+	hash := sha256.Sum256([]byte("expanded key"))
+	signSMs := make(map[string]tss.StateMachine)
+	signOutMsgs := make(map[string][]tss.Message)
+	for _, p := range signers {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   signers,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-sign-expand"),
+		}
+		sm, msgs, err := sign.NewStateMachine(params, newKeyData[p.ID()], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign SM for %s: %v", p.ID(), err)
+		}
+		signSMs[p.ID()] = sm
+		signOutMsgs[p.ID()] = msgs
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOutMsgs = routeByID(t, signSMs, signOutMsgs)
+	}
+	sig, ok := signSMs["3"].Result().(*sign.Signature)
+	if !ok {
+		t.Fatal("Sign failed for party 3")
+	}
+	pub := curves.NewSecp256k1().MarshalCompressed(oldKeyData["1"].PublicKeyX, oldKeyData["1"].PublicKeyY)
+	if valid, err := sign.VerifyWithPubKeyBytes(pub, hash[:], sig); err != nil || !valid {
+		t.Fatalf("Signature does not verify under the original key: %v, %v", valid, err)
+	}
+}
+
+func TestExpandInvalidParameters(t *testing.T) {
+	p1, p2, p3, p4 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}, &MockPartyID{id: "4"}
+	committee := func(threshold int, parties ...tss.PartyID) *tss.Parameters {
+		return &tss.Parameters{PartyID: p1, Parties: parties, Threshold: threshold, Curve: "secp256k1", SessionID: []byte("test-session-expand-params")}
+	}
+	tests := []struct {
+		name      string
+		oldParams *tss.Parameters
+		newParams *tss.Parameters
+	}{
+		{"old committee of 3", committee(1, p1, p2, p3), committee(1, p1, p2, p3, p4)},
+		{"new committee not larger", committee(1, p1, p2), committee(1, p1, p2)},
+		{"single signer", committee(1, p1, p2), committee(0, p1, p2, p3)},
+		{"original party dropped", committee(1, p1, p2), committee(1, p1, p3, p4)},
+		{"new threshold too large", committee(1, p1, p2), committee(3, p1, p2, p3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Expand(tt.oldParams, tt.newParams, &keygen.LocalPartySaveData{}); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+}
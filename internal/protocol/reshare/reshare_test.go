@@ -1,9 +1,17 @@
 package reshare
 
 import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
@@ -348,6 +356,16 @@ func TestReshareE2E(t *testing.T) {
 		if newData.Xi == nil {
 			t.Fatalf("Party %s missing new secret share", id)
 		}
+
+		// 3. New shares start the next epoch, also for the joining party
+		if newData.Epoch != oldKeyData["1"].Epoch+1 {
+			t.Fatalf("Party %s has epoch %d, expected %d", id, newData.Epoch, oldKeyData["1"].Epoch+1)
+		}
+	}
+
+	// Party 3 left the committee and finishes without a share
+	if res := reshareSMs["3"].Result(); res != nil {
+		t.Fatalf("Old-only party 3 returned a result: %T", res)
 	}
 }
 
@@ -366,3 +384,520 @@ func chainMsgRound(msg tss.Message) uint32 {
 	}
 	return msg.RoundNumber()
 }
+
+func TestReshareGrowCommittee(t *testing.T) {
+	// Scenario: Committee Growth (Old is a subset of New)
+	// Old: 1, 2, 3 (t=1, 2-of-3)
+	// New: 1, 2, 3, 4, 5 (t=2, 3-of-5)
+
+	allParties := make(map[string]tss.PartyID)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		allParties[id] = &MockPartyID{id: id}
+	}
+
+	oldCommitteeIDs := []string{"1", "2", "3"}
+	newCommitteeIDs := []string{"1", "2", "3", "4", "5"}
+
+	oldParties := make([]tss.PartyID, len(oldCommitteeIDs))
+	for i, id := range oldCommitteeIDs {
+		oldParties[i] = allParties[id]
+	}
+	newParties := make([]tss.PartyID, len(newCommitteeIDs))
+	for i, id := range newCommitteeIDs {
+		newParties[i] = allParties[id]
+	}
+
+	// 1. KeyGen on Old Committee
+	keygenSMs := make(map[string]tss.StateMachine)
+	outMsgs := make(map[string][]tss.Message)
+	for _, id := range oldCommitteeIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   oldParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-keygen"),
+		}
+		sm, msgs, err := keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine for %s: %v", id, err)
+		}
+		keygenSMs[id] = sm
+		outMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		keygenSMs, outMsgs = routeByID(t, keygenSMs, outMsgs)
+	}
+
+	oldKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range oldCommitteeIDs {
+		res := keygenSMs[id].Result()
+		if res == nil {
+			t.Fatalf("KeyGen failed for party %s", id)
+		}
+		oldKeyData[id] = res.(*keygen.LocalPartySaveData)
+	}
+
+	// 2. Reshare: every old member is also a new member
+	oldParams := &tss.Parameters{
+		Parties:   oldParties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+	}
+
+	reshareSMs := make(map[string]tss.StateMachine)
+	reshareOutMsgs := make(map[string][]tss.Message)
+	for _, id := range newCommitteeIDs {
+		newParams := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   newParties,
+			Threshold: 2,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-reshare-grow"),
+		}
+		sm, msgs, err := NewStateMachine(newParams, oldParams, oldKeyData[id])
+		if err != nil {
+			t.Fatalf("Failed to create reshare SM for %s: %v", id, err)
+		}
+		reshareSMs[id] = sm
+		reshareOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		reshareSMs, reshareOutMsgs = routeByID(t, reshareSMs, reshareOutMsgs)
+	}
+
+	newKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range newCommitteeIDs {
+		res := reshareSMs[id].Result()
+		if res == nil {
+			t.Fatalf("Reshare failed for party %s", id)
+		}
+		data := res.(*keygen.LocalPartySaveData)
+		if data.PublicKeyX.Cmp(oldKeyData["1"].PublicKeyX) != 0 ||
+			data.PublicKeyY.Cmp(oldKeyData["1"].PublicKeyY) != 0 {
+			t.Fatalf("Public Key changed for party %s", id)
+		}
+		if len(data.PeerPaillierPks) != len(newCommitteeIDs)-1 {
+			t.Fatalf("Party %s has %d peer paillier keys, expected %d", id, len(data.PeerPaillierPks), len(newCommitteeIDs)-1)
+		}
+		newKeyData[id] = data
+	}
+
+	// 3. All 5 members sign with their new shares
+	hash := sha256.Sum256([]byte("grown committee"))
+	signSMs := make(map[string]tss.StateMachine)
+	signOutMsgs := make(map[string][]tss.Message)
+	for _, id := range newCommitteeIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   newParties,
+			Threshold: 2,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-sign-grow"),
+		}
+		sm, msgs, err := sign.NewStateMachine(params, newKeyData[id], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign SM for %s: %v", id, err)
+		}
+		signSMs[id] = sm
+		signOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOutMsgs = routeByID(t, signSMs, signOutMsgs)
+	}
+
+	for _, id := range newCommitteeIDs {
+		res := signSMs[id].Result()
+		if res == nil {
+			t.Fatalf("Sign failed for party %s", id)
+		}
+		if _, ok := res.(*sign.Signature); !ok {
+			t.Fatalf("Expected *sign.Signature for party %s, got %T", id, res)
+		}
+	}
+}
+
+// routeByID delivers all pending messages to the addressed state machines,
+// iterating parties in sorted order, and returns the newly produced messages.
+func routeByID(t *testing.T, sms map[string]tss.StateMachine, pending map[string][]tss.Message) (map[string]tss.StateMachine, map[string][]tss.Message) {
+	t.Helper()
+
+	allMsgs := []tss.Message{}
+	for _, msgs := range pending {
+		allMsgs = append(allMsgs, msgs...)
+	}
+
+	ids := make([]string, 0, len(sms))
+	for id := range sms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	newOut := make(map[string][]tss.Message)
+	for _, id := range ids {
+		for _, msg := range allMsgs {
+			if msg.From().ID() == id {
+				continue
+			}
+			if !msg.IsBroadcast() {
+				found := false
+				for _, dest := range msg.To() {
+					if dest.ID() == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					continue
+				}
+			}
+
+			next, out, err := sms[id].Update(msg)
+			if err != nil {
+				t.Fatalf("Party %s failed at round %d processing msg from %s: %v", id, msg.RoundNumber(), msg.From().ID(), err)
+			}
+			if next == nil {
+				t.Fatalf("Party %s Update returned nil next state", id)
+			}
+			sms[id] = next
+			newOut[id] = append(newOut[id], out...)
+		}
+	}
+	return sms, newOut
+}
+
+func TestReshareReusePaillierKey(t *testing.T) {
+	// Scenario: 1 and 2 are retained, 3 leaves, 4 joins.
+	// Retained members keep their Paillier modulus, 4 generates a fresh one.
+	allParties := make(map[string]tss.PartyID)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		allParties[id] = &MockPartyID{id: id}
+	}
+
+	oldCommitteeIDs := []string{"1", "2", "3"}
+	newCommitteeIDs := []string{"1", "2", "4"}
+
+	oldParties := make([]tss.PartyID, len(oldCommitteeIDs))
+	for i, id := range oldCommitteeIDs {
+		oldParties[i] = allParties[id]
+	}
+	newParties := make([]tss.PartyID, len(newCommitteeIDs))
+	for i, id := range newCommitteeIDs {
+		newParties[i] = allParties[id]
+	}
+
+	// 1. KeyGen on Old Committee
+	keygenSMs := make(map[string]tss.StateMachine)
+	outMsgs := make(map[string][]tss.Message)
+	for _, id := range oldCommitteeIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   oldParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-keygen"),
+		}
+		sm, msgs, err := keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine for %s: %v", id, err)
+		}
+		keygenSMs[id] = sm
+		outMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		keygenSMs, outMsgs = routeByID(t, keygenSMs, outMsgs)
+	}
+
+	oldKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range oldCommitteeIDs {
+		res := keygenSMs[id].Result()
+		if res == nil {
+			t.Fatalf("KeyGen failed for party %s", id)
+		}
+		oldKeyData[id] = res.(*keygen.LocalPartySaveData)
+	}
+
+	// 2. Reshare with Paillier key reuse
+	oldParams := &tss.Parameters{
+		Parties:   oldParties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+	}
+
+	reshareSMs := make(map[string]tss.StateMachine)
+	reshareOutMsgs := make(map[string][]tss.Message)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		newParams := &tss.Parameters{
+			PartyID:          allParties[id],
+			Parties:          newParties,
+			Threshold:        1,
+			Curve:            "secp256k1",
+			SessionID:        []byte("test-session-reshare-reuse"),
+			ReusePaillierKey: true,
+		}
+		sm, msgs, err := NewStateMachine(newParams, oldParams, oldKeyData[id])
+		if err != nil {
+			t.Fatalf("Failed to create reshare SM for %s: %v", id, err)
+		}
+		reshareSMs[id] = sm
+		reshareOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		reshareSMs, reshareOutMsgs = routeByID(t, reshareSMs, reshareOutMsgs)
+	}
+
+	newKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range newCommitteeIDs {
+		res := reshareSMs[id].Result()
+		if res == nil {
+			t.Fatalf("Reshare failed for party %s", id)
+		}
+		newKeyData[id] = res.(*keygen.LocalPartySaveData)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if newKeyData[id].PaillierPk.N.Cmp(oldKeyData[id].PaillierPk.N) != 0 {
+			t.Fatalf("Retained party %s did not keep its Paillier key", id)
+		}
+	}
+	for _, id := range oldCommitteeIDs {
+		if newKeyData["4"].PaillierPk.N.Cmp(oldKeyData[id].PaillierPk.N) == 0 {
+			t.Fatalf("New party 4 reused the Paillier key of party %s", id)
+		}
+	}
+	// Peers learn the reused modulus during reshare
+	if newKeyData["4"].PeerPaillierPks["1"].N.Cmp(oldKeyData["1"].PaillierPk.N) != 0 {
+		t.Fatal("Party 4 has a stale Paillier key for party 1")
+	}
+
+	// 3. New committee signs
+	hash := sha256.Sum256([]byte("reused paillier"))
+	signSMs := make(map[string]tss.StateMachine)
+	signOutMsgs := make(map[string][]tss.Message)
+	for _, id := range newCommitteeIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   newParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-sign-reuse"),
+		}
+		sm, msgs, err := sign.NewStateMachine(params, newKeyData[id], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign SM for %s: %v", id, err)
+		}
+		signSMs[id] = sm
+		signOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOutMsgs = routeByID(t, signSMs, signOutMsgs)
+	}
+
+	for _, id := range newCommitteeIDs {
+		if _, ok := signSMs[id].Result().(*sign.Signature); !ok {
+			t.Fatalf("Sign failed for party %s", id)
+		}
+	}
+}
+
+func TestReshareThresholdChange(t *testing.T) {
+	// Scenario: 2-of-3 (t=1) on {1,2,3} becomes 3-of-4 (t'=2) on {2,3,4,5}.
+	allParties := make(map[string]tss.PartyID)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		allParties[id] = &MockPartyID{id: id}
+	}
+
+	oldCommitteeIDs := []string{"1", "2", "3"}
+	newCommitteeIDs := []string{"2", "3", "4", "5"}
+
+	oldParties := make([]tss.PartyID, len(oldCommitteeIDs))
+	for i, id := range oldCommitteeIDs {
+		oldParties[i] = allParties[id]
+	}
+	newParties := make([]tss.PartyID, len(newCommitteeIDs))
+	for i, id := range newCommitteeIDs {
+		newParties[i] = allParties[id]
+	}
+
+	// 1. KeyGen on Old Committee
+	keygenSMs := make(map[string]tss.StateMachine)
+	outMsgs := make(map[string][]tss.Message)
+	for _, id := range oldCommitteeIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   oldParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-keygen"),
+		}
+		sm, msgs, err := keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine for %s: %v", id, err)
+		}
+		keygenSMs[id] = sm
+		outMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		keygenSMs, outMsgs = routeByID(t, keygenSMs, outMsgs)
+	}
+
+	oldKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range oldCommitteeIDs {
+		res := keygenSMs[id].Result()
+		if res == nil {
+			t.Fatalf("KeyGen failed for party %s", id)
+		}
+		oldKeyData[id] = res.(*keygen.LocalPartySaveData)
+	}
+
+	// 2. Reshare from t=1 to t'=2
+	oldParams := &tss.Parameters{
+		Parties:   oldParties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+	}
+
+	reshareSMs := make(map[string]tss.StateMachine)
+	reshareOutMsgs := make(map[string][]tss.Message)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		newParams := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   newParties,
+			Threshold: 2,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-reshare-threshold"),
+		}
+		sm, msgs, err := NewStateMachine(newParams, oldParams, oldKeyData[id])
+		if err != nil {
+			t.Fatalf("Failed to create reshare SM for %s: %v", id, err)
+		}
+		reshareSMs[id] = sm
+		reshareOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 4; r++ {
+		reshareSMs, reshareOutMsgs = routeByID(t, reshareSMs, reshareOutMsgs)
+	}
+
+	if res := reshareSMs["1"].Result(); res != nil {
+		t.Fatalf("Old-only party 1 returned a result: %T", res)
+	}
+
+	newKeyData := make(map[string]*keygen.LocalPartySaveData)
+	for _, id := range newCommitteeIDs {
+		res := reshareSMs[id].Result()
+		if res == nil {
+			t.Fatalf("Reshare failed for party %s", id)
+		}
+		newKeyData[id] = res.(*keygen.LocalPartySaveData)
+	}
+
+	// 3. Any t'+1 = 3 shares reconstruct the key, t' = 2 shares do not
+	curve := curves.NewSecp256k1()
+	reconstructs := func(ids ...string) bool {
+		indices := make([]*big.Int, len(ids))
+		for i, id := range ids {
+			indices[i] = newKeyData[id].ShareIDs[id]
+		}
+		var x, y *big.Int
+		for i, id := range ids {
+			lambda := polynomial.LagrangeCoefficient(curve, indices[i], indices)
+			tx, ty := curve.ScalarMult(newKeyData[id].XiX, newKeyData[id].XiY, lambda)
+			if x == nil {
+				x, y = tx, ty
+			} else {
+				x, y = curve.Add(x, y, tx, ty)
+			}
+		}
+		return x.Cmp(oldKeyData["1"].PublicKeyX) == 0 && y.Cmp(oldKeyData["1"].PublicKeyY) == 0
+	}
+	for _, subset := range [][]string{{"2", "3", "4"}, {"2", "4", "5"}, {"3", "4", "5"}} {
+		if !reconstructs(subset...) {
+			t.Fatalf("Quorum %v does not reconstruct the public key", subset)
+		}
+	}
+	for _, subset := range [][]string{{"2", "3"}, {"4", "5"}} {
+		if reconstructs(subset...) {
+			t.Fatalf("Only %d shares %v reconstruct the public key", len(subset), subset)
+		}
+	}
+
+	// 4. A quorum of t'+1 new members signs
+	signerIDs := []string{"3", "4", "5"}
+	signers := make([]tss.PartyID, len(signerIDs))
+	for i, id := range signerIDs {
+		signers[i] = allParties[id]
+	}
+	hash := sha256.Sum256([]byte("new threshold"))
+	signSMs := make(map[string]tss.StateMachine)
+	signOutMsgs := make(map[string][]tss.Message)
+	for _, id := range signerIDs {
+		params := &tss.Parameters{
+			PartyID:   allParties[id],
+			Parties:   signers,
+			Threshold: 2,
+			Curve:     "secp256k1",
+			SessionID: []byte("test-session-sign-threshold"),
+		}
+		sm, msgs, err := sign.NewStateMachine(params, newKeyData[id], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign SM for %s: %v", id, err)
+		}
+		signSMs[id] = sm
+		signOutMsgs[id] = msgs
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOutMsgs = routeByID(t, signSMs, signOutMsgs)
+	}
+
+	for _, id := range signerIDs {
+		if _, ok := signSMs[id].Result().(*sign.Signature); !ok {
+			t.Fatalf("Sign failed for party %s", id)
+		}
+	}
+}
+
+func TestReshareInvalidParameters(t *testing.T) {
+	p1, p2, p3, p4 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}, &MockPartyID{id: "4"}
+	committee := func(threshold int, parties ...tss.PartyID) *tss.Parameters {
+		return &tss.Parameters{PartyID: p1, Parties: parties, Threshold: threshold, Curve: "secp256k1", SessionID: []byte("test-session-params")}
+	}
+	tests := []struct {
+		name      string
+		newParams *tss.Parameters
+		oldParams *tss.Parameters
+		partyID   tss.PartyID
+	}{
+		{"new threshold too large", committee(3, p1, p2, p3), committee(1, p1, p2, p3), p1},
+		{"old threshold too large", committee(1, p1, p2, p3), committee(2, p1, p2), p1},
+		{"empty new committee", committee(0), committee(1, p1, p2, p3), p1},
+		{"duplicate new party IDs", committee(1, p1, p2, &MockPartyID{id: "2"}), committee(1, p1, p2, p3), p1},
+		{"local party in neither committee", committee(1, p1, p2, p3), committee(1, p1, p2, p3), p4},
+		{"old member without key data", committee(1, p1, p2, p3), committee(1, p1, p2, p3), p1},
+		{"echo broadcasts", &tss.Parameters{Parties: []tss.PartyID{p1, p2, p4}, Threshold: 1, Curve: "secp256k1", SessionID: []byte("test-session-params"), EchoBroadcast: true}, committee(1, p1, p2, p3), p4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.newParams.PartyID = tt.partyID
+			tt.oldParams.PartyID = tt.partyID
+			if _, _, err := NewStateMachine(tt.newParams, tt.oldParams, nil); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReshareImpossibleNewThreshold(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}}
+	oldParams := &tss.Parameters{PartyID: parties[0], Parties: parties, Threshold: 1, Curve: "secp256k1", SessionID: []byte("test-session-threshold")}
+	for _, threshold := range []int{-1, 3, 4} {
+		newParams := &tss.Parameters{PartyID: parties[0], Parties: parties, Threshold: threshold, Curve: "secp256k1", SessionID: []byte("test-session-threshold")}
+		// Old key data is irrelevant: the check must fail before any round runs
+		sm, msgs, err := NewStateMachine(newParams, oldParams, &keygen.LocalPartySaveData{})
+		if !errors.Is(err, tss.ErrInvalidParameters) || !strings.Contains(err.Error(), "new committee") {
+			t.Fatalf("threshold %d: expected a new committee ErrInvalidParameters, got %v", threshold, err)
+		}
+		if sm != nil || msgs != nil {
+			t.Fatalf("threshold %d: round 1 ran for an impossible threshold", threshold)
+		}
+	}
+}
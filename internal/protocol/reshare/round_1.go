@@ -19,9 +19,9 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 
 	// 2. New Committee: Generate Paillier Key
 	if s.isNewCommittee {
-		paillierSk, err := paillier.GenerateKey(rand.Reader, 2048)
+		paillierSk, err := s.paillierKey()
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to generate paillier key: %w", err)
+			return nil, nil, err
 		}
 
 		s.saveData.PaillierSk = paillierSk
@@ -54,6 +54,7 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 		cData.VSS = vssCommitments
 		cData.GlobalPubX = s.oldKeyData.PublicKeyX.Bytes()
 		cData.GlobalPubY = s.oldKeyData.PublicKeyY.Bytes()
+		cData.Epoch = s.oldKeyData.Epoch
 	}
 
 	// 4. Create and Broadcast Commitment
@@ -76,7 +77,22 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 		Data:       comm.C,
 		TypeString: "ReshareRound1",
 		RoundNum:   1,
+		Session:    s.params.SessionID,
 	}
 
 	return s, []tss.Message{msg}, nil
 }
+
+// paillierKey returns the Paillier key this party uses in the new committee.
+// Retained members keep their existing key when ReusePaillierKey is set,
+// everyone else generates a fresh one.
+func (s *state) paillierKey() (*paillier.PrivateKey, error) {
+	if s.params.ReusePaillierKey && s.isOldCommittee && s.oldKeyData.PaillierSk != nil {
+		return s.oldKeyData.PaillierSk, nil
+	}
+	paillierSk, err := paillier.GenerateKeyWithConcurrency(rand.Reader, paillier.KeyBits, s.params.Concurrency())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate paillier key: %w", err)
+	}
+	return paillierSk, nil
+}
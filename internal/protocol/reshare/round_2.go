@@ -47,6 +47,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		cData.VSS = vssCommitments
 		cData.GlobalPubX = s.oldKeyData.PublicKeyX.Bytes()
 		cData.GlobalPubY = s.oldKeyData.PublicKeyY.Bytes()
+		cData.Epoch = s.oldKeyData.Epoch
 	}
 
 	decommitData, err := json.Marshal(cData)
@@ -65,6 +66,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		Data:       payload,
 		TypeString: "ReshareRound2_Decommit",
 		RoundNum:   2,
+		Session:    s.params.SessionID,
 	}
 	outMsgs = append(outMsgs, broadcastMsg)
 
@@ -96,6 +98,7 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 				Data:       share.Bytes(),
 				TypeString: "ReshareRound2_Share",
 				RoundNum:   2,
+				Session:    s.params.SessionID,
 			}
 			outMsgs = append(outMsgs, p2pMsg)
 		}
@@ -9,6 +9,7 @@ import (
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -94,6 +95,10 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		return -1
 	}
 
+	// The new shares start the epoch after the old committee's.
+	// Old members already set it from their own key data.
+	epochKnown := s.oldKeyData != nil
+
 	// Iterate over peers
 	for id, msgs := range s.receivedMsgs {
 		var decommitMsg, shareMsg tss.Message
@@ -126,18 +131,15 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 			// Parse Data
 			var cData CommitData
 			if err := json.Unmarshal(data, &cData); err != nil {
-				return nil, nil, fmt.Errorf("failed to unmarshal commit data from %s: %w", id, err)
+				return nil, nil, tss.MalformedPayload(decommitMsg, err)
 			}
 
-			// DEBUG
-			// fmt.Printf("Party %s received Decommit from %s. PubX Len: %d\n", s.params.PartyID.ID(), id, len(cData.GlobalPubX))
-
 			// Store Paillier PK (from peers in New Committee)
 			// But wait, do we need Paillier keys of New Committee?
 			// Usually for MtA during Signing. So yes, keep them.
 			if cData.PaillierN != nil {
 				paillierN := new(big.Int).SetBytes(cData.PaillierN)
-				peerPk := &paillier.PublicKey{N: paillierN, N2: new(big.Int).Mul(paillierN, paillierN)}
+				peerPk := paillier.NewPublicKey(paillierN)
 
 				if s.saveData.PeerPaillierPks == nil {
 					s.saveData.PeerPaillierPks = make(map[string]*paillier.PublicKey)
@@ -161,6 +163,13 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 					// TODO: Also verify that this PubKey matches what we expect if we had prior knowledge?
 					// For reshare, we assume Old Parties provide the truth.
 				}
+
+				if !epochKnown {
+					s.saveData.Epoch = cData.Epoch + 1
+					epochKnown = true
+				} else if s.saveData.Epoch != cData.Epoch+1 {
+					return nil, nil, fmt.Errorf("inconsistent key epoch from party %s", id)
+				}
 			}
 
 			// If message has VSS, we verify the Share
@@ -168,25 +177,8 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 				// 1. Verify Share against VSS
 				share := new(big.Int).SetBytes(shareMsg.Payload())
 
-				shareG_x, shareG_y := curve.ScalarBaseMult(share)
-
-				// Evaluate VSS Poly at My Index (myIdx)
-				var rhsX, rhsY *big.Int
-				for k := 0; k < len(cData.VSS)/2; k++ {
-					akX := cData.VSS[k*2]
-					akY := cData.VSS[k*2+1]
-
-					scalar := new(big.Int).Exp(myIdx, big.NewInt(int64(k)), N)
-					termX, termY := curve.ScalarMult(akX, akY, scalar)
-
-					if k == 0 {
-						rhsX, rhsY = termX, termY
-					} else {
-						rhsX, rhsY = curve.Add(rhsX, rhsY, termX, termY)
-					}
-				}
-
-				if shareG_x.Cmp(rhsX) != 0 || shareG_y.Cmp(rhsY) != 0 {
+				// share * G == VSS poly evaluated at my index (myIdx)
+				if !polynomial.VerifyFeldmanShare(curve, myIdx, share, cData.VSS) {
 					return nil, nil, tss.NewBlame(shareMsg.From(), "vss share verification failed", nil)
 				}
 
@@ -212,53 +204,40 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		}
 	}
 
-	// Reconstruction: Use ALL valid shares (assuming t_old <= available shares)
+	// Reconstruction: interpolate over exactly t_old+1 old contributors.
+	// Every new member must pick the same subset, otherwise the resulting
+	// shares would not lie on a common polynomial, so take the first t_old+1
+	// old members (in committee order) that delivered a valid share.
 	expectedThreshold := s.oldParams.Threshold
-	if len(validShares) < expectedThreshold+1 {
+	contributors := make([]string, 0, expectedThreshold+1)
+	for _, p := range s.oldParams.Parties {
+		if len(contributors) == expectedThreshold+1 {
+			break
+		}
+		if _, ok := validShares[p.ID()]; ok {
+			contributors = append(contributors, p.ID())
+		}
+	}
+	if len(contributors) < expectedThreshold+1 {
 		return nil, nil, fmt.Errorf("not enough shares received: have %d, need %d", len(validShares), expectedThreshold+1)
 	}
 
-	// Use all valid indices for Lagrange
-	subsetIndices := make([]*big.Int, 0, len(validIndices))
-	for _, idx := range validIndices {
-		subsetIndices = append(subsetIndices, idx)
+	subsetIndices := make([]*big.Int, 0, len(contributors))
+	for _, id := range contributors {
+		subsetIndices = append(subsetIndices, validIndices[id])
 	}
 
-	// Compute Share Sum using proper Lagrange coefficients for the FULL SET
-	for id, share := range validShares {
+	// Compute Share Sum using Lagrange coefficients over the contributor subset
+	for _, id := range contributors {
 		idx := validIndices[id]
 
-		// Calculate Lagrange Coefficient L_j(0) over the FULL SET
-		lagrange := big.NewInt(1)
-		for _, k := range subsetIndices {
-			if k.Cmp(idx) == 0 {
-				continue
-			}
-			// num = 0 - k = -k
-			num := new(big.Int).Sub(big.NewInt(0), k)
-			num.Mod(num, N)
-			if num.Sign() < 0 {
-				num.Add(num, N)
-			}
-
-			// den = idx - k
-			den := new(big.Int).Sub(idx, k)
-			den.Mod(den, N)
-			if den.Sign() < 0 {
-				den.Add(den, N)
-			}
-
-			// invDen = den^-1
-			invDen := new(big.Int).ModInverse(den, N)
-
-			term := new(big.Int).Mul(num, invDen)
-			term.Mod(term, N)
-
-			lagrange.Mul(lagrange, term)
-			lagrange.Mod(lagrange, N)
+		// Calculate Lagrange Coefficient L_j(0) over the subset
+		lagrange := polynomial.LagrangeCoefficient(curve, idx, subsetIndices)
+		if lagrange == nil {
+			return nil, nil, fmt.Errorf("failed to compute lagrange coefficient for %s", id)
 		}
 
-		weightedShare := new(big.Int).Mul(share, lagrange)
+		weightedShare := new(big.Int).Mul(validShares[id], lagrange)
 		weightedShare.Mod(weightedShare, N)
 
 		shareSum.Add(shareSum, weightedShare)
@@ -290,7 +269,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 	Xi_jac.Y = fy
 	Xi_jac.Z.SetInt(1)
 
-	proof, err := schnorr.Prove(shareSum, &Xi_jac)
+	proof, err := schnorr.Prove(shareSum, &Xi_jac, schnorr.Aux(s.params.SessionID, s.params.PartyID.ID()))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate schnorr proof: %w", err)
 	}
@@ -320,6 +299,7 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		Data:       data,
 		TypeString: "ReshareRound3",
 		RoundNum:   3,
+		Session:    s.params.SessionID,
 	}
 
 	s.receivedMsgs = make(map[string][]tss.Message)
@@ -7,24 +7,26 @@ import (
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/schnorr"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
+	// The dealing polynomial and our share of it are no longer needed
+	s.zeroizeSecrets()
+
 	// If I am not in the new committee, I am done.
 	if !s.isNewCommittee {
 		return &finishedState{saveData: nil}, nil, nil
 	}
 
 	curve := curves.NewSecp256k1()
-	N := curve.Params().N
 
 	// Map PartyID to index (x coordinate) within NEW committee
-	partyIndices := make(map[string]*big.Int)
-	for i, p := range s.params.Parties {
-		partyIndices[p.ID()] = big.NewInt(int64(i + 1))
-	}
+	partyIndices := keygen.ShareIndices(s.params.Parties)
+	s.saveData.ShareIDs = partyIndices
 
 	// Collect all X_j (including own)
 	allXiX := make(map[string]*big.Int)
@@ -33,6 +35,8 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	// Own
 	allXiX[s.params.PartyID.ID()] = s.saveData.XiX
 	allXiY[s.params.PartyID.ID()] = s.saveData.XiY
+	s.saveData.PeerXiX = make(map[string]*big.Int)
+	s.saveData.PeerXiY = make(map[string]*big.Int)
 
 	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 {
@@ -42,7 +46,7 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 
 		var payload Round3Payload
 		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
-			return nil, nil, err
+			return nil, nil, tss.MalformedPayload(msg, err)
 		}
 
 		// Verify Schnorr Proof
@@ -70,55 +74,42 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 			S: new(big.Int).SetBytes(payload.ProofS),
 		}
 
-		if !proof.Verify(&Xj_jac) {
+		if !proof.Verify(&Xj_jac, schnorr.Aux(s.params.SessionID, id)) {
 			return nil, nil, tss.NewBlame(msg.From(), "schnorr proof verification failed", nil)
 		}
 
 		allXiX[id] = Xj_x
 		allXiY[id] = Xj_y
+		s.saveData.PeerXiX[id] = Xj_x
+		s.saveData.PeerXiY[id] = Xj_y
 	}
 
 	// Compute X = sum(lambda_j * X_j)
 	var X_sum_x, X_sum_y *big.Int
 
-	// We iterate over all parties in the session (assuming all participated)
-	// If threshold < n, we only need t+1 parties.
-	// But Refresh usually involves all parties (n-out-of-n for resharing, or same committee).
-	// Here we assume all parties in s.params.Parties participated.
+	// Interpolate over the first t'+1 members of the new committee: if the
+	// new shares really form a degree-t' sharing, that quorum alone must
+	// reconstruct the public key.
+	quorum := s.params.Parties
+	if len(quorum) > s.params.Threshold+1 {
+		quorum = quorum[:s.params.Threshold+1]
+	}
+
+	allIndices := make([]*big.Int, 0, len(quorum))
+	for _, p := range quorum {
+		allIndices = append(allIndices, partyIndices[p.ID()])
+	}
 
-	for _, p := range s.params.Parties {
+	for _, p := range quorum {
 		id := p.ID()
 		xj := partyIndices[id]
 
 		// Calculate lambda_j (Lagrange coefficient at x=0)
-		num := big.NewInt(1)
-		den := big.NewInt(1)
-
-		for _, op := range s.params.Parties {
-			oid := op.ID()
-			if id == oid {
-				continue
-			}
-			ox := partyIndices[oid]
-
-			// num *= ox
-			num.Mul(num, ox)
-			num.Mod(num, N)
-
-			// den *= (ox - xj)
-			diff := new(big.Int).Sub(ox, xj)
-			diff.Mod(diff, N)
-			if diff.Sign() < 0 {
-				diff.Add(diff, N)
-			}
-			den.Mul(den, diff)
-			den.Mod(den, N)
+		lambda := polynomial.LagrangeCoefficient(curve, xj, allIndices)
+		if lambda == nil {
+			return nil, nil, fmt.Errorf("failed to compute lagrange coefficient for %s", id)
 		}
-
-		denInv := new(big.Int).ModInverse(den, N)
-		lambda := new(big.Int).Mul(num, denInv)
-		lambda.Mod(lambda, N)
-
+		
 		// term = lambda * X_j
 		tx, ty := curve.ScalarMult(allXiX[id], allXiY[id], lambda)
 
@@ -3,6 +3,7 @@ package reshare
 import (
 	"fmt"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -16,6 +17,7 @@ type state struct {
 	saveData     *keygen.LocalPartySaveData
 	tempData     map[string]interface{}
 	receivedMsgs map[string][]tss.Message
+	pending      []tss.Message // Messages for a future round, replayed once we get there
 
 	isOldCommittee bool
 	isNewCommittee bool
@@ -26,6 +28,27 @@ type state struct {
 // oldParams: The configuration for the OLD committee.
 // oldKeyData: Existing key data (required for old committee members).
 func NewStateMachine(params *tss.Parameters, oldParams *tss.Parameters, oldKeyData *keygen.LocalPartySaveData) (tss.StateMachine, []tss.Message, error) {
+	if params == nil || oldParams == nil || params.PartyID == nil {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	if err := params.ValidateCommittee(); err != nil {
+		return nil, nil, fmt.Errorf("new committee: %w", err)
+	}
+	if err := oldParams.ValidateCommittee(); err != nil {
+		return nil, nil, fmt.Errorf("old committee: %w", err)
+	}
+	// Echoes are checked within one committee, but a reshare spans two
+	if params.EchoBroadcast || oldParams.EchoBroadcast {
+		return nil, nil, fmt.Errorf("%w: echo broadcasts are not supported in reshare", tss.ErrInvalidParameters)
+	}
+
+	// Both committees in canonical order, so old and new share indices agree
+	// on every node. The local party may belong to only one of them.
+	canonicalParams, canonicalOld := *params, *oldParams
+	canonicalParams.Parties = tss.SortParties(params.Parties)
+	canonicalOld.Parties = tss.SortParties(oldParams.Parties)
+	params, oldParams = &canonicalParams, &canonicalOld
+
 	// Identify role
 	myID := params.PartyID.ID()
 
@@ -46,11 +69,11 @@ func NewStateMachine(params *tss.Parameters, oldParams *tss.Parameters, oldKeyDa
 	}
 
 	if !isOld && !isNew {
-		return nil, nil, fmt.Errorf("party %s is not in old or new committee", myID)
+		return nil, nil, fmt.Errorf("%w: party %s is not in old or new committee", tss.ErrInvalidParameters, myID)
 	}
 
 	if isOld && oldKeyData == nil {
-		return nil, nil, fmt.Errorf("party %s is in old committee but missing key data", myID)
+		return nil, nil, fmt.Errorf("%w: party %s is in old committee but missing key data", tss.ErrInvalidParameters, myID)
 	}
 
 	s := &state{
@@ -74,39 +97,53 @@ func NewStateMachine(params *tss.Parameters, oldParams *tss.Parameters, oldKeyDa
 				ECDSAPubY:    oldKeyData.ECDSAPubY,
 				PublicKeyX:   oldKeyData.PublicKeyX,
 				PublicKeyY:   oldKeyData.PublicKeyY,
+				Epoch:        oldKeyData.Epoch + 1,
+				Threshold:    params.Threshold,
 			}
 		} else {
 			// Will be populated later
 			s.saveData = &keygen.LocalPartySaveData{
 				LocalPartyID: params.PartyID,
+				Threshold:    params.Threshold,
 			}
 		}
 	}
 
-	return s.round1()
+	return tss.Wrap(params, oldParams.Parties...)(s.round1())
 }
 
 func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
-	if msg.RoundNumber() < uint32(s.round) {
-		return s, nil, nil
+	// Messages replayed from another session are never processed
+	if err := s.params.CheckSession(msg); err != nil {
+		return s, nil, err
 	}
-	if msg.RoundNumber() > uint32(s.round) {
-		return nil, nil, fmt.Errorf("received message for round %d, expected %d", msg.RoundNumber(), s.round)
+
+	switch {
+	case msg.RoundNumber() < uint32(s.round):
+		// Late message for a round we already completed
+		return s, nil, nil
+	case msg.RoundNumber() > uint32(s.round):
+		// Early message: keep it until we reach its round
+		s.pending = append(s.pending, msg)
+		return s, nil, nil
 	}
 
-	senderID := msg.From().ID()
-	if senderID == s.params.PartyID.ID() {
-		return nil, nil, nil
+	if s.params.IsOwnMessage(msg) {
+		return s, nil, nil
 	}
+	senderID := msg.From().ID()
 
 	if s.receivedMsgs == nil {
 		s.receivedMsgs = make(map[string][]tss.Message)
 	}
 
-	for _, existing := range s.receivedMsgs[senderID] {
-		if existing.Type() == msg.Type() {
-			return nil, nil, fmt.Errorf("duplicate message type %s from party %s", msg.Type(), senderID)
-		}
+	// Exact retransmissions are ignored, conflicting ones are equivocation
+	duplicate, err := tss.CheckDuplicate(s.receivedMsgs[senderID], msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if duplicate {
+		return s, nil, nil
 	}
 
 	s.receivedMsgs[senderID] = append(s.receivedMsgs[senderID], msg)
@@ -167,16 +204,22 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 		sharesReceived := 0
 
 		// Expected Shares = Old Committee Size (minus self if I am Old)
-		// Used only if I am New Committee
+		// Used only if I am New Committee. When the old committee is a subset
+		// of the new one, old members are both senders and receivers, and
+		// their own share is kept locally instead of being sent.
+		oldIDs := make(map[string]bool)
+		for _, p := range s.oldParams.Parties {
+			oldIDs[p.ID()] = true
+		}
 		expectedShares := 0
 		if s.isNewCommittee {
-			expectedShares = len(s.oldParams.Parties)
+			expectedShares = len(oldIDs)
 			if s.isOldCommittee {
 				expectedShares--
 			}
 		}
 
-		for _, msgs := range s.receivedMsgs {
+		for id, msgs := range s.receivedMsgs {
 			hasDecommit := false
 			hasShare := false
 			for _, m := range msgs {
@@ -190,7 +233,8 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 			if hasDecommit {
 				distinctDecommits++
 			}
-			if hasShare {
+			// Only old committee members hold a share of the key to split
+			if hasShare && oldIDs[id] {
 				sharesReceived++
 			}
 		}
@@ -219,7 +263,92 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 		return s, nil, nil
 	}
 
-	return s.nextRound()
+	return s.advance()
+}
+
+// WaitingFor returns the parties whose messages for the current round are
+// still outstanding. Rounds 1 and 2 involve both committees; in round 2 new
+// members additionally wait for a share from every old member. Later rounds
+// only involve the new committee.
+func (s *state) WaitingFor() []tss.PartyID {
+	senders, expected := s.expectedFrom()
+	var missing []tss.PartyID
+	for _, p := range senders {
+		if len(s.receivedMsgs[p.ID()]) < expected[p.ID()] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// RemainingForRound returns how many messages are still outstanding for the
+// current round, across both committees.
+func (s *state) RemainingForRound() int {
+	senders, expected := s.expectedFrom()
+	remaining := 0
+	for _, p := range senders {
+		if n := len(s.receivedMsgs[p.ID()]); n < expected[p.ID()] {
+			remaining += expected[p.ID()] - n
+		}
+	}
+	return remaining
+}
+
+// expectedFrom returns the parties other than us that send messages in the
+// current round, and how many each of them sends us, keyed by PartyID.ID().
+func (s *state) expectedFrom() ([]tss.PartyID, map[string]int) {
+	if s.round > 2 {
+		expected := make(map[string]int, len(s.params.Parties))
+		var senders []tss.PartyID
+		for _, p := range s.params.Parties {
+			if p.ID() != s.params.PartyID.ID() {
+				senders = append(senders, p)
+				expected[p.ID()] = 1
+			}
+		}
+		return senders, expected
+	}
+
+	oldIDs := make(map[string]bool)
+	seen := make(map[string]bool)
+	var union []tss.PartyID
+	for _, p := range s.oldParams.Parties {
+		oldIDs[p.ID()] = true
+		seen[p.ID()] = true
+		union = append(union, p)
+	}
+	for _, p := range s.params.Parties {
+		if !seen[p.ID()] {
+			union = append(union, p)
+		}
+	}
+
+	var senders []tss.PartyID
+	expected := make(map[string]int, len(union))
+	for _, p := range union {
+		if p.ID() == s.params.PartyID.ID() {
+			continue
+		}
+		senders = append(senders, p)
+		expected[p.ID()] = 1
+		if s.round == 2 && s.isNewCommittee && oldIDs[p.ID()] {
+			expected[p.ID()] = 2 // Decommit + Share
+		}
+	}
+	return senders, expected
+}
+
+// advance moves to the next round and replays messages that arrived early.
+func (s *state) advance() (tss.StateMachine, []tss.Message, error) {
+	pending := s.pending
+	s.pending = nil
+	next, out, err := s.nextRound()
+	if err != nil {
+		// The session is over, so nothing needs the secrets any more
+		s.zeroizeSecrets()
+		return &abortedState{err: err}, nil, err
+	}
+	return tss.Replay(next, out, pending)
 }
 
 func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
@@ -252,10 +381,43 @@ func (s *finishedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message
 	return s, nil, nil
 }
 
+// Result returns the new key share, or nil for members that only belonged
+// to the old committee.
 func (s *finishedState) Result() interface{} {
+	if s.saveData == nil {
+		return nil
+	}
 	return s.saveData
 }
 
 func (s *finishedState) Details() string {
 	return "Reshare Finished"
 }
+
+// abortedState is the terminal state after a fatal error in a round. Late
+// messages are rejected with tss.ErrProtocolDone instead of reaching a state
+// that can no longer make progress.
+type abortedState struct {
+	err error
+}
+
+func (s *abortedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (s *abortedState) Result() interface{} {
+	return nil
+}
+
+func (s *abortedState) Details() string {
+	return fmt.Sprintf("Reshare Aborted: %v", s.err)
+}
+
+// secretTempData lists the tempData entries holding secrets: the re-sharing polynomial and the share we dealt to ourselves.
+var secretTempData = []string{"polynomial", "self_share"}
+
+// zeroizeSecrets overwrites and drops the secret tempData entries once the
+// rounds that need them are over, or the session aborted.
+func (s *state) zeroizeSecrets() {
+	zeroize.Entries(s.tempData, secretTempData...)
+}
@@ -12,6 +12,17 @@ type CommitData struct {
 	VSS        []*big.Int `json:"vss,omitempty"`
 	GlobalPubX []byte     `json:"global_pub_x,omitempty"`
 	GlobalPubY []byte     `json:"global_pub_y,omitempty"`
+	Epoch      uint64     `json:"epoch,omitempty"` // Epoch of the old committee's shares
+}
+
+// Message types emitted by reshare, for tss.DecodeMessage
+func init() {
+	tss.RegisterMessageType("reshare",
+		"ReshareRound1",
+		"ReshareRound2_Decommit",
+		"ReshareRound2_Share",
+		"ReshareRound3",
+	)
 }
 
 // ReshareMessage is the concrete message type for Key Resharing.
@@ -22,6 +33,7 @@ type ReshareMessage struct {
 	Data       []byte
 	TypeString string
 	RoundNum   uint32
+	Session    []byte // Session the message belongs to
 }
 
 func (m *ReshareMessage) Type() string {
@@ -47,3 +59,7 @@ func (m *ReshareMessage) Payload() []byte {
 func (m *ReshareMessage) RoundNumber() uint32 {
 	return m.RoundNum
 }
+
+func (m *ReshareMessage) SessionID() []byte {
+	return m.Session
+}
@@ -1,96 +1,312 @@
 package sign
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 // BatchSignResult holds the result of a batch signing operation.
 type BatchSignResult struct {
-	Signatures []*Signature
+	Signatures []*Signature // One per message, in input order
+}
+
+// batchMessageType is the Type() of every message of a batch signing session.
+const batchMessageType = "SignBatch"
+
+// batchOnlineRoundOffset is added to the round numbers of the online phase,
+// so that they follow the presigning rounds (1-3) on the wire: online
+// round 0 is sent as round 4, online round 4 as round 8.
+const batchOnlineRoundOffset = 4
+
+// BatchPayload carries the messages of every instance of a batch for one
+// recipient: Payloads[i] is the payload instance i sent, all of the same
+// Type.
+type BatchPayload struct {
+	Type     string
+	Payloads [][]byte
 }
 
-// NewBatchSignStateMachine creates a state machine that signs multiple messages.
-// This is more efficient than signing messages one-by-one as it can
-// amortize some cryptographic overhead.
+// NewBatchSignStateMachine creates a state machine that signs multiple
+// messages (digests, as in NewStateMachine) in one session. Its result is a
+// *BatchSignResult with one signature per message.
 //
-// The implementation generates a presigning tuple for each message and then
-// uses the online phase to produce all signatures.
+// The session runs one presigning instance per message in lockstep, then one
+// online phase per message. In every round the messages of all instances to
+// the same recipient travel as one message, so the batch needs as many
+// messages as signing a single digest, however many digests it signs.
 func NewBatchSignStateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData, messages [][]byte) (tss.StateMachine, []tss.Message, error) {
-	if len(messages) == 0 {
-		return nil, nil, tss.ErrInvalidParameters
+	b, out, err := NewBatchSign(params, keyData, messages)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// For batch signing, we use a sequential approach:
-	// First message goes through the normal sign flow.
-	// This is a simplified implementation - a full optimization would
-	// batch the cryptographic operations.
-	return NewStateMachine(params, keyData, messages[0])
+	return tss.Wrap(b.params)(b, out, nil)
 }
 
-// batchState manages the state for batch signing.
+// batchState multiplexes one sign state per message over a single session.
 type batchState struct {
 	params   *tss.Parameters
+	curve    curves.Curve
 	keyData  *keygen.LocalPartySaveData
-	messages [][]byte
-	current  int
-	innerSM  tss.StateMachine
-	results  []*Signature
+	messages [][]byte // Digests to sign, with params.DomainTag applied
+
+	online    bool               // False while presigning, true in the online phase
+	instances []tss.StateMachine // One per message
+	sessions  [][]byte           // Session ID of each instance
+
+	// Online messages that arrived before we finished presigning
+	pending []tss.Message
 }
 
-// NewBatchSign creates a batch signing session that signs multiple messages.
-// Returns all signatures after completion.
+// NewBatchSign creates a batch signing session, without the message
+// authentication and diagnostics wrappers that NewBatchSignStateMachine
+// applies from params.
 func NewBatchSign(params *tss.Parameters, keyData *keygen.LocalPartySaveData, messages [][]byte) (*batchState, []tss.Message, error) {
 	if len(messages) == 0 {
 		return nil, nil, tss.ErrInvalidParameters
 	}
+	for _, msg := range messages {
+		if err := checkDigest(msg); err != nil {
+			return nil, nil, err
+		}
+	}
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedKey(params, keyData); err != nil {
+		return nil, nil, err
+	}
+
+	digests := make([][]byte, len(messages))
+	for i, msg := range messages {
+		digests[i] = DomainDigest(params.DomainTag, msg)
+	}
 
-	// Start with first message
-	sm, msgs, err := NewStateMachine(params, keyData, messages[0])
+	b := &batchState{
+		params:    params,
+		curve:     curve,
+		keyData:   keyData,
+		messages:  digests,
+		instances: make([]tss.StateMachine, len(messages)),
+		sessions:  make([][]byte, len(messages)),
+	}
+	outs := make([][]tss.Message, len(messages))
+	for i := range messages {
+		s := b.newInstance("sign-batch-presign", i, nil)
+		b.instances[i], outs[i], err = s.round1()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	out, err := b.pack(outs)
 	if err != nil {
 		return nil, nil, err
 	}
+	return b, out, nil
+}
 
-	return &batchState{
-		params:   params,
-		keyData:  keyData,
-		messages: messages,
-		current:  0,
-		innerSM:  sm,
-		results:  make([]*Signature, 0, len(messages)),
-	}, msgs, nil
+// newInstance returns the sign state of instance i for the given phase. Each
+// instance runs under its own session ID derived from ours.
+func (b *batchState) newInstance(phase string, i int, preSig *PreSignature) *state {
+	params := *b.params
+	params.SessionID = tss.DeriveSessionID(fmt.Sprintf("%s/%d", phase, i), b.params.Parties, b.params.SessionID)
+	b.sessions[i] = params.SessionID
+
+	s := &state{
+		params:       &params,
+		curve:        b.curve,
+		keyData:      b.keyData,
+		round:        1,
+		tempData:     make(map[string]interface{}),
+		receivedMsgs: make(map[string][]tss.Message),
+	}
+	if preSig != nil {
+		s.msgToSign = b.messages[i]
+		s.preSignature = preSig
+		s.round = 0
+	}
+	return s
 }
 
-// Update processes an incoming message and advances the batch signing state.
+// Update unpacks a batch message into one message per instance and feeds
+// them to the instances.
 func (b *batchState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
-	next, outMsgs, err := b.innerSM.Update(msg)
+	// Messages replayed from another session are never processed
+	if err := b.params.CheckSession(msg); err != nil {
+		return b, nil, err
+	}
+	if b.params.IsOwnMessage(msg) {
+		return b, nil, nil
+	}
+	if msg.Type() != batchMessageType {
+		return nil, nil, tss.NewBlame(msg.From(), fmt.Sprintf("unexpected %s message in batch signing", msg.Type()), tss.ErrInvalidMsg)
+	}
+
+	round := msg.RoundNumber()
+	switch {
+	case !b.online && round >= batchOnlineRoundOffset:
+		// A peer finished presigning before us
+		b.pending = append(b.pending, msg)
+		return b, nil, nil
+	case b.online && round < batchOnlineRoundOffset:
+		// Late presigning message
+		return b, nil, nil
+	case b.online:
+		round -= batchOnlineRoundOffset
+	}
+
+	var payload BatchPayload
+	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+		return nil, nil, tss.NewBlame(msg.From(), "malformed batch message", err)
+	}
+	if len(payload.Payloads) != len(b.instances) {
+		return nil, nil, tss.NewBlame(msg.From(), fmt.Sprintf("batch message has %d payloads, expected %d", len(payload.Payloads), len(b.instances)), tss.ErrInvalidMsg)
+	}
+
+	outs := make([][]tss.Message, len(b.instances))
+	for i, sm := range b.instances {
+		inner := &SignMessage{
+			FromParty:  msg.From(),
+			ToParties:  msg.To(),
+			IsBcast:    msg.IsBroadcast(),
+			Data:       payload.Payloads[i],
+			TypeString: payload.Type,
+			RoundNum:   round,
+			Session:    b.sessions[i],
+		}
+		next, out, err := sm.Update(inner)
+		if err != nil {
+			// The instances can no longer stay in lockstep
+			return &abortedState{err: err}, nil, err
+		}
+		if next != nil {
+			b.instances[i] = next
+		}
+		outs[i] = out
+	}
+
+	out, err := b.pack(outs)
 	if err != nil {
-		return nil, nil, err
+		return &abortedState{err: err}, nil, err
+	}
+	if b.instances[0].Result() == nil {
+		return b, out, nil
+	}
+	if b.online {
+		return b.finish()
+	}
+	return b.startOnline(out)
+}
+
+// startOnline begins the online phase once every instance holds its
+// presignature, and replays the online messages that arrived early.
+func (b *batchState) startOnline(out []tss.Message) (tss.StateMachine, []tss.Message, error) {
+	outs := make([][]tss.Message, len(b.instances))
+	for i, sm := range b.instances {
+		preSig, ok := sm.Result().(*PreSignature)
+		if !ok {
+			err := fmt.Errorf("batch presigning instance %d did not finish", i)
+			return &abortedState{err: err}, nil, err
+		}
+		// The presignature never leaves the batch, but is single-use all the same
+		preSig.used.Store(true)
+
+		var err error
+		b.instances[i], outs[i], err = b.newInstance("sign-batch-online", i, preSig).roundOnline0()
+		if err != nil {
+			return &abortedState{err: err}, nil, err
+		}
+	}
+	b.online = true
+
+	online, err := b.pack(outs)
+	if err != nil {
+		return &abortedState{err: err}, nil, err
 	}
+	pending := b.pending
+	b.pending = nil
+	return tss.Replay(b, append(out, online...), pending)
+}
 
-	// Check if inner state machine finished
-	if result := next.Result(); result != nil {
-		if sig, ok := result.(*Signature); ok {
-			b.results = append(b.results, sig)
-			b.current++
+// finish collects the signatures once every online instance is done.
+func (b *batchState) finish() (tss.StateMachine, []tss.Message, error) {
+	results := make([]*Signature, len(b.instances))
+	for i, sm := range b.instances {
+		sig, ok := sm.Result().(*Signature)
+		if !ok {
+			err := fmt.Errorf("batch signing instance %d did not finish", i)
+			return &abortedState{err: err}, nil, err
+		}
+		results[i] = sig
+	}
+	return &batchFinishedState{results: results}, nil, nil
+}
 
-			// Move to next message if any
-			if b.current < len(b.messages) {
-				newSM, msgs, err := NewStateMachine(b.params, b.keyData, b.messages[b.current])
-				if err != nil {
-					return nil, nil, err
+// pack merges the outgoing messages of all instances into one message per
+// recipient. The instances run in lockstep, so each must have produced
+// messages of the same types for the same recipients.
+func (b *batchState) pack(outs [][]tss.Message) ([]tss.Message, error) {
+	batches := make(map[string]*BatchPayload)
+	var order []tss.Message // First instance's message for each key
+	for i, out := range outs {
+		if len(out) != len(outs[0]) {
+			return nil, fmt.Errorf("batch instance %d sent %d messages, instance 0 sent %d", i, len(out), len(outs[0]))
+		}
+		for _, m := range out {
+			key := batchKey(m)
+			payload, ok := batches[key]
+			if !ok {
+				if i != 0 {
+					return nil, fmt.Errorf("batch instance %d sent an unmatched %s message", i, m.Type())
 				}
-				b.innerSM = newSM
-				return b, msgs, nil
+				payload = &BatchPayload{Type: m.Type(), Payloads: make([][]byte, len(outs))}
+				batches[key] = payload
+				order = append(order, m)
 			}
+			if payload.Payloads[i] != nil {
+				return nil, fmt.Errorf("batch instance %d sent two %s messages to the same recipients", i, m.Type())
+			}
+			payload.Payloads[i] = m.Payload()
+		}
+	}
 
-			// All done
-			return &batchFinishedState{results: b.results}, nil, nil
+	packed := make([]tss.Message, 0, len(order))
+	for _, m := range order {
+		data, err := json.Marshal(batches[batchKey(m)])
+		if err != nil {
+			return nil, err
+		}
+		round := m.RoundNumber()
+		if b.online {
+			round += batchOnlineRoundOffset
 		}
+		packed = append(packed, &SignMessage{
+			FromParty:  b.params.PartyID,
+			ToParties:  m.To(),
+			IsBcast:    m.IsBroadcast(),
+			Data:       data,
+			TypeString: batchMessageType,
+			RoundNum:   round,
+			Session:    b.params.SessionID,
+		})
 	}
+	return packed, nil
+}
 
-	b.innerSM = next
-	return b, outMsgs, nil
+// batchKey identifies a message by type, round and recipients.
+func batchKey(m tss.Message) string {
+	to := make([]string, len(m.To()))
+	for i, p := range m.To() {
+		to[i] = p.ID()
+	}
+	sort.Strings(to)
+	return fmt.Sprintf("%s|%d|%t|%s", m.Type(), m.RoundNumber(), m.IsBroadcast(), strings.Join(to, ","))
 }
 
 // Result returns nil while batch signing is in progress.
@@ -100,7 +316,20 @@ func (b *batchState) Result() interface{} {
 
 // Details returns a string describing the current state.
 func (b *batchState) Details() string {
-	return "Batch Signing"
+	return fmt.Sprintf("Batch Signing (%d messages): %s", len(b.instances), b.instances[0].Details())
+}
+
+// WaitingFor returns the signers whose messages for the current round are
+// still outstanding. All instances wait for the same messages.
+func (b *batchState) WaitingFor() []tss.PartyID {
+	return tss.WaitingFor(b.instances[0])
+}
+
+// RemainingForRound returns how many messages the current round still
+// needs. Every message carries all instances, so this is the count of one
+// instance.
+func (b *batchState) RemainingForRound() int {
+	return tss.RemainingForRound(b.instances[0])
 }
 
 // batchFinishedState represents the completed batch signing state.
@@ -2,141 +2,125 @@ package sign
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"testing"
 
-	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 func TestBatchSign(t *testing.T) {
-	// 1. Run KeyGen first
-	pIDs := []string{"1", "2", "3"}
-	parties := make([]tss.PartyID, 3)
-	for i, id := range pIDs {
-		parties[i] = &MockPartyID{id: id}
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	// runToEnd routes until no party has anything left to send and returns
+	// the number of messages sent
+	runToEnd := func(sms []tss.StateMachine, outMsgs [][]tss.Message) int {
+		sent := 0
+		for {
+			n := 0
+			for _, msgs := range outMsgs {
+				n += len(msgs)
+			}
+			if n == 0 {
+				return sent
+			}
+			sent += n
+			sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+		}
 	}
 
-	keygenSMs := make([]tss.StateMachine, 3)
-	outMsgs := make([][]tss.Message, 3)
-	var err error
+	// Reference: a single signature with the full protocol
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	single := runToEnd(sms, outMsgs)
+	for i := range parties {
+		if _, ok := sms[i].Result().(*Signature); !ok {
+			t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+		}
+	}
 
-	for i := 0; i < 3; i++ {
+	messages := make([][]byte, 5)
+	for i := range messages {
+		messages[i] = sha256Hash([]byte(fmt.Sprintf("message %d", i+1)))
+	}
+	for i := range parties {
 		params := &tss.Parameters{
 			PartyID:   parties[i],
 			Parties:   parties,
 			Threshold: 1,
 			Curve:     "secp256k1",
-			SessionID: []byte("test-session"),
+			SessionID: []byte("test-session-batch"),
 		}
-		keygenSMs[i], outMsgs[i], err = keygen.NewStateMachine(params)
+		var err error
+		sms[i], outMsgs[i], err = NewBatchSignStateMachine(params, keyData[i], messages)
 		if err != nil {
-			t.Fatalf("Failed to create keygen state machine: %v", err)
+			t.Fatalf("Failed to create batch sign state machine: %v", err)
 		}
 	}
-
-	route := func(sms []tss.StateMachine, outMsgs [][]tss.Message) ([]tss.StateMachine, [][]tss.Message) {
-		allMsgs := []tss.Message{}
-		for _, msgs := range outMsgs {
-			allMsgs = append(allMsgs, msgs...)
+	batch := runToEnd(sms, outMsgs)
+
+	curve := curves.NewSecp256k1()
+	var first *BatchSignResult
+	for i := range parties {
+		res, ok := sms[i].Result().(*BatchSignResult)
+		if !ok {
+			t.Fatalf("Party %d did not finish batch signing: %s", i, sms[i].Details())
 		}
-		newOutMsgs := make([][]tss.Message, 3)
-
-		for i := 0; i < 3; i++ {
-			if sms[i] == nil {
-				continue
+		if len(res.Signatures) != len(messages) {
+			t.Fatalf("Party %d: %d signatures for %d messages", i, len(res.Signatures), len(messages))
+		}
+		for j, sig := range res.Signatures {
+			if !verifyECDSA(curve, keyData[i].PublicKeyX, keyData[i].PublicKeyY, messages[j], sig.R, sig.S) {
+				t.Fatalf("Party %d: signature %d does not verify", i, j)
 			}
-
-			for _, msg := range allMsgs {
-				if msg.From().ID() == parties[i].ID() {
-					continue
-				}
-				if !msg.IsBroadcast() {
-					found := false
-					for _, dest := range msg.To() {
-						if dest.ID() == parties[i].ID() {
-							found = true
-							break
-						}
-					}
-					if !found {
-						continue
-					}
-				}
-
-				next, newOut, err := sms[i].Update(msg)
-				if err != nil {
-					t.Fatalf("Party %d failed: %v", i, err)
-				}
-				sms[i] = next
-				if newOut != nil {
-					newOutMsgs[i] = append(newOutMsgs[i], newOut...)
-				}
+			if first != nil && first.Signatures[j].S.Cmp(sig.S) != 0 {
+				t.Fatalf("Party %d: signature %d differs from party 1's", i, j)
 			}
 		}
-		return sms, newOutMsgs
-	}
-
-	// Run KeyGen rounds
-	for r := 1; r <= 4; r++ {
-		keygenSMs, outMsgs = route(keygenSMs, outMsgs)
+		first = res
 	}
-
-	// Collect KeyGen results
-	keyData := make([]*keygen.LocalPartySaveData, 3)
-	for i := 0; i < 3; i++ {
-		res := keygenSMs[i].Result()
-		if res == nil {
-			t.Fatalf("KeyGen failed for party %d", i)
+	for j := 1; j < len(messages); j++ {
+		if first.Signatures[j].R.Cmp(first.Signatures[0].R) == 0 {
+			t.Fatal("Two signatures of the batch share a nonce")
 		}
-		keyData[i] = res.(*keygen.LocalPartySaveData)
 	}
 
-	// 2. Test Batch Signing with multiple messages
-	messages := [][]byte{
-		sha256Hash([]byte("message 1")),
-		sha256Hash([]byte("message 2")),
-		sha256Hash([]byte("message 3")),
+	// One batch message per recipient and round, whatever the batch size
+	t.Logf("single signature: %d messages, batch of %d: %d messages", single, len(messages), batch)
+	if batch >= 2*single {
+		t.Fatalf("Batch of %d sent %d messages, single signature %d", len(messages), batch, single)
 	}
+}
 
-	t.Run("BatchSignFirstMessage", func(t *testing.T) {
-		// Create batch sign state machines for all parties
-		batchSMs := make([]tss.StateMachine, 3)
-		batchOutMsgs := make([][]tss.Message, 3)
-
-		for i := 0; i < 3; i++ {
-			params := &tss.Parameters{
-				PartyID:   parties[i],
-				Parties:   parties,
-				Threshold: 1,
-				Curve:     "secp256k1",
-				SessionID: []byte("test-session-batch"),
-			}
-			batchSMs[i], batchOutMsgs[i], err = NewBatchSignStateMachine(params, keyData[i], messages[:1])
-			if err != nil {
-				t.Fatalf("Failed to create batch sign state machine: %v", err)
-			}
-		}
-
-		// Run signing rounds
-		for r := 1; r <= 5; r++ {
-			batchSMs, batchOutMsgs = route(batchSMs, batchOutMsgs)
-		}
+func TestBatchSignRejectsForeignMessage(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	params := &tss.Parameters{
+		PartyID:   parties[1],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("sign-session"),
+	}
+	hash := sha256.Sum256([]byte("hello world"))
+	var err error
+	sms[1], _, err = NewBatchSignStateMachine(params, keyData[1], [][]byte{hash[:], hash[:]})
+	if err != nil {
+		t.Fatalf("Failed to create batch sign state machine: %v", err)
+	}
 
-		// Verify results
-		for i := 0; i < 3; i++ {
-			res := batchSMs[i].Result()
-			if res == nil {
-				t.Fatalf("Batch signing failed for party %d", i)
-			}
-			sig, ok := res.(*Signature)
-			if !ok {
-				t.Fatalf("Expected *Signature, got %T", res)
-			}
-			if sig.R == nil || sig.S == nil {
-				t.Fatalf("Invalid signature")
-			}
-		}
-	})
+	// A regular sign message cannot be fed into a batch
+	err = deliverTo(sms, 1, parties[1], outMsgs[0])
+	expectBlame(t, err, "1", "unexpected SignRound1 message")
 }
 
 func sha256Hash(data []byte) []byte {
@@ -0,0 +1,287 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/ripemd160"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+const bip322Tag = "BIP0322-signed-message"
+
+// sighashAll is the only sighash type used for BIP-322 simple signatures.
+const sighashAll = 0x01
+
+// NewBIP322StateMachine initializes a Signing state machine that produces a
+// BIP-322 "simple" signature of message for the group's P2WPKH address.
+// The parties sign the BIP-143 sighash of the virtual to_sign transaction;
+// encode the resulting *Signature with EncodeBIP322Simple.
+// Only secp256k1 keys are supported.
+func NewBIP322StateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData, message []byte) (tss.StateMachine, []tss.Message, error) {
+	curve, err := resolveCurve(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if curve.Params().Name != curves.NewSecp256k1().Params().Name {
+		return nil, nil, fmt.Errorf("%w: BIP-322 requires secp256k1", tss.ErrInvalidParameters)
+	}
+	if keyData == nil || keyData.PublicKeyX == nil || keyData.PublicKeyY == nil {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	script, err := P2WPKHScript(keyData.PublicKeyX, keyData.PublicKeyY)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewStateMachine(params, keyData, bip322Sighash(script, message))
+}
+
+// BIP322MessageHash returns the tagged hash of message that BIP-322 commits
+// to in the to_spend transaction.
+func BIP322MessageHash(message []byte) []byte {
+	tag := sha256.Sum256([]byte(bip322Tag))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// P2WPKHScript returns the P2WPKH scriptPubKey (OP_0 <HASH160(pubkey)>) of a
+// secp256k1 public key, using its compressed encoding.
+func P2WPKHScript(pkX, pkY *big.Int) ([]byte, error) {
+	pub, err := compressedPubKey(pkX, pkY)
+	if err != nil {
+		return nil, err
+	}
+	pkh := hash160(pub)
+	return append([]byte{0x00, 0x14}, pkh[:]...), nil
+}
+
+// EncodeBIP322Simple encodes sig as a base64 BIP-322 "simple" signature,
+// i.e. the P2WPKH witness stack [DER(sig) || SIGHASH_ALL, pubkey].
+// S is normalized to the lower half of the order as Bitcoin policy requires.
+func EncodeBIP322Simple(sig *Signature, pkX, pkY *big.Int) (string, error) {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return "", errors.New("bip322: missing signature")
+	}
+	pub, err := compressedPubKey(pkX, pkY)
+	if err != nil {
+		return "", err
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig.R.Bytes()) || s.SetByteSlice(sig.S.Bytes()) {
+		return "", errors.New("bip322: signature out of range")
+	}
+	der := append(ecdsa.NewSignature(&r, &s).Serialize(), sighashAll)
+
+	var buf bytes.Buffer
+	writeVarInt(&buf, 2)
+	writeVarBytes(&buf, der)
+	writeVarBytes(&buf, pub)
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// VerifyBIP322Simple checks a base64 BIP-322 "simple" signature of message
+// for a P2WPKH scriptPubKey.
+func VerifyBIP322Simple(scriptPubKey, message []byte, signature string) error {
+	if len(scriptPubKey) != 22 || scriptPubKey[0] != 0x00 || scriptPubKey[1] != 0x14 {
+		return errors.New("bip322: only P2WPKH scripts are supported")
+	}
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("bip322: invalid base64: %w", err)
+	}
+	witness, err := readWitness(raw)
+	if err != nil {
+		return err
+	}
+	if len(witness) != 2 {
+		return fmt.Errorf("bip322: expected 2 witness items, got %d", len(witness))
+	}
+	sigBytes, pubBytes := witness[0], witness[1]
+
+	pkh := hash160(pubBytes)
+	if !bytes.Equal(pkh[:], scriptPubKey[2:]) {
+		return errors.New("bip322: public key does not match script")
+	}
+	pub, err := secp256k1.ParsePubKey(pubBytes)
+	if err != nil || len(pubBytes) != 33 {
+		return errors.New("bip322: invalid compressed public key")
+	}
+	if len(sigBytes) < 1 || sigBytes[len(sigBytes)-1] != sighashAll {
+		return errors.New("bip322: unsupported sighash type")
+	}
+	sig, err := ecdsa.ParseDERSignature(sigBytes[:len(sigBytes)-1])
+	if err != nil {
+		return fmt.Errorf("bip322: invalid signature encoding: %w", err)
+	}
+	if !sig.Verify(bip322Sighash(scriptPubKey, message), pub) {
+		return errors.New("bip322: signature verification failed")
+	}
+	return nil
+}
+
+// bip322Sighash returns the BIP-143 SIGHASH_ALL digest of the to_sign
+// transaction spending the to_spend output locked by a P2WPKH scriptPubKey.
+func bip322Sighash(scriptPubKey, message []byte) []byte {
+	// to_spend: a virtual transaction committing to the message hash
+	//   input:  prevout 00..00:0xFFFFFFFF, scriptSig OP_0 PUSH32 <hash>, nSequence 0
+	//   output: value 0, scriptPubKey
+	var toSpend bytes.Buffer
+	writeUint32(&toSpend, 0) // nVersion
+	writeVarInt(&toSpend, 1)
+	toSpend.Write(make([]byte, 32))
+	writeUint32(&toSpend, 0xffffffff)
+	writeVarBytes(&toSpend, append([]byte{0x00, 0x20}, BIP322MessageHash(message)...))
+	writeUint32(&toSpend, 0) // nSequence
+	writeVarInt(&toSpend, 1)
+	writeUint64(&toSpend, 0)
+	writeVarBytes(&toSpend, scriptPubKey)
+	writeUint32(&toSpend, 0) // nLockTime
+	toSpendID := doubleSHA256(toSpend.Bytes())
+
+	// to_sign spends to_spend:0 with nSequence 0 into a single OP_RETURN output
+	var outpoint bytes.Buffer
+	outpoint.Write(toSpendID)
+	writeUint32(&outpoint, 0)
+
+	var sequence bytes.Buffer
+	writeUint32(&sequence, 0)
+
+	var outputs bytes.Buffer
+	writeUint64(&outputs, 0)
+	writeVarBytes(&outputs, []byte{0x6a}) // OP_RETURN
+
+	// P2WPKH scriptCode: OP_DUP OP_HASH160 <pkh> OP_EQUALVERIFY OP_CHECKSIG
+	scriptCode := append(append([]byte{0x76, 0xa9, 0x14}, scriptPubKey[2:]...), 0x88, 0xac)
+
+	// BIP-143 preimage
+	var preimage bytes.Buffer
+	writeUint32(&preimage, 0) // nVersion
+	preimage.Write(doubleSHA256(outpoint.Bytes()))
+	preimage.Write(doubleSHA256(sequence.Bytes()))
+	preimage.Write(outpoint.Bytes())
+	writeVarBytes(&preimage, scriptCode)
+	writeUint64(&preimage, 0) // amount
+	writeUint32(&preimage, 0) // nSequence
+	preimage.Write(doubleSHA256(outputs.Bytes()))
+	writeUint32(&preimage, 0) // nLockTime
+	writeUint32(&preimage, sighashAll)
+	return doubleSHA256(preimage.Bytes())
+}
+
+// compressedPubKey returns the 33-byte SEC1 compressed encoding of a
+// secp256k1 point.
+func compressedPubKey(pkX, pkY *big.Int) ([]byte, error) {
+	curve := curves.NewSecp256k1()
+	if pkX == nil || pkY == nil || !curve.IsOnCurve(pkX, pkY) {
+		return nil, errors.New("invalid secp256k1 public key")
+	}
+	return curve.MarshalCompressed(pkX, pkY), nil
+}
+
+func hash160(b []byte) [ripemd160.Size]byte {
+	h := sha256.Sum256(b)
+	return ripemd160.Sum(h[:])
+}
+
+func doubleSHA256(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:]
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeVarInt writes a Bitcoin CompactSize integer.
+func writeVarInt(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 0xfd:
+		buf.WriteByte(byte(v))
+	case v <= 0xffff:
+		buf.WriteByte(0xfd)
+		var b [2]byte
+		binary.LittleEndian.PutUint16(b[:], uint16(v))
+		buf.Write(b[:])
+	case v <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		writeUint32(buf, uint32(v))
+	default:
+		buf.WriteByte(0xff)
+		writeUint64(buf, v)
+	}
+}
+
+func writeVarBytes(buf *bytes.Buffer, b []byte) {
+	writeVarInt(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+// readVarInt reads a Bitcoin CompactSize integer.
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var size int
+	switch prefix {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(prefix), nil
+	}
+	var b [8]byte
+	if _, err := r.Read(b[:size]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+// readWitness parses a serialized witness stack.
+func readWitness(raw []byte) ([][]byte, error) {
+	r := bytes.NewReader(raw)
+	count, err := readVarInt(r)
+	if err != nil || count > uint64(len(raw)) {
+		return nil, errors.New("bip322: invalid witness")
+	}
+	items := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		n, err := readVarInt(r)
+		if err != nil || n > uint64(r.Len()) {
+			return nil, errors.New("bip322: invalid witness")
+		}
+		item := make([]byte, n)
+		if _, err := r.Read(item); err != nil && n > 0 {
+			return nil, errors.New("bip322: invalid witness")
+		}
+		items = append(items, item)
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("bip322: trailing bytes after witness")
+	}
+	return items, nil
+}
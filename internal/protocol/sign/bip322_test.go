@@ -0,0 +1,97 @@
+package sign
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestBIP322MessageHash(t *testing.T) {
+	vectors := map[string]string{
+		"":            "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+		"Hello World": "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+	}
+	for msg, want := range vectors {
+		if got := hex.EncodeToString(BIP322MessageHash([]byte(msg))); got != want {
+			t.Errorf("BIP322MessageHash(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestBIP322ReferenceVector(t *testing.T) {
+	// "Hello World" signed by bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l (BIP-322 test vectors)
+	const sig = "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	script, _ := hex.DecodeString("00142b05d564e6a7a33c087f16e0f730d1440123799d")
+
+	if err := VerifyBIP322Simple(script, []byte("Hello World"), sig); err != nil {
+		t.Fatalf("Reference signature rejected: %v", err)
+	}
+	if err := VerifyBIP322Simple(script, []byte("Hello World!"), sig); err == nil {
+		t.Fatal("Reference signature accepted for a different message")
+	}
+}
+
+func TestBIP322Sign(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}}
+	keyData := runKeyGen(t, parties, 1)
+	message := []byte("BIP-322 threshold signature")
+
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-bip322"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewBIP322StateMachine(params, keyData[i], message)
+		if err != nil {
+			t.Fatalf("Failed to create BIP-322 state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	script, err := P2WPKHScript(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	if err != nil {
+		t.Fatalf("P2WPKHScript: %v", err)
+	}
+	sig, ok := sms[0].Result().(*Signature)
+	if !ok {
+		t.Fatalf("Expected Signature result, got %T", sms[0].Result())
+	}
+	encoded, err := EncodeBIP322Simple(sig, keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	if err != nil {
+		t.Fatalf("EncodeBIP322Simple: %v", err)
+	}
+	if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+		t.Fatalf("Encoded signature is not base64: %v", err)
+	}
+	if err := VerifyBIP322Simple(script, message, encoded); err != nil {
+		t.Fatalf("BIP-322 verification failed: %v", err)
+	}
+	if err := VerifyBIP322Simple(script, []byte("another message"), encoded); err == nil {
+		t.Fatal("BIP-322 signature verified for the wrong message")
+	}
+}
+
+func TestBIP322RequiresSecp256k1(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "p384",
+		SessionID: []byte("sign-bip322-p384"),
+	}
+	if _, _, err := NewBIP322StateMachine(params, nil, []byte("msg")); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package sign
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// ContributionReport returns the Lagrange weight of each signer in
+// params.Parties, keyed by PartyID.ID(), for audit logs. A signature's S is
+// the sum of the signers' contributions, and the weights satisfy
+// sum(lambda_i * X_i) = P for their public key shares X_i.
+//
+// keyData supplies the signers' share indices; any signer's key data works.
+// Returns nil if a signer is not part of the key committee.
+func ContributionReport(params *tss.Parameters, keyData *keygen.LocalPartySaveData) map[string]*big.Int {
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil
+	}
+	weights, err := lagrangeWeights(curve, params.Parties, keyData)
+	if err != nil {
+		return nil
+	}
+	return weights
+}
@@ -0,0 +1,63 @@
+package sign
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestContributionReport(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	curve := curves.NewSecp256k1()
+	N := curve.Params().N
+
+	// Signers 3 and 1, listed out of canonical order
+	signers := []tss.PartyID{parties[2], parties[0]}
+	params := &tss.Parameters{
+		PartyID:   signers[0],
+		Parties:   signers,
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("contribution-report"),
+	}
+	weights := ContributionReport(params, keyData[2])
+	if len(weights) != 2 || weights["1"] == nil || weights["3"] == nil {
+		t.Fatalf("Expected weights for signers 1 and 3, got %v", weights)
+	}
+
+	// sum(lambda_i * x_i) * G = P and sum(lambda_i * X_i) = P
+	x := new(big.Int)
+	var px, py *big.Int
+	for _, i := range []int{0, 2} {
+		d := keyData[i]
+		lambda := weights[parties[i].ID()]
+		x.Add(x, new(big.Int).Mul(lambda, d.Xi)).Mod(x, N)
+
+		wx, wy := curve.ScalarMult(d.XiX, d.XiY, lambda)
+		if px == nil {
+			px, py = wx, wy
+		} else {
+			px, py = curve.Add(px, py, wx, wy)
+		}
+	}
+	gx, gy := curve.ScalarBaseMult(x)
+	if gx.Cmp(keyData[0].PublicKeyX) != 0 || gy.Cmp(keyData[0].PublicKeyY) != 0 {
+		t.Fatal("Weighted shares do not reconstruct the group secret")
+	}
+	if px.Cmp(keyData[0].PublicKeyX) != 0 || py.Cmp(keyData[0].PublicKeyY) != 0 {
+		t.Fatal("Weighted public key shares do not sum to the group key")
+	}
+
+	// A signer outside the committee has no weight
+	params.Parties = []tss.PartyID{signers[0], &MockPartyID{id: "4"}}
+	if weights := ContributionReport(params, keyData[2]); weights != nil {
+		t.Fatalf("Expected nil report for an unknown signer, got %v", weights)
+	}
+}
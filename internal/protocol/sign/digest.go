@@ -0,0 +1,57 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// MinDigestSize is the shortest message digest accepted for signing, in bytes.
+// Signing takes a hash, never a raw message; anything shorter than a 256-bit
+// hash is almost certainly a caller bug.
+const MinDigestSize = 32
+
+// checkDigest rejects digests shorter than MinDigestSize.
+func checkDigest(digest []byte) error {
+	if len(digest) < MinDigestSize {
+		return fmt.Errorf("%w: digest is %d bytes, need at least %d", tss.ErrInvalidParameters, len(digest), MinDigestSize)
+	}
+	return nil
+}
+
+// DomainDigest binds digest to an application domain: it returns the
+// tagged hash SHA-256(SHA-256(tag) || SHA-256(tag) || digest), as in
+// BIP-340, or digest itself if tag is empty. Signing with
+// Parameters.DomainTag set signs DomainDigest(DomainTag, digest), so a
+// verifier checks the signature against this value.
+func DomainDigest(tag, digest []byte) []byte {
+	if len(tag) == 0 {
+		return digest
+	}
+	tagHash := sha256.Sum256(tag)
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+// hashToInt converts a digest to the integer m that is signed, following
+// SEC 1 / FIPS 186-4: digests longer than the group order are truncated to
+// their leftmost N.BitLen() bits, shorter ones are used as is. This matches
+// crypto/ecdsa, so e.g. a SHA-512 digest on secp256k1 signs its first 32 bytes.
+func hashToInt(curve curves.Curve, digest []byte) *big.Int {
+	orderBits := curve.Params().N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+	m := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		m.Rsh(m, uint(excess))
+	}
+	return m
+}
@@ -0,0 +1,59 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSignRejectsShortDigest(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("sign-short-digest"),
+	}
+	short := bytes.Repeat([]byte{0xab}, 20)
+
+	if _, _, err := NewStateMachine(params, nil, short); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("NewStateMachine: expected ErrInvalidParameters, got %v", err)
+	}
+	if _, _, err := NewOnlineStateMachine(params, nil, &PreSignature{}, short); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("NewOnlineStateMachine: expected ErrInvalidParameters, got %v", err)
+	}
+	if _, _, err := NewBatchSign(params, nil, [][]byte{make([]byte, 32), short}); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("NewBatchSign: expected ErrInvalidParameters, got %v", err)
+	}
+}
+
+func TestHashToInt(t *testing.T) {
+	digest := make([]byte, 64)
+	for i := range digest {
+		digest[i] = byte(i + 1)
+	}
+
+	// secp256k1: a 64-byte digest signs its leftmost 32 bytes
+	secp := curves.NewSecp256k1()
+	if got, want := hashToInt(secp, digest), new(big.Int).SetBytes(digest[:32]); got.Cmp(want) != 0 {
+		t.Fatalf("secp256k1: got %x, want %x", got, want)
+	}
+	// A digest of exactly the order size is used as is
+	if got, want := hashToInt(secp, digest[:32]), new(big.Int).SetBytes(digest[:32]); got.Cmp(want) != 0 {
+		t.Fatalf("secp256k1 32 bytes: got %x, want %x", got, want)
+	}
+
+	// P-384: a 32-byte digest is shorter than the order and is not padded
+	p384 := curves.NewP384()
+	if got, want := hashToInt(p384, digest[:32]), new(big.Int).SetBytes(digest[:32]); got.Cmp(want) != 0 {
+		t.Fatalf("p384 32 bytes: got %x, want %x", got, want)
+	}
+	if got, want := hashToInt(p384, digest), new(big.Int).SetBytes(digest[:48]); got.Cmp(want) != 0 {
+		t.Fatalf("p384 64 bytes: got %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,367 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/logstar"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// identifyRound is the round number of the identification messages. It
+// replaces Round 4 when the Delta_j do not add up to delta * G, or the
+// Sigma_j to delta * P.
+const identifyRound = 5
+
+// IdentifyPayload opens everything a signer fed into delta_i: its nonce
+// shares and the C_delta it sent in Round 2, with their Paillier randomness.
+// It is only sent when R or the Sigma_j are wrong, so the nonce will never be
+// used and no s_i has been released that its k_i could be combined with.
+type IdentifyPayload struct {
+	Ki     *big.Int
+	RK     *big.Int // Randomness of EncK_i
+	GammaI *big.Int
+
+	// By recipient party ID: C_delta_ij, beta_ij and the randomness of
+	// Enc(beta_ij)
+	CDeltas    map[string]*big.Int
+	Betas      map[string]*big.Int
+	BetaNonces map[string]*big.Int
+
+	// By sender party ID: the C_sigma_ji we received, M_ji = mu_ji * G for
+	// the mu_ji we decrypted from it, the quotient h_ji = mu_ji div q, and a
+	// proof that C_sigma_ji - q*h_ji encrypts the discrete log of M_ji. mu_ji
+	// itself stays secret: with k_i open it would reveal w_j.
+	CSigmas     map[string]*big.Int
+	Mus         map[string][]byte
+	MuQuotients map[string]*big.Int
+	MuProofs    map[string]*LogStarProofPayload
+}
+
+// startIdentification broadcasts our openings instead of s_i.
+func (s *state) startIdentification() (tss.StateMachine, []tss.Message, error) {
+	opening, err := s.ownOpening()
+	if err != nil {
+		return nil, nil, err
+	}
+	s.tempData["opening"] = opening
+	data, err := json.Marshal(opening)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg := &SignMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  nil,
+		IsBcast:    true,
+		Data:       data,
+		TypeString: "SignIdentify",
+		RoundNum:   identifyRound,
+		Session:    s.params.SessionID,
+	}
+	newState := &state{
+		params:       s.params,
+		curve:        s.curve,
+		keyData:      s.keyData,
+		msgToSign:    s.msgToSign,
+		tweak:        s.tweak,
+		round:        identifyRound,
+		tempData:     s.tempData,
+		receivedMsgs: make(map[string][]tss.Message),
+	}
+	return newState, []tss.Message{msg}, nil
+}
+
+func (s *state) ownOpening() (*IdentifyPayload, error) {
+	o := &IdentifyPayload{
+		Ki:         s.tempData["ki"].(*big.Int),
+		RK:         s.tempData["rK"].(*big.Int),
+		GammaI:     s.tempData["gammai"].(*big.Int),
+		CDeltas:    s.tempData["cDeltas"].(map[string]*big.Int),
+		Betas:      s.tempData["betas"].(map[string]*big.Int),
+		BetaNonces: s.tempData["betaNonces"].(map[string]*big.Int),
+
+		CSigmas:     s.tempData["peerCSigmas"].(map[string]*big.Int),
+		Mus:         make(map[string][]byte),
+		MuQuotients: make(map[string]*big.Int),
+		MuProofs:    make(map[string]*LogStarProofPayload),
+	}
+
+	q := s.curve.Params().N
+	pk := s.keyData.PaillierPk
+	Gx, Gy := s.curve.ScalarBaseMult(big.NewInt(1))
+	mus := s.tempData["mus"].(map[string]*big.Int)
+	for id, c := range o.CSigmas {
+		h, low := new(big.Int).DivMod(mus[id], q, new(big.Int))
+		Mx, My := s.curve.ScalarBaseMult(low)
+		rho, err := s.keyData.PaillierSk.Nonce(c)
+		if err != nil {
+			return nil, err
+		}
+		cLow, err := muRemainder(pk, q, c, h)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := logstar.Prove(s.curve, pk, cLow, low, rho, Gx, Gy, Mx, My, s.params.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prove M for %s: %w", id, err)
+		}
+		o.Mus[id] = s.curve.MarshalCompressed(Mx, My)
+		o.MuQuotients[id] = h
+		o.MuProofs[id] = newLogStarProofPayload(s.curve, proof)
+	}
+	return o, nil
+}
+
+// muRemainder returns C_sigma - q*h, an encryption of mu mod q with the same
+// randomness as C_sigma when C_sigma encrypts mu and h = mu div q.
+func muRemainder(pk *paillier.PublicKey, q, c, h *big.Int) (*big.Int, error) {
+	neg := new(big.Int).Mul(q, h)
+	neg.Neg(neg).Mod(neg, pk.N)
+	e, err := pk.EncryptWithNonce(neg, big.NewInt(1))
+	if err != nil {
+		return nil, err
+	}
+	return pk.Add(c, e), nil
+}
+
+// identify checks every signer's openings against what it committed to in
+// Rounds 1-2, then recomputes each delta_j and Sigma_j from the openings and
+// compares them with what was broadcast in Round 3. It always returns an
+// error: a tss.Blame on the first signer, in canonical order, that fails a
+// check.
+func (s *state) identify() (tss.StateMachine, []tss.Message, error) {
+	defer s.zeroizeSecrets()
+
+	self := s.params.PartyID.ID()
+	openings := map[string]*IdentifyPayload{self: s.tempData["opening"].(*IdentifyPayload)}
+	for id, msgs := range s.receivedMsgs {
+		var payload IdentifyPayload
+		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "malformed identification message", err)
+		}
+		openings[id] = &payload
+	}
+
+	// Public values of every signer, ourselves included
+	pks := map[string]*paillier.PublicKey{self: s.keyData.PaillierPk}
+	for id, pk := range s.tempData["peerPaillierPks"].(map[string]*paillier.PublicKey) {
+		pks[id] = pk
+	}
+	encKs := map[string]*big.Int{self: s.tempData["encK"].(*big.Int)}
+	for id, c := range s.tempData["peerEncK"].(map[string]*big.Int) {
+		encKs[id] = c
+	}
+	deltas := map[string]*big.Int{self: s.tempData["delta_i"].(*big.Int)}
+	for id, d := range s.tempData["peerDeltas"].(map[string]*big.Int) {
+		deltas[id] = d
+	}
+	gammaX := map[string]*big.Int{self: s.tempData["GammaX"].(*big.Int)}
+	gammaY := map[string]*big.Int{self: s.tempData["GammaY"].(*big.Int)}
+	for id := range s.tempData["peerGammaX"].(map[string]*big.Int) {
+		gammaX[id] = s.tempData["peerGammaX"].(map[string]*big.Int)[id]
+		gammaY[id] = s.tempData["peerGammaY"].(map[string]*big.Int)[id]
+	}
+	hashes := s.tempData["peerDeltaHashes"].(map[string]map[string][]byte)
+
+	// 1. Each opening must match the signer's EncK_i, Gamma_i and the C_delta
+	// hashes it broadcast
+	for _, p := range s.params.Parties {
+		if p.ID() == self {
+			continue
+		}
+		if reason := s.checkOpening(p.ID(), openings[p.ID()], pks, encKs, gammaX, gammaY, hashes[p.ID()]); reason != "" {
+			return nil, nil, tss.NewBlame(p, reason, tss.ErrInvalidMsg)
+		}
+	}
+
+	// 2. With all openings valid, delta_j = k_j*gamma_j + sum(alpha_ji - beta_ji)
+	// where alpha_ji = k_j*gamma_i + beta_ij mod N_j is what j decrypted
+	q := s.curve.Params().N
+	for _, p := range s.params.Parties {
+		j := p.ID()
+		want := new(big.Int).Mul(openings[j].Ki, openings[j].GammaI)
+		for _, other := range s.params.Parties {
+			i := other.ID()
+			if i == j {
+				continue
+			}
+			alpha := new(big.Int).Mul(openings[j].Ki, openings[i].GammaI)
+			alpha.Add(alpha, openings[i].Betas[j])
+			alpha.Mod(alpha, pks[j].N)
+			want.Add(want, alpha)
+			want.Sub(want, openings[j].Betas[i])
+		}
+		want.Mod(want, q)
+		if want.Cmp(deltas[j]) != 0 {
+			return nil, nil, tss.NewBlame(p, "delta_i does not match the opened nonce shares and MtA", tss.ErrInvalidMsg)
+		}
+	}
+
+	// 3. Each M_ji must be proven against the C_sigma_ji j committed to
+	sigmaHashes := map[string]map[string][]byte{self: make(map[string][]byte)}
+	for id, c := range s.tempData["cSigmas"].(map[string]*big.Int) {
+		sigmaHashes[self][id] = ciphertextHash(c)
+	}
+	for id, h := range s.tempData["peerSigmaHashes"].(map[string]map[string][]byte) {
+		sigmaHashes[id] = h
+	}
+	mus := make(map[string]map[string][2]*big.Int, len(openings))
+	for _, p := range s.params.Parties {
+		m, reason := s.checkMus(p.ID(), openings[p.ID()], pks[p.ID()], sigmaHashes)
+		if reason != "" {
+			return nil, nil, tss.NewBlame(p, reason, tss.ErrInvalidMsg)
+		}
+		mus[p.ID()] = m
+	}
+
+	// 4. With nu_ij * G = M_ji - k_j * W_i, Sigma_i = gamma * sigma_i * G
+	// must satisfy Sigma_i + gamma * sum(M_ji) =
+	// gamma * (k * W_i + t * k_i * G + sum(M_ij)) for k = sum(k_j)
+	gamma, k := new(big.Int), new(big.Int)
+	for _, o := range openings {
+		gamma.Add(gamma, o.GammaI)
+		k.Add(k, o.Ki)
+	}
+	gamma.Mod(gamma, q)
+	k.Mod(k, q)
+	GammaX, GammaY := s.gammaSum()
+	wX := map[string]*big.Int{self: s.tempData["WX"].(*big.Int)}
+	wY := map[string]*big.Int{self: s.tempData["WY"].(*big.Int)}
+	for id, x := range s.tempData["peerWX"].(map[string]*big.Int) {
+		wX[id] = x
+		wY[id] = s.tempData["peerWY"].(map[string]*big.Int)[id]
+	}
+	peerBigSigmas := s.tempData["peerBigSigmas"].(map[string][]byte)
+	for _, p := range s.params.Parties {
+		i := p.ID()
+		var lx, ly *big.Int
+		if i == self {
+			lx, ly = s.curve.ScalarMult(GammaX, GammaY, s.tweakedSigma())
+		} else {
+			var err error
+			if lx, ly, err = s.curve.UnmarshalCompressed(peerBigSigmas[i]); err != nil {
+				return nil, nil, tss.NewBlame(p, "invalid Sigma_i point", err)
+			}
+		}
+		scalar := new(big.Int).Mul(gamma, k)
+		rx, ry := s.curve.ScalarMult(wX[i], wY[i], scalar.Mod(scalar, q))
+		if s.tweak != nil {
+			tk := new(big.Int).Mul(gamma, s.tweak)
+			tk.Mul(tk, openings[i].Ki)
+			tx, ty := s.curve.ScalarBaseMult(tk.Mod(tk, q))
+			rx, ry = s.curve.Add(rx, ry, tx, ty)
+		}
+		for _, other := range s.params.Parties {
+			j := other.ID()
+			if j == i {
+				continue
+			}
+			in, out := mus[i][j], mus[j][i]
+			ix, iy := s.curve.ScalarMult(in[0], in[1], gamma)
+			rx, ry = s.curve.Add(rx, ry, ix, iy)
+			ox, oy := s.curve.ScalarMult(out[0], out[1], gamma)
+			lx, ly = s.curve.Add(lx, ly, ox, oy)
+		}
+		if lx.Cmp(rx) != 0 || ly.Cmp(ry) != 0 {
+			return nil, nil, tss.NewBlame(p, "Sigma_i does not match the opened nonce shares and MtA", tss.ErrInvalidMsg)
+		}
+	}
+
+	// Unreachable unless the Paillier arithmetic wrapped around, or a W_j
+	// could not be checked against the key data
+	return nil, nil, fmt.Errorf("R or the Sigma_j are wrong, but every signer's openings are consistent")
+}
+
+// checkMus verifies the M_ji opened by signer id and returns them as points
+// by sender, or why they are invalid.
+func (s *state) checkMus(
+	id string,
+	o *IdentifyPayload,
+	pk *paillier.PublicKey,
+	sigmaHashes map[string]map[string][]byte,
+) (map[string][2]*big.Int, string) {
+	q := s.curve.Params().N
+	Gx, Gy := s.curve.ScalarBaseMult(big.NewInt(1))
+	points := make(map[string][2]*big.Int, len(s.params.Parties))
+	for _, p := range s.params.Parties {
+		j := p.ID()
+		if j == id {
+			continue
+		}
+		c, h := o.CSigmas[j], o.MuQuotients[j]
+		if c == nil || h == nil || o.Mus[j] == nil || o.MuProofs[j] == nil {
+			return nil, "incomplete identification message"
+		}
+		if !bytes.Equal(sigmaHashes[j][id], ciphertextHash(c)) {
+			return nil, "opened C_sigma does not match its broadcast hash"
+		}
+		if h.Sign() < 0 || h.Cmp(pk.N) >= 0 {
+			return nil, "opened mu quotient out of range"
+		}
+		Mx, My, err := s.curve.UnmarshalCompressed(o.Mus[j])
+		if err != nil {
+			return nil, "invalid M point"
+		}
+		proof, err := o.MuProofs[j].proof(s.curve)
+		if err != nil {
+			return nil, "malformed M proof"
+		}
+		cLow, err := muRemainder(pk, q, c, h)
+		if err != nil || !proof.Verify(s.curve, pk, cLow, Gx, Gy, Mx, My, s.params.SessionID) {
+			return nil, "M proof verification failed"
+		}
+		points[j] = [2]*big.Int{Mx, My}
+	}
+	return points, ""
+}
+
+// checkOpening returns why the opening of signer id is invalid, or "" if it
+// is valid.
+func (s *state) checkOpening(
+	id string,
+	o *IdentifyPayload,
+	pks map[string]*paillier.PublicKey,
+	encKs, gammaX, gammaY map[string]*big.Int,
+	hashes map[string][]byte,
+) string {
+	q := s.curve.Params().N
+	if o.Ki == nil || o.RK == nil || o.GammaI == nil {
+		return "incomplete identification message"
+	}
+	if o.Ki.Sign() < 0 || o.Ki.Cmp(q) >= 0 || o.GammaI.Sign() < 0 || o.GammaI.Cmp(q) >= 0 {
+		return "opened nonce share out of range"
+	}
+
+	encK, err := pks[id].EncryptWithNonce(o.Ki, o.RK)
+	if err != nil || encK.Cmp(encKs[id]) != 0 {
+		return "opened k_i does not match EncK_i"
+	}
+	gx, gy := s.curve.ScalarBaseMult(o.GammaI)
+	if gx.Cmp(gammaX[id]) != 0 || gy.Cmp(gammaY[id]) != 0 {
+		return "opened gamma_i does not match Gamma_i"
+	}
+
+	for _, p := range s.params.Parties {
+		j := p.ID()
+		if j == id {
+			continue
+		}
+		c, beta, nonce := o.CDeltas[j], o.Betas[j], o.BetaNonces[j]
+		if c == nil || beta == nil || nonce == nil {
+			return "incomplete identification message"
+		}
+		if !bytes.Equal(hashes[j], ciphertextHash(c)) {
+			return "opened C_delta does not match its broadcast hash"
+		}
+		// C_delta_ij = EncK_j^gamma_i * Enc_j(beta_ij)
+		pkj := pks[j]
+		encBeta, err := pkj.EncryptWithNonce(beta, nonce)
+		if err != nil || pkj.Add(pkj.Mul(encKs[j], o.GammaI), encBeta).Cmp(c) != 0 {
+			return "opened C_delta was not formed from EncK_j, gamma_i and beta_ij"
+		}
+	}
+	return ""
+}
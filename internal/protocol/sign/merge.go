@@ -0,0 +1,40 @@
+package sign
+
+import (
+	"fmt"
+)
+
+// MergeResults checks that the finished Result() of every signer is a *Signature
+// with identical (R, S) and returns the consensus signature.
+//
+// Diverging results indicate a bug or a malicious party and are reported as an error.
+func MergeResults(results []interface{}) (*Signature, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results to merge")
+	}
+
+	var consensus *Signature
+	for i, res := range results {
+		sig, ok := res.(*Signature)
+		if !ok || sig == nil {
+			return nil, fmt.Errorf("result %d is %T, expected *Signature", i, res)
+		}
+		if sig.R == nil || sig.S == nil {
+			return nil, fmt.Errorf("result %d is an incomplete signature", i)
+		}
+
+		if consensus == nil {
+			consensus = sig
+			continue
+		}
+		if sig.R.Cmp(consensus.R) != 0 || sig.S.Cmp(consensus.S) != 0 {
+			return nil, fmt.Errorf("result %d diverges from result 0", i)
+		}
+	}
+
+	return &Signature{
+		R:     consensus.R,
+		S:     consensus.S,
+		RecID: consensus.RecID,
+	}, nil
+}
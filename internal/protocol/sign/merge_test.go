@@ -0,0 +1,40 @@
+package sign
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMergeResults(t *testing.T) {
+	sig := func(r, s int64) *Signature {
+		return &Signature{R: big.NewInt(r), S: big.NewInt(s)}
+	}
+
+	// Identical results merge into the consensus signature
+	merged, err := MergeResults([]interface{}{sig(1, 2), sig(1, 2), sig(1, 2)})
+	if err != nil {
+		t.Fatalf("MergeResults failed: %v", err)
+	}
+	if merged.R.Int64() != 1 || merged.S.Int64() != 2 {
+		t.Fatalf("Unexpected merged signature (%s, %s)", merged.R, merged.S)
+	}
+
+	// One divergent result
+	if _, err := MergeResults([]interface{}{sig(1, 2), sig(1, 3), sig(1, 2)}); err == nil {
+		t.Fatal("Expected error for divergent S")
+	}
+	if _, err := MergeResults([]interface{}{sig(1, 2), sig(1, 2), sig(4, 2)}); err == nil {
+		t.Fatal("Expected error for divergent R")
+	}
+
+	// Wrong result types
+	if _, err := MergeResults([]interface{}{sig(1, 2), &PreSignature{}, sig(1, 2)}); err == nil {
+		t.Fatal("Expected error for non-signature result")
+	}
+	if _, err := MergeResults([]interface{}{sig(1, 2), nil}); err == nil {
+		t.Fatal("Expected error for nil result")
+	}
+	if _, err := MergeResults(nil); err == nil {
+		t.Fatal("Expected error for empty results")
+	}
+}
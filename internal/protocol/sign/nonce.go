@@ -0,0 +1,37 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"math/big"
+)
+
+const nonceCommitmentDomain = "go-cggmp-tss/sign/nonce-commitment/v1"
+
+// NonceCommitment returns H(r) for the nonce point R of a PreSignature.
+// It can be published at presign time and later checked against the finished
+// signature with VerifyNonceCommitment, e.g. as front-running protection.
+// Returns nil if the PreSignature has no R.
+func NonceCommitment(preSig *PreSignature) []byte {
+	if preSig == nil || preSig.R == nil {
+		return nil
+	}
+	return nonceCommitment(preSig.R)
+}
+
+// VerifyNonceCommitment reports whether commitment was computed by
+// NonceCommitment for the PreSignature that produced sig.
+func VerifyNonceCommitment(commitment []byte, sig *Signature) bool {
+	if len(commitment) == 0 || sig == nil || sig.R == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(commitment, nonceCommitment(sig.R)) == 1
+}
+
+// nonceCommitment hashes r = R.x mod N, the only part of R a signature reveals.
+func nonceCommitment(r *big.Int) []byte {
+	h := sha256.New()
+	h.Write([]byte(nonceCommitmentDomain))
+	h.Write(r.Bytes())
+	return h.Sum(nil)
+}
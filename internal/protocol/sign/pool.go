@@ -0,0 +1,155 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+var (
+	// ErrPreSignPoolEmpty is returned by PreSignPool.Take when every
+	// presignature has been consumed.
+	ErrPreSignPoolEmpty = errors.New("presignature pool exhausted")
+	// ErrPreSignatureUsed is returned when a presignature is requested again
+	// after it was handed out, or passed to NewOnlineStateMachine a second
+	// time. Signing two messages with the same presignature reveals the
+	// private key.
+	ErrPreSignatureUsed = errors.New("presignature already used")
+)
+
+// PreSignPool stores presignatures produced by NewPreSignStateMachine ahead of
+// time, so that online signing (NewOnlineStateMachine) can start at once.
+//
+// Every presignature is handed out at most once: the pool remembers the IDs
+// it has given out and refuses to return or accept them again. All parties
+// must take the same presignature (by ID) for a signing session. A pool is
+// safe for concurrent use.
+type PreSignPool struct {
+	mu       sync.Mutex
+	unused   map[string]*PreSignature
+	order    []string        // IDs in insertion order; Take hands out the oldest
+	consumed map[string]bool // IDs already handed out
+}
+
+// NewPreSignPool returns an empty pool.
+func NewPreSignPool() *PreSignPool {
+	return &PreSignPool{
+		unused:   make(map[string]*PreSignature),
+		consumed: make(map[string]bool),
+	}
+}
+
+// Add stores preSig under id. The ID must be unique over the lifetime of the
+// pool, including presignatures that were already taken.
+func (p *PreSignPool) Add(id string, preSig *PreSignature) error {
+	if id == "" || preSig == nil {
+		return fmt.Errorf("%w: presignature and ID are required", tss.ErrInvalidParameters)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumed[id] {
+		return fmt.Errorf("%w: %s", ErrPreSignatureUsed, id)
+	}
+	if _, ok := p.unused[id]; ok {
+		return fmt.Errorf("%w: duplicate presignature ID %s", tss.ErrInvalidParameters, id)
+	}
+	p.unused[id] = preSig
+	p.order = append(p.order, id)
+	return nil
+}
+
+// Take removes the oldest unused presignature from the pool and returns it
+// with its ID. It returns ErrPreSignPoolEmpty if none is left.
+func (p *PreSignPool) Take() (string, *PreSignature, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.order) > 0 {
+		id := p.order[0]
+		p.order = p.order[1:]
+		if preSig, ok := p.unused[id]; ok {
+			p.consume(id)
+			return id, preSig, nil
+		}
+	}
+	return "", nil, ErrPreSignPoolEmpty
+}
+
+// TakeID removes the presignature with the given ID from the pool, e.g. the
+// one another party chose with Take. A second request for the same ID fails
+// with ErrPreSignatureUsed.
+func (p *PreSignPool) TakeID(id string) (*PreSignature, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumed[id] {
+		return nil, fmt.Errorf("%w: %s", ErrPreSignatureUsed, id)
+	}
+	preSig, ok := p.unused[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown presignature ID %s", tss.ErrInvalidParameters, id)
+	}
+	p.consume(id)
+	return preSig, nil
+}
+
+// Len returns the number of unused presignatures.
+func (p *PreSignPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.unused)
+}
+
+// consume marks id as handed out. The caller holds p.mu.
+func (p *PreSignPool) consume(id string) {
+	delete(p.unused, id)
+	p.consumed[id] = true
+}
+
+// poolSessionPurpose separates the session IDs of SignFromPool sessions.
+const poolSessionPurpose = "sign-from-pool"
+
+// SignFromPool signs msgHash with the oldest usable presignature in pool: it
+// takes the presignature, runs the online signing over t (see
+// NewOnlineStateMachine and tss.RunParty) and returns the signature.
+//
+// Every signer must call it with a pool holding the same presignatures in
+// the same order, so that all take the same one. A presignature whose r is 0
+// cannot sign; every signer sees that from the common R, skips it and takes
+// the next. The session runs under tss.DeriveSessionID("sign-from-pool",
+// Parties, SessionID || ID), so params.SessionID can be reused across calls.
+// It returns ErrPreSignPoolEmpty once the pool has no usable presignature.
+//
+// Calls must not overlap on one transport: the signers finish one signature
+// before any of them starts the next, as a message of another session fails
+// the session check.
+func SignFromPool(pool *PreSignPool, params *tss.Parameters, keyData *keygen.LocalPartySaveData, msgHash []byte, t tss.Transport) (*Signature, error) {
+	if pool == nil || params == nil || t == nil {
+		return nil, fmt.Errorf("%w: pool, parameters and transport are required", tss.ErrInvalidParameters)
+	}
+	for {
+		id, preSig, err := pool.Take()
+		if err != nil {
+			return nil, err
+		}
+		sessionParams := *params
+		sessionParams.SessionID = tss.DeriveSessionID(poolSessionPurpose, params.Parties, append(append([]byte(nil), params.SessionID...), id...))
+		sm, out, err := NewOnlineStateMachine(&sessionParams, keyData, preSig, msgHash)
+		if errors.Is(err, ErrRetrySign) {
+			continue // Burned; the other signers skip it too
+		}
+		if err != nil {
+			return nil, fmt.Errorf("presignature %s: %w", id, err)
+		}
+		result, err := tss.RunParty(sm, out, t)
+		if err != nil {
+			return nil, fmt.Errorf("presignature %s: %w", id, err)
+		}
+		sig, ok := result.(*Signature)
+		if !ok {
+			return nil, fmt.Errorf("presignature %s: unexpected result %T", id, result)
+		}
+		return sig, nil
+	}
+}
@@ -0,0 +1,168 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestPreSignPoolExhaustion(t *testing.T) {
+	pool := NewPreSignPool()
+	for i, id := range []string{"a", "b", "c"} {
+		if err := pool.Add(id, &PreSignature{R: big.NewInt(int64(i + 1))}); err != nil {
+			t.Fatalf("Add %s: %v", id, err)
+		}
+	}
+	if pool.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", pool.Len())
+	}
+
+	// Oldest first, each exactly once
+	for i, want := range []string{"a", "b", "c"} {
+		id, preSig, err := pool.Take()
+		if err != nil {
+			t.Fatalf("Take %d: %v", i, err)
+		}
+		if id != want || preSig.R.Int64() != int64(i+1) {
+			t.Fatalf("Take %d returned %s, want %s", i, id, want)
+		}
+	}
+	if pool.Len() != 0 {
+		t.Fatalf("Len = %d after draining the pool", pool.Len())
+	}
+	if _, _, err := pool.Take(); !errors.Is(err, ErrPreSignPoolEmpty) {
+		t.Fatalf("expected ErrPreSignPoolEmpty, got %v", err)
+	}
+}
+
+func TestPreSignPoolDoubleTake(t *testing.T) {
+	pool := NewPreSignPool()
+	if err := pool.Add("a", &PreSignature{R: big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add("b", &PreSignature{R: big.NewInt(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add("b", &PreSignature{R: big.NewInt(3)}); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("expected duplicate ID to be rejected, got %v", err)
+	}
+
+	preSig, err := pool.TakeID("b")
+	if err != nil || preSig.R.Int64() != 2 {
+		t.Fatalf("TakeID(b) = %v, %v", preSig, err)
+	}
+	if _, err := pool.TakeID("b"); !errors.Is(err, ErrPreSignatureUsed) {
+		t.Fatalf("expected ErrPreSignatureUsed on second TakeID, got %v", err)
+	}
+	// A used presignature cannot be put back either
+	if err := pool.Add("b", preSig); !errors.Is(err, ErrPreSignatureUsed) {
+		t.Fatalf("expected ErrPreSignatureUsed on re-adding, got %v", err)
+	}
+	if _, err := pool.TakeID("missing"); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("expected unknown ID to be rejected, got %v", err)
+	}
+
+	// Take skips the ID already taken by TakeID
+	id, _, err := pool.Take()
+	if err != nil || id != "a" {
+		t.Fatalf("Take = %s, %v", id, err)
+	}
+	if _, err := pool.TakeID("a"); !errors.Is(err, ErrPreSignatureUsed) {
+		t.Fatalf("expected ErrPreSignatureUsed after Take, got %v", err)
+	}
+	if _, _, err := pool.Take(); !errors.Is(err, ErrPreSignPoolEmpty) {
+		t.Fatalf("expected ErrPreSignPoolEmpty, got %v", err)
+	}
+}
+
+// TestSignFromPool signs three messages from a pool of three presignatures
+// plus one whose r is 0, which every signer skips.
+func TestSignFromPool(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	pools := make([]*PreSignPool, len(parties))
+	for i := range pools {
+		pools[i] = NewPreSignPool()
+	}
+	for n := 0; n < 3; n++ {
+		preSigs := runPreSign(t, parties, keyData, fmt.Sprintf("pool-presign-%d", n))
+		for i, preSig := range preSigs {
+			if n == 1 {
+				// Injected ahead of the second presignature
+				broken := &PreSignature{R: big.NewInt(0), Rx: preSig.Rx, Ry: preSig.Ry, Ki: preSig.Ki, SigmaI: preSig.SigmaI, PreSignSessionID: preSig.PreSignSessionID}
+				if err := pools[i].Add("broken", broken); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := pools[i].Add(fmt.Sprintf("presig-%d", n), preSig); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	net := tss.NewMemoryNetwork(parties)
+	defer net.Close()
+	sign := func(text string) ([]*Signature, []error) {
+		hash := sha256.Sum256([]byte(text))
+		sigs := make([]*Signature, len(parties))
+		errs := make([]error, len(parties))
+		var wg sync.WaitGroup
+		for i := range parties {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				params := &tss.Parameters{
+					PartyID:   parties[i],
+					Parties:   parties,
+					Threshold: 1,
+					Curve:     "secp256k1",
+					SessionID: []byte("pool-sign"),
+				}
+				sigs[i], errs[i] = SignFromPool(pools[i], params, keyData[i], hash[:], net.Transport(parties[i].ID()))
+			}(i)
+		}
+		wg.Wait()
+		return sigs, errs
+	}
+
+	pub := curves.NewSecp256k1().MarshalCompressed(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	seenR := make(map[string]bool)
+	for _, text := range []string{"first", "second", "third"} {
+		sigs, errs := sign(text)
+		hash := sha256.Sum256([]byte(text))
+		for i := range parties {
+			if errs[i] != nil {
+				t.Fatalf("%s message, party %d: %v", text, i, errs[i])
+			}
+			if valid, err := VerifyWithPubKeyBytes(pub, hash[:], sigs[i]); err != nil || !valid {
+				t.Fatalf("%s message, party %d: invalid signature: %v", text, i, err)
+			}
+		}
+		if seenR[sigs[0].R.String()] {
+			t.Fatalf("%s message signed with a presignature already used", text)
+		}
+		seenR[sigs[0].R.String()] = true
+	}
+
+	for i := range pools {
+		if pools[i].Len() != 0 {
+			t.Fatalf("Party %d: %d presignatures left, want 0", i, pools[i].Len())
+		}
+	}
+	_, errs := sign("fourth")
+	for i, err := range errs {
+		if !errors.Is(err, ErrPreSignPoolEmpty) {
+			t.Fatalf("Party %d: expected ErrPreSignPoolEmpty, got %v", i, err)
+		}
+	}
+}
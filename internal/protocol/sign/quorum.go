@@ -0,0 +1,35 @@
+package sign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// PublicKeyFromQuorum checks a quorum certificate, the IDs of the parties
+// that signed, against the key committee of saveData and returns the group
+// public key their signature must verify under. The quorum must consist of
+// at least saveData.Threshold+1 distinct committee members.
+//
+// Any committee member's key data works. The returned key is a copy.
+func PublicKeyFromQuorum(saveData *keygen.LocalPartySaveData, signerIDs []string) (x, y *big.Int, err error) {
+	if saveData == nil || saveData.PublicKeyX == nil || saveData.PublicKeyY == nil {
+		return nil, nil, fmt.Errorf("%w: key data has no public key", tss.ErrInvalidParameters)
+	}
+	seen := make(map[string]bool, len(signerIDs))
+	for _, id := range signerIDs {
+		if _, ok := saveData.ShareIDs[id]; !ok {
+			return nil, nil, fmt.Errorf("%w: quorum member %s is not in the committee", tss.ErrInvalidParameters, id)
+		}
+		if seen[id] {
+			return nil, nil, fmt.Errorf("%w: quorum lists %s twice", tss.ErrInvalidParameters, id)
+		}
+		seen[id] = true
+	}
+	if len(seen) < saveData.Threshold+1 {
+		return nil, nil, fmt.Errorf("%w: quorum of %d, need at least %d", tss.ErrInvalidParameters, len(seen), saveData.Threshold+1)
+	}
+	return new(big.Int).Set(saveData.PublicKeyX), new(big.Int).Set(saveData.PublicKeyY), nil
+}
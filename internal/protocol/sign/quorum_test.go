@@ -0,0 +1,44 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestPublicKeyFromQuorum(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	if keyData[0].Threshold != 1 {
+		t.Fatalf("KeyGen recorded threshold %d, want 1", keyData[0].Threshold)
+	}
+
+	x, y, err := PublicKeyFromQuorum(keyData[2], []string{"3", "1"})
+	if err != nil {
+		t.Fatalf("Valid quorum rejected: %v", err)
+	}
+	if x.Cmp(keyData[0].PublicKeyX) != 0 || y.Cmp(keyData[0].PublicKeyY) != 0 {
+		t.Fatal("Quorum returned a different public key")
+	}
+	x.SetInt64(1)
+	if keyData[2].PublicKeyX.Int64() == 1 {
+		t.Fatal("PublicKeyFromQuorum aliases the key data")
+	}
+
+	bad := map[string][]string{
+		"undersized":   {"2"},
+		"empty":        nil,
+		"duplicate":    {"2", "2"},
+		"not a member": {"1", "4"},
+	}
+	for name, quorum := range bad {
+		if _, _, err := PublicKeyFromQuorum(keyData[0], quorum); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("%s: expected ErrInvalidParameters, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// ErrRetrySign is returned when the nonces of a signing session turn out
+// unusable: r = 0 or delta = k * gamma not invertible. Every signer hits it
+// at the same point, as both values are common to the session, and nobody
+// is at fault; signing again with fresh nonces succeeds. Both cases are
+// astronomically rare with honest randomness.
+var ErrRetrySign = errors.New("signing must be retried with fresh nonces")
+
+// retrySessionPurpose separates the session IDs of retried attempts.
+const retrySessionPurpose = "sign-retry"
+
+// NewStateMachineWithRetry is NewStateMachine, restarting the session with
+// fresh nonces when it fails with ErrRetrySign, for at most maxAttempts
+// attempts in total. Attempt a > 1 runs under the session ID
+// tss.DeriveSessionID("sign-retry", Parties, SessionID || a), so all
+// signers agree on it without coordination and no message of one attempt
+// is taken for another. Every signer must use this constructor.
+func NewStateMachineWithRetry(params *tss.Parameters, keyData *keygen.LocalPartySaveData, msg []byte, maxAttempts int) (tss.StateMachine, []tss.Message, error) {
+	if maxAttempts < 1 {
+		return nil, nil, fmt.Errorf("%w: maxAttempts must be at least 1, got %d", tss.ErrInvalidParameters, maxAttempts)
+	}
+	if params == nil {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	r := &retryState{params: params, keyData: keyData, msg: msg, maxAttempts: maxAttempts}
+	out, err := r.start(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, out, nil
+}
+
+type retryState struct {
+	params      *tss.Parameters // As passed in; SessionID is that of attempt 1
+	keyData     *keygen.LocalPartySaveData
+	msg         []byte
+	maxAttempts int
+
+	attempt int
+	inner   tss.StateMachine
+	pending []tss.Message // Messages for the next attempt, replayed once it starts
+}
+
+// sessionID returns the session ID of the given attempt.
+func (r *retryState) sessionID(attempt int) []byte {
+	if attempt == 1 {
+		return r.params.SessionID
+	}
+	nonce := binary.BigEndian.AppendUint32(append([]byte(nil), r.params.SessionID...), uint32(attempt))
+	return tss.DeriveSessionID(retrySessionPurpose, r.params.Parties, nonce)
+}
+
+// start runs NewStateMachine for the given attempt and replays the messages
+// that arrived for it early.
+func (r *retryState) start(attempt int) ([]tss.Message, error) {
+	params := *r.params
+	params.SessionID = r.sessionID(attempt)
+	sm, out, err := NewStateMachine(&params, r.keyData, r.msg)
+	if err != nil {
+		return nil, err
+	}
+	r.attempt, r.inner = attempt, sm
+	pending := r.pending
+	r.pending = nil
+	next, out, err := tss.Replay(sm, out, pending)
+	if next != nil {
+		r.inner = next
+	}
+	return out, err
+}
+
+func (r *retryState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	if !bytes.Equal(msg.SessionID(), r.sessionID(r.attempt)) {
+		// Peers that already restarted may run one attempt ahead
+		if r.attempt < r.maxAttempts && bytes.Equal(msg.SessionID(), r.sessionID(r.attempt+1)) {
+			r.pending = append(r.pending, msg)
+			return r, nil, nil
+		}
+		for a := 1; a < r.attempt; a++ {
+			if bytes.Equal(msg.SessionID(), r.sessionID(a)) {
+				return r, nil, nil // Late message of an abandoned attempt
+			}
+		}
+	}
+
+	next, out, err := r.inner.Update(msg)
+	if errors.Is(err, ErrRetrySign) && r.attempt < r.maxAttempts {
+		out, err := r.start(r.attempt + 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r, out, nil
+	}
+	if next == nil {
+		return nil, out, err
+	}
+	r.inner = next
+	return r, out, err
+}
+
+func (r *retryState) Result() interface{} {
+	return r.inner.Result()
+}
+
+func (r *retryState) Details() string {
+	if r.attempt > 1 {
+		return fmt.Sprintf("%s (attempt %d)", r.inner.Details(), r.attempt)
+	}
+	return r.inner.Details()
+}
+
+func (r *retryState) WaitingFor() []tss.PartyID {
+	return tss.WaitingFor(r.inner)
+}
+
+func (r *retryState) RemainingForRound() int {
+	return tss.RemainingForRound(r.inner)
+}
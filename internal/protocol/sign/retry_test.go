@@ -0,0 +1,133 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// zeroNonceShares makes the nonce shares of the session with the given ID
+// add up to k = 0, so that delta = k * gamma is not invertible.
+func zeroNonceShares(t *testing.T, sessionID []byte) {
+	t.Helper()
+	orig := newNonceShare
+	t.Cleanup(func() { newNonceShare = orig })
+	newNonceShare = func(params *tss.Parameters, curve curves.Curve) (*big.Int, error) {
+		if !bytes.Equal(params.SessionID, sessionID) {
+			return orig(params, curve)
+		}
+		switch params.PartyID.ID() {
+		case "1":
+			return big.NewInt(5), nil
+		case "2":
+			return big.NewInt(7), nil
+		default:
+			return new(big.Int).Sub(curve.Params().N, big.NewInt(12)), nil
+		}
+	}
+}
+
+func TestSignRetry(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	hash := sha256.Sum256([]byte("hello world"))
+	sessionID := []byte("sign-session")
+	zeroNonceShares(t, sessionID)
+
+	t.Run("WithoutRetry", func(t *testing.T) {
+		sms, outMsgs := newSignSession(t, parties, keyData)
+		var err error
+		for r := 0; r < 4 && err == nil; r++ {
+			all := []tss.Message{}
+			for _, msgs := range outMsgs {
+				all = append(all, msgs...)
+			}
+			outMsgs = make([][]tss.Message, len(sms))
+			for i := range sms {
+				for _, msg := range all {
+					if !isRecipient(msg, parties[i]) {
+						continue
+					}
+					var out []tss.Message
+					sms[i], out, err = sms[i].Update(msg)
+					if err != nil {
+						break
+					}
+					outMsgs[i] = append(outMsgs[i], out...)
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+		if !errors.Is(err, ErrRetrySign) {
+			t.Fatalf("Expected ErrRetrySign, got %v", err)
+		}
+	})
+
+	t.Run("WithRetry", func(t *testing.T) {
+		coord := tss.NewLocalCoordinator()
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: sessionID,
+			}
+			sm, out, err := NewStateMachineWithRetry(params, keyData[i], hash[:], 2)
+			if err != nil {
+				t.Fatalf("Failed to create sign state machine: %v", err)
+			}
+			coord.Add(parties[i].ID(), sm, out)
+		}
+		results, err := coord.Run()
+		if err != nil {
+			t.Fatalf("Signing failed: %v", err)
+		}
+		pub := curves.NewSecp256k1().MarshalCompressed(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+		for id, res := range results {
+			sig := res.(*Signature)
+			if valid, err := VerifyWithPubKeyBytes(pub, hash[:], sig); err != nil || !valid {
+				t.Fatalf("Party %s: invalid signature after retry: %v", id, err)
+			}
+		}
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		coord := tss.NewLocalCoordinator()
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: sessionID,
+			}
+			sm, out, err := NewStateMachineWithRetry(params, keyData[i], hash[:], 1)
+			if err != nil {
+				t.Fatalf("Failed to create sign state machine: %v", err)
+			}
+			coord.Add(parties[i].ID(), sm, out)
+		}
+		if _, err := coord.Run(); !errors.Is(err, ErrRetrySign) {
+			t.Fatalf("Expected ErrRetrySign, got %v", err)
+		}
+	})
+
+	t.Run("InvalidAttempts", func(t *testing.T) {
+		params := &tss.Parameters{PartyID: parties[0], Parties: parties, Threshold: 1, SessionID: sessionID}
+		if _, _, err := NewStateMachineWithRetry(params, keyData[0], hash[:], 0); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+		}
+	})
+}
@@ -5,21 +5,44 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/polynomial"
+	range_proof "github.com/smallyu/go-cggmp-tss/internal/crypto/zk/range"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 type Round1Payload struct {
-	EncK    []byte // Paillier ciphertext of k_i
-	GammaX  []byte // Gamma_i X
-	GammaY  []byte // Gamma_i Y
+	EncK        []byte             // Paillier ciphertext of k_i
+	KProof      *range_proof.Proof // Range proof that EncK encrypts a value in [0, 2^kRangeBits)
+	GammaCommit []byte             // Commitment to Gamma_i, revealed in Round 2
+}
+
+// kRangeBits is the bit length bound proven for the encrypted nonce share k_i:
+// the bit length of the curve order (256 for secp256k1, 384 for P-384).
+func (s *state) kRangeBits() int {
+	return s.curve.Params().N.BitLen()
+}
+
+// newNonceShare draws the nonce share k_i. Tests replace it to force a
+// degenerate nonce; see ErrRetrySign.
+var newNonceShare = func(params *tss.Parameters, curve curves.Curve) (*big.Int, error) {
+	return curve.NewScalar()
 }
 
 func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
-	curve := curves.NewSecp256k1()
+	// Fail before any work if the MtA in round 2 could not run
+	if err := s.checkPaillierKeys(); err != nil {
+		return nil, nil, err
+	}
+	s.tempData["peerPaillierPks"] = s.precomputedPeerKeys()
+
+	curve := s.curve
 	
 	// 1. Generate k_i, gamma_i
-	ki, err := curve.NewScalar()
+	ki, err := newNonceShare(s.params, curve)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -36,27 +59,47 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 	if err != nil { return nil, nil, err }
 	
 	wi := new(big.Int).Mul(s.keyData.Xi, lambda)
+	if share := s.tweakShare(); share != nil {
+		wi.Add(wi, share)
+	}
 	wi.Mod(wi, curve.Params().N)
 	s.tempData["wi"] = wi
 
 	// 2. Encrypt k_i using our Paillier Key
 	// We use the Paillier key generated in KeyGen
-	encK, _, err := s.keyData.PaillierPk.Encrypt(ki)
+	encK, rK, err := s.keyData.PaillierPk.Encrypt(ki)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to encrypt k_i: %w", err)
 	}
 	s.tempData["encK"] = encK
+	s.tempData["rK"] = rK // Opened only if the nonce is burned, see identify
+
+	// Prove that EncK encrypts a value in range, so peers cannot be handed
+	// an oversized k_i that would bias the MtA shares.
+	kProof, err := range_proof.Prove(s.keyData.PaillierPk, encK, ki, rK, s.kRangeBits(), s.params.SessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prove range of k_i: %w", err)
+	}
 
 	// 3. Compute Gamma_i = gamma_i * G
 	Gx, Gy := curve.ScalarBaseMult(gammai)
 	s.tempData["GammaX"] = Gx
 	s.tempData["GammaY"] = Gy
 
-	// 4. Broadcast
+	// 4. Commit to Gamma_i
+	// Gamma_i is only revealed in Round 2, after everyone has committed,
+	// so a rushing party cannot choose its nonce based on the others'.
+	comm, err := commitment.New(gammaCommitData(curve, Gx, Gy))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit to Gamma_i: %w", err)
+	}
+	s.tempData["gamma_decommit"] = comm.D
+
+	// 5. Broadcast
 	payload := Round1Payload{
-		EncK:   encK.Bytes(),
-		GammaX: Gx.Bytes(),
-		GammaY: Gy.Bytes(),
+		EncK:        encK.Bytes(),
+		KProof:      kProof,
+		GammaCommit: comm.C,
 	}
 	
 	data, err := json.Marshal(payload)
@@ -71,61 +114,132 @@ func (s *state) round1() (tss.StateMachine, []tss.Message, error) {
 		Data:        data,
 		TypeString:  "SignRound1",
 		RoundNum:    1,
+		Session:    s.params.SessionID,
 	}
 
 	return s, []tss.Message{msg}, nil
 }
 
-func (s *state) calcLagrangeCoeffs() (*big.Int, error) {
-	curve := curves.NewSecp256k1()
-	N := curve.Params().N
-	
-	// Identify x-coordinates
-	// Assuming s.params.Parties matches KeyGen order and we use all of them.
-	// x_i = index + 1
-	
-	var myX *big.Int
-	allX := make([]*big.Int, len(s.params.Parties))
-	
-	for i, p := range s.params.Parties {
-		x := big.NewInt(int64(i + 1))
-		allX[i] = x
+// checkPaillierKeys checks that we hold our own Paillier key pair and a
+// Paillier key for every other signer, all of the size KeyGen generates.
+func (s *state) checkPaillierKeys() error {
+	if s.keyData == nil {
+		return fmt.Errorf("%w: missing key data", tss.ErrInvalidParameters)
+	}
+	own := s.keyData.PaillierPk
+	if own == nil || s.keyData.PaillierSk == nil || own.N == nil || s.keyData.PaillierSk.N == nil || own.N.Cmp(s.keyData.PaillierSk.N) != 0 {
+		return fmt.Errorf("%w: missing or mismatched own Paillier key pair", tss.ErrInvalidParameters)
+	}
+	if err := checkPaillierSize(own); err != nil {
+		return fmt.Errorf("%w: own Paillier key: %v", tss.ErrInvalidParameters, err)
+	}
+	for _, p := range s.params.Parties {
 		if p.ID() == s.params.PartyID.ID() {
-			myX = x
+			continue
+		}
+		pk := s.keyData.PeerPaillierPks[p.ID()]
+		if pk == nil || pk.N == nil {
+			return fmt.Errorf("%w: no Paillier key for signer %s", tss.ErrInvalidParameters, p.ID())
+		}
+		if err := checkPaillierSize(pk); err != nil {
+			return fmt.Errorf("%w: Paillier key of signer %s: %v", tss.ErrInvalidParameters, p.ID(), err)
 		}
 	}
-	
-	if myX == nil {
+	return nil
+}
+
+// precomputedPeerKeys returns the other signers' Paillier keys, with n^2
+// computed once here instead of on every MtA operation. The keys are copied,
+// so key data shared between concurrent sessions is never modified.
+func (s *state) precomputedPeerKeys() map[string]*paillier.PublicKey {
+	keys := make(map[string]*paillier.PublicKey, len(s.params.Parties)-1)
+	for _, p := range s.params.Parties {
+		if p.ID() == s.params.PartyID.ID() {
+			continue
+		}
+		keys[p.ID()] = paillier.NewPublicKey(s.keyData.PeerPaillierPks[p.ID()].N)
+	}
+	return keys
+}
+
+// checkPaillierSize accepts a modulus of paillier.KeyBits bits; the product
+// of two KeyBits/2-bit primes may be one bit shorter.
+func checkPaillierSize(pk *paillier.PublicKey) error {
+	if bits := pk.N.BitLen(); bits < paillier.KeyBits-1 || bits > paillier.KeyBits {
+		return fmt.Errorf("modulus is %d bits, expected %d", bits, paillier.KeyBits)
+	}
+	return nil
+}
+
+// gammaCommitData serializes Gamma_i with fixed-width coordinates so that
+// the committed and revealed encodings are always identical.
+func gammaCommitData(curve curves.Curve, x, y *big.Int) []byte {
+	size := curves.ByteSize(curve)
+	data := make([]byte, 2*size)
+	x.FillBytes(data[:size])
+	y.FillBytes(data[size:])
+	return data
+}
+
+func (s *state) calcLagrangeCoeffs() (*big.Int, error) {
+	weights, err := lagrangeWeights(s.curve, s.params.Parties, s.keyData)
+	if err != nil {
+		return nil, err
+	}
+	lambda, ok := weights[s.params.PartyID.ID()]
+	if !ok {
 		return nil, fmt.Errorf("party not found in list")
 	}
-	
-	num := big.NewInt(1)
-	den := big.NewInt(1)
-	
-	for _, x := range allX {
-		if x.Cmp(myX) == 0 { continue }
-		
-		// num = num * x
-		num.Mul(num, x)
-		num.Mod(num, N)
-		
-		// den = den * (x - myX)
-		diff := new(big.Int).Sub(x, myX)
-		diff.Mod(diff, N)
-		if diff.Sign() < 0 {
-			diff.Add(diff, N)
+	return lambda, nil
+}
+
+// tweakShare returns t/|S| for t = params.DerivationTweak, which each
+// signer adds to w_i so that sum(w_i) = x + t, or nil if untweaked.
+func (s *state) tweakShare() *big.Int {
+	t := s.params.DerivationTweak
+	if t == nil {
+		return nil
+	}
+	n := big.NewInt(int64(len(s.params.Parties)))
+	share := new(big.Int).ModInverse(n, s.curve.Params().N)
+	share.Mul(share, t)
+	return share.Mod(share, s.curve.Params().N)
+}
+
+// lagrangeWeights returns the Lagrange coefficient at x = 0 of each signer,
+// keyed by PartyID.ID().
+func lagrangeWeights(curve curves.Curve, signers []tss.PartyID, keyData *keygen.LocalPartySaveData) (map[string]*big.Int, error) {
+	// signers may be any t+1 subset of the committee, so use each signer's
+	// original keygen index rather than its position.
+	// Key data without ShareIDs numbered the committee x_i = index + 1, which
+	// signers reproduces only if the whole committee signs.
+	var indices map[string]*big.Int
+	if keyData != nil {
+		indices = keyData.ShareIDs
+	}
+	if indices == nil {
+		if keyData == nil || len(signers) < len(keyData.PeerPaillierPks)+1 {
+			return nil, fmt.Errorf("%w: key data without share indices can only sign with the whole committee", tss.ErrInvalidParameters)
 		}
-		den.Mul(den, diff)
-		den.Mod(den, N)
+		indices = keygen.ShareIndices(signers)
 	}
-	
-	denInv := new(big.Int).ModInverse(den, N)
-	if denInv == nil {
-		return nil, fmt.Errorf("failed to invert denominator")
+
+	allX := make([]*big.Int, len(signers))
+	for i, p := range signers {
+		x, ok := indices[p.ID()]
+		if !ok {
+			return nil, fmt.Errorf("signer %s is not part of the key committee", p.ID())
+		}
+		allX[i] = x
 	}
-	
-	lambda := new(big.Int).Mul(num, denInv)
-	lambda.Mod(lambda, N)
-	
-	return lambda, nil
+
+	weights := make(map[string]*big.Int, len(signers))
+	for i, p := range signers {
+		lambda := polynomial.LagrangeCoefficient(curve, allX[i], allX)
+		if lambda == nil {
+			return nil, fmt.Errorf("failed to invert denominator")
+		}
+		weights[p.ID()] = lambda
+	}
+	return weights, nil
 }
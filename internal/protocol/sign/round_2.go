@@ -2,42 +2,135 @@ package sign
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math/big"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/mta"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 type Round2Payload struct {
 	C_delta *big.Int
 	C_sigma *big.Int
+
+	// MtA proofs that C_delta/C_sigma were formed as EncK_j^x * E(beta) for
+	// x = gamma_i (bound to Gamma_i) and x = w_i (bound to W).
+	DeltaProof *MtAProofPayload
+	SigmaProof *MtAProofPayload
+	// W is W_i = w_i * G, compressed. Receivers check it against
+	// lambda_i * X_i + (t/|S|) * G when the key data records X_i.
+	W []byte
+}
+
+// MtAProofPayload is the wire form of an mta.Proof.
+type MtAProofPayload struct {
+	Z     *big.Int
+	U     []byte // Compressed U point
+	S     *big.Int
+	SBeta *big.Int
+	SR    *big.Int
+}
+
+func newMtAProofPayload(curve curves.Curve, p *mta.Proof) *MtAProofPayload {
+	return &MtAProofPayload{
+		Z:     p.Z,
+		U:     curve.MarshalCompressed(p.Ux, p.Uy),
+		S:     p.S,
+		SBeta: p.SBeta,
+		SR:    p.SR,
+	}
+}
+
+func (m *MtAProofPayload) proof(curve curves.Curve) (*mta.Proof, error) {
+	if m == nil {
+		return nil, fmt.Errorf("missing MtA proof")
+	}
+	Ux, Uy, err := curve.UnmarshalCompressed(m.U)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MtA proof U point: %w", err)
+	}
+	return &mta.Proof{
+		Z:     m.Z,
+		Ux:    Ux,
+		Uy:    Uy,
+		S:     m.S,
+		SBeta: m.SBeta,
+		SR:    m.SR,
+	}, nil
+}
+
+// Round2DecommitPayload reveals Gamma_i committed to in Round 1.
+type Round2DecommitPayload struct {
+	Salt   []byte
+	GammaX []byte
+	GammaY []byte
+
+	// DeltaHashes and SigmaHashes commit to the C_delta and C_sigma sent to
+	// each peer (by party ID), so the P2P ciphertexts can be checked by
+	// everyone during identification.
+	DeltaHashes map[string][]byte
+	SigmaHashes map[string][]byte
+}
+
+// ciphertextHash is the hash of a C_delta or C_sigma published in
+// DeltaHashes or SigmaHashes.
+func ciphertextHash(c *big.Int) []byte {
+	h := sha256.Sum256(c.Bytes())
+	return h[:]
 }
 
 func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
+	peerPks, _ := s.tempData["peerPaillierPks"].(map[string]*paillier.PublicKey)
+
 	// 1. Process Round 1 Messages
 	peerEncK := make(map[string]*big.Int)
-	peerGammaX := make(map[string]*big.Int)
-	peerGammaY := make(map[string]*big.Int)
+	peerGammaCommits := make(map[string][]byte)
 	
 	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 { continue }
 		var payload Round1Payload
 		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
-			return nil, nil, err
+			return nil, nil, tss.MalformedPayload(msgs[0], err)
+		}
+		encKj := new(big.Int).SetBytes(payload.EncK)
+
+		// Verify the range proof on EncK_j before using it in MtA
+		pkj := peerPks[id]
+		if pkj == nil {
+			return nil, nil, fmt.Errorf("missing paillier key for %s", id)
 		}
-		peerEncK[id] = new(big.Int).SetBytes(payload.EncK)
-		peerGammaX[id] = new(big.Int).SetBytes(payload.GammaX)
-		peerGammaY[id] = new(big.Int).SetBytes(payload.GammaY)
+		if err := pkj.ValidateCiphertext(encKj); err != nil {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "invalid EncK ciphertext", err)
+		}
+		if !payload.KProof.Verify(pkj, encKj, s.kRangeBits(), s.params.SessionID) {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "EncK range proof verification failed", nil)
+		}
+		peerEncK[id] = encKj
+		peerGammaCommits[id] = payload.GammaCommit
 	}
 	s.tempData["peerEncK"] = peerEncK
-	s.tempData["peerGammaX"] = peerGammaX
-	s.tempData["peerGammaY"] = peerGammaY
+	s.tempData["peerGammaCommits"] = peerGammaCommits
 
-	// 2. Perform MtA with each peer
 	var outMsgs []tss.Message
+
+	// 2. Perform MtA with each peer
+	gammai := s.tempData["gammai"].(*big.Int)
+	wi := s.tempData["wi"].(*big.Int)
+	GammaX := s.tempData["GammaX"].(*big.Int)
+	GammaY := s.tempData["GammaY"].(*big.Int)
+
+	curve := s.curve
+	Wx, Wy := curve.ScalarBaseMult(wi)
+	wBytes := curve.MarshalCompressed(Wx, Wy)
 	
 	betas := make(map[string]*big.Int)
+	betaNonces := make(map[string]*big.Int)
+	cDeltas := make(map[string]*big.Int)
+	cSigmas := make(map[string]*big.Int)
 	nus := make(map[string]*big.Int)
 	
 	for _, peer := range s.params.Parties {
@@ -47,41 +140,53 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 		
 		pid := peer.ID()
 		encKj := peerEncK[pid]
-		pkj := s.keyData.PeerPaillierPks[pid]
+		pkj := peerPks[pid]
 		if pkj == nil {
 			return nil, nil, fmt.Errorf("missing paillier key for %s", pid)
 		}
 		
 		// 2a. Compute C_delta_ij = EncK_j * gamma_i + Enc(beta_ij)
-		gammai := s.tempData["gammai"].(*big.Int)
-		
 		beta_ij, err := rand.Int(rand.Reader, pkj.N)
 		if err != nil { return nil, nil, err }
 		betas[pid] = beta_ij
 		
-		encBeta, _, err := pkj.Encrypt(beta_ij)
+		encBeta, rBeta, err := pkj.Encrypt(beta_ij)
 		if err != nil { return nil, nil, err }
+		betaNonces[pid] = rBeta
 		
 		term1 := pkj.Mul(encKj, gammai)
 		c_delta := pkj.Add(term1, encBeta)
+		cDeltas[pid] = c_delta
+
+		deltaProof, err := mta.Prove(curve, pkj, encKj, gammai, beta_ij, rBeta, GammaX, GammaY, s.params.SessionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to prove MtA for C_delta: %w", err)
+		}
 		
 		// 2b. Compute C_sigma_ij = EncK_j * w_i + Enc(nu_ij)
-		wi := s.tempData["wi"].(*big.Int)
-		
 		nu_ij, err := rand.Int(rand.Reader, pkj.N)
 		if err != nil { return nil, nil, err }
 		nus[pid] = nu_ij
 		
-		encNu, _, err := pkj.Encrypt(nu_ij)
+		encNu, rNu, err := pkj.Encrypt(nu_ij)
 		if err != nil { return nil, nil, err }
 		
 		term2 := pkj.Mul(encKj, wi)
 		c_sigma := pkj.Add(term2, encNu)
+		cSigmas[pid] = c_sigma
+
+		sigmaProof, err := mta.Prove(curve, pkj, encKj, wi, nu_ij, rNu, Wx, Wy, s.params.SessionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to prove MtA for C_sigma: %w", err)
+		}
 		
 		// Create Message
 		payload := Round2Payload{
-			C_delta: c_delta,
-			C_sigma: c_sigma,
+			C_delta:    c_delta,
+			C_sigma:    c_sigma,
+			DeltaProof: newMtAProofPayload(curve, deltaProof),
+			SigmaProof: newMtAProofPayload(curve, sigmaProof),
+			W:          wBytes,
 		}
 		data, err := json.Marshal(payload)
 		if err != nil { return nil, nil, err }
@@ -93,17 +198,58 @@ func (s *state) round2() (tss.StateMachine, []tss.Message, error) {
 			Data:      data,
 			TypeString: "SignRound2_MtA",
 			RoundNum:  2,
+			Session:    s.params.SessionID,
 		}
 		outMsgs = append(outMsgs, msg)
 	}
 	
 	s.tempData["betas"] = betas
+	s.tempData["betaNonces"] = betaNonces
+	s.tempData["cDeltas"] = cDeltas
+	s.tempData["cSigmas"] = cSigmas
+	s.tempData["WX"] = Wx
+	s.tempData["WY"] = Wy
 	s.tempData["nus"] = nus
+
+	// 3. Reveal Gamma_i, along with what we sent in the MtA
+	salt, ok := s.tempData["gamma_decommit"].([]byte)
+	if !ok {
+		return nil, nil, fmt.Errorf("missing Gamma_i decommitment")
+	}
+	decommit := Round2DecommitPayload{
+		Salt:   salt,
+		GammaX: s.tempData["GammaX"].(*big.Int).Bytes(),
+		GammaY: s.tempData["GammaY"].(*big.Int).Bytes(),
+
+		DeltaHashes: make(map[string][]byte, len(cDeltas)),
+		SigmaHashes: make(map[string][]byte, len(cSigmas)),
+	}
+	for pid, c := range cDeltas {
+		decommit.DeltaHashes[pid] = ciphertextHash(c)
+	}
+	for pid, c := range cSigmas {
+		decommit.SigmaHashes[pid] = ciphertextHash(c)
+	}
+	decommitData, err := json.Marshal(decommit)
+	if err != nil {
+		return nil, nil, err
+	}
+	outMsgs = append([]tss.Message{&SignMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  nil,
+		IsBcast:    true,
+		Data:       decommitData,
+		TypeString: "SignRound2_Decommit",
+		RoundNum:   2,
+		Session:    s.params.SessionID,
+	}}, outMsgs...)
 	
 	newState := &state{
 		params:       s.params,
+		curve:        s.curve,
 		keyData:      s.keyData,
 		msgToSign:    s.msgToSign,
+		tweak:        s.tweak,
 		round:        2,
 		tempData:     s.tempData,
 		receivedMsgs: make(map[string][]tss.Message),
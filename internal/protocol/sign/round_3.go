@@ -1,53 +1,178 @@
 package sign
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"math/big"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/commitment"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zk/logstar"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 type Round3Payload struct {
 	DeltaI *big.Int
+
+	// BigDelta is Delta_i = k_i * Gamma, compressed, with a proof that it
+	// uses the k_i in EncK_i. The Delta_j must sum to delta * G, otherwise
+	// R is wrong and the signers run identification instead of Round 4.
+	BigDelta      []byte
+	BigDeltaProof *LogStarProofPayload
+
+	// BigSigma is Sigma_i = sigma_i * Gamma, compressed (with the tweak
+	// folded into sigma_i in tweaked presigning). The Sigma_j must sum to
+	// delta * P for public key P, otherwise the signers run identification;
+	// then s_j * Gamma = m * Delta_j + r * Sigma_j pins down the s_j each
+	// signer must send in Round 4.
+	BigSigma []byte
+}
+
+// LogStarProofPayload is the wire form of a logstar.Proof.
+type LogStarProofPayload struct {
+	A  *big.Int
+	Y  []byte // Compressed Y point
+	Z1 *big.Int
+	Z2 *big.Int
+}
+
+func newLogStarProofPayload(curve curves.Curve, p *logstar.Proof) *LogStarProofPayload {
+	return &LogStarProofPayload{
+		A:  p.A,
+		Y:  curve.MarshalCompressed(p.Yx, p.Yy),
+		Z1: p.Z1,
+		Z2: p.Z2,
+	}
+}
+
+func (m *LogStarProofPayload) proof(curve curves.Curve) (*logstar.Proof, error) {
+	if m == nil {
+		return nil, fmt.Errorf("missing Delta proof")
+	}
+	Yx, Yy, err := curve.UnmarshalCompressed(m.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Delta proof Y point: %w", err)
+	}
+	return &logstar.Proof{
+		A:  m.A,
+		Yx: Yx,
+		Yy: Yy,
+		Z1: m.Z1,
+		Z2: m.Z2,
+	}, nil
 }
 
 func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	N := curve.Params().N
 
-	// 1. Process Round 2 Messages (MtA Responses)
-	// We expect 1 message from each peer containing C_delta, C_sigma
+	// 1. Process Round 2 Messages (Gamma reveal + MtA Responses)
+	// We expect from each peer a broadcast revealing Gamma_j and
+	// a P2P message containing C_delta, C_sigma
 	
 	alphas := make(map[string]*big.Int)
 	mus := make(map[string]*big.Int)
+	peerGammaCommits := s.tempData["peerGammaCommits"].(map[string][]byte)
+	peerGammaX := make(map[string]*big.Int)
+	peerGammaY := make(map[string]*big.Int)
+	peerDeltaHashes := make(map[string]map[string][]byte)
+	peerSigmaHashes := make(map[string]map[string][]byte)
+	peerCSigmas := make(map[string]*big.Int)
+	peerWX := make(map[string]*big.Int)
+	peerWY := make(map[string]*big.Int)
+	myPk := s.keyData.PaillierPk
+	myEncK := s.tempData["encK"].(*big.Int)
+	weights, err := lagrangeWeights(curve, s.params.Parties, s.keyData)
+	if err != nil {
+		return nil, nil, err
+	}
 	
 	for id, msgs := range s.receivedMsgs {
-		if len(msgs) == 0 { continue }
+		var decommitMsg, mtaMsg tss.Message
+		for _, m := range msgs {
+			if m.Type() == "SignRound2_Decommit" {
+				decommitMsg = m
+			} else if m.Type() == "SignRound2_MtA" {
+				mtaMsg = m
+			}
+		}
+		if decommitMsg == nil || mtaMsg == nil {
+			return nil, nil, fmt.Errorf("missing messages from party %s", id)
+		}
+		culprit := decommitMsg.From()
+
+		// Verify the revealed Gamma_j against the Round 1 commitment
+		var decommit Round2DecommitPayload
+		if err := json.Unmarshal(decommitMsg.Payload(), &decommit); err != nil {
+			return nil, nil, tss.MalformedPayload(decommitMsg, err)
+		}
+		gx := new(big.Int).SetBytes(decommit.GammaX)
+		gy := new(big.Int).SetBytes(decommit.GammaY)
+		if !curve.IsOnCurve(gx, gy) {
+			return nil, nil, tss.NewBlame(culprit, "revealed Gamma is not on curve", nil)
+		}
+		if !commitment.Verify(peerGammaCommits[id], decommit.Salt, gammaCommitData(curve, gx, gy)) {
+			return nil, nil, tss.NewBlame(culprit, "Gamma commitment verification failed", nil)
+		}
+		peerGammaX[id] = gx
+		peerGammaY[id] = gy
+		peerDeltaHashes[id] = decommit.DeltaHashes
+		peerSigmaHashes[id] = decommit.SigmaHashes
+
 		var payload Round2Payload
-		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
-			return nil, nil, err
+		if err := json.Unmarshal(mtaMsg.Payload(), &payload); err != nil {
+			return nil, nil, tss.MalformedPayload(mtaMsg, err)
 		}
 
-		// Find party ID
-		var culprit tss.PartyID
-		for _, p := range s.params.Parties {
-			if p.ID() == id {
-				culprit = p
-				break
+		// Verify the MtA proofs before decrypting anything
+		for _, c := range []*big.Int{payload.C_delta, payload.C_sigma} {
+			if c == nil || myPk.ValidateCiphertextStrict(c) != nil {
+				return nil, nil, tss.NewBlame(culprit, "invalid MtA ciphertext", nil)
 			}
 		}
+		deltaProof, err := payload.DeltaProof.proof(curve)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "malformed MtA proof for C_delta", err)
+		}
+		if !deltaProof.Verify(curve, myPk, myEncK, payload.C_delta, gx, gy, s.params.SessionID) {
+			return nil, nil, tss.NewBlame(culprit, "MtA proof for C_delta verification failed", nil)
+		}
+		Wx, Wy, err := curve.UnmarshalCompressed(payload.W)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "invalid W point", err)
+		}
+		if ex, ey := s.expectedW(weights, id); ex != nil && (ex.Cmp(Wx) != 0 || ey.Cmp(Wy) != 0) {
+			return nil, nil, tss.NewBlame(culprit, "W does not match the signer's weighted public share", tss.ErrInvalidMsg)
+		}
+		sigmaProof, err := payload.SigmaProof.proof(curve)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "malformed MtA proof for C_sigma", err)
+		}
+		if !sigmaProof.Verify(curve, myPk, myEncK, payload.C_sigma, Wx, Wy, s.params.SessionID) {
+			return nil, nil, tss.NewBlame(culprit, "MtA proof for C_sigma verification failed", nil)
+		}
+		// The C_delta we got must be the one the sender committed to publicly
+		if !bytes.Equal(decommit.DeltaHashes[s.params.PartyID.ID()], ciphertextHash(payload.C_delta)) {
+			return nil, nil, tss.NewBlame(culprit, "C_delta does not match its broadcast hash", tss.ErrInvalidMsg)
+		}
+		if !bytes.Equal(decommit.SigmaHashes[s.params.PartyID.ID()], ciphertextHash(payload.C_sigma)) {
+			return nil, nil, tss.NewBlame(culprit, "C_sigma does not match its broadcast hash", tss.ErrInvalidMsg)
+		}
+		peerCSigmas[id] = payload.C_sigma
+		peerWX[id] = Wx
+		peerWY[id] = Wy
 		
 		// Decrypt C_delta to get alpha_ij
 		// This is response to MY EncK_i. So I use MY Secret Key.
-		alpha, err := s.keyData.PaillierSk.Decrypt(payload.C_delta)
+		alpha, err := s.keyData.PaillierSk.DecryptBlinded(payload.C_delta)
 		if err != nil {
 			return nil, nil, tss.NewBlame(culprit, "failed to decrypt alpha", err)
 		}
 		alphas[id] = alpha
 		
 		// Decrypt C_sigma to get mu_ij
-		mu, err := s.keyData.PaillierSk.Decrypt(payload.C_sigma)
+		mu, err := s.keyData.PaillierSk.DecryptBlinded(payload.C_sigma)
 		if err != nil {
 			return nil, nil, tss.NewBlame(culprit, "failed to decrypt mu", err)
 		}
@@ -100,12 +225,35 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		}
 	}
 	
+	s.tempData["peerGammaX"] = peerGammaX
+	s.tempData["peerGammaY"] = peerGammaY
+	s.tempData["peerDeltaHashes"] = peerDeltaHashes
+	s.tempData["peerSigmaHashes"] = peerSigmaHashes
+	s.tempData["peerCSigmas"] = peerCSigmas
+	s.tempData["peerWX"] = peerWX
+	s.tempData["peerWY"] = peerWY
+	s.tempData["mus"] = mus
 	s.tempData["delta_i"] = delta_i
 	s.tempData["sigma_i"] = sigma_i
 
-	// 3. Broadcast delta_i
+	// 3. Compute Delta_i = k_i * Gamma and prove it matches EncK_i
+	GammaX, GammaY := s.gammaSum()
+	DeltaX, DeltaY := curve.ScalarMult(GammaX, GammaY, ki)
+	rK := s.tempData["rK"].(*big.Int)
+	deltaProof, err := logstar.Prove(curve, myPk, myEncK, ki, rK, GammaX, GammaY, DeltaX, DeltaY, s.params.SessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prove Delta_i: %w", err)
+	}
+
+	// 4. Compute Sigma_i = sigma_i * Gamma
+	SigmaX, SigmaY := curve.ScalarMult(GammaX, GammaY, s.tweakedSigma())
+
+	// 5. Broadcast delta_i, Delta_i and Sigma_i
 	payload := Round3Payload{
-		DeltaI: delta_i,
+		DeltaI:        delta_i,
+		BigDelta:      curve.MarshalCompressed(DeltaX, DeltaY),
+		BigDeltaProof: newLogStarProofPayload(curve, deltaProof),
+		BigSigma:      curve.MarshalCompressed(SigmaX, SigmaY),
 	}
 	data, err := json.Marshal(payload)
 	if err != nil { return nil, nil, err }
@@ -117,12 +265,15 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 		Data:      data,
 		TypeString: "SignRound3_Delta",
 		RoundNum:  3,
+		Session:    s.params.SessionID,
 	}
 	
 	newState := &state{
 		params:       s.params,
+		curve:        s.curve,
 		keyData:      s.keyData,
 		msgToSign:    s.msgToSign,
+		tweak:        s.tweak,
 		round:        3,
 		tempData:     s.tempData,
 		receivedMsgs: make(map[string][]tss.Message),
@@ -130,3 +281,49 @@ func (s *state) round3() (tss.StateMachine, []tss.Message, error) {
 
 	return newState, []tss.Message{msg}, nil
 }
+
+// expectedW returns W_j = lambda_j * X_j + (t/|S|) * G for signer id, or nil
+// if the key data does not record its public share X_j.
+func (s *state) expectedW(weights map[string]*big.Int, id string) (*big.Int, *big.Int) {
+	Xx, Xy := s.keyData.PeerXiX[id], s.keyData.PeerXiY[id]
+	if Xx == nil || Xy == nil {
+		return nil, nil
+	}
+	Wx, Wy := s.curve.ScalarMult(Xx, Xy, weights[id])
+	if share := s.tweakShare(); share != nil {
+		Tx, Ty := s.curve.ScalarBaseMult(share)
+		Wx, Wy = s.curve.Add(Wx, Wy, Tx, Ty)
+	}
+	return Wx, Wy
+}
+
+// keyTweak returns the total additive tweak t of the key we sign for,
+// P' = P + t*G, or nil if untweaked: in online signing the PreSignature's,
+// otherwise params.DerivationTweak (folded into w_i) plus the presigning
+// tweak (folded into sigma_i).
+func (s *state) keyTweak() *big.Int {
+	if s.preSignature != nil {
+		return s.preSignature.Tweak
+	}
+	t := s.params.DerivationTweak
+	if s.tweak == nil {
+		return t
+	}
+	if t == nil {
+		return s.tweak
+	}
+	sum := new(big.Int).Add(t, s.tweak)
+	return sum.Mod(sum, s.curve.Params().N)
+}
+
+// tweakedSigma returns sigma_i, with the tweak folded in when presigning for
+// a tweaked key: sum(sigma_i + t*k_i) = k*(x + t).
+func (s *state) tweakedSigma() *big.Int {
+	sigma_i := s.tempData["sigma_i"].(*big.Int)
+	if s.tweak == nil {
+		return sigma_i
+	}
+	tk := new(big.Int).Mul(s.tweak, s.tempData["ki"].(*big.Int))
+	tk.Add(tk, sigma_i)
+	return tk.Mod(tk, s.curve.Params().N)
+}
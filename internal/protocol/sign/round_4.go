@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
@@ -14,74 +14,133 @@ type Round4Payload struct {
 }
 
 func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	N := curve.Params().N
 
-	// 1. Process Round 3 Messages (Delta_j)
+	// 1. Process Round 3 Messages (delta_j, Delta_j)
+	GammaX, GammaY := s.gammaSum()
+	peerPks := s.tempData["peerPaillierPks"].(map[string]*paillier.PublicKey)
+	peerEncK := s.tempData["peerEncK"].(map[string]*big.Int)
+
 	delta := new(big.Int).Set(s.tempData["delta_i"].(*big.Int))
-	
-	for _, msgs := range s.receivedMsgs {
+	ki := s.tempData["ki"].(*big.Int)
+	sumDeltaX, sumDeltaY := curve.ScalarMult(GammaX, GammaY, ki)
+	peerDeltas := make(map[string]*big.Int)
+	peerBigDeltas := make(map[string][]byte)
+	peerBigSigmas := make(map[string][]byte)
+	var sumSigmaX, sumSigmaY *big.Int
+
+	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 { continue }
+		culprit := msgs[0].From()
 		var payload Round3Payload
 		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
-			return nil, nil, err
+			return nil, nil, tss.MalformedPayload(msgs[0], err)
+		}
+		if payload.DeltaI == nil || payload.DeltaI.Sign() < 0 || payload.DeltaI.Cmp(N) >= 0 {
+			return nil, nil, tss.NewBlame(culprit, "delta_i out of range", tss.ErrInvalidMsg)
+		}
+		Dx, Dy, err := curve.UnmarshalCompressed(payload.BigDelta)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "invalid Delta_i point", err)
+		}
+		proof, err := payload.BigDeltaProof.proof(curve)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "malformed Delta_i proof", err)
+		}
+		if !proof.Verify(curve, peerPks[id], peerEncK[id], GammaX, GammaY, Dx, Dy, s.params.SessionID) {
+			return nil, nil, tss.NewBlame(culprit, "Delta_i proof verification failed", tss.ErrInvalidMsg)
+		}
+		Sx, Sy, err := curve.UnmarshalCompressed(payload.BigSigma)
+		if err != nil {
+			return nil, nil, tss.NewBlame(culprit, "invalid Sigma_i point", err)
+		}
+		peerDeltas[id] = payload.DeltaI
+		peerBigDeltas[id] = payload.BigDelta
+		peerBigSigmas[id] = payload.BigSigma
+		sumDeltaX, sumDeltaY = curve.Add(sumDeltaX, sumDeltaY, Dx, Dy)
+		if sumSigmaX == nil {
+			sumSigmaX, sumSigmaY = Sx, Sy
+		} else {
+			sumSigmaX, sumSigmaY = curve.Add(sumSigmaX, sumSigmaY, Sx, Sy)
 		}
+
 		delta.Add(delta, payload.DeltaI)
 		delta.Mod(delta, N)
 	}
-	
-	// 2. Compute R = delta^-1 * Gamma
-	// Gamma = sum(Gamma_j)
-	
-	// Start with own Gamma_i
-	GammaX := s.tempData["GammaX"].(*big.Int)
-	GammaY := s.tempData["GammaY"].(*big.Int)
-	
-	peerGammaX := s.tempData["peerGammaX"].(map[string]*big.Int)
-	peerGammaY := s.tempData["peerGammaY"].(map[string]*big.Int)
-	
-	for id := range peerGammaX {
-		gx := peerGammaX[id]
-		gy := peerGammaY[id]
-		GammaX, GammaY = curve.Add(GammaX, GammaY, gx, gy)
+	s.tempData["peerDeltas"] = peerDeltas
+	s.tempData["peerBigDeltas"] = peerBigDeltas
+	s.tempData["peerBigSigmas"] = peerBigSigmas
+
+	// 2. Check delta * G = sum(Delta_j) = k * Gamma before any s_i is
+	// released. Otherwise some delta_j is wrong: the nonce is burned, and the
+	// signers open their shares to find out whose.
+	dGx, dGy := curve.ScalarBaseMult(delta)
+	if dGx.Cmp(sumDeltaX) != 0 || dGy.Cmp(sumDeltaY) != 0 {
+		return s.startIdentification()
+	}
+
+	// Likewise sum(Sigma_j) = sigma * Gamma = delta * P; only then does the
+	// check on each s_j in Round 5 identify a bad one. If not, some Sigma_j
+	// is wrong, and identification finds whose.
+	Sx, Sy := curve.ScalarMult(GammaX, GammaY, s.tweakedSigma())
+	if sumSigmaX != nil {
+		Sx, Sy = curve.Add(Sx, Sy, sumSigmaX, sumSigmaY)
+	}
+	pkX, pkY := s.keyData.PublicKeyX, s.keyData.PublicKeyY
+	if t := s.keyTweak(); t != nil {
+		var err error
+		pkX, pkY, err = tweakPublicKey(curve, pkX, pkY, t)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	dPx, dPy := curve.ScalarMult(pkX, pkY, delta)
+	if dPx.Cmp(Sx) != 0 || dPy.Cmp(Sy) != 0 {
+		return s.startIdentification()
 	}
+
+	// 3. Compute R = delta^-1 * Gamma
 	
 	// delta^-1
 	deltaInv := new(big.Int).ModInverse(delta, N)
 	if deltaInv == nil {
-		return nil, nil, fmt.Errorf("delta is not invertible")
+		return nil, nil, fmt.Errorf("%w: delta is not invertible", ErrRetrySign)
 	}
 	
 	// R = delta^-1 * Gamma
 	Rx, Ry := curve.ScalarMult(GammaX, GammaY, deltaInv)
 	
-	r := Rx
-	r.Mod(r, N)
+	// r = R.x mod N; Rx itself is kept as the point's coordinate
+	r := new(big.Int).Mod(Rx, N)
 	if r.Sign() == 0 {
-		return nil, nil, fmt.Errorf("calculated r is 0, retry signing")
+		return nil, nil, fmt.Errorf("%w: calculated r is 0", ErrRetrySign)
 	}
 
 	if s.msgToSign == nil {
 		// Pre-signing mode: Stop here and return PreSignature
+		sigma_i := s.tweakedSigma()
 		preSig := &PreSignature{
 			R:      r,
 			Rx:     Rx,
 			Ry:     Ry,
-			Ki:     s.tempData["ki"].(*big.Int),
-			SigmaI: s.tempData["sigma_i"].(*big.Int),
+			Ki:     new(big.Int).Set(ki),
+			SigmaI: new(big.Int).Set(sigma_i),
+			Tweak:  s.keyTweak(),
+
+			PreSignSessionID: append([]byte(nil), s.params.SessionID...),
+		}
+		if err := preSig.checkR(curve); err != nil {
+			return nil, nil, err
 		}
+		s.zeroizeSecrets()
 		return &finishedState{preSignature: preSig}, nil, nil
 	}
 	
-	// 3. Compute s_i = m * k_i + r * sigma_i
+	// 4. Compute s_i = m * k_i + r * sigma_i
 	// m is hash of message
-	m := new(big.Int).SetBytes(s.msgToSign)
-	// Truncate m if longer than N?
-	// Usually we assume m is already hashed and mod N or similar.
-	// Standard ECDSA: z = hash(msg), if z > N, truncate.
-	// Here we assume msgToSign is the digest.
+	m := hashToInt(s.curve, s.msgToSign)
 	
-	ki := s.tempData["ki"].(*big.Int)
 	sigma_i := s.tempData["sigma_i"].(*big.Int)
 	
 	// term1 = m * k_i
@@ -100,7 +159,7 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	s.tempData["Rx"] = Rx
 	s.tempData["Ry"] = Ry
 
-	// 4. Broadcast s_i
+	// 5. Broadcast s_i
 	payload := Round4Payload{
 		Si: si,
 	}
@@ -114,6 +173,7 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 		Data:      data,
 		TypeString: "SignRound4_Si",
 		RoundNum:  4,
+		Session:    s.params.SessionID,
 	}
 	
 	// We need a final step to aggregate s_i
@@ -127,6 +187,7 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 	
 	newState := &state{
 		params:       s.params,
+		curve:        s.curve,
 		keyData:      s.keyData,
 		msgToSign:    s.msgToSign,
 		round:        4,
@@ -136,3 +197,15 @@ func (s *state) round4() (tss.StateMachine, []tss.Message, error) {
 
 	return newState, []tss.Message{msg}, nil
 }
+
+// gammaSum returns Gamma = sum(Gamma_j) over all signers, ourselves included.
+func (s *state) gammaSum() (*big.Int, *big.Int) {
+	GammaX := s.tempData["GammaX"].(*big.Int)
+	GammaY := s.tempData["GammaY"].(*big.Int)
+	peerGammaX := s.tempData["peerGammaX"].(map[string]*big.Int)
+	peerGammaY := s.tempData["peerGammaY"].(map[string]*big.Int)
+	for id := range peerGammaX {
+		GammaX, GammaY = s.curve.Add(GammaX, GammaY, peerGammaX[id], peerGammaY[id])
+	}
+	return GammaX, GammaY
+}
@@ -5,63 +5,159 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/decred/dcrd/dcrec/secp256k1/v4"
-	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 func (s *state) round5() (tss.StateMachine, []tss.Message, error) {
-	curve := curves.NewSecp256k1()
+	curve := s.curve
 	N := curve.Params().N
 
 	// 1. Process Round 4 Messages (s_j)
 	si := s.tempData["si"].(*big.Int)
 	finalS := new(big.Int).Set(si)
+	peerS := make(map[string]*big.Int)
 	
-	for _, msgs := range s.receivedMsgs {
+	for id, msgs := range s.receivedMsgs {
 		if len(msgs) == 0 { continue }
 		var payload Round4Payload
 		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
-			return nil, nil, err
+			return nil, nil, tss.MalformedPayload(msgs[0], err)
+		}
+		if payload.Si == nil || payload.Si.Sign() < 0 || payload.Si.Cmp(N) >= 0 {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "s_i out of range", tss.ErrInvalidMsg)
 		}
+		peerS[id] = payload.Si
 		finalS.Add(finalS, payload.Si)
 		finalS.Mod(finalS, N)
 	}
 	
 	// 2. Verify Signature (r, s)
 	r := s.tempData["r"].(*big.Int)
+	if s.preSignature != nil && r.Cmp(s.preSignature.R) != 0 {
+		// Online signing must use the nonce announced in Round 0
+		return nil, nil, fmt.Errorf("%w: online r does not match the presignature", tss.ErrInvalidMsg)
+	}
 	
 	// Construct Signature
 	signature := &Signature{
 		R: r,
 		S: finalS,
 	}
+	for _, p := range s.params.Parties {
+		signature.Signers = append(signature.Signers, p.ID())
+	}
 	
 	// Verify using standard ECDSA verification
 	// We need the global public key
 	pkX := s.keyData.PublicKeyX
 	pkY := s.keyData.PublicKeyY
+	if t := s.keyTweak(); t != nil {
+		// We signed for the tweaked key P + t*G
+		var err error
+		pkX, pkY, err = tweakPublicKey(curve, pkX, pkY, t)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	
-	// Use secp256k1 library to verify
-	var fx, fy secp256k1.FieldVal
-	fx.SetByteSlice(pkX.Bytes())
-	fy.SetByteSlice(pkY.Bytes())
-	pk := secp256k1.NewPublicKey(&fx, &fy)
-	
-	// Parse Signature
-	var rMod, sMod secp256k1.ModNScalar
-	rMod.SetByteSlice(r.Bytes())
-	sMod.SetByteSlice(finalS.Bytes())
-	
-	sig := ecdsa.NewSignature(&rMod, &sMod)
-	
-	// Verify
-	// ecdsa.Verify expects hash as []byte
-	if !sig.Verify(s.msgToSign, pk) {
-		return nil, nil, fmt.Errorf("signature verification failed")
+	if !verifyECDSA(curve, pkX, pkY, s.msgToSign, r, finalS) {
+		if err := s.blameBadShare(peerS); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: signature verification failed", tss.ErrInvalidMsg)
+	}
+
+	// Recovery ID from the nonce point, checked to recover the key we signed for
+	signature.RecID = recoveryID(curve, s.tempData["Rx"].(*big.Int), s.tempData["Ry"].(*big.Int))
+	if err := verifyRecoverable(curve, pkX, pkY, signature, s.msgToSign); err != nil {
+		return nil, nil, fmt.Errorf("signature self-check: %w", err)
 	}
 	
 	// Success!
+	s.zeroizeSecrets()
 	return &finishedState{signature: signature}, nil, nil
 }
+
+// blameBadShare finds a signer whose s_j breaks s_j * Gamma = m * Delta_j +
+// r * Sigma_j, with Delta_j and Sigma_j from Round 3. Round 4 checked that
+// these points add up, so a signature that fails verification has at least
+// one such s_j. Online signing has no Round 3 points and returns nil.
+func (s *state) blameBadShare(peerS map[string]*big.Int) error {
+	peerBigDeltas, ok := s.tempData["peerBigDeltas"].(map[string][]byte)
+	if !ok {
+		return nil
+	}
+	peerBigSigmas := s.tempData["peerBigSigmas"].(map[string][]byte)
+	curve := s.curve
+	GammaX, GammaY := s.gammaSum()
+	m := hashToInt(curve, s.msgToSign)
+	r := s.tempData["r"].(*big.Int)
+
+	for _, p := range s.params.Parties {
+		sj, ok := peerS[p.ID()]
+		if !ok {
+			continue
+		}
+		Dx, Dy, err := curve.UnmarshalCompressed(peerBigDeltas[p.ID()])
+		if err != nil {
+			return err
+		}
+		Sx, Sy, err := curve.UnmarshalCompressed(peerBigSigmas[p.ID()])
+		if err != nil {
+			return err
+		}
+		lhsX, lhsY := curve.ScalarMult(GammaX, GammaY, sj)
+		mDx, mDy := curve.ScalarMult(Dx, Dy, m)
+		rSx, rSy := curve.ScalarMult(Sx, Sy, r)
+		rhsX, rhsY := curve.Add(mDx, mDy, rSx, rSy)
+		if lhsX.Cmp(rhsX) != 0 || lhsY.Cmp(rhsY) != 0 {
+			return tss.NewBlame(p, "s_i does not match Delta_i and Sigma_i", tss.ErrInvalidMsg)
+		}
+	}
+	return nil
+}
+
+// verifyECDSA checks (r, s) over the digest against public key P on curve.
+// The digest is interpreted as an integer exactly as in the signing rounds.
+func verifyECDSA(curve curves.Curve, pkX, pkY *big.Int, digest []byte, r, s *big.Int) bool {
+	N := curve.Params().N
+	if r.Sign() <= 0 || r.Cmp(N) >= 0 || s.Sign() <= 0 || s.Cmp(N) >= 0 {
+		return false
+	}
+	if !curve.IsOnCurve(pkX, pkY) {
+		return false
+	}
+
+	// u1 = m * s^-1, u2 = r * s^-1
+	sInv := new(big.Int).ModInverse(s, N)
+	m := hashToInt(curve, digest)
+	u1 := new(big.Int).Mul(m, sInv)
+	u1.Mod(u1, N)
+	u2 := new(big.Int).Mul(r, sInv)
+	u2.Mod(u2, N)
+
+	// R' = u1*G + u2*P; valid iff R'.x mod N == r
+	x1, y1 := curve.ScalarBaseMult(u1)
+	x2, y2 := curve.ScalarMult(pkX, pkY, u2)
+	x, y := curve.Add(x1, y1, x2, y2)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	return x.Mod(x, N).Cmp(r) == 0
+}
+
+// TweakPublicKey returns the tweaked secp256k1 public key P' = P + tweak*G.
+func TweakPublicKey(pkX, pkY, tweak *big.Int) (*big.Int, *big.Int, error) {
+	return tweakPublicKey(curves.NewSecp256k1(), pkX, pkY, tweak)
+}
+
+// tweakPublicKey returns P' = P + tweak*G on curve.
+func tweakPublicKey(curve curves.Curve, pkX, pkY, tweak *big.Int) (*big.Int, *big.Int, error) {
+	tx, ty := curve.ScalarBaseMult(tweak)
+	x, y := curve.Add(pkX, pkY, tx, ty)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, nil, fmt.Errorf("tweaked public key is the point at infinity")
+	}
+	return x, y, nil
+}
@@ -1,15 +1,61 @@
 package sign
 
 import (
+	"bytes"
 	"encoding/json"
 	"math/big"
 
-	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
+// OnlinePreSignPayload identifies the presignature a signer is about to use.
+type OnlinePreSignPayload struct {
+	PreSignSessionID []byte
+	R                *big.Int
+}
+
+// roundOnline0 broadcasts which presignature we hold. s_i is only released
+// once every signer is known to hold a presignature from the same presign
+// session: s_i computed over a mismatched presignature is wasted, and that
+// presignature must not be used again.
+func (s *state) roundOnline0() (tss.StateMachine, []tss.Message, error) {
+	payload := OnlinePreSignPayload{
+		PreSignSessionID: s.preSignature.PreSignSessionID,
+		R:                s.preSignature.R,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := &SignMessage{
+		FromParty:  s.params.PartyID,
+		ToParties:  nil, // Broadcast
+		IsBcast:    true,
+		Data:       data,
+		TypeString: "SignOnline_PreSign",
+		RoundNum:   0,
+		Session:    s.params.SessionID,
+	}
+	return s, []tss.Message{msg}, nil
+}
+
 func (s *state) roundOnline1() (tss.StateMachine, []tss.Message, error) {
-	curve := curves.NewSecp256k1()
+	// Every signer must hold a presignature from our presign session
+	for _, msgs := range s.receivedMsgs {
+		var payload OnlinePreSignPayload
+		if err := json.Unmarshal(msgs[0].Payload(), &payload); err != nil {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "malformed presignature announcement", err)
+		}
+		if !bytes.Equal(payload.PreSignSessionID, s.preSignature.PreSignSessionID) {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "presignature from a different presign session", tss.ErrInvalidMsg)
+		}
+		if payload.R == nil || payload.R.Cmp(s.preSignature.R) != 0 {
+			return nil, nil, tss.NewBlame(msgs[0].From(), "presignature has a different R", tss.ErrInvalidMsg)
+		}
+	}
+
+	curve := s.curve
 	N := curve.Params().N
 
 	// Populate tempData for round5
@@ -18,7 +64,7 @@ func (s *state) roundOnline1() (tss.StateMachine, []tss.Message, error) {
 	s.tempData["Ry"] = s.preSignature.Ry
 
 	// Compute s_i = m * k_i + r * sigma_i
-	m := new(big.Int).SetBytes(s.msgToSign)
+	m := hashToInt(curve, s.msgToSign)
 	
 	ki := s.preSignature.Ki
 	sigma_i := s.preSignature.SigmaI
@@ -54,7 +100,18 @@ func (s *state) roundOnline1() (tss.StateMachine, []tss.Message, error) {
 		Data:       data,
 		TypeString: "SignRound4", // Reuse existing type string so round5 can process it
 		RoundNum:   4,            // Reuse existing round number
+		Session:    s.params.SessionID,
 	}
 
-	return s, []tss.Message{msg}, nil
+	newState := &state{
+		params:       s.params,
+		curve:        s.curve,
+		keyData:      s.keyData,
+		msgToSign:    s.msgToSign,
+		preSignature: s.preSignature,
+		round:        4,
+		tempData:     s.tempData,
+		receivedMsgs: make(map[string][]tss.Message),
+	}
+	return newState, []tss.Message{msg}, nil
 }
@@ -0,0 +1,108 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestGammaRevealEquivocation(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	hash := sha256.Sum256([]byte("hello world"))
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := 0; i < 3; i++ {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-session"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+
+	// Round 1: commitments to Gamma_i
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 equivocates: it reveals a Gamma different from the one it committed to.
+	var tampered []tss.Message
+	for _, msg := range outMsgs[1] {
+		if msg.Type() != "SignRound2_Decommit" {
+			tampered = append(tampered, msg)
+			continue
+		}
+		var payload Round2DecommitPayload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		curve := secp256k1.S256()
+		payload.GammaX = curve.Gx.Bytes()
+		payload.GammaY = curve.Gy.Bytes()
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := *msg.(*SignMessage)
+		m.Data = data
+		tampered = append(tampered, &m)
+	}
+
+	// Party 3 (honest view) must accept the untampered reveal.
+	for _, msgs := range [][]tss.Message{outMsgs[0], outMsgs[1]} {
+		for _, msg := range msgs {
+			if !isRecipient(msg, parties[2]) {
+				continue
+			}
+			next, _, err := sms[2].Update(msg)
+			if err != nil {
+				t.Fatalf("Honest reveal rejected: %v", err)
+			}
+			sms[2] = next
+		}
+	}
+	if sms[2].Details() != "Sign Round 3" {
+		t.Fatalf("Expected party 3 to reach round 3, got %s", sms[2].Details())
+	}
+
+	// Party 1 receives the equivocated reveal and must blame party 2.
+	var err error
+	for _, msgs := range [][]tss.Message{tampered, outMsgs[2]} {
+		for _, msg := range msgs {
+			if !isRecipient(msg, parties[0]) {
+				continue
+			}
+			var next tss.StateMachine
+			next, _, err = sms[0].Update(msg)
+			if err != nil {
+				break
+			}
+			sms[0] = next
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	var blame *tss.Blame
+	if !errors.As(err, &blame) {
+		t.Fatalf("Expected blame error, got %v", err)
+	}
+	if blame.PartyID.ID() != "2" {
+		t.Fatalf("Expected blame on party 2, got %s", blame.PartyID.ID())
+	}
+}
@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignDerivationTweak signs for the child key P + t*G, once in full
+// signing and once through presigning.
+func TestSignDerivationTweak(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	th := sha256.Sum256([]byte("m/44'/0'/0'/0/7"))
+	tweak := new(big.Int).SetBytes(th[:])
+	tweak.Mod(tweak, secp256k1.S256().N)
+	childX, childY, err := TweakPublicKey(keyData[0].PublicKeyX, keyData[0].PublicKeyY, tweak)
+	if err != nil {
+		t.Fatalf("TweakPublicKey failed: %v", err)
+	}
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(childX.Bytes())
+	fy.SetByteSlice(childY.Bytes())
+	childPk := secp256k1.NewPublicKey(&fx, &fy)
+
+	newParams := func(i int, session string) *tss.Parameters {
+		return &tss.Parameters{
+			PartyID:         parties[i],
+			Parties:         parties,
+			Threshold:       1,
+			Curve:           "secp256k1",
+			SessionID:       []byte(session),
+			DerivationTweak: tweak,
+		}
+	}
+	verify := func(sms []tss.StateMachine, hash []byte) {
+		t.Helper()
+		for i := range sms {
+			sig, ok := sms[i].Result().(*Signature)
+			if !ok {
+				t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+			}
+			var r, s secp256k1.ModNScalar
+			r.SetByteSlice(sig.R.Bytes())
+			s.SetByteSlice(sig.S.Bytes())
+			if !ecdsa.NewSignature(&r, &s).Verify(hash, childPk) {
+				t.Fatalf("Party %d: signature does not verify under the child key", i)
+			}
+		}
+	}
+
+	// 1. Full signing, two of three signers
+	signers := parties[:2]
+	hash := sha256.Sum256([]byte("pay from child key"))
+	sms := make([]tss.StateMachine, 2)
+	outMsgs := make([][]tss.Message, 2)
+	for i := range signers {
+		params := newParams(i, "sign-derive")
+		params.Parties = signers
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, signers, sms, outMsgs)
+	}
+	verify(sms, hash[:])
+
+	// 2. The tweak is fixed when presigning; online signing cannot add one
+	sms = make([]tss.StateMachine, 3)
+	outMsgs = make([][]tss.Message, 3)
+	for i := range parties {
+		sms[i], outMsgs[i], err = NewPreSignStateMachine(newParams(i, "presign-derive"), keyData[i])
+		if err != nil {
+			t.Fatalf("Failed to create presign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 4; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	hash = sha256.Sum256([]byte("presigned child payment"))
+	for i := range parties {
+		preSig, ok := sms[i].Result().(*PreSignature)
+		if !ok {
+			t.Fatalf("Party %d did not finish presigning: %s", i, sms[i].Details())
+		}
+		if _, _, err := NewOnlineStateMachine(newParams(i, "online-derive"), keyData[i], preSig, hash[:]); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("Expected ErrInvalidParameters for an online tweak, got %v", err)
+		}
+		params := newParams(i, "online-derive")
+		params.DerivationTweak = nil
+		sms[i], outMsgs[i], err = NewOnlineStateMachine(params, keyData[i], preSig, hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create online state machine: %v", err)
+		}
+	}
+	for r := 0; r < 2; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	verify(sms, hash[:])
+
+	// 3. Out-of-range tweaks
+	for _, bad := range []*big.Int{big.NewInt(0), secp256k1.S256().N} {
+		params := newParams(0, "sign-derive-bad")
+		params.DerivationTweak = bad
+		if _, _, err := NewStateMachine(params, keyData[0], hash[:]); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Fatalf("Expected ErrInvalidParameters for tweak %v, got %v", bad, err)
+		}
+	}
+}
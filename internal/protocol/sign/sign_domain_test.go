@@ -0,0 +1,75 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignDomainTag signs under tag A and checks that the signature only
+// verifies against the digest tagged with A.
+func TestSignDomainTag(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	keyData := runKeyGen(t, parties, 1)
+	digest := sha256.Sum256([]byte("transfer 10 coins"))
+	tagA, tagB := []byte("app-A/v1"), []byte("app-B/v1")
+
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-domain-tag"),
+			DomainTag: tagA,
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], digest[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	sig, ok := sms[0].Result().(*Signature)
+	if !ok {
+		t.Fatalf("Expected Signature, got %T", sms[0].Result())
+	}
+
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(keyData[0].PublicKeyX.Bytes())
+	fy.SetByteSlice(keyData[0].PublicKeyY.Bytes())
+	pk := secp256k1.NewPublicKey(&fx, &fy)
+	var r, s secp256k1.ModNScalar
+	r.SetByteSlice(sig.R.Bytes())
+	s.SetByteSlice(sig.S.Bytes())
+	ecSig := ecdsa.NewSignature(&r, &s)
+
+	if !ecSig.Verify(DomainDigest(tagA, digest[:]), pk) {
+		t.Fatal("Signature does not verify under its own tag")
+	}
+	if ecSig.Verify(DomainDigest(tagB, digest[:]), pk) {
+		t.Fatal("Signature made under tag A verifies under tag B")
+	}
+	if ecSig.Verify(digest[:], pk) {
+		t.Fatal("Signature made under tag A verifies without a tag")
+	}
+}
+
+func TestDomainDigest(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	if got := DomainDigest(nil, digest[:]); !bytes.Equal(got, digest[:]) {
+		t.Fatal("An empty tag must leave the digest unchanged")
+	}
+	a, b := DomainDigest([]byte("A"), digest[:]), DomainDigest([]byte("B"), digest[:])
+	if len(a) != sha256.Size || bytes.Equal(a, b) {
+		t.Fatal("Different tags must give different 32-byte digests")
+	}
+}
@@ -0,0 +1,59 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignDuplicateMessages delivers every message twice, as an
+// at-least-once transport might. Retransmissions must be ignored.
+// The last batch is delivered once: a finished machine reports
+// tss.ErrProtocolDone for anything it receives.
+func TestSignDuplicateMessages(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	for r := 1; r <= 5; r++ {
+		if r < 4 {
+			for i := range outMsgs {
+				outMsgs[i] = append(outMsgs[i], outMsgs[i]...)
+			}
+		}
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	for i := range parties {
+		if _, ok := sms[i].Result().(*Signature); !ok {
+			t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+		}
+	}
+}
+
+// TestSignEquivocation sends party 1 two different round 1 messages from
+// party 2. The conflicting copy must be blamed on party 2.
+func TestSignEquivocation(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	original := outMsgs[1][0].(*SignMessage)
+	conflicting := *original
+	conflicting.Data = append([]byte{}, original.Data...)
+	conflicting.Data[len(conflicting.Data)-1] ^= 0x01
+
+	if err := deliverTo(sms, 0, parties[0], []tss.Message{original}); err != nil {
+		t.Fatalf("Original message rejected: %v", err)
+	}
+	err := deliverTo(sms, 0, parties[0], []tss.Message{&conflicting})
+	expectBlame(t, err, "2", "equivocation")
+}
@@ -0,0 +1,98 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSignEchoBroadcast(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	hash := sha256.Sum256([]byte("echo message"))
+
+	coord := tss.NewLocalCoordinator()
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:       parties[i],
+			Parties:       parties,
+			Threshold:     1,
+			Curve:         "secp256k1",
+			SessionID:     []byte("sign-echo"),
+			EchoBroadcast: true,
+		}
+		sm, out, err := NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+		coord.Add(parties[i].ID(), sm, out)
+	}
+	results, err := coord.Run()
+	if err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+	pub := curves.NewSecp256k1().MarshalCompressed(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	for id, res := range results {
+		if valid, err := VerifyWithPubKeyBytes(pub, hash[:], res.(*Signature)); err != nil || !valid {
+			t.Fatalf("Signature of %s does not verify: %v", id, err)
+		}
+	}
+}
+
+func TestBatchSignEchoBroadcast(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	hashes := [][]byte{}
+	for _, m := range []string{"echo batch 1", "echo batch 2"} {
+		h := sha256.Sum256([]byte(m))
+		hashes = append(hashes, h[:])
+	}
+
+	var echoed bool
+	coord := tss.NewLocalCoordinator()
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:       parties[i],
+			Parties:       parties,
+			Threshold:     1,
+			Curve:         "secp256k1",
+			SessionID:     []byte("sign-batch-echo"),
+			EchoBroadcast: true,
+			OnRoundComplete: func(round int, out []tss.Message) {
+				for _, msg := range out {
+					echoed = echoed || msg.Type() == tss.EchoMessageType
+				}
+			},
+		}
+		sm, out, err := NewBatchSignStateMachine(params, keyData[i], hashes)
+		if err != nil {
+			t.Fatalf("Failed to create batch sign state machine: %v", err)
+		}
+		coord.Add(parties[i].ID(), sm, out)
+	}
+	results, err := coord.Run()
+	if err != nil {
+		t.Fatalf("Batch signing failed: %v", err)
+	}
+	if !echoed {
+		t.Fatal("Batch signing sent no echoes")
+	}
+	pub := curves.NewSecp256k1().MarshalCompressed(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	for id, res := range results {
+		for j, sig := range res.(*BatchSignResult).Signatures {
+			if valid, err := VerifyWithPubKeyBytes(pub, hashes[j], sig); err != nil || !valid {
+				t.Fatalf("Signature %d of %s does not verify: %v", j, id, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSignIdentifiesCheatingDelta(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	// Rounds 1 and 2
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 broadcasts delta_i + 1, which makes R wrong
+	q := curves.NewSecp256k1().Params().N
+	var tampered []tss.Message
+	for _, msg := range outMsgs[1] {
+		var payload Round3Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.DeltaI.Add(payload.DeltaI, big.NewInt(1))
+		payload.DeltaI.Mod(payload.DeltaI, q)
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := *msg.(*SignMessage)
+		m.Data = data
+		tampered = append(tampered, &m)
+	}
+	outMsgs[1] = tampered
+
+	// The honest parties notice before releasing s_i
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	for _, i := range []int{0, 2} {
+		if len(outMsgs[i]) != 1 || outMsgs[i][0].Type() != "SignIdentify" {
+			t.Fatalf("Party %d sent %v instead of its openings", i+1, outMsgs[i])
+		}
+		if sms[i].Details() != "Sign Identification" {
+			t.Fatalf("Party %d is in %s", i+1, sms[i].Details())
+		}
+	}
+
+	// Party 2 took part in identification like everyone else
+	var err error
+	_, outMsgs[1], err = sms[1].(*state).startIdentification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{0, 2} {
+		err := deliverTo(sms, i, parties[i], outMsgs...)
+		expectBlame(t, err, "2", "delta_i")
+	}
+}
+
+func TestSignIdentifiesFalseOpening(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 3 sends a wrong delta_i
+	for _, msg := range outMsgs[2] {
+		var payload Round3Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.DeltaI.Add(payload.DeltaI, big.NewInt(1))
+		msg.(*SignMessage).Data, _ = json.Marshal(payload)
+	}
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// ...then opens a beta_ij other than the one in its C_delta, so that
+	// party 1's delta_i would look wrong
+	var err error
+	_, outMsgs[2], err = sms[2].(*state).startIdentification()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload IdentifyPayload
+	if err := json.Unmarshal(outMsgs[2][0].Payload(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	payload.Betas["1"].Add(payload.Betas["1"], big.NewInt(1))
+	outMsgs[2][0].(*SignMessage).Data, _ = json.Marshal(payload)
+
+	err = deliverTo(sms, 1, parties[1], outMsgs...)
+	expectBlame(t, err, "3", "C_delta")
+}
+
+// addG replaces the compressed point b with b + G.
+func addG(t *testing.T, b []byte) []byte {
+	t.Helper()
+	curve := curves.NewSecp256k1()
+	x, y, err := curve.UnmarshalCompressed(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Gx, Gy := curve.ScalarBaseMult(big.NewInt(1))
+	return curve.MarshalCompressed(curve.Add(x, y, Gx, Gy))
+}
+
+func TestSignIdentifiesCheatingSigma(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 broadcasts Sigma_i + G, so the Sigma_j do not add up
+	for _, msg := range outMsgs[1] {
+		var payload Round3Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.BigSigma = addG(t, payload.BigSigma)
+		msg.(*SignMessage).Data, _ = json.Marshal(payload)
+	}
+
+	// The honest parties notice before releasing s_i
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	for _, i := range []int{0, 2} {
+		if len(outMsgs[i]) != 1 || outMsgs[i][0].Type() != "SignIdentify" {
+			t.Fatalf("Party %d sent %v instead of its openings", i+1, outMsgs[i])
+		}
+	}
+	var err error
+	_, outMsgs[1], err = sms[1].(*state).startIdentification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{0, 2} {
+		err := deliverTo(sms, i, parties[i], outMsgs...)
+		expectBlame(t, err, "2", "Sigma_i")
+	}
+}
+
+func TestSignIdentifiesFalseMu(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 3 sends a wrong Sigma_i...
+	for _, msg := range outMsgs[2] {
+		var payload Round3Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.BigSigma = addG(t, payload.BigSigma)
+		msg.(*SignMessage).Data, _ = json.Marshal(payload)
+	}
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// ...then opens an M_ji other than mu_ji * G to shift the blame
+	var err error
+	_, outMsgs[2], err = sms[2].(*state).startIdentification()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload IdentifyPayload
+	if err := json.Unmarshal(outMsgs[2][0].Payload(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	payload.Mus["1"] = addG(t, payload.Mus["1"])
+	outMsgs[2][0].(*SignMessage).Data, _ = json.Marshal(payload)
+
+	err = deliverTo(sms, 1, parties[1], outMsgs...)
+	expectBlame(t, err, "3", "M proof")
+}
+
+func TestSignBlamesWrongW(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 claims a W_i other than lambda_i * X_i
+	for _, msg := range outMsgs[1] {
+		if msg.Type() != "SignRound2_MtA" {
+			continue
+		}
+		var payload Round2Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.W = addG(t, payload.W)
+		msg.(*SignMessage).Data, _ = json.Marshal(payload)
+	}
+
+	err := deliverTo(sms, 0, parties[0], outMsgs...)
+	expectBlame(t, err, "2", "W does not match")
+}
+
+// tamperSi adds one to the s_i of every Round 4 message in msgs.
+func tamperSi(t *testing.T, msgs []tss.Message) {
+	t.Helper()
+	for _, msg := range msgs {
+		var payload Round4Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		payload.Si.Add(payload.Si, big.NewInt(1))
+		payload.Si.Mod(payload.Si, curves.NewSecp256k1().Params().N)
+		msg.(*SignMessage).Data, _ = json.Marshal(payload)
+	}
+}
+
+func TestSignBlamesBadPartialSignature(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	for r := 1; r <= 3; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	// Party 3 sends a wrong s_i; the others must not return the bad signature
+	tamperSi(t, outMsgs[2])
+	err := deliverTo(sms, 0, parties[0], outMsgs...)
+	expectBlame(t, err, "3", "s_i")
+	if !errors.Is(err, tss.ErrInvalidMsg) {
+		t.Fatalf("Expected ErrInvalidMsg, got %v", err)
+	}
+	if sms[0].Result() != nil {
+		t.Fatal("Party 1 returned a result for an invalid signature")
+	}
+}
+
+func TestOnlineSignRejectsBadPartialSignature(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	preSigs := runPreSign(t, parties, keyData, "presign-bad-si")
+
+	hash := sha256.Sum256([]byte("hello world"))
+	sms := make([]tss.StateMachine, 2)
+	outMsgs := make([][]tss.Message, 2)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("online-bad-si"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewOnlineStateMachine(params, keyData[i], preSigs[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create online state machine: %v", err)
+		}
+	}
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Without Round 3 points the culprit is unknown, but the signature is
+	// still rejected
+	tamperSi(t, outMsgs[1])
+	err := deliverTo(sms, 0, parties[0], outMsgs...)
+	if !errors.Is(err, tss.ErrInvalidMsg) || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Fatalf("Expected signature verification to fail with ErrInvalidMsg, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignOutOfOrder delivers messages to party 1 newest round first and
+// delays the link from party 3 to party 1 by one step, so party 1 regularly
+// sees messages for a round it has not reached yet. They must be buffered
+// and replayed instead of aborting the session.
+func TestSignOutOfOrder(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	hash := sha256.Sum256([]byte("out of order"))
+	sms := make([]tss.StateMachine, len(parties))
+	inbox := make([][]tss.Message, len(parties))
+	var delayed []tss.Message
+
+	deliver := func(msgs []tss.Message) {
+		for _, msg := range msgs {
+			for i, p := range parties {
+				if msg.From().ID() == p.ID() || !isRecipient(msg, p) {
+					continue
+				}
+				if i == 0 && msg.From().ID() == "3" {
+					delayed = append(delayed, msg)
+					continue
+				}
+				inbox[i] = append(inbox[i], msg)
+			}
+		}
+	}
+
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-out-of-order"),
+		}
+		sm, msgs, err := NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+		sms[i] = sm
+		deliver(msgs)
+	}
+
+	early := 0
+	for step := 0; step < 20; step++ {
+		var produced []tss.Message
+		for i := range parties {
+			msgs := inbox[i]
+			inbox[i] = nil
+			if i == 0 {
+				sort.SliceStable(msgs, func(a, b int) bool {
+					return msgs[a].RoundNumber() > msgs[b].RoundNumber()
+				})
+			}
+			for _, msg := range msgs {
+				if i == 0 && msg.RoundNumber() > currentRound(sms[0]) {
+					early++
+				}
+				next, out, err := sms[i].Update(msg)
+				if err != nil {
+					t.Fatalf("Party %d failed at round %d processing msg from %s: %v", i, msg.RoundNumber(), msg.From().ID(), err)
+				}
+				sms[i] = next
+				produced = append(produced, out...)
+			}
+		}
+		inbox[0] = append(inbox[0], delayed...)
+		delayed = nil
+		deliver(produced)
+	}
+
+	if early == 0 {
+		t.Fatal("Test did not deliver any message ahead of its round")
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*Signature); !ok {
+			t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+		}
+	}
+}
+
+// currentRound parses the round number from a sign state's Details.
+func currentRound(sm tss.StateMachine) uint32 {
+	var r uint32
+	if _, err := fmt.Sscanf(sm.Details(), "Sign Round %d", &r); err != nil {
+		return ^uint32(0) // finished
+	}
+	return r
+}
@@ -0,0 +1,60 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSignP256(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGenOnCurve(t, parties, 1, "secp256r1")
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     keyData[0].PublicKeyX,
+		Y:     keyData[0].PublicKeyY,
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		t.Fatal("Group public key is not a P-256 point")
+	}
+
+	hash := sha256.Sum256([]byte("p256 message"))
+	coord := tss.NewLocalCoordinator()
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256r1",
+			SessionID: []byte("sign-p256"),
+		}
+		sm, out, err := NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+		coord.Add(parties[i].ID(), sm, out)
+	}
+	results, err := coord.Run()
+	if err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), pub.X, pub.Y)
+	for id, res := range results {
+		sig := res.(*Signature)
+		if !ecdsa.Verify(pub, hash[:], sig.R, sig.S) {
+			t.Fatalf("Signature from party %s does not verify with crypto/ecdsa", id)
+		}
+		if valid, err := VerifyOnCurve("secp256r1", compressed, hash[:], sig); err != nil || !valid {
+			t.Fatalf("VerifyOnCurve rejected the signature of party %s: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestSignP384(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGenOnCurve(t, parties, 1, "p384")
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P384(),
+		X:     keyData[0].PublicKeyX,
+		Y:     keyData[0].PublicKeyY,
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		t.Fatal("Group public key is not a P-384 point")
+	}
+
+	hash := sha256.Sum256([]byte("p384 message"))
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "p384",
+			SessionID: []byte("sign-p384"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	for i := range parties {
+		sig, ok := sms[i].Result().(*Signature)
+		if !ok {
+			t.Fatalf("Expected Signature result for party %d, got %T", i, sms[i].Result())
+		}
+		if !ecdsa.Verify(pub, hash[:], sig.R, sig.S) {
+			t.Fatalf("Signature from party %d does not verify with crypto/ecdsa", i)
+		}
+	}
+}
+
+func TestSignUnknownCurve(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "p521",
+		SessionID: []byte("sign-unknown-curve"),
+	}
+	if _, _, err := NewStateMachine(params, nil, []byte("msg")); err == nil {
+		t.Fatal("Expected error for unsupported curve")
+	}
+}
@@ -0,0 +1,140 @@
+package sign
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestNewStateMachineInvalidParameters(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	digest := sha256.Sum256([]byte("message"))
+	tests := []struct {
+		name      string
+		partyID   tss.PartyID
+		parties   []tss.PartyID
+		threshold int
+	}{
+		{"no signers", p1, nil, 1},
+		{"too few signers", p1, []tss.PartyID{p1, p2}, 2},
+		{"duplicate signers", p1, []tss.PartyID{p1, p2, &MockPartyID{id: "2"}}, 1},
+		{"local party not signing", p3, []tss.PartyID{p1, p2}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:   tt.partyID,
+				Parties:   tt.parties,
+				Threshold: tt.threshold,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-params"),
+			}
+			if _, _, err := NewStateMachine(params, nil, digest[:]); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("NewStateMachine: expected ErrInvalidParameters, got %v", err)
+			}
+			if _, _, err := NewPreSignStateMachine(params, nil); !errors.Is(err, tss.ErrInvalidParameters) {
+				t.Fatalf("NewPreSignStateMachine: expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExpectedPublicKey(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	keyData := runKeyGen(t, parties, 1)
+	digest := sha256.Sum256([]byte("message"))
+	G := curves.NewSecp256k1().Params()
+
+	tests := []struct {
+		name     string
+		expected *tss.PublicKey
+		wantErr  bool
+	}{
+		{"not set", nil, false},
+		{"matching", &tss.PublicKey{X: keyData[0].PublicKeyX, Y: keyData[0].PublicKeyY}, false},
+		{"mismatching", &tss.PublicKey{X: G.Gx, Y: G.Gy}, true},
+		{"incomplete", &tss.PublicKey{X: keyData[0].PublicKeyX}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:           parties[0],
+				Parties:           parties,
+				Threshold:         1,
+				Curve:             "secp256k1",
+				SessionID:         []byte("test-session-expected-key"),
+				ExpectedPublicKey: tt.expected,
+			}
+			_, _, err := NewStateMachine(params, keyData[0], digest[:])
+			_, _, preErr := NewPreSignStateMachine(params, keyData[0])
+			for _, err := range []error{err, preErr} {
+				if tt.wantErr && !errors.Is(err, tss.ErrInvalidParameters) {
+					t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+				}
+				if !tt.wantErr && err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSignPaillierKeysCheckedAtRound1(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}}
+	keyData := runKeyGen(t, parties, 1)
+	digest := sha256.Sum256([]byte("message"))
+	small, err := paillier.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// withPeers returns a copy of party 1's key data with the given peer keys
+	withPeers := func(edit func(map[string]*paillier.PublicKey)) *keygen.LocalPartySaveData {
+		kd := *keyData[0]
+		kd.PeerPaillierPks = make(map[string]*paillier.PublicKey)
+		for id, pk := range keyData[0].PeerPaillierPks {
+			kd.PeerPaillierPks[id] = pk
+		}
+		edit(kd.PeerPaillierPks)
+		return &kd
+	}
+	noOwnSk := *keyData[0]
+	noOwnSk.PaillierSk = nil
+
+	tests := []struct {
+		name    string
+		keyData *keygen.LocalPartySaveData
+	}{
+		{"missing peer key", withPeers(func(m map[string]*paillier.PublicKey) { delete(m, "3") })},
+		{"undersized peer key", withPeers(func(m map[string]*paillier.PublicKey) { m["2"] = &small.PublicKey })},
+		{"missing own private key", &noOwnSk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &tss.Parameters{
+				PartyID:   parties[0],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte("test-session-paillier"),
+			}
+			sm, out, err := NewStateMachine(params, tt.keyData, digest[:])
+			if !errors.Is(err, tss.ErrInvalidParameters) || sm != nil || out != nil {
+				t.Fatalf("Expected ErrInvalidParameters before any message, got %v", err)
+			}
+		})
+	}
+
+	// A key for a party outside the signing set is not needed
+	subset := []tss.PartyID{parties[0], parties[1]}
+	params := &tss.Parameters{PartyID: parties[0], Parties: subset, Threshold: 1, Curve: "secp256k1", SessionID: []byte("test-session-paillier")}
+	if _, _, err := NewStateMachine(params, withPeers(func(m map[string]*paillier.PublicKey) { delete(m, "3") }), digest[:]); err != nil {
+		t.Fatalf("Unexpected error for a key outside the signing set: %v", err)
+	}
+}
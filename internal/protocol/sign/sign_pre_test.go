@@ -1,9 +1,15 @@
 package sign
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -123,6 +129,14 @@ func TestPreSignE2E(t *testing.T) {
 		preSignatures[i] = preSig
 	}
 
+	// Publish the nonce commitment before any signature exists
+	nonceCommit := NonceCommitment(preSignatures[0])
+	for i := 1; i < 3; i++ {
+		if !bytes.Equal(NonceCommitment(preSignatures[i]), nonceCommit) {
+			t.Fatalf("Party %d computed a different nonce commitment", i)
+		}
+	}
+
 	// 3. Run Online Sign
 	msg := []byte("hello world")
 	hash := sha256.Sum256(msg)
@@ -145,11 +159,12 @@ func TestPreSignE2E(t *testing.T) {
 	}
 	
 	// Run Online rounds
-	// Round 1 (Online): Broadcast s_i (already done in NewOnlineStateMachine)
+	// Round 0 (Online): Announce the presignature (sent by NewOnlineStateMachine)
+	// Round 1 (Online): Check the announcements, broadcast s_i
 	// Round 2 (Online): Receive s_i, verify
-	
-	// We need to route the initial messages generated by NewOnlineStateMachine
-	onlineSMs, onlineOutMsgs = route(onlineSMs, onlineOutMsgs)
+	for r := 0; r < 2; r++ {
+		onlineSMs, onlineOutMsgs = route(onlineSMs, onlineOutMsgs)
+	}
 	
 	// Collect Final Signatures
 	for i := 0; i < 3; i++ {
@@ -164,5 +179,307 @@ func TestPreSignE2E(t *testing.T) {
 		if sig.R == nil || sig.S == nil {
 			t.Fatalf("Invalid signature")
 		}
+		if !VerifyNonceCommitment(nonceCommit, sig) {
+			t.Fatalf("Signature R of party %d does not match the presign nonce commitment", i)
+		}
+		other := &Signature{R: new(big.Int).Add(sig.R, big.NewInt(1)), S: sig.S}
+		if VerifyNonceCommitment(nonceCommit, other) {
+			t.Fatal("Nonce commitment matched a different R")
+		}
+	}
+}
+
+func TestPreSignTweaked(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	// Taproot-style tweak t = H(P || merkle_root) mod N
+	th := sha256.Sum256(append(keyData[0].PublicKeyX.Bytes(), []byte("merkle-root")...))
+	tweak := new(big.Int).SetBytes(th[:])
+	tweak.Mod(tweak, secp256k1.S256().N)
+
+	tweakedX, tweakedY, err := TweakPublicKey(keyData[0].PublicKeyX, keyData[0].PublicKeyY, tweak)
+	if err != nil {
+		t.Fatalf("TweakPublicKey failed: %v", err)
+	}
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(tweakedX.Bytes())
+	fy.SetByteSlice(tweakedY.Bytes())
+	tweakedPk := secp256k1.NewPublicKey(&fx, &fy)
+
+	if _, _, err := NewPreSignTweaked(nil, keyData[0], big.NewInt(0)); err == nil {
+		t.Fatal("Expected error for zero tweak")
+	}
+
+	for _, text := range []string{"first message", "second message"} {
+		// 1. Tweaked PreSign (one presignature per message)
+		sms := make([]tss.StateMachine, 3)
+		outMsgs := make([][]tss.Message, 3)
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte("presign-tweaked-" + text),
+			}
+			sms[i], outMsgs[i], err = NewPreSignTweaked(params, keyData[i], tweak)
+			if err != nil {
+				t.Fatalf("Failed to create tweaked presign state machine: %v", err)
+			}
+		}
+		for r := 1; r <= 4; r++ {
+			sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+		}
+
+		// 2. Online Sign
+		hash := sha256.Sum256([]byte(text))
+		for i := range parties {
+			preSig, ok := sms[i].Result().(*PreSignature)
+			if !ok {
+				t.Fatalf("Expected PreSignature result, got %T", sms[i].Result())
+			}
+			params := &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte("online-tweaked-" + text),
+			}
+			sms[i], outMsgs[i], err = NewOnlineStateMachine(params, keyData[i], preSig, hash[:])
+			if err != nil {
+				t.Fatalf("Failed to create online state machine: %v", err)
+			}
+		}
+		for r := 0; r < 2; r++ {
+			sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+		}
+
+		// 3. Verify under the tweaked key
+		for i := range parties {
+			sig, ok := sms[i].Result().(*Signature)
+			if !ok {
+				t.Fatalf("Expected Signature result, got %T", sms[i].Result())
+			}
+			var r, s secp256k1.ModNScalar
+			r.SetByteSlice(sig.R.Bytes())
+			s.SetByteSlice(sig.S.Bytes())
+			if !ecdsa.NewSignature(&r, &s).Verify(hash[:], tweakedPk) {
+				t.Fatalf("Signature for %q does not verify under the tweaked key", text)
+			}
+		}
+	}
+}
+
+// runPreSign runs the offline phase for all parties and returns their presignatures.
+func runPreSign(t *testing.T, parties []tss.PartyID, keyData []*keygen.LocalPartySaveData, sessionID string) []*PreSignature {
+	t.Helper()
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte(sessionID),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewPreSignStateMachine(params, keyData[i])
+		if err != nil {
+			t.Fatalf("Failed to create presign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 4; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	preSigs := make([]*PreSignature, len(parties))
+	for i := range parties {
+		preSig, ok := sms[i].Result().(*PreSignature)
+		if !ok {
+			t.Fatalf("Party %d did not finish presigning: %s", i, sms[i].Details())
+		}
+		preSigs[i] = preSig
+	}
+	return preSigs
+}
+
+func TestOnlineSignPreSignSessionMismatch(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	first := runPreSign(t, parties, keyData, "presign-a")
+	second := runPreSign(t, parties, keyData, "presign-b")
+	if !bytes.Equal(first[0].PreSignSessionID, []byte("presign-a")) {
+		t.Fatalf("PreSignSessionID = %q, want presign-a", first[0].PreSignSessionID)
+	}
+
+	// Party 1 uses a presignature of the other presign session
+	preSigs := []*PreSignature{second[0], first[1], first[2]}
+	hash := sha256.Sum256([]byte("hello world"))
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("online-mismatch"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewOnlineStateMachine(params, keyData[i], preSigs[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create online state machine: %v", err)
+		}
+		if len(outMsgs[i]) != 1 || outMsgs[i][0].Type() != "SignOnline_PreSign" {
+			t.Fatalf("Party %d released more than its presignature announcement", i)
+		}
+	}
+
+	err := deliverTo(sms, 1, parties[1], outMsgs...)
+	expectBlame(t, err, "1", "different presign session")
+	if err := deliverTo(sms, 0, parties[0], outMsgs...); !errors.Is(err, tss.ErrInvalidMsg) {
+		t.Fatalf("Party 1 should reject the others' presignatures, got %v", err)
+	}
+}
+
+func TestOnlineSignRejectsUsedPreSignature(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	preSigs := runPreSign(t, parties, keyData, "presign-reuse")
+
+	newOnline := func(i int, text string) (tss.StateMachine, []tss.Message, error) {
+		hash := sha256.Sum256([]byte(text))
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("online-" + text),
+		}
+		return NewOnlineStateMachine(params, keyData[i], preSigs[i], hash[:])
+	}
+
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		if preSigs[i].Used() {
+			t.Fatalf("Party %d: fresh presignature marked used", i)
+		}
+		var err error
+		sms[i], outMsgs[i], err = newOnline(i, "first message")
+		if err != nil {
+			t.Fatalf("Failed to create online state machine: %v", err)
+		}
+	}
+	for r := 0; r < 2; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*Signature); !ok {
+			t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+		}
+	}
+
+	// The same presignature must not sign a second message
+	for i := range parties {
+		if !preSigs[i].Used() {
+			t.Fatalf("Party %d: presignature not marked used", i)
+		}
+		if _, _, err := newOnline(i, "second message"); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Fatalf("Party %d: expected ErrPreSignatureUsed, got %v", i, err)
+		}
+	}
+}
+
+func TestPreSignRPoint(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	preSigs := runPreSign(t, parties, keyData, "presign-rpoint")
+
+	Rx, Ry := preSigs[0].RPoint()
+	if Rx == nil || !secp256k1.S256().IsOnCurve(Rx, Ry) {
+		t.Fatal("RPoint is not a curve point")
+	}
+	for i, preSig := range preSigs {
+		x, y := preSig.RPoint()
+		if x.Cmp(Rx) != 0 || y.Cmp(Ry) != 0 {
+			t.Fatalf("Party %d holds a different R", i)
+		}
+		if preSig.ID() != hex.EncodeToString([]byte("presign-rpoint")) {
+			t.Fatalf("Party %d: ID = %s", i, preSig.ID())
+		}
+	}
+	// RPoint returns a copy
+	Rx.SetInt64(1)
+	if x, _ := preSigs[0].RPoint(); x.Cmp(Rx) == 0 {
+		t.Fatal("RPoint exposed the presignature's R")
+	}
+
+	// A presignature whose r does not match R is rejected before use
+	bad := &PreSignature{
+		R:      new(big.Int).Add(preSigs[0].R, big.NewInt(1)),
+		Rx:     preSigs[0].Rx,
+		Ry:     preSigs[0].Ry,
+		Ki:     preSigs[0].Ki,
+		SigmaI: preSigs[0].SigmaI,
+	}
+	hash := sha256.Sum256([]byte("audited message"))
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("online-rpoint"),
+	}
+	if _, _, err := NewOnlineStateMachine(params, keyData[0], bad, hash[:]); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for inconsistent R, got %v", err)
+	}
+	if bad.Used() {
+		t.Fatal("Rejected presignature marked used")
+	}
+
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := *params
+		params.PartyID = parties[i]
+		var err error
+		sms[i], outMsgs[i], err = NewOnlineStateMachine(&params, keyData[i], preSigs[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create online state machine: %v", err)
+		}
+	}
+	for r := 0; r < 2; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	// The signature's r is the x-coordinate of the R exposed before signing
+	Rx, _ = preSigs[0].RPoint()
+	wantR := new(big.Int).Mod(Rx, secp256k1.S256().N)
+	for i := range parties {
+		sig, ok := sms[i].Result().(*Signature)
+		if !ok {
+			t.Fatalf("Party %d did not finish signing: %s", i, sms[i].Details())
+		}
+		if sig.R.Cmp(wantR) != 0 {
+			t.Fatalf("Party %d: signature r does not match the presignature's R", i)
+		}
 	}
 }
@@ -0,0 +1,241 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func newSignSession(t *testing.T, parties []tss.PartyID, keyData []*keygen.LocalPartySaveData) ([]tss.StateMachine, [][]tss.Message) {
+	t.Helper()
+	hash := sha256.Sum256([]byte("hello world"))
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-session"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	return sms, outMsgs
+}
+
+// deliverTo feeds every message addressed to party idx and returns the first error.
+func deliverTo(sms []tss.StateMachine, idx int, party tss.PartyID, batches ...[]tss.Message) error {
+	for _, msgs := range batches {
+		for _, msg := range msgs {
+			if !isRecipient(msg, party) {
+				continue
+			}
+			next, _, err := sms[idx].Update(msg)
+			if err != nil {
+				return err
+			}
+			sms[idx] = next
+		}
+	}
+	return nil
+}
+
+func expectBlame(t *testing.T, err error, partyID, reason string) {
+	t.Helper()
+	var blame *tss.Blame
+	if !errors.As(err, &blame) {
+		t.Fatalf("Expected blame error, got %v", err)
+	}
+	if blame.PartyID.ID() != partyID {
+		t.Fatalf("Expected blame on party %s, got %s", partyID, blame.PartyID.ID())
+	}
+	if !strings.Contains(blame.Reason, reason) {
+		t.Fatalf("Expected blame reason containing %q, got %q", reason, blame.Reason)
+	}
+}
+
+func TestMtAProofRejected(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	// Round 1: EncK + Gamma commitments
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 shifts the plaintext of C_delta sent to party 1 by adding E(1),
+	// keeping the original proof.
+	pk1 := keyData[0].PaillierPk
+	var tampered []tss.Message
+	for _, msg := range outMsgs[1] {
+		if msg.Type() != "SignRound2_MtA" || !isRecipient(msg, parties[0]) {
+			tampered = append(tampered, msg)
+			continue
+		}
+		var payload Round2Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		one, _, err := pk1.Encrypt(big.NewInt(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload.C_delta = pk1.Add(payload.C_delta, one)
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := *msg.(*SignMessage)
+		m.Data = data
+		tampered = append(tampered, &m)
+	}
+
+	// Party 3 is unaffected.
+	if err := deliverTo(sms, 2, parties[2], outMsgs[0], outMsgs[1]); err != nil {
+		t.Fatalf("Honest MtA rejected: %v", err)
+	}
+	if sms[2].Details() != "Sign Round 3" {
+		t.Fatalf("Expected party 3 to reach round 3, got %s", sms[2].Details())
+	}
+
+	// Party 1 must reject the tampered ciphertext before decrypting it.
+	err := deliverTo(sms, 0, parties[0], tampered, outMsgs[2])
+	expectBlame(t, err, "2", "C_delta")
+}
+
+func TestMalformedPayloadBlamed(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	// Round 1: EncK + Gamma commitments
+	sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+
+	// Party 2 sends party 1 an MtA message that is not valid JSON.
+	var tampered []tss.Message
+	for _, msg := range outMsgs[1] {
+		if msg.Type() != "SignRound2_MtA" || !isRecipient(msg, parties[0]) {
+			tampered = append(tampered, msg)
+			continue
+		}
+		m := *msg.(*SignMessage)
+		m.Data = []byte(`{"C_delta":`)
+		tampered = append(tampered, &m)
+	}
+
+	err := deliverTo(sms, 0, parties[0], tampered, outMsgs[2])
+	expectBlame(t, err, "2", "round 2: malformed payload")
+	if !errors.Is(err, tss.ErrInvalidMsg) {
+		t.Fatalf("Expected ErrInvalidMsg, got %v", err)
+	}
+}
+
+func TestEncKRangeProofRejected(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	// Party 2 replaces EncK with an encryption of an out-of-range k,
+	// keeping the original range proof.
+	pk2 := keyData[1].PaillierPk
+	var tampered []tss.Message
+	for _, msg := range outMsgs[1] {
+		var payload Round1Payload
+		if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		bigK := new(big.Int).Sub(pk2.N, big.NewInt(1))
+		encK, _, err := pk2.Encrypt(bigK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload.EncK = encK.Bytes()
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := *msg.(*SignMessage)
+		m.Data = data
+		tampered = append(tampered, &m)
+	}
+
+	err := deliverTo(sms, 0, parties[0], tampered, outMsgs[2])
+	expectBlame(t, err, "2", "range proof")
+}
+
+func TestUpdateAfterAbort(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+
+	// Party 2 sends an EncK that does not match its range proof
+	pk2 := keyData[1].PaillierPk
+	var payload Round1Payload
+	if err := json.Unmarshal(outMsgs[1][0].Payload(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	encK, _, err := pk2.Encrypt(big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload.EncK = encK.Bytes()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := *outMsgs[1][0].(*SignMessage)
+	m.Data = data
+
+	sm := sms[0]
+	var abortErr error
+	for _, msg := range []tss.Message{&m, outMsgs[2][0]} {
+		next, _, err := sm.Update(msg)
+		if err != nil {
+			if next == nil {
+				t.Fatalf("Expected a terminal state on abort, got nil: %v", err)
+			}
+			sm, abortErr = next, err
+			break
+		}
+		sm = next
+	}
+	expectBlame(t, abortErr, "2", "range proof")
+	if !strings.Contains(sm.Details(), "Aborted") {
+		t.Fatalf("Expected aborted state, got %s", sm.Details())
+	}
+
+	// A late message must be rejected, not panic
+	next, out, err := sm.Update(outMsgs[2][0])
+	if !errors.Is(err, tss.ErrProtocolDone) || next != nil || out != nil {
+		t.Fatalf("Expected ErrProtocolDone after abort, got %v, %v, %v", next, out, err)
+	}
+	if sm.Result() != nil {
+		t.Fatal("Expected no result after abort")
+	}
+}
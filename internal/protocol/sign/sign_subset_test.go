@@ -0,0 +1,182 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignSubset runs a 2-of-3 KeyGen and signs with only parties 1 and 3.
+// Party 3 sits at position 2 of the signing set, so Lagrange coefficients
+// computed from positions would be wrong.
+func TestSignSubset(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+
+	signers := []tss.PartyID{parties[0], parties[2]}
+	signerData := []*keygen.LocalPartySaveData{keyData[0], keyData[2]}
+
+	hash := sha256.Sum256([]byte("subset message"))
+	sms := make([]tss.StateMachine, len(signers))
+	outMsgs := make([][]tss.Message, len(signers))
+	for i := range signers {
+		params := &tss.Parameters{
+			PartyID:   signers[i],
+			Parties:   signers,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-subset"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, signerData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, signers, sms, outMsgs)
+	}
+
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(keyData[0].PublicKeyX.Bytes())
+	fy.SetByteSlice(keyData[0].PublicKeyY.Bytes())
+	pk := secp256k1.NewPublicKey(&fx, &fy)
+
+	for i := range signers {
+		sig, ok := sms[i].Result().(*Signature)
+		if !ok {
+			t.Fatalf("Expected Signature result for signer %d, got %T", i, sms[i].Result())
+		}
+		var r, s secp256k1.ModNScalar
+		r.SetByteSlice(sig.R.Bytes())
+		s.SetByteSlice(sig.S.Bytes())
+		if !ecdsa.NewSignature(&r, &s).Verify(hash[:], pk) {
+			t.Fatalf("Signature from signer %d does not verify under the group key", i)
+		}
+		if len(sig.Signers) != 2 || sig.Signers[0] != "1" || sig.Signers[1] != "3" {
+			t.Fatalf("Expected contributing signers [1 3], got %v", sig.Signers)
+		}
+	}
+}
+
+func TestSignSubsetUnknownSigner(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	keyData := &keygen.LocalPartySaveData{
+		ShareIDs: keygen.ShareIndices(parties),
+	}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   []tss.PartyID{parties[0], &MockPartyID{id: "4"}},
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("sign-subset-unknown"),
+	}
+	s := &state{params: params, keyData: keyData}
+	if _, err := s.calcLagrangeCoeffs(); err == nil {
+		t.Fatal("Expected error for signer outside the key committee")
+	}
+}
+
+func TestSignSubsetWithoutShareIDs(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+		&MockPartyID{id: "3"},
+	}
+	// Key data from before ShareIDs: only the Paillier keys of the peers
+	// tell how large the committee was
+	keyData := &keygen.LocalPartySaveData{
+		PeerPaillierPks: map[string]*paillier.PublicKey{"2": nil, "3": nil},
+	}
+	params := &tss.Parameters{
+		PartyID:   parties[0],
+		Parties:   parties[:2],
+		Threshold: 1,
+		Curve:     "secp256k1",
+		SessionID: []byte("sign-subset-legacy"),
+	}
+	s := &state{params: params, curve: curves.NewSecp256k1(), keyData: keyData}
+	if _, err := s.calcLagrangeCoeffs(); !errors.Is(err, tss.ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a subset without share indices, got %v", err)
+	}
+
+	// The whole committee still signs at x_i = index + 1
+	params.Parties = parties
+	if _, err := s.calcLagrangeCoeffs(); err != nil {
+		t.Fatalf("Expected the whole committee to sign, got %v", err)
+	}
+}
+
+// TestSignSigningSet runs a 3-of-5 KeyGen and signs with parties 1, 3 and 5
+// given as the SigningSet, while Parties still lists the whole committee:
+// only the signers are online, and no round waits for the other two.
+func TestSignSigningSet(t *testing.T) {
+	parties := make([]tss.PartyID, 5)
+	for i := range parties {
+		parties[i] = &MockPartyID{id: string(rune('1' + i))}
+	}
+	keyData := runKeyGen(t, parties, 2)
+	signers := []tss.PartyID{parties[0], parties[2], parties[4]}
+	hash := sha256.Sum256([]byte("signing set message"))
+
+	newParams := func(self tss.PartyID, set []tss.PartyID) *tss.Parameters {
+		return &tss.Parameters{
+			PartyID:    self,
+			Parties:    parties,
+			SigningSet: set,
+			Threshold:  2,
+			Curve:      "secp256k1",
+			SessionID:  []byte("sign-signing-set"),
+		}
+	}
+
+	coord := tss.NewLocalCoordinator()
+	for _, i := range []int{0, 2, 4} {
+		sm, out, err := NewStateMachine(newParams(parties[i], signers), keyData[i], hash[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+		coord.Add(parties[i].ID(), sm, out)
+	}
+	results, err := coord.Run()
+	if err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+	pub := curves.NewSecp256k1().MarshalCompressed(keyData[0].PublicKeyX, keyData[0].PublicKeyY)
+	for id, res := range results {
+		sig := res.(*Signature)
+		if valid, err := VerifyWithPubKeyBytes(pub, hash[:], sig); err != nil || !valid {
+			t.Fatalf("Signature of %s does not verify: %v", id, err)
+		}
+		if len(sig.Signers) != 3 || sig.Signers[0] != "1" || sig.Signers[1] != "3" || sig.Signers[2] != "5" {
+			t.Fatalf("Expected contributing signers [1 3 5], got %v", sig.Signers)
+		}
+	}
+
+	invalid := map[string]*tss.Parameters{
+		"local party not signing": newParams(parties[1], signers),
+		"too few signers":         newParams(parties[0], signers[:2]),
+		"signer outside committee": newParams(parties[0], []tss.PartyID{
+			parties[0], parties[2], &MockPartyID{id: "9"},
+		}),
+	}
+	for name, params := range invalid {
+		if _, _, err := NewStateMachine(params, keyData[0], hash[:]); !errors.Is(err, tss.ErrInvalidParameters) {
+			t.Errorf("%s: expected ErrInvalidParameters, got %v", name, err)
+		}
+	}
+}
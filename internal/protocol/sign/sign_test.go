@@ -129,3 +129,92 @@ func TestSignE2E(t *testing.T) {
 		t.Logf("Party %d Signature: (R: %x, S: %x)", i, sig.R, sig.S)
 	}
 }
+
+// runKeyGen runs a full KeyGen among parties and returns each party's save data.
+func runKeyGen(t *testing.T, parties []tss.PartyID, threshold int) []*keygen.LocalPartySaveData {
+	t.Helper()
+	return runKeyGenOnCurve(t, parties, threshold, "secp256k1")
+}
+
+// runKeyGenOnCurve runs a full KeyGen on the named curve.
+func runKeyGenOnCurve(t *testing.T, parties []tss.PartyID, threshold int, curve string) []*keygen.LocalPartySaveData {
+	t.Helper()
+
+	n := len(parties)
+	sms := make([]tss.StateMachine, n)
+	outMsgs := make([][]tss.Message, n)
+	for i := 0; i < n; i++ {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: threshold,
+			Curve:     curve,
+			SessionID: []byte("test-session"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine: %v", err)
+		}
+	}
+
+	for r := 1; r <= 4; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+
+	keyData := make([]*keygen.LocalPartySaveData, n)
+	for i := 0; i < n; i++ {
+		res := sms[i].Result()
+		if res == nil {
+			t.Fatalf("KeyGen failed for party %d", i)
+		}
+		keyData[i] = res.(*keygen.LocalPartySaveData)
+	}
+	return keyData
+}
+
+// routeMessages delivers every pending message to its recipients and
+// returns the messages produced in response.
+func routeMessages(t *testing.T, parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Message) ([]tss.StateMachine, [][]tss.Message) {
+	t.Helper()
+
+	allMsgs := []tss.Message{}
+	for _, msgs := range outMsgs {
+		allMsgs = append(allMsgs, msgs...)
+	}
+	newOutMsgs := make([][]tss.Message, len(sms))
+
+	for i := range sms {
+		if sms[i] == nil {
+			continue
+		}
+		for _, msg := range allMsgs {
+			if !isRecipient(msg, parties[i]) {
+				continue
+			}
+			next, newOut, err := sms[i].Update(msg)
+			if err != nil {
+				t.Fatalf("Party %d failed: %v", i, err)
+			}
+			sms[i] = next
+			newOutMsgs[i] = append(newOutMsgs[i], newOut...)
+		}
+	}
+	return sms, newOutMsgs
+}
+
+// isRecipient reports whether msg should be delivered to party.
+func isRecipient(msg tss.Message, party tss.PartyID) bool {
+	if msg.From().ID() == party.ID() {
+		return false
+	}
+	if msg.IsBroadcast() {
+		return true
+	}
+	for _, dest := range msg.To() {
+		if dest.ID() == party.ID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package sign
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestSignZeroizesSecrets checks that the nonce shares, MtA masks and the
+// additive key share are overwritten once the signature is produced.
+func TestSignZeroizesSecrets(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs, tempData, secrets := runToLastRound(t, parties, sms, outMsgs)
+
+	sms, _ = routeMessages(t, parties, sms, outMsgs)
+	if _, ok := sms[0].Result().(*Signature); !ok {
+		t.Fatalf("Expected a signature, got %T", sms[0].Result())
+	}
+	checkZeroized(t, tempData, secrets)
+}
+
+// TestSignZeroizesSecretsOnAbort checks that the secrets are overwritten as
+// well when the last round fails.
+func TestSignZeroizesSecretsOnAbort(t *testing.T) {
+	parties := []tss.PartyID{
+		&MockPartyID{id: "1"},
+		&MockPartyID{id: "2"},
+	}
+	keyData := runKeyGen(t, parties, 1)
+	sms, outMsgs := newSignSession(t, parties, keyData)
+	sms, outMsgs, tempData, secrets := runToLastRound(t, parties, sms, outMsgs)
+
+	// Party 2 sends a malformed s_j
+	if len(outMsgs[1]) != 1 {
+		t.Fatalf("Expected one message from party 2, got %d", len(outMsgs[1]))
+	}
+	bad := *outMsgs[1][0].(*SignMessage)
+	bad.Data = []byte("not a share")
+	next, _, err := sms[0].Update(&bad)
+	if err == nil {
+		t.Fatal("Expected the malformed s_j to abort signing")
+	}
+	if _, ok := next.(*abortedState); !ok {
+		t.Fatalf("Expected an aborted state, got %T", next)
+	}
+	checkZeroized(t, tempData, secrets)
+}
+
+// runToLastRound routes messages until party 1 waits for the s_j of Round
+// 4, and returns its tempData with the secrets held in it.
+func runToLastRound(t *testing.T, parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Message) ([]tss.StateMachine, [][]tss.Message, map[string]interface{}, []*big.Int) {
+	t.Helper()
+	var tempData map[string]interface{}
+	for tempData == nil {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+		if s, ok := sms[0].(*state); ok && s.round == 4 {
+			tempData = s.tempData
+		}
+	}
+	var secrets []*big.Int
+	for _, key := range []string{"ki", "gammai", "wi", "sigma_i"} {
+		secrets = append(secrets, tempData[key].(*big.Int))
+	}
+	for _, key := range []string{"betas", "nus"} {
+		for _, v := range tempData[key].(map[string]*big.Int) {
+			secrets = append(secrets, v)
+		}
+	}
+	return sms, outMsgs, tempData, secrets
+}
+
+func checkZeroized(t *testing.T, tempData map[string]interface{}, secrets []*big.Int) {
+	t.Helper()
+	for i, v := range secrets {
+		if v.Sign() != 0 {
+			t.Fatalf("Secret %d not zeroed", i)
+		}
+	}
+	for _, key := range secretTempData {
+		if _, ok := tempData[key]; ok {
+			t.Fatalf("tempData[%q] still present", key)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/base58"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// EdDSASignature is an Ed25519 signature (R, S) over the Ed25519 curve,
+// e.g. the sum of the parties' partial signatures in a threshold EdDSA run.
+type EdDSASignature struct {
+	R curves.Point  // Nonce commitment R = r*G
+	S curves.Scalar // S = r + H(R || A || M) * a mod l
+}
+
+// SerializeSolana returns the 64-byte signature layout Solana expects, the
+// standard RFC 8032 encoding: the compressed point R followed by S as a
+// 32-byte little-endian scalar. The result verifies with ed25519.Verify.
+// R must be an Ed25519 point: other 32-byte encodings, such as ristretto255,
+// would never verify.
+func SerializeSolana(sig *EdDSASignature) ([]byte, error) {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return nil, errors.New("solana: missing signature")
+	}
+	r, ok := sig.R.(*curves.Ed25519Point)
+	if !ok {
+		return nil, errors.New("solana: R is not an Ed25519 point")
+	}
+	s, ok := sig.S.(*curves.Ed25519Scalar)
+	if !ok {
+		return nil, errors.New("solana: S is not an Ed25519 scalar")
+	}
+	return append(append(make([]byte, 0, 64), r.Bytes()...), s.Bytes()...), nil
+}
+
+// SolanaAddress returns the Solana address of an Ed25519 group public key:
+// the Base58 encoding of its 32-byte compressed form.
+func SolanaAddress(pub curves.Point) (string, error) {
+	if pub == nil {
+		return "", errors.New("solana: missing public key")
+	}
+	p, ok := pub.(*curves.Ed25519Point)
+	if !ok {
+		return "", errors.New("solana: not an Ed25519 public key")
+	}
+	return base58.Encode(p.Bytes()), nil
+}
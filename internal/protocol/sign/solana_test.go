@@ -0,0 +1,134 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/base58"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// randScalar returns a uniformly random Ed25519 scalar as a big.Int.
+func randScalar(t *testing.T, curve *curves.Ed25519Curve) *big.Int {
+	t.Helper()
+	s, err := curve.NewScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.BigInt()
+}
+
+func TestSerializeSolanaThreshold(t *testing.T) {
+	curve := &curves.Ed25519Curve{}
+	l := curve.Order()
+	G := curve.BasePoint()
+	mul := func(x ...*big.Int) *big.Int {
+		r := big.NewInt(1)
+		for _, v := range x {
+			r.Mul(r, v)
+		}
+		return r.Mod(r, l)
+	}
+
+	// 2-of-3 sharing of the group secret a: x_i = a + c*i
+	a, c := randScalar(t, curve), randScalar(t, curve)
+	share := func(i int64) *big.Int {
+		return new(big.Int).Mod(new(big.Int).Add(a, mul(c, big.NewInt(i))), l)
+	}
+	A := G.ScalarMult(curve.NewScalarFromBigInt(a))
+
+	// Parties 1 and 3 sign; lambda_1 = 3/(3-1), lambda_3 = 1/(1-3)
+	signers := []int64{1, 3}
+	lambdas := []*big.Int{
+		mul(big.NewInt(3), new(big.Int).ModInverse(big.NewInt(2), l)),
+		mul(big.NewInt(1), new(big.Int).ModInverse(new(big.Int).Sub(l, big.NewInt(2)), l)),
+	}
+
+	// R = sum r_i*G
+	nonces := []*big.Int{randScalar(t, curve), randScalar(t, curve)}
+	R := G.ScalarMult(curve.NewScalarFromBigInt(nonces[0])).Add(G.ScalarMult(curve.NewScalarFromBigInt(nonces[1])))
+
+	// k = SHA-512(R || A || M) as a little-endian integer mod l
+	msg := []byte("transfer 1 SOL")
+	h := sha512.New()
+	h.Write(R.Bytes())
+	h.Write(A.Bytes())
+	h.Write(msg)
+	digest := h.Sum(nil)
+	for i, j := 0, len(digest)-1; i < j; i, j = i+1, j-1 {
+		digest[i], digest[j] = digest[j], digest[i]
+	}
+	k := new(big.Int).Mod(new(big.Int).SetBytes(digest), l)
+
+	// S = sum (r_i + k*lambda_i*x_i)
+	S := new(big.Int)
+	for i, id := range signers {
+		S.Add(S, nonces[i])
+		S.Add(S, mul(k, lambdas[i], share(id)))
+	}
+	S.Mod(S, l)
+
+	sig, err := SerializeSolana(&EdDSASignature{R: R, S: curve.NewScalarFromBigInt(S)})
+	if err != nil {
+		t.Fatalf("SerializeSolana failed: %v", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		t.Fatalf("Expected %d-byte signature, got %d", ed25519.SignatureSize, len(sig))
+	}
+	if !ed25519.Verify(ed25519.PublicKey(A.Bytes()), msg, sig) {
+		t.Fatal("Threshold signature does not verify with ed25519.Verify")
+	}
+	if ed25519.Verify(ed25519.PublicKey(A.Bytes()), []byte("transfer 2 SOL"), sig) {
+		t.Fatal("Signature verifies for a different message")
+	}
+
+	if _, err := SerializeSolana(&EdDSASignature{R: R}); err == nil {
+		t.Fatal("Expected an error for an incomplete signature")
+	}
+
+	// A ristretto255 point encodes to 32 bytes too, but is no Ed25519 R
+	ristretto := (&curves.Ristretto255Curve{}).BasePoint()
+	if _, err := SerializeSolana(&EdDSASignature{R: ristretto, S: curve.NewScalarFromBigInt(S)}); err == nil {
+		t.Fatal("Expected an error for a ristretto255 R")
+	}
+}
+
+func TestSolanaAddress(t *testing.T) {
+	// RFC 8032 test 1 public key
+	seed := make([]byte, ed25519.SeedSize)
+	copy(seed, []byte{0x9d, 0x61, 0xb1, 0x9d, 0xef, 0xfd, 0x5a, 0x60, 0xba, 0x84, 0x4a, 0xf4, 0x92, 0xec, 0x2c, 0xc4,
+		0x44, 0x49, 0xc5, 0x69, 0x7b, 0x32, 0x69, 0x19, 0x70, 0x3b, 0xac, 0x03, 0x1c, 0xae, 0x7f, 0x60})
+	pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	point, err := (&curves.Ed25519Curve{}).NewPointFromBytes(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := SolanaAddress(point)
+	if err != nil {
+		t.Fatalf("SolanaAddress failed: %v", err)
+	}
+	// Known answer: public key d75a9801...f707511a in Base58
+	const want = "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z"
+	if hex.EncodeToString(pub) != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Fatalf("Unexpected RFC 8032 public key %x", pub)
+	}
+	if addr != want {
+		t.Fatalf("SolanaAddress = %s, want %s", addr, want)
+	}
+	decoded, err := base58.Decode(addr)
+	if err != nil || !bytes.Equal(decoded, pub) {
+		t.Fatalf("Address %s does not decode to the public key", addr)
+	}
+
+	if _, err := SolanaAddress(nil); err == nil {
+		t.Fatal("Expected an error for a missing key")
+	}
+	if _, err := SolanaAddress((&curves.Ristretto255Curve{}).BasePoint()); err == nil {
+		t.Fatal("Expected an error for a ristretto255 key")
+	}
+}
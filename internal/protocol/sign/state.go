@@ -2,84 +2,252 @@ package sign
 
 import (
 	"fmt"
+	"math/big"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/zeroize"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
 type state struct {
 	params   *tss.Parameters
+	curve    curves.Curve // Resolved from params.Curve
 	keyData  *keygen.LocalPartySaveData
 	msgToSign []byte // The message (hash) to sign. Nil if PreSign mode.
 	preSignature *PreSignature // Populated in Online mode
+	tweak    *big.Int // Additive key tweak folded into the PreSignature (PreSign mode only)
 
 	round    int
 	tempData map[string]interface{}
 	
 	// Messages received in the current round
 	receivedMsgs map[string][]tss.Message
+	// Messages that arrived for a future round, replayed once we get there
+	pending []tss.Message
 }
 
 // NewStateMachine initializes a new Signing state machine.
+// msg is the message digest, at least MinDigestSize bytes; see hashToInt for
+// how longer digests are truncated.
 func NewStateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData, msg []byte) (tss.StateMachine, []tss.Message, error) {
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedKey(params, keyData); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDigest(msg); err != nil {
+		return nil, nil, err
+	}
 	s := &state{
 		params:       params,
+		curve:        curve,
 		keyData:      keyData,
-		msgToSign:    msg,
+		msgToSign:    DomainDigest(params.DomainTag, msg),
 		round:        1,
 		tempData:     make(map[string]interface{}),
 		receivedMsgs: make(map[string][]tss.Message),
 	}
 
-	return s.round1()
+	return tss.Wrap(params)(s.round1())
 }
 
 // NewPreSignStateMachine initializes a new Pre-Signing state machine (Offline phase).
 func NewPreSignStateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData) (tss.StateMachine, []tss.Message, error) {
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedKey(params, keyData); err != nil {
+		return nil, nil, err
+	}
 	s := &state{
 		params:       params,
+		curve:        curve,
 		keyData:      keyData,
 		msgToSign:    nil, // Indicates PreSign mode
 		round:        1,
 		tempData:     make(map[string]interface{}),
 		receivedMsgs: make(map[string][]tss.Message),
 	}
-	return s.round1()
+	return tss.Wrap(params)(s.round1())
+}
+
+// NewPreSignTweaked initializes a Pre-Signing state machine whose PreSignature is
+// valid under the tweaked public key P' = P + tweak*G (e.g. a Taproot output key).
+// The tweak is folded into sigma_i during presigning, so the online phase needs no
+// extra work and produces signatures that verify under the tweaked key.
+// All parties must use the same tweak.
+func NewPreSignTweaked(params *tss.Parameters, keyData *keygen.LocalPartySaveData, tweak *big.Int) (tss.StateMachine, []tss.Message, error) {
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedKey(params, keyData); err != nil {
+		return nil, nil, err
+	}
+	if tweak == nil || tweak.Sign() <= 0 || tweak.Cmp(curve.Params().N) >= 0 {
+		return nil, nil, tss.ErrInvalidParameters
+	}
+	s := &state{
+		params:       params,
+		curve:        curve,
+		keyData:      keyData,
+		msgToSign:    nil, // Indicates PreSign mode
+		tweak:        tweak,
+		round:        1,
+		tempData:     make(map[string]interface{}),
+		receivedMsgs: make(map[string][]tss.Message),
+	}
+	return tss.Wrap(params)(s.round1())
 }
 
 // NewOnlineStateMachine initializes a new Online Signing state machine.
+// msg is the message digest, with the same requirements as in NewStateMachine.
+// preSig is marked used; passing it again fails with ErrPreSignatureUsed.
+// The mark is not persisted: a stored copy of preSig must be deleted before
+// calling, or a restart could sign a second message with the same nonce.
 func NewOnlineStateMachine(params *tss.Parameters, keyData *keygen.LocalPartySaveData, preSig *PreSignature, msg []byte) (tss.StateMachine, []tss.Message, error) {
+	params, curve, err := canonical(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExpectedKey(params, keyData); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDigest(msg); err != nil {
+		return nil, nil, err
+	}
+	if params.DerivationTweak != nil {
+		// The key signed for is fixed when presigning
+		return nil, nil, fmt.Errorf("%w: derivation tweak must be set when presigning", tss.ErrInvalidParameters)
+	}
+	if preSig == nil || preSig.R == nil || preSig.Ki == nil || preSig.SigmaI == nil {
+		return nil, nil, fmt.Errorf("%w: incomplete presignature", tss.ErrInvalidParameters)
+	}
+	if err := preSig.checkR(curve); err != nil {
+		return nil, nil, err
+	}
+	// A presignature signs exactly one message, even if this session aborts
+	if !preSig.used.CompareAndSwap(false, true) {
+		return nil, nil, ErrPreSignatureUsed
+	}
 	s := &state{
 		params:       params,
+		curve:        curve,
 		keyData:      keyData,
-		msgToSign:    msg,
+		msgToSign:    DomainDigest(params.DomainTag, msg),
 		preSignature: preSig,
-		round:        4, // We start by sending Round 4 message (s_i) and waiting for others
+		round:        0, // Signers first agree on the presignature, then send s_i as in Round 4
 		tempData:     make(map[string]interface{}),
 		receivedMsgs: make(map[string][]tss.Message),
 	}
-	return s.roundOnline1()
+	return tss.Wrap(params)(s.roundOnline0())
+}
+
+// canonical returns params with Parties in canonical order, and the curve
+// it names.
+func canonical(params *tss.Parameters) (*tss.Parameters, curves.Curve, error) {
+	curve, err := resolveCurve(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, err = restrictToSigningSet(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Any t+1 members of the committee can sign
+	if len(params.Parties) > 0 && params.Threshold >= len(params.Parties) {
+		return nil, nil, fmt.Errorf("%w: too few signers: %d, need at least %d", tss.ErrInvalidParameters, len(params.Parties), params.Threshold+1)
+	}
+	if t := params.DerivationTweak; t != nil && (t.Sign() <= 0 || t.Cmp(curve.Params().N) >= 0) {
+		return nil, nil, fmt.Errorf("%w: derivation tweak out of range", tss.ErrInvalidParameters)
+	}
+	params, err = params.Canonical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return params, curve, nil
+}
+
+// restrictToSigningSet returns params with Parties replaced by SigningSet,
+// if one is set, so that every round waits for and runs the MtA with the
+// signers only. Every member of SigningSet must be one of Parties.
+func restrictToSigningSet(params *tss.Parameters) (*tss.Parameters, error) {
+	if len(params.SigningSet) == 0 {
+		return params, nil
+	}
+	committee := make(map[string]bool, len(params.Parties))
+	for _, p := range params.Parties {
+		if p != nil {
+			committee[p.ID()] = true
+		}
+	}
+	for _, p := range params.SigningSet {
+		if p == nil || !committee[p.ID()] {
+			return nil, fmt.Errorf("%w: signing set member is not in Parties", tss.ErrInvalidParameters)
+		}
+	}
+	c := *params
+	c.Parties = params.SigningSet
+	c.SigningSet = nil
+	return &c, nil
+}
+
+// checkExpectedKey refuses to sign with key data for another group key than
+// params.ExpectedPublicKey, if one is set.
+func checkExpectedKey(params *tss.Parameters, keyData *keygen.LocalPartySaveData) error {
+	if params.ExpectedPublicKey == nil {
+		return nil
+	}
+	if keyData == nil || !params.ExpectedPublicKey.Equal(keyData.PublicKeyX, keyData.PublicKeyY) {
+		return fmt.Errorf("%w: key data does not match the expected public key", tss.ErrInvalidParameters)
+	}
+	return nil
+}
+
+// resolveCurve looks up the curve named by params.Curve.
+func resolveCurve(params *tss.Parameters) (curves.Curve, error) {
+	if params == nil {
+		return nil, tss.ErrInvalidParameters
+	}
+	return params.ResolveCurve()
 }
 
 func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
-	if msg.RoundNumber() != uint32(s.round) {
-		return nil, nil, fmt.Errorf("received message for round %d, expected %d", msg.RoundNumber(), s.round)
+	// Messages replayed from another session are never processed
+	if err := s.params.CheckSession(msg); err != nil {
+		return s, nil, err
 	}
 
-	senderID := msg.From().ID()
-	if senderID == s.params.PartyID.ID() {
-		return nil, nil, nil
+	switch {
+	case msg.RoundNumber() < uint32(s.round):
+		// Late message for a round we already completed
+		return s, nil, nil
+	case msg.RoundNumber() > uint32(s.round):
+		// Early message: keep it until we reach its round
+		s.pending = append(s.pending, msg)
+		return s, nil, nil
+	}
+
+	if s.params.IsOwnMessage(msg) {
+		return s, nil, nil
 	}
+	senderID := msg.From().ID()
 
 	if s.receivedMsgs == nil {
 		s.receivedMsgs = make(map[string][]tss.Message)
 	}
 	
-	// Check for duplicates
-	for _, existing := range s.receivedMsgs[senderID] {
-		if existing.Type() == msg.Type() {
-			return nil, nil, fmt.Errorf("duplicate message type %s from party %s", msg.Type(), senderID)
-		}
+	// Exact retransmissions are ignored, conflicting ones are equivocation
+	duplicate, err := tss.CheckDuplicate(s.receivedMsgs[senderID], msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if duplicate {
+		return s, nil, nil
 	}
 	
 	s.receivedMsgs[senderID] = append(s.receivedMsgs[senderID], msg)
@@ -95,19 +263,7 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 	}
 	
 	// Check if we have all expected messages per peer
-	expectedCount := 0
-	switch s.round {
-	case 1:
-		expectedCount = 1 // Broadcast K, G commitments
-	case 2:
-		expectedCount = 1 // P2P MtA shares (actually multiple P2P messages? or one bundled?)
-		// In MtA, we exchange with everyone.
-		// Let's assume 1 message per peer containing all MtA data.
-	case 3:
-		expectedCount = 1 // Partial Signature (s_i)
-	case 4:
-		expectedCount = 1 // We expect s_j from everyone in Round 4
-	}
+	expectedCount := s.expectedPerPeer()
 	
 	for _, msgs := range s.receivedMsgs {
 		if len(msgs) < expectedCount {
@@ -115,11 +271,57 @@ func (s *state) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error)
 		}
 	}
 
-	return s.nextRound()
+	return s.advance()
+}
+
+// expectedPerPeer returns how many messages each peer sends in the current round.
+func (s *state) expectedPerPeer() int {
+	switch s.round {
+	case 0:
+		return 1 // Online: presignature session and R
+	case 1:
+		return 1 // Broadcast K, Gamma commitment
+	case 2:
+		return 2 // Broadcast Gamma reveal + P2P MtA shares
+	case 3:
+		return 1 // Partial Signature (s_i)
+	case 4:
+		return 1 // We expect s_j from everyone in Round 4
+	case identifyRound:
+		return 1 // Openings, in place of s_j
+	}
+	return 0
+}
+
+// WaitingFor returns the signers whose messages for the current round are
+// still outstanding.
+func (s *state) WaitingFor() []tss.PartyID {
+	return tss.MissingSenders(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// RemainingForRound returns how many messages the signers still owe for the
+// current round.
+func (s *state) RemainingForRound() int {
+	return tss.RemainingMessages(s.params.Parties, s.params.PartyID, s.receivedMsgs, s.expectedPerPeer())
+}
+
+// advance moves to the next round and replays messages that arrived early.
+func (s *state) advance() (tss.StateMachine, []tss.Message, error) {
+	pending := s.pending
+	s.pending = nil
+	next, out, err := s.nextRound()
+	if err != nil {
+		// The session is over, so nothing needs the secrets any more
+		s.zeroizeSecrets()
+		return &abortedState{err: err}, nil, err
+	}
+	return tss.Replay(next, out, pending)
 }
 
 func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
 	switch s.round {
+	case 0:
+		return s.roundOnline1()
 	case 1:
 		return s.round2()
 	case 2:
@@ -128,6 +330,8 @@ func (s *state) nextRound() (tss.StateMachine, []tss.Message, error) {
 		return s.round4()
 	case 4:
 		return s.round5()
+	case identifyRound:
+		return s.identify()
 	default:
 		return nil, nil, fmt.Errorf("unknown round %d", s.round)
 	}
@@ -138,6 +342,9 @@ func (s *state) Result() interface{} {
 }
 
 func (s *state) Details() string {
+	if s.round == identifyRound {
+		return "Sign Identification"
+	}
 	return fmt.Sprintf("Sign Round %d", s.round)
 }
 
@@ -161,3 +368,33 @@ func (s *finishedState) Result() interface{} {
 func (s *finishedState) Details() string {
 	return "Sign Finished"
 }
+
+// abortedState is the terminal state after a fatal error in a round. Late
+// messages are rejected with tss.ErrProtocolDone instead of reaching a state
+// that can no longer make progress.
+type abortedState struct {
+	err error
+}
+
+func (s *abortedState) Update(msg tss.Message) (tss.StateMachine, []tss.Message, error) {
+	return nil, nil, tss.ErrProtocolDone
+}
+
+func (s *abortedState) Result() interface{} {
+	return nil
+}
+
+func (s *abortedState) Details() string {
+	return fmt.Sprintf("Sign Aborted: %v", s.err)
+}
+
+// secretTempData lists the tempData entries holding secrets: the nonce
+// shares and their Paillier randomness, the MtA masks and outputs and our
+// additive key share.
+var secretTempData = []string{"ki", "rK", "gammai", "wi", "betas", "betaNonces", "nus", "mus", "sigma_i"}
+
+// zeroizeSecrets overwrites and drops the secret tempData entries once the
+// rounds that need them are over, or the session aborted.
+func (s *state) zeroizeSecrets() {
+	zeroize.Entries(s.tempData, secretTempData...)
+}
@@ -1,8 +1,12 @@
 package sign
 
 import (
+	"encoding/hex"
+	"fmt"
 	"math/big"
+	"sync/atomic"
 
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
 
@@ -11,15 +15,91 @@ type Signature struct {
 	R *big.Int
 	S *big.Int
 	RecID int // Recovery ID (optional)
+
+	// Signers lists the IDs of the parties that contributed, in canonical
+	// order; see ContributionReport for their weights.
+	Signers []string
 }
 
 // PreSignature represents the pre-processed data generated in the offline phase.
+//
+// A PreSignature may sign only one message. It records that it was used in
+// memory only, so a copy that was serialized and is loaded again, for
+// instance after a restart, starts out unused. Callers that persist
+// presignatures must track consumption themselves: delete the stored copy,
+// durably, before passing the PreSignature to NewOnlineStateMachine.
 type PreSignature struct {
 	R      *big.Int
 	Rx     *big.Int
 	Ry     *big.Int
 	Ki     *big.Int
 	SigmaI *big.Int
+	Tweak  *big.Int // Additive tweak t of the key signed for, P + t*G; nil if untweaked
+
+	// PreSignSessionID is the session ID of the presigning run that produced
+	// the PreSignature. Online signing checks that every signer's
+	// presignature comes from the same run.
+	PreSignSessionID []byte
+
+	// used is set once online signing has started with the PreSignature.
+	// It is not part of any serialized form; see above.
+	used atomic.Bool
+}
+
+// Used reports whether NewOnlineStateMachine has already been given this
+// PreSignature value in this process. A used PreSignature is rejected: two
+// signatures with the same nonce k reveal the private key. Used does not
+// know about copies, so it is no substitute for tracking persisted
+// presignatures.
+func (p *PreSignature) Used() bool {
+	return p.used.Load()
+}
+
+// RPoint returns a copy of the nonce point R of the presignature. Every
+// signature made with it has r = x mod N, and the parity of y gives its
+// recovery ID, so both are known before the message is signed.
+func (p *PreSignature) RPoint() (x, y *big.Int) {
+	if p.Rx == nil || p.Ry == nil {
+		return nil, nil
+	}
+	return new(big.Int).Set(p.Rx), new(big.Int).Set(p.Ry)
+}
+
+// ID returns the hex-encoded session ID of the presigning run that produced
+// the PreSignature. The presignatures of all signers from one run share the
+// ID.
+func (p *PreSignature) ID() string {
+	return hex.EncodeToString(p.PreSignSessionID)
+}
+
+// checkR verifies that R is a point on curve and that r is its x-coordinate
+// mod N.
+func (p *PreSignature) checkR(curve curves.Curve) error {
+	if p.R != nil && p.R.Sign() == 0 {
+		return fmt.Errorf("%w: presignature r is 0", ErrRetrySign)
+	}
+	if p.R == nil || p.Rx == nil || p.Ry == nil || !curve.IsOnCurve(p.Rx, p.Ry) {
+		return fmt.Errorf("%w: presignature R is not a curve point", tss.ErrInvalidParameters)
+	}
+	if new(big.Int).Mod(p.Rx, curve.Params().N).Cmp(p.R) != 0 {
+		return fmt.Errorf("%w: presignature r does not match R", tss.ErrInvalidParameters)
+	}
+	return nil
+}
+
+// Message types emitted by sign, for tss.DecodeMessage
+func init() {
+	tss.RegisterMessageType("sign",
+		"SignRound1",
+		"SignRound2_Decommit",
+		"SignRound2_MtA",
+		"SignRound3_Delta",
+		"SignRound4_Si",
+		"SignRound4",
+		"SignIdentify",
+		"SignOnline_PreSign",
+		"SignBatch",
+	)
 }
 
 // SignMessage is the concrete message type for Signing.
@@ -30,6 +110,7 @@ type SignMessage struct {
 	Data        []byte
 	TypeString  string
 	RoundNum    uint32
+	Session     []byte // Session the message belongs to
 }
 
 func (m *SignMessage) Type() string {
@@ -55,3 +136,7 @@ func (m *SignMessage) Payload() []byte {
 func (m *SignMessage) RoundNumber() uint32 {
 	return m.RoundNum
 }
+
+func (m *SignMessage) SessionID() []byte {
+	return m.Session
+}
@@ -0,0 +1,152 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
+
+// ErrRecoveryMismatch is returned by VerifyRecoverable when the recovery ID
+// of a signature does not lead back to the expected public key.
+var ErrRecoveryMismatch = errors.New("recovery ID recovers a different public key")
+
+// VerifyWithPubKeyBytes reports whether sig is a valid ECDSA signature over
+// msgHash under a public key given as a SEC 1 compressed point: 33 bytes on
+// secp256k1 or 49 bytes on P-384. The digest is interpreted as in signing,
+// so a signature made with Parameters.DomainTag verifies against
+// DomainDigest(tag, digest). P-256 keys are 33 bytes as well; verify them
+// with VerifyOnCurve.
+//
+// A malformed key or a missing signature is an error; a well-formed
+// signature that does not verify is not.
+func VerifyWithPubKeyBytes(pubKeyCompressed []byte, msgHash []byte, sig *Signature) (bool, error) {
+	curve, x, y, err := parseCompressedKey(pubKeyCompressed)
+	if err != nil {
+		return false, err
+	}
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return false, errors.New("missing signature")
+	}
+	return verifyECDSA(curve, x, y, msgHash, sig.R, sig.S), nil
+}
+
+// VerifyOnCurve is VerifyWithPubKeyBytes for a public key on the named curve
+// (see tss.Parameters.Curve), which need not be told apart by length.
+func VerifyOnCurve(curveName string, pubKeyCompressed []byte, msgHash []byte, sig *Signature) (bool, error) {
+	curve, err := curves.Get(curveName)
+	if err != nil {
+		return false, err
+	}
+	x, y, err := curve.UnmarshalCompressed(pubKeyCompressed)
+	if err != nil {
+		return false, fmt.Errorf("invalid public key: %w", err)
+	}
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return false, errors.New("missing signature")
+	}
+	return verifyECDSA(curve, x, y, msgHash, sig.R, sig.S), nil
+}
+
+// VerifyRecoverable recovers the public key from (R, S, RecID) and msgHash,
+// as e.g. Ethereum's ecrecover does, and checks that it is
+// expectedPubCompressed, given as for VerifyWithPubKeyBytes. A signature that
+// verifies but carries the wrong recovery ID fails with ErrRecoveryMismatch.
+func VerifyRecoverable(sig *Signature, msgHash, expectedPubCompressed []byte) error {
+	curve, x, y, err := parseCompressedKey(expectedPubCompressed)
+	if err != nil {
+		return err
+	}
+	return verifyRecoverable(curve, x, y, sig, msgHash)
+}
+
+// verifyRecoverable is VerifyRecoverable for the public key (x, y) on curve.
+func verifyRecoverable(curve curves.Curve, x, y *big.Int, sig *Signature, msgHash []byte) error {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return errors.New("missing signature")
+	}
+	if !verifyECDSA(curve, x, y, msgHash, sig.R, sig.S) {
+		return errors.New("signature verification failed")
+	}
+	qx, qy, err := recoverPublicKey(curve, sig, msgHash)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRecoveryMismatch, err)
+	}
+	if qx.Cmp(x) != 0 || qy.Cmp(y) != 0 {
+		return ErrRecoveryMismatch
+	}
+	return nil
+}
+
+// recoveryID returns the recovery ID of a signature with nonce point R: bit 0
+// is the parity of R.y, bit 1 is set if R.x overflowed the group order.
+func recoveryID(curve curves.Curve, Rx, Ry *big.Int) int {
+	id := int(Ry.Bit(0))
+	if Rx.Cmp(curve.Params().N) >= 0 {
+		id |= 2
+	}
+	return id
+}
+
+// recoverPublicKey computes Q = r^-1 * (s*R - e*G), where R is the point
+// with x-coordinate r (+ N if bit 1 of RecID is set) and the y parity in
+// bit 0 (SEC 1, section 4.1.6).
+func recoverPublicKey(curve curves.Curve, sig *Signature, msgHash []byte) (*big.Int, *big.Int, error) {
+	N := curve.Params().N
+	r, s := sig.R, sig.S
+	if sig.RecID < 0 || sig.RecID > 3 || r.Sign() <= 0 || r.Cmp(N) >= 0 || s.Sign() <= 0 || s.Cmp(N) >= 0 {
+		return nil, nil, errors.New("invalid signature or recovery ID")
+	}
+
+	Rx := new(big.Int).Set(r)
+	if sig.RecID&2 != 0 {
+		Rx.Add(Rx, N)
+	}
+	if Rx.Cmp(curve.Params().P) >= 0 {
+		return nil, nil, errors.New("R.x out of field range")
+	}
+	encoded := make([]byte, 1+curves.ByteSize(curve))
+	encoded[0] = 0x02 | byte(sig.RecID&1)
+	Rx.FillBytes(encoded[1:])
+	Rpx, Rpy, err := curve.UnmarshalCompressed(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no point R: %w", err)
+	}
+
+	// Q = u1*G + u2*R with u1 = -e * r^-1, u2 = s * r^-1
+	rInv := new(big.Int).ModInverse(r, N)
+	u1 := new(big.Int).Mul(hashToInt(curve, msgHash), rInv)
+	u1.Neg(u1).Mod(u1, N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, N)
+	x1, y1 := curve.ScalarBaseMult(u1)
+	x2, y2 := curve.ScalarMult(Rpx, Rpy, u2)
+	qx, qy := curve.Add(x1, y1, x2, y2)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, nil, errors.New("recovered the point at infinity")
+	}
+	return qx, qy, nil
+}
+
+// parseCompressedKey decodes a SEC 1 compressed point on secp256k1 or P-384,
+// telling the curves apart by length.
+func parseCompressedKey(pubKeyCompressed []byte) (curves.Curve, *big.Int, *big.Int, error) {
+	var curve curves.Curve
+	switch len(pubKeyCompressed) {
+	case 1 + curves.ByteSize(curves.NewSecp256k1()):
+		curve = curves.NewSecp256k1()
+	case 1 + curves.ByteSize(curves.NewP384()):
+		curve = curves.NewP384()
+	default:
+		return nil, nil, nil, fmt.Errorf("invalid public key: %d bytes is not a compressed secp256k1 or P-384 point", len(pubKeyCompressed))
+	}
+	if pubKeyCompressed[0] != 0x02 && pubKeyCompressed[0] != 0x03 {
+		return nil, nil, nil, fmt.Errorf("invalid public key: prefix 0x%02x is not a compressed point", pubKeyCompressed[0])
+	}
+	x, y, err := curve.UnmarshalCompressed(pubKeyCompressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return curve, x, y, nil
+}
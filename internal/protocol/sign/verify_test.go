@@ -0,0 +1,102 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+func TestVerifyWithPubKeyBytes(t *testing.T) {
+	parties := []tss.PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "2"}}
+	keyData := runKeyGen(t, parties, 1)
+	digest := sha256.Sum256([]byte("verify with compressed key"))
+
+	sms := make([]tss.StateMachine, len(parties))
+	outMsgs := make([][]tss.Message, len(parties))
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("sign-verify-bytes"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = NewStateMachine(params, keyData[i], digest[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		sms, outMsgs = routeMessages(t, parties, sms, outMsgs)
+	}
+	sig, ok := sms[0].Result().(*Signature)
+	if !ok {
+		t.Fatalf("Expected Signature, got %T", sms[0].Result())
+	}
+
+	pub := (&tss.PublicKey{X: keyData[0].PublicKeyX, Y: keyData[0].PublicKeyY}).SerializeCompressed()
+	if ok, err := VerifyWithPubKeyBytes(pub, digest[:], sig); err != nil || !ok {
+		t.Fatalf("Signature does not verify against the compressed group key: %v, %v", ok, err)
+	}
+
+	other := sha256.Sum256([]byte("another message"))
+	if ok, err := VerifyWithPubKeyBytes(pub, other[:], sig); err != nil || ok {
+		t.Fatalf("Signature verifies over another digest: %v, %v", ok, err)
+	}
+	bad := &Signature{R: sig.R, S: new(big.Int).Add(sig.S, big.NewInt(1))}
+	if ok, err := VerifyWithPubKeyBytes(pub, digest[:], bad); err != nil || ok {
+		t.Fatalf("Tampered signature verifies: %v, %v", ok, err)
+	}
+
+	// Malformed keys are errors
+	uncompressed := (&tss.PublicKey{X: keyData[0].PublicKeyX, Y: keyData[0].PublicKeyY}).SerializeUncompressed()
+	notOnCurve := append([]byte{0x02}, make([]byte, 32)...)
+	wrongPrefix := append([]byte{0x04}, pub[1:]...)
+	for name, key := range map[string][]byte{
+		"empty":        nil,
+		"truncated":    pub[:32],
+		"uncompressed": uncompressed,
+		"not on curve": notOnCurve,
+		"wrong prefix": wrongPrefix,
+	} {
+		if _, err := VerifyWithPubKeyBytes(key, digest[:], sig); err == nil {
+			t.Errorf("%s key: expected a parse error", name)
+		}
+	}
+	if _, err := VerifyWithPubKeyBytes(pub, digest[:], nil); err == nil {
+		t.Error("Expected an error for a missing signature")
+	}
+
+	// Round 5 sets a recovery ID that recovers the group key, also with an
+	// independent implementation
+	if err := VerifyRecoverable(sig, digest[:], pub); err != nil {
+		t.Fatalf("Recovery failed: %v", err)
+	}
+	compact := make([]byte, 65)
+	compact[0] = byte(27 + 4 + sig.RecID)
+	sig.R.FillBytes(compact[1:33])
+	sig.S.FillBytes(compact[33:])
+	recovered, _, err := ecdsa.RecoverCompact(compact, digest[:])
+	if err != nil || !bytes.Equal(recovered.SerializeCompressed(), pub) {
+		t.Fatalf("RecoverCompact with recovery ID %d: %v", sig.RecID, err)
+	}
+
+	// A wrong v recovers another key, or none
+	for _, v := range []int{sig.RecID ^ 1, sig.RecID ^ 2} {
+		wrong := *sig
+		wrong.RecID = v
+		if err := VerifyRecoverable(&wrong, digest[:], pub); !errors.Is(err, ErrRecoveryMismatch) {
+			t.Fatalf("Recovery ID %d: expected ErrRecoveryMismatch, got %v", v, err)
+		}
+	}
+	if err := VerifyRecoverable(bad, digest[:], pub); err == nil || errors.Is(err, ErrRecoveryMismatch) {
+		t.Fatalf("Expected a verification error for a tampered signature, got %v", err)
+	}
+}
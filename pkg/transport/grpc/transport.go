@@ -0,0 +1,200 @@
+// Package grpc implements tss.Transport over gRPC.
+//
+// Every party serves one unary method, Deliver, which takes a message in the
+// wire form of tss.EncodeMessage, and calls it on its peers to send. Frames
+// travel as raw bytes, so no generated protobuf code is needed.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	gogrpc "google.golang.org/grpc"
+
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// SendTimeout bounds each delivery to a peer, including the wait for the
+// peer to come up.
+const SendTimeout = 30 * time.Second
+
+// inboxSize is how many received messages wait for Recv before Deliver calls
+// block.
+const inboxSize = 256
+
+const deliverMethod = "/gocggmptss.transport.Transport/Deliver"
+
+var serviceDesc = gogrpc.ServiceDesc{
+	ServiceName: "gocggmptss.transport.Transport",
+	HandlerType: (*interface{})(nil),
+	Methods:     []gogrpc.MethodDesc{{MethodName: "Deliver", Handler: deliverHandler}},
+	Metadata:    "transport.go",
+}
+
+// Transport is a tss.Transport over gRPC for one party.
+type Transport struct {
+	self   string
+	server *gogrpc.Server
+	peers  map[string]*gogrpc.ClientConn // Keyed by PartyID.ID()
+
+	inbox     chan tss.Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New serves the party self on lis and connects to its peers, given as
+// addresses keyed by PartyID.ID(). opts configure the connections to peers,
+// e.g. grpc.WithTransportCredentials; serverOpts configure the server.
+//
+// The transport does not authenticate peers: any client can deliver
+// messages. Sign messages by setting tss.Parameters.SigningKey (see
+// tss.WithAuthentication), or secure the connections with mutual TLS.
+func New(self string, lis net.Listener, peers map[string]string, opts []gogrpc.DialOption, serverOpts ...gogrpc.ServerOption) (*Transport, error) {
+	t := &Transport{
+		self:  self,
+		peers: make(map[string]*gogrpc.ClientConn, len(peers)),
+		inbox: make(chan tss.Message, inboxSize),
+		done:  make(chan struct{}),
+	}
+	for id, addr := range peers {
+		if id == self {
+			continue
+		}
+		conn, err := gogrpc.NewClient(addr, opts...)
+		if err != nil {
+			t.closeConns()
+			return nil, fmt.Errorf("connect to %s: %w", id, err)
+		}
+		t.peers[id] = conn
+	}
+
+	t.server = gogrpc.NewServer(append(serverOpts, gogrpc.ForceServerCodec(rawCodec{}))...)
+	t.server.RegisterService(&serviceDesc, t)
+	go t.server.Serve(lis)
+	return t, nil
+}
+
+// Send delivers msg to the parties in msg.To().
+func (t *Transport) Send(msg tss.Message) error {
+	ids := make([]string, len(msg.To()))
+	for i, p := range msg.To() {
+		ids[i] = p.ID()
+	}
+	return t.deliver(msg, ids)
+}
+
+// Broadcast delivers msg to every peer.
+func (t *Transport) Broadcast(msg tss.Message) error {
+	ids := make([]string, 0, len(t.peers))
+	for id := range t.peers {
+		ids = append(ids, id)
+	}
+	return t.deliver(msg, ids)
+}
+
+// Recv blocks until a peer delivers a message or the transport is closed.
+func (t *Transport) Recv() (tss.Message, error) {
+	select {
+	case msg := <-t.inbox:
+		return msg, nil
+	case <-t.done:
+		return nil, tss.ErrTransportClosed
+	}
+}
+
+// Close stops the server and closes the connections to peers. Pending and
+// later calls to Recv return tss.ErrTransportClosed.
+func (t *Transport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.server.Stop()
+		t.closeConns()
+	})
+	return nil
+}
+
+func (t *Transport) closeConns() {
+	for _, conn := range t.peers {
+		conn.Close()
+	}
+}
+
+func (t *Transport) deliver(msg tss.Message, ids []string) error {
+	frame, err := tss.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		conn, ok := t.peers[id]
+		if !ok {
+			return fmt.Errorf("unknown peer %s", id)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), SendTimeout)
+		var reply []byte
+		err := conn.Invoke(ctx, deliverMethod, &frame, &reply, gogrpc.ForceCodec(rawCodec{}), gogrpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			return fmt.Errorf("send %s to %s: %w", msg.Type(), id, err)
+		}
+	}
+	return nil
+}
+
+// receive queues a frame delivered by a peer, waiting for room in the inbox
+// until ctx ends.
+func (t *Transport) receive(ctx context.Context, frame []byte) error {
+	msg, err := tss.DecodeMessage(frame)
+	if err != nil {
+		return err
+	}
+	select {
+	case t.inbox <- msg:
+		return nil
+	case <-t.done:
+		return tss.ErrTransportClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func deliverHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	var frame []byte
+	if err := dec(&frame); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &[]byte{}, srv.(*Transport).receive(ctx, *req.(*[]byte))
+	}
+	if interceptor == nil {
+		return handler(ctx, &frame)
+	}
+	info := &gogrpc.UnaryServerInfo{Server: srv, FullMethod: deliverMethod}
+	return interceptor(ctx, &frame, info, handler)
+}
+
+// rawCodec passes frames through unchanged. Messages are *[]byte.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: cannot marshal %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec: cannot unmarshal into %T", v)
+	}
+	*b = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "raw"
+}
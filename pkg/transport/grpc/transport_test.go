@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestKeyGenOverGRPC runs a keygen with three parties, each with its own
+// gRPC server on the loopback interface.
+func TestKeyGenOverGRPC(t *testing.T) {
+	parties := make([]tss.PartyID, 3)
+	listeners := make([]net.Listener, len(parties))
+	addrs := make(map[string]string)
+	for i := range parties {
+		id := fmt.Sprintf("%d", i+1)
+		parties[i] = &tss.BasicPartyID{IDVal: id, MonikerVal: id}
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skipf("cannot listen on loopback: %v", err)
+		}
+		listeners[i] = lis
+		addrs[id] = lis.Addr().String()
+	}
+
+	transports := make([]*Transport, len(parties))
+	for i, p := range parties {
+		tr, err := New(p.ID(), listeners[i], addrs, []gogrpc.DialOption{gogrpc.WithTransportCredentials(insecure.NewCredentials())})
+		if err != nil {
+			t.Fatal(err)
+		}
+		transports[i] = tr
+		defer tr.Close()
+	}
+
+	results := make([]interface{}, len(parties))
+	errs := make([]error, len(parties))
+	var wg sync.WaitGroup
+	for i, p := range parties {
+		sm, out, err := keygen.NewStateMachine(&tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("grpc-keygen"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = tss.RunParty(sm, out, transports[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range parties {
+		if errs[i] != nil {
+			t.Fatalf("party %d: %v", i, errs[i])
+		}
+		key := results[i].(*keygen.LocalPartySaveData)
+		if key.PublicKeyX.Cmp(results[0].(*keygen.LocalPartySaveData).PublicKeyX) != 0 {
+			t.Fatalf("party %d has a different public key", i)
+		}
+	}
+
+	// Closing unblocks Recv
+	transports[0].Close()
+	if _, err := transports[0].Recv(); !errors.Is(err, tss.ErrTransportClosed) {
+		t.Fatalf("expected ErrTransportClosed, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package address
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/base58"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/bech32"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/ripemd160"
+)
+
+// Bitcoin networks accepted by BitcoinAddress and BitcoinP2WPKHAddress
+const (
+	Mainnet = "mainnet"
+	Testnet = "testnet"
+)
+
+// ErrInvalidPublicKey is returned for a public key that is not a point on
+// secp256k1.
+var ErrInvalidPublicKey = errors.New("address: invalid secp256k1 public key")
+
+// bitcoinNetwork holds the address prefixes of a Bitcoin network.
+type bitcoinNetwork struct {
+	p2pkhVersion byte   // Base58Check version byte of P2PKH addresses
+	hrp          string // Bech32 human-readable part of SegWit addresses
+}
+
+var bitcoinNetworks = map[string]bitcoinNetwork{
+	Mainnet: {p2pkhVersion: 0x00, hrp: "bc"},
+	Testnet: {p2pkhVersion: 0x6f, hrp: "tb"},
+}
+
+// BitcoinAddress returns the Base58Check P2PKH address ("1..." on mainnet,
+// "m..." or "n..." on testnet) of the secp256k1 public key (pubX, pubY),
+// hashing its compressed encoding.
+func BitcoinAddress(pubX, pubY *big.Int, network string) (string, error) {
+	net, pkh, err := bitcoinPubKeyHash(pubX, pubY, network)
+	if err != nil {
+		return "", err
+	}
+	payload := append([]byte{net.p2pkhVersion}, pkh[:]...)
+	first := sha256.Sum256(payload)
+	checksum := sha256.Sum256(first[:])
+	return base58.Encode(append(payload, checksum[:4]...)), nil
+}
+
+// BitcoinP2WPKHAddress returns the Bech32 P2WPKH address ("bc1q..." on
+// mainnet, "tb1q..." on testnet) of the secp256k1 public key (pubX, pubY),
+// hashing its compressed encoding.
+func BitcoinP2WPKHAddress(pubX, pubY *big.Int, network string) (string, error) {
+	net, pkh, err := bitcoinPubKeyHash(pubX, pubY, network)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeSegwit(net.hrp, 0, pkh[:])
+}
+
+// bitcoinPubKeyHash returns the network's prefixes and HASH160 of the
+// compressed public key.
+func bitcoinPubKeyHash(pubX, pubY *big.Int, network string) (bitcoinNetwork, [ripemd160.Size]byte, error) {
+	net, ok := bitcoinNetworks[network]
+	if !ok {
+		return bitcoinNetwork{}, [ripemd160.Size]byte{}, fmt.Errorf("address: unknown bitcoin network %q", network)
+	}
+	curve := curves.NewSecp256k1()
+	if pubX == nil || pubY == nil || !curve.IsOnCurve(pubX, pubY) {
+		return bitcoinNetwork{}, [ripemd160.Size]byte{}, ErrInvalidPublicKey
+	}
+	h := sha256.Sum256(curve.MarshalCompressed(pubX, pubY))
+	return net, ripemd160.Sum(h[:]), nil
+}
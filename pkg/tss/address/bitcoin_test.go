@@ -0,0 +1,48 @@
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestBitcoinAddress(t *testing.T) {
+	// The compressed key of private key 1, also the BIP-173 example key
+	compressed, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	pub, err := secp256k1.ParsePubKey(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := []struct {
+		network string
+		p2pkh   string
+		p2wpkh  string
+	}{
+		{Mainnet, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{Testnet, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+	}
+	for _, v := range vectors {
+		got, err := BitcoinAddress(pub.X(), pub.Y(), v.network)
+		if err != nil || got != v.p2pkh {
+			t.Errorf("%s P2PKH: got %s (%v), want %s", v.network, got, err, v.p2pkh)
+		}
+		got, err = BitcoinP2WPKHAddress(pub.X(), pub.Y(), v.network)
+		if err != nil || got != v.p2wpkh {
+			t.Errorf("%s P2WPKH: got %s (%v), want %s", v.network, got, err, v.p2wpkh)
+		}
+	}
+}
+
+func TestBitcoinAddressInvalid(t *testing.T) {
+	pub := secp256k1.NewPrivateKey(new(secp256k1.ModNScalar).SetInt(1)).PubKey()
+	if _, err := BitcoinAddress(pub.X(), pub.Y(), "regtest"); err == nil {
+		t.Fatal("Expected an error for an unknown network")
+	}
+	if _, err := BitcoinP2WPKHAddress(big.NewInt(1), big.NewInt(1), Mainnet); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
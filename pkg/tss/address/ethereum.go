@@ -0,0 +1,22 @@
+// Package address derives blockchain addresses from a threshold group public
+// key. It lives apart from package tss so that users who only sign do not
+// pull in the hash functions addresses need.
+package address
+
+import (
+	"math/big"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/keccak"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// EthereumAddress returns the 20-byte Ethereum address of the secp256k1
+// public key (pubX, pubY): the last 20 bytes of
+// keccak256(uncompressed_pubkey[1:]), i.e. of the hash of X || Y.
+func EthereumAddress(pubX, pubY *big.Int) [20]byte {
+	pub := (&tss.PublicKey{X: pubX, Y: pubY}).SerializeUncompressed()
+	hash := keccak.Sum256(pub[1:])
+	var addr [20]byte
+	copy(addr[:], hash[12:])
+	return addr
+}
@@ -0,0 +1,30 @@
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestEthereumAddress(t *testing.T) {
+	// Well-known addresses of the private keys 1 and 2
+	vectors := []struct {
+		priv byte
+		addr string
+	}{
+		{1, "7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		{2, "2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},
+	}
+	for _, v := range vectors {
+		var key [32]byte
+		key[31] = v.priv
+		pub := secp256k1.PrivKeyFromBytes(key[:]).PubKey()
+
+		addr := EthereumAddress(pub.X(), pub.Y())
+		if got := hex.EncodeToString(addr[:]); got != strings.ToLower(v.addr) {
+			t.Errorf("Private key %d: address %s, want %s", v.priv, got, strings.ToLower(v.addr))
+		}
+	}
+}
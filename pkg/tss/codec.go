@@ -0,0 +1,138 @@
+package tss
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// BasicPartyID is a plain PartyID, as produced by DecodeMessage.
+// State machines match parties by ID(), so a decoded sender is interchangeable
+// with the caller's own PartyID for the same participant.
+type BasicPartyID struct {
+	IDVal      string
+	MonikerVal string
+	KeyVal     []byte
+}
+
+func (p *BasicPartyID) ID() string      { return p.IDVal }
+func (p *BasicPartyID) Moniker() string { return p.MonikerVal }
+func (p *BasicPartyID) Key() []byte     { return p.KeyVal }
+
+// BasicMessage is a generic Message, as produced by DecodeMessage.
+// It is accepted by every protocol's Update.
+type BasicMessage struct {
+	FromParty  PartyID
+	ToParties  []PartyID
+	IsBcast    bool
+	Data       []byte
+	TypeString string
+	RoundNum   uint32
+	Session    []byte
+}
+
+func (m *BasicMessage) Type() string        { return m.TypeString }
+func (m *BasicMessage) From() PartyID       { return m.FromParty }
+func (m *BasicMessage) To() []PartyID       { return m.ToParties }
+func (m *BasicMessage) IsBroadcast() bool   { return m.IsBcast }
+func (m *BasicMessage) Payload() []byte     { return m.Data }
+func (m *BasicMessage) RoundNumber() uint32 { return m.RoundNum }
+func (m *BasicMessage) SessionID() []byte   { return m.Session }
+
+var messageTypes = struct {
+	sync.RWMutex
+	protocol map[string]string // Type() -> protocol name
+}{protocol: make(map[string]string)}
+
+// RegisterMessageType registers the Type() strings a protocol emits, so that
+// DecodeMessage accepts them. Protocol packages call it from init.
+// Registering a type for two different protocols panics.
+func RegisterMessageType(protocol string, types ...string) {
+	messageTypes.Lock()
+	defer messageTypes.Unlock()
+	for _, t := range types {
+		if existing, ok := messageTypes.protocol[t]; ok && existing != protocol {
+			panic(fmt.Sprintf("tss: message type %q registered by both %s and %s", t, existing, protocol))
+		}
+		messageTypes.protocol[t] = protocol
+	}
+}
+
+// MessageProtocol returns the protocol that registered the message type.
+func MessageProtocol(msgType string) (string, bool) {
+	messageTypes.RLock()
+	defer messageTypes.RUnlock()
+	p, ok := messageTypes.protocol[msgType]
+	return p, ok
+}
+
+// wireMessage is the JSON wire form of a Message. Byte fields are base64.
+type wireMessage struct {
+	Type      string   `json:"type"`
+	Round     uint32   `json:"round"`
+	From      string   `json:"from"`
+	To        []string `json:"to,omitempty"`
+	Broadcast bool     `json:"isBroadcast"`
+	Payload   []byte   `json:"payload"`
+	SessionID []byte   `json:"sessionID"`
+	Signature []byte   `json:"signature,omitempty"` // Only for SignedMessage
+}
+
+// EncodeMessage serializes a message of any protocol into its wire form.
+// A *SignedMessage keeps its signature.
+func EncodeMessage(msg Message) ([]byte, error) {
+	signed, isSigned := msg.(*SignedMessage)
+	if msg == nil || (isSigned && signed.Message == nil) || msg.From() == nil {
+		return nil, fmt.Errorf("%w: message has no sender", ErrInvalidMsg)
+	}
+	if _, ok := MessageProtocol(msg.Type()); !ok {
+		return nil, fmt.Errorf("%w: unregistered message type %q", ErrInvalidMsg, msg.Type())
+	}
+	w := wireMessage{
+		Type:      msg.Type(),
+		Round:     msg.RoundNumber(),
+		From:      msg.From().ID(),
+		Broadcast: msg.IsBroadcast(),
+		Payload:   msg.Payload(),
+		SessionID: msg.SessionID(),
+	}
+	for _, p := range msg.To() {
+		w.To = append(w.To, p.ID())
+	}
+	if isSigned {
+		w.Signature = signed.Signature
+	}
+	return json.Marshal(w)
+}
+
+// DecodeMessage parses the wire form produced by EncodeMessage.
+// It returns a *BasicMessage, or a *SignedMessage wrapping one if the message
+// was signed. Unregistered message types are rejected.
+func DecodeMessage(data []byte) (Message, error) {
+	var w wireMessage
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMsg, err)
+	}
+	if _, ok := MessageProtocol(w.Type); !ok {
+		return nil, fmt.Errorf("%w: unregistered message type %q", ErrInvalidMsg, w.Type)
+	}
+	if w.From == "" {
+		return nil, fmt.Errorf("%w: message has no sender", ErrInvalidMsg)
+	}
+
+	msg := &BasicMessage{
+		FromParty:  &BasicPartyID{IDVal: w.From, MonikerVal: w.From},
+		IsBcast:    w.Broadcast,
+		Data:       w.Payload,
+		TypeString: w.Type,
+		RoundNum:   w.Round,
+		Session:    w.SessionID,
+	}
+	for _, id := range w.To {
+		msg.ToParties = append(msg.ToParties, &BasicPartyID{IDVal: id, MonikerVal: id})
+	}
+	if w.Signature != nil {
+		return &SignedMessage{Message: msg, Signature: w.Signature}, nil
+	}
+	return msg, nil
+}
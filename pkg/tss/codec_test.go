@@ -0,0 +1,90 @@
+package tss
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func init() {
+	RegisterMessageType("codec-test", "TestRound1", "TestRound2")
+}
+
+func TestMessageCodecRoundTrip(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	msgs := []*MockMessage{
+		{msgType: "TestRound1", from: p1, isBroadcast: true, payload: []byte{0x00, 0xff, 0x10}, round: 1, sessionID: []byte("sid")},
+		{msgType: "TestRound2", from: p2, to: []PartyID{p1, p3}, payload: []byte("share"), round: 2},
+		{msgType: "TestRound2", from: p3, to: []PartyID{p1}, round: 2},
+	}
+	for _, msg := range msgs {
+		data, err := EncodeMessage(msg)
+		if err != nil {
+			t.Fatalf("EncodeMessage: %v", err)
+		}
+		decoded, err := DecodeMessage(data)
+		if err != nil {
+			t.Fatalf("DecodeMessage: %v", err)
+		}
+		basic, ok := decoded.(*BasicMessage)
+		if !ok {
+			t.Fatalf("Expected *BasicMessage, got %T", decoded)
+		}
+		if basic.Type() != msg.Type() || basic.RoundNumber() != msg.RoundNumber() ||
+			basic.From().ID() != msg.From().ID() || basic.IsBroadcast() != msg.IsBroadcast() ||
+			!bytes.Equal(basic.Payload(), msg.Payload()) || len(basic.To()) != len(msg.To()) ||
+			!bytes.Equal(basic.SessionID(), msg.SessionID()) {
+			t.Fatalf("Round trip changed %s message: %+v", msg.Type(), basic)
+		}
+		for i, to := range msg.To() {
+			if basic.To()[i].ID() != to.ID() {
+				t.Fatalf("Recipient %d changed: %s", i, basic.To()[i].ID())
+			}
+		}
+
+		// The encoding is deterministic
+		again, _ := EncodeMessage(decoded)
+		if !bytes.Equal(again, data) {
+			t.Fatalf("Re-encoding differs:\n%s\n%s", data, again)
+		}
+	}
+}
+
+func TestMessageCodecSigned(t *testing.T) {
+	alice, priv := newIdentity(t, "alice")
+	signed := NewSignedMessage(&MockMessage{msgType: "TestRound1", from: alice, isBroadcast: true, payload: []byte("x"), round: 1, sessionID: []byte("session")})
+	if err := signed.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncodeMessage(signed)
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage: %v", err)
+	}
+	got, ok := decoded.(*SignedMessage)
+	if !ok {
+		t.Fatalf("Expected *SignedMessage, got %T", decoded)
+	}
+	if err := got.Verify(alice.Key()); err != nil {
+		t.Fatalf("Signature lost on the wire: %v", err)
+	}
+}
+
+func TestMessageCodecRejects(t *testing.T) {
+	unknown := &MockMessage{msgType: "Unknown", from: &MockPartyID{id: "1"}, round: 1}
+	if _, err := EncodeMessage(unknown); !errors.Is(err, ErrInvalidMsg) {
+		t.Fatalf("Expected ErrInvalidMsg for unregistered type, got %v", err)
+	}
+	for _, data := range []string{
+		`not json`,
+		`{"type":"Unknown","round":1,"from":"1","isBroadcast":true,"payload":""}`,
+		`{"type":"TestRound1","round":1,"from":"","isBroadcast":true,"payload":""}`,
+	} {
+		if _, err := DecodeMessage([]byte(data)); !errors.Is(err, ErrInvalidMsg) {
+			t.Fatalf("Expected ErrInvalidMsg for %s, got %v", data, err)
+		}
+	}
+}
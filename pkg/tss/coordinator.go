@@ -0,0 +1,124 @@
+package tss
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LocalCoordinator runs every party of a protocol session in one process,
+// e.g. for tests, simulations or a single-machine deployment. It routes each
+// outgoing message to its recipients' state machines, as a network would,
+// until no messages are left.
+//
+// Messages are delivered in the order they were sent, so a party never sees a
+// message of the next round before those of the current one.
+type LocalCoordinator struct {
+	machines map[string]StateMachine
+	pending  []Message
+}
+
+// NewLocalCoordinator returns a coordinator without parties; see Add.
+func NewLocalCoordinator() *LocalCoordinator {
+	return &LocalCoordinator{machines: make(map[string]StateMachine)}
+}
+
+// Add registers the state machine of the party with the given ID, along with
+// the messages its constructor returned.
+func (c *LocalCoordinator) Add(id string, sm StateMachine, out []Message) {
+	c.machines[id] = sm
+	c.pending = append(c.pending, out...)
+}
+
+// Run delivers messages until none are left and returns the result of every
+// party, keyed by ID. It stops at the first error a state machine returns,
+// or if a party has not finished once no messages are left.
+func (c *LocalCoordinator) Run() (map[string]interface{}, error) {
+	ids := make([]string, 0, len(c.machines))
+	for id := range c.machines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for len(c.pending) > 0 {
+		msg := c.pending[0]
+		c.pending = c.pending[1:]
+		for _, id := range c.recipients(msg, ids) {
+			sm, ok := c.machines[id]
+			if !ok {
+				return nil, fmt.Errorf("no state machine for recipient %s of %s", id, msg.Type())
+			}
+			if sm.Result() != nil {
+				continue // Already finished
+			}
+			next, out, err := sm.Update(msg)
+			if err != nil {
+				return nil, fmt.Errorf("party %s: %w", id, err)
+			}
+			if next != nil {
+				c.machines[id] = next
+			}
+			c.pending = append(c.pending, out...)
+		}
+	}
+
+	results := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		sm := c.machines[id]
+		if sm.Result() == nil {
+			return nil, fmt.Errorf("party %s did not finish: %s", id, sm.Details())
+		}
+		results[id] = sm.Result()
+	}
+	return results, nil
+}
+
+// recipients returns the IDs msg is delivered to: every party but the sender
+// for a broadcast, otherwise its To list.
+func (c *LocalCoordinator) recipients(msg Message, ids []string) []string {
+	if msg.IsBroadcast() || len(msg.To()) == 0 {
+		to := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if id != msg.From().ID() {
+				to = append(to, id)
+			}
+		}
+		return to
+	}
+	to := make([]string, len(msg.To()))
+	for i, p := range msg.To() {
+		to[i] = p.ID()
+	}
+	return to
+}
+
+// RunParty is the networked counterpart of LocalCoordinator: it drives the
+// state machine of a single party over t until it finishes, and returns its
+// result. out are the messages the state machine's constructor returned.
+//
+// Peers may run ahead, so t can deliver a message before the round it
+// belongs to; the protocols keep such messages until they get there.
+func RunParty(sm StateMachine, out []Message, t Transport) (interface{}, error) {
+	for {
+		for _, msg := range out {
+			if err := Deliver(t, msg); err != nil {
+				return nil, err
+			}
+		}
+		if result := sm.Result(); result != nil {
+			return result, nil
+		}
+
+		msg, err := t.Recv()
+		if err != nil {
+			return nil, err
+		}
+		next, msgs, err := sm.Update(msg)
+		if err != nil {
+			return nil, err
+		}
+		if next != nil {
+			sm = next
+		}
+		out = msgs
+	}
+}
@@ -0,0 +1,82 @@
+package tss
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// helloMachine broadcasts one message and finishes once it has heard from
+// every peer.
+type helloMachine struct {
+	self  PartyID
+	peers int
+	heard map[string]bool
+	fail  bool
+}
+
+func newHello(self PartyID, peers int) (StateMachine, []Message) {
+	return &helloMachine{self: self, peers: peers, heard: make(map[string]bool)},
+		[]Message{&MockMessage{msgType: "hello", from: self, isBroadcast: true, round: 1}}
+}
+
+func (h *helloMachine) Update(msg Message) (StateMachine, []Message, error) {
+	if h.fail {
+		return nil, nil, errors.New("boom")
+	}
+	h.heard[msg.From().ID()] = true
+	return h, nil, nil
+}
+
+func (h *helloMachine) Result() interface{} {
+	if len(h.heard) < h.peers {
+		return nil
+	}
+	return h.self.ID()
+}
+
+func (h *helloMachine) Details() string { return "hello" }
+
+func TestLocalCoordinator(t *testing.T) {
+	c := NewLocalCoordinator()
+	for _, id := range []string{"1", "2", "3"} {
+		sm, out := newHello(&MockPartyID{id: id}, 2)
+		c.Add(id, sm, out)
+	}
+	results, err := c.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results["2"] != "2" {
+		t.Fatalf("unexpected results %v", results)
+	}
+
+	// A party that never hears from everyone stalls the run
+	c = NewLocalCoordinator()
+	for _, id := range []string{"1", "2"} {
+		sm, out := newHello(&MockPartyID{id: id}, 2)
+		c.Add(id, sm, out)
+	}
+	if _, err := c.Run(); err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+
+	// Update errors name the party
+	c = NewLocalCoordinator()
+	sm1, out1 := newHello(&MockPartyID{id: "1"}, 1)
+	sm2, _ := newHello(&MockPartyID{id: "2"}, 1)
+	sm2.(*helloMachine).fail = true
+	c.Add("1", sm1, out1)
+	c.Add("2", sm2, nil)
+	if _, err := c.Run(); err == nil || !strings.HasPrefix(err.Error(), "party 2:") {
+		t.Fatalf("expected error from party 2, got %v", err)
+	}
+
+	// Messages to an unknown party are an error
+	c = NewLocalCoordinator()
+	sm1, _ = newHello(&MockPartyID{id: "1"}, 0)
+	c.Add("1", sm1, []Message{&MockMessage{msgType: "hello", from: &MockPartyID{id: "1"}, to: []PartyID{&MockPartyID{id: "9"}}}})
+	if _, err := c.Run(); err == nil {
+		t.Fatal("expected error for unknown recipient")
+	}
+}
@@ -0,0 +1,82 @@
+package tss
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ErrInvalidDerivation is returned for a BIP32 derivation that is not
+// possible from a public key: a hardened index, or one of the (negligibly
+// rare) indices BIP32 declares invalid, which callers should skip.
+var ErrInvalidDerivation = errors.New("invalid key derivation")
+
+// HardenedIndex is the first hardened BIP32 child index. Hardened children
+// need the parent secret key, which no single party holds.
+const HardenedIndex uint32 = 1 << 31
+
+// DeriveChildPublicKey derives the non-hardened BIP32 child at index of the
+// secp256k1 public key (parentX, parentY) with the given 32-byte chain code
+// (CKDpub). It needs no secret, so e.g. a wallet backend can derive receive
+// addresses from the group public key; see DerivePublicKeyPath for the tweak
+// that makes them signable.
+func DeriveChildPublicKey(parentX, parentY *big.Int, chainCode []byte, index uint32) (childX, childY *big.Int, childChainCode []byte, err error) {
+	childX, childY, childChainCode, _, err = deriveChild(parentX, parentY, chainCode, index)
+	return childX, childY, childChainCode, err
+}
+
+// DerivePublicKeyPath applies DeriveChildPublicKey along path and returns the
+// final public key and chain code, along with the tweak t (the sum of every
+// step's I_L mod n) such that the child key is P + t*G. Setting
+// Parameters.DerivationTweak to t lets the committee sign for the child key
+// without a new KeyGen; every signer must set the same t, and with
+// presignatures it must be set when presigning.
+func DerivePublicKeyPath(parentX, parentY *big.Int, chainCode []byte, path []uint32) (child *PublicKey, childChainCode []byte, tweak *big.Int, err error) {
+	x, y, cc := parentX, parentY, chainCode
+	tweak = new(big.Int)
+	for _, index := range path {
+		var il *big.Int
+		if x, y, cc, il, err = deriveChild(x, y, cc, index); err != nil {
+			return nil, nil, nil, err
+		}
+		tweak.Add(tweak, il)
+		tweak.Mod(tweak, secp256k1.S256().N)
+	}
+	return &PublicKey{X: x, Y: y}, cc, tweak, nil
+}
+
+// deriveChild is CKDpub, also returning I_L.
+func deriveChild(parentX, parentY *big.Int, chainCode []byte, index uint32) (*big.Int, *big.Int, []byte, *big.Int, error) {
+	if index >= HardenedIndex {
+		return nil, nil, nil, nil, fmt.Errorf("%w: hardened index %d", ErrInvalidDerivation, index)
+	}
+	if len(chainCode) != 32 {
+		return nil, nil, nil, nil, fmt.Errorf("%w: chain code of %d bytes", ErrInvalidParameters, len(chainCode))
+	}
+	curve := secp256k1.S256()
+	if parentX == nil || parentY == nil || !curve.IsOnCurve(parentX, parentY) {
+		return nil, nil, nil, nil, fmt.Errorf("%w: parent key is not on secp256k1", ErrInvalidParameters)
+	}
+
+	// I = HMAC-SHA512(c_par, ser_P(K_par) || ser_32(i))
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write((&PublicKey{X: parentX, Y: parentY}).SerializeCompressed())
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curve.N) >= 0 {
+		return nil, nil, nil, nil, fmt.Errorf("%w: I_L out of range at index %d", ErrInvalidDerivation, index)
+	}
+	tx, ty := curve.ScalarBaseMult(sum[:32])
+	x, y := curve.Add(tx, ty, parentX, parentY)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, nil, nil, nil, fmt.Errorf("%w: child key at infinity at index %d", ErrInvalidDerivation, index)
+	}
+	return x, y, sum[32:], il, nil
+}
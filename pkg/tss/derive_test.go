@@ -0,0 +1,97 @@
+package tss
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/base58"
+)
+
+// parseXpub decodes a BIP32 extended public key into its key and chain code.
+func parseXpub(t *testing.T, xpub string) (*PublicKey, []byte) {
+	t.Helper()
+	raw, err := base58.Decode(xpub)
+	if err != nil || len(raw) != 82 {
+		t.Fatalf("Malformed xpub %s: %v", xpub, err)
+	}
+	first := sha256.Sum256(raw[:78])
+	check := sha256.Sum256(first[:])
+	if !bytes.Equal(check[:4], raw[78:]) {
+		t.Fatalf("Bad checksum in xpub %s", xpub)
+	}
+	key, err := ParsePublicKey(raw[45:78])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, raw[13:45]
+}
+
+// TestDeriveChildPublicKey follows the public derivation steps of BIP32 test
+// vector 1.
+func TestDeriveChildPublicKey(t *testing.T) {
+	steps := []struct {
+		parent, child string
+		index         uint32
+	}{
+		{ // m/0H -> m/0H/1
+			"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			1,
+		},
+		{ // m/0H/1/2H -> m/0H/1/2H/2
+			"xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			"xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			2,
+		},
+		{ // m/0H/1/2H/2 -> m/0H/1/2H/2/1000000000
+			"xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+			1000000000,
+		},
+	}
+	for _, step := range steps {
+		parent, chainCode := parseXpub(t, step.parent)
+		want, wantChainCode := parseXpub(t, step.child)
+		x, y, cc, err := DeriveChildPublicKey(parent.X, parent.Y, chainCode, step.index)
+		if err != nil {
+			t.Fatalf("Index %d: %v", step.index, err)
+		}
+		if !want.Equal(x, y) || !bytes.Equal(cc, wantChainCode) {
+			t.Fatalf("Index %d: derived key or chain code does not match the test vector", step.index)
+		}
+	}
+
+	// Two steps at once, with the tweak that relates the child to the parent
+	parent, chainCode := parseXpub(t, steps[1].parent)
+	want, wantChainCode := parseXpub(t, steps[2].child)
+	child, cc, tweak, err := DerivePublicKeyPath(parent.X, parent.Y, chainCode, []uint32{2, 1000000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !want.Equal(child.X, child.Y) || !bytes.Equal(cc, wantChainCode) {
+		t.Fatal("Derived path does not match the test vector")
+	}
+	curve := secp256k1.S256()
+	tx, ty := curve.ScalarBaseMult(tweak.Bytes())
+	if x, y := curve.Add(parent.X, parent.Y, tx, ty); !child.Equal(x, y) {
+		t.Fatal("Child key is not parent + tweak*G")
+	}
+}
+
+func TestDeriveChildPublicKeyInvalid(t *testing.T) {
+	parent, chainCode := parseXpub(t, "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8")
+	if _, _, _, err := DeriveChildPublicKey(parent.X, parent.Y, chainCode, HardenedIndex); !errors.Is(err, ErrInvalidDerivation) {
+		t.Fatalf("Expected ErrInvalidDerivation for a hardened index, got %v", err)
+	}
+	if _, _, _, err := DeriveChildPublicKey(parent.X, parent.Y, chainCode[:31], 0); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a short chain code, got %v", err)
+	}
+	if _, _, _, err := DeriveChildPublicKey(parent.X, new(big.Int).Add(parent.Y, big.NewInt(1)), chainCode, 0); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a point off the curve, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package tss
+
+import "bytes"
+
+// CheckDuplicate compares msg with the messages already received from its
+// sender in the current round.
+//
+// An exact retransmission (same type, round and payload) is common with
+// at-least-once transports; it is reported as a duplicate so the caller can
+// ignore it. A different payload for the same type and round means the
+// sender equivocated, which is returned as a Blame.
+func CheckDuplicate(received []Message, msg Message) (duplicate bool, err error) {
+	for _, existing := range received {
+		if existing.Type() != msg.Type() || existing.RoundNumber() != msg.RoundNumber() {
+			continue
+		}
+		if bytes.Equal(existing.Payload(), msg.Payload()) {
+			return true, nil
+		}
+		return false, NewBlame(msg.From(), "equivocation: conflicting "+msg.Type()+" message", nil)
+	}
+	return false, nil
+}
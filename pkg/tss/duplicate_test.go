@@ -0,0 +1,34 @@
+package tss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDuplicate(t *testing.T) {
+	sender := &MockPartyID{id: "p2"}
+	first := &MockMessage{msgType: "Round1", from: sender, isBroadcast: true, payload: []byte{1, 2, 3}, round: 1}
+	received := []Message{first}
+
+	// Exact retransmission
+	retransmit := &MockMessage{msgType: "Round1", from: sender, isBroadcast: true, payload: []byte{1, 2, 3}, round: 1}
+	dup, err := CheckDuplicate(received, retransmit)
+	if err != nil || !dup {
+		t.Fatalf("Expected benign duplicate, got dup=%v err=%v", dup, err)
+	}
+
+	// Different message type from the same sender
+	other := &MockMessage{msgType: "Round1_P2P", from: sender, payload: []byte{9}, round: 1}
+	dup, err = CheckDuplicate(received, other)
+	if err != nil || dup {
+		t.Fatalf("Expected new message, got dup=%v err=%v", dup, err)
+	}
+
+	// Same type and round with a different payload
+	conflicting := &MockMessage{msgType: "Round1", from: sender, isBroadcast: true, payload: []byte{1, 2, 4}, round: 1}
+	_, err = CheckDuplicate(received, conflicting)
+	var blame *Blame
+	if !errors.As(err, &blame) || blame.PartyID.ID() != "p2" {
+		t.Fatalf("Expected blame on p2, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package tss
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// EchoMessageType is the Type() of the echo messages of WithEchoBroadcast.
+const EchoMessageType = "EchoBroadcast"
+
+// echoDomain separates echo hashes from any other hash of a message.
+const echoDomain = "go-cggmp-tss/echo-broadcast/v1"
+
+// ErrEquivocation is wrapped by the error returned when peers hold
+// different copies of a broadcast message.
+var ErrEquivocation = errors.New("broadcast equivocation")
+
+func init() {
+	RegisterMessageType("echo", EchoMessageType)
+}
+
+// echoPayload is the payload of an echo message: the hash of the broadcast
+// message of type Type and round Round that the echoing party received from
+// From.
+type echoPayload struct {
+	From  string
+	Type  string
+	Round uint32
+	Hash  []byte
+}
+
+// echoKey identifies a broadcast message: its sender, type and round.
+type echoKey struct {
+	from  string
+	typ   string
+	round uint32
+}
+
+// WithEchoBroadcast wraps the result of a protocol constructor so that
+// broadcasts are checked for equivocation, as CGGMP assumes a reliable
+// broadcast channel: a party sending different broadcast payloads to
+// different peers would otherwise go unnoticed, as every peer only sees its
+// own copy.
+//
+// For every broadcast message it receives, the party broadcasts an echo
+// with the hash of the message (type EchoMessageType), and holds the
+// message back until every other peer has echoed the same hash; only then
+// is it passed to the protocol. An echo whose hash differs from that of our
+// own copy fails with ErrEquivocation, so the protocol never advances on,
+// or releases secrets in response to, equivocated input. The error names
+// both the sender and the echoer but blames neither: an echo carries only
+// a hash, so a lying echoer cannot be told apart from an equivocating
+// sender. The echoes cost one extra message per broadcast and peer. If params.EchoBroadcast is not set, the result is
+// returned unchanged.
+//
+// Every party must enable it; reshare, which spans two committees, rejects
+// it. Apply it inside WithAuthentication, so that echoes are signed like any
+// other message:
+//
+//	return tss.WithAuthentication(params)(tss.WithEchoBroadcast(params)(tss.WithRoundOrder(params)(s.round1())))
+func WithEchoBroadcast(params *Parameters) func(StateMachine, []Message, error) (StateMachine, []Message, error) {
+	return func(sm StateMachine, msgs []Message, err error) (StateMachine, []Message, error) {
+		if err != nil || params == nil || !params.EchoBroadcast || sm == nil {
+			return sm, msgs, err
+		}
+		e := &echoStateMachine{
+			inner:    sm,
+			params:   params,
+			received: make(map[echoKey]receivedBroadcast),
+			echoes:   make(map[echoKey]map[string][]byte),
+		}
+		return e, msgs, nil
+	}
+}
+
+// receivedBroadcast is the hash of our copy of a broadcast message, and the
+// sender to blame if a peer's copy differs.
+type receivedBroadcast struct {
+	from PartyID
+	hash []byte
+}
+
+type echoStateMachine struct {
+	inner    StateMachine
+	params   *Parameters
+	received map[echoKey]receivedBroadcast
+	echoes   map[echoKey]map[string][]byte // Echoing party ID -> hash, for broadcasts of peers
+	held     []Message                     // Broadcasts not yet echoed by every peer, in arrival order
+}
+
+func (e *echoStateMachine) Update(msg Message) (StateMachine, []Message, error) {
+	if msg == nil || msg.From() == nil {
+		return nil, nil, ErrInvalidMsg
+	}
+	if msg.Type() == EchoMessageType {
+		if err := e.addEcho(msg); err != nil {
+			return nil, nil, err
+		}
+		return e.release(nil)
+	}
+	if e.inner.Result() != nil {
+		return e, nil, nil // Late messages; the peers have our echoes already
+	}
+	if !msg.IsBroadcast() || msg.From().ID() == e.params.PartyID.ID() || e.params.CheckSession(msg) != nil {
+		// Nothing to echo; the protocol handles (or rejects) these itself
+		return e.deliver(msg, nil)
+	}
+
+	echo, err := e.record(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if echo == nil {
+		return e, nil, nil
+	}
+	e.held = append(e.held, msg)
+	return e.release([]Message{echo})
+}
+
+// release passes every held broadcast that all peers have echoed to the
+// protocol, in arrival order, after the messages in out.
+func (e *echoStateMachine) release(out []Message) (StateMachine, []Message, error) {
+	for i := 0; i < len(e.held); {
+		msg := e.held[i]
+		if !e.confirmed(echoKeyOf(msg)) {
+			i++
+			continue
+		}
+		e.held = append(e.held[:i], e.held[i+1:]...)
+		next, delivered, err := e.deliver(msg, out)
+		if next == nil || err != nil {
+			return next, delivered, err
+		}
+		out = delivered
+	}
+	return e, out, nil
+}
+
+// deliver passes msg to the protocol and appends its output to out.
+func (e *echoStateMachine) deliver(msg Message, out []Message) (StateMachine, []Message, error) {
+	next, msgs, err := e.inner.Update(msg)
+	out = append(out, msgs...)
+	if next == nil {
+		return nil, out, err
+	}
+	e.inner = next
+	return e, out, err
+}
+
+func echoKeyOf(msg Message) echoKey {
+	return echoKey{from: msg.From().ID(), typ: msg.Type(), round: msg.RoundNumber()}
+}
+
+// record stores the hash of the broadcast msg and returns the echo to send,
+// or nil if msg was already echoed (a retransmission).
+func (e *echoStateMachine) record(msg Message) (Message, error) {
+	key := echoKeyOf(msg)
+	hash := e.hash(msg)
+	if prev, seen := e.received[key]; seen {
+		if !bytes.Equal(prev.hash, hash) {
+			return nil, NewBlame(msg.From(), fmt.Sprintf("round %d: conflicting copies of %s", key.round, key.typ), ErrEquivocation)
+		}
+		return nil, nil
+	}
+	e.received[key] = receivedBroadcast{from: msg.From(), hash: hash}
+	if err := e.check(key); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(echoPayload{From: key.from, Type: key.typ, Round: key.round, Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+	return &BasicMessage{
+		FromParty:  e.params.PartyID,
+		IsBcast:    true,
+		Data:       data,
+		TypeString: EchoMessageType,
+		RoundNum:   msg.RoundNumber(),
+		Session:    e.params.SessionID,
+	}, nil
+}
+
+// addEcho stores the echo msg and compares it with our copy, if we have
+// one yet.
+func (e *echoStateMachine) addEcho(msg Message) error {
+	if !bytes.Equal(msg.SessionID(), e.params.SessionID) {
+		return fmt.Errorf("%w: echo for a different session", ErrInvalidMsg)
+	}
+	var p echoPayload
+	if err := json.Unmarshal(msg.Payload(), &p); err != nil {
+		return MalformedPayload(msg, err)
+	}
+	if p.From == msg.From().ID() {
+		return NewBlame(msg.From(), "echo of its own broadcast", ErrInvalidMsg)
+	}
+	if p.From == e.params.PartyID.ID() {
+		return nil // Our own broadcasts are echoed to us as well; nothing to compare
+	}
+	key := echoKey{from: p.From, typ: p.Type, round: p.Round}
+	if e.echoes[key] == nil {
+		e.echoes[key] = make(map[string][]byte)
+	}
+	if prev, ok := e.echoes[key][msg.From().ID()]; ok {
+		if !bytes.Equal(prev, p.Hash) {
+			return NewBlame(msg.From(), "equivocation: conflicting echoes", ErrInvalidMsg)
+		}
+		return nil
+	}
+	e.echoes[key][msg.From().ID()] = p.Hash
+	return e.check(key)
+}
+
+// check compares our copy of the broadcast key with the peers' echoes of it.
+func (e *echoStateMachine) check(key echoKey) error {
+	own, ok := e.received[key]
+	if !ok {
+		return nil
+	}
+	for echoer, hash := range e.echoes[key] {
+		if !bytes.Equal(hash, own.hash) {
+			return fmt.Errorf("%w: round %d: our copy of %s from %s differs from the copy echoed by %s", ErrEquivocation, key.round, key.typ, own.from.ID(), echoer)
+		}
+	}
+	return nil
+}
+
+// confirmed reports whether every peer other than its sender has echoed
+// the broadcast key. Echoes that differ from our copy fail in check, so
+// only matching echoes are left here.
+func (e *echoStateMachine) confirmed(key echoKey) bool {
+	for _, p := range e.params.Parties {
+		if p.ID() == e.params.PartyID.ID() || p.ID() == key.from {
+			continue
+		}
+		if _, ok := e.echoes[key][p.ID()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pendingEchoers returns the peers that have not yet echoed every held
+// broadcast.
+func (e *echoStateMachine) pendingEchoers() []PartyID {
+	var pending []PartyID
+	for _, p := range e.params.Parties {
+		if p.ID() == e.params.PartyID.ID() {
+			continue
+		}
+		for _, msg := range e.held {
+			key := echoKeyOf(msg)
+			if key.from == p.ID() {
+				continue
+			}
+			if _, ok := e.echoes[key][p.ID()]; !ok {
+				pending = append(pending, p)
+				break
+			}
+		}
+	}
+	return pending
+}
+
+// hash returns the hash of the broadcast msg that peers compare. Every
+// variable-length field is length-prefixed.
+func (e *echoStateMachine) hash(msg Message) []byte {
+	h := sha256.New()
+	for _, field := range [][]byte{[]byte(echoDomain), msg.SessionID(), []byte(msg.From().ID()), []byte(msg.Type()), msg.Payload()} {
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(field))))
+		h.Write(field)
+	}
+	h.Write(binary.BigEndian.AppendUint32(nil, msg.RoundNumber()))
+	return h.Sum(nil)
+}
+
+func (e *echoStateMachine) Result() interface{} {
+	return e.inner.Result()
+}
+
+func (e *echoStateMachine) Details() string {
+	if len(e.held) > 0 {
+		return e.inner.Details() + " (awaiting broadcast echoes)"
+	}
+	return e.inner.Details()
+}
+
+func (e *echoStateMachine) WaitingFor() []PartyID {
+	if len(e.held) > 0 {
+		return e.pendingEchoers()
+	}
+	return WaitingFor(e.inner)
+}
+
+func (e *echoStateMachine) RemainingForRound() int {
+	if len(e.held) > 0 {
+		return len(e.pendingEchoers())
+	}
+	return RemainingForRound(e.inner)
+}
@@ -0,0 +1,117 @@
+package tss
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newEchoHello(t *testing.T, self PartyID, parties []PartyID) (StateMachine, []Message) {
+	t.Helper()
+	params := &Parameters{PartyID: self, Parties: parties, EchoBroadcast: true}
+	sm, out := newHello(self, len(parties)-1)
+	sm, out, err := WithEchoBroadcast(params)(sm, out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sm, out
+}
+
+func TestEchoBroadcast(t *testing.T) {
+	parties := []PartyID{&MockPartyID{id: "a"}, &MockPartyID{id: "b"}, &MockPartyID{id: "c"}}
+
+	t.Run("Honest", func(t *testing.T) {
+		c := NewLocalCoordinator()
+		for _, p := range parties {
+			sm, out := newEchoHello(t, p, parties)
+			c.Add(p.ID(), sm, out)
+		}
+		results, err := c.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("unexpected results %v", results)
+		}
+	})
+
+	t.Run("HoldsUntilEchoed", func(t *testing.T) {
+		a, _ := newEchoHello(t, parties[0], parties)
+		b, _ := newEchoHello(t, parties[1], parties)
+		c, _ := newEchoHello(t, parties[2], parties)
+		fromB := &MockMessage{msgType: "hello", from: parties[1], isBroadcast: true, round: 1}
+		fromC := &MockMessage{msgType: "hello", from: parties[2], isBroadcast: true, round: 1}
+		var err error
+		if a, _, err = a.Update(fromB); err != nil {
+			t.Fatal(err)
+		}
+		if a, _, err = a.Update(fromC); err != nil {
+			t.Fatal(err)
+		}
+		if a.Result() != nil {
+			t.Fatal("broadcasts passed on before they were echoed")
+		}
+		if waiting := WaitingFor(a); len(waiting) != 2 {
+			t.Fatalf("expected to wait for echoes of b and c, got %v", waiting)
+		}
+		_, echoes, err := b.Update(fromC)
+		if err != nil || len(echoes) != 1 || echoes[0].Type() != EchoMessageType {
+			t.Fatalf("expected one echo from b, got %v, %v", echoes, err)
+		}
+		if a, _, err = a.Update(echoes[0]); err != nil {
+			t.Fatal(err)
+		}
+		if waiting := WaitingFor(a); len(waiting) != 1 || waiting[0].ID() != "c" {
+			t.Fatalf("expected to wait for the echo of c, got %v", waiting)
+		}
+		if a.Result() != nil {
+			t.Fatal("broadcast of b passed on before c echoed it")
+		}
+		_, echoes, err = c.Update(fromB)
+		if err != nil || len(echoes) != 1 {
+			t.Fatalf("expected one echo from c, got %v, %v", echoes, err)
+		}
+		if a, _, err = a.Update(echoes[0]); err != nil {
+			t.Fatal(err)
+		}
+		if a.Result() == nil {
+			t.Fatal("expected both broadcasts to be passed on once echoed")
+		}
+	})
+
+	t.Run("Equivocation", func(t *testing.T) {
+		a, _ := newEchoHello(t, parties[0], parties)
+		b, _ := newEchoHello(t, parties[1], parties)
+		// c sends different payloads to a and b
+		toA := &MockMessage{msgType: "hello", from: parties[2], isBroadcast: true, round: 1, payload: []byte("x")}
+		toB := &MockMessage{msgType: "hello", from: parties[2], isBroadcast: true, round: 1, payload: []byte("y")}
+		a, _, err := a.Update(toA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, echoes, err := b.Update(toB)
+		if err != nil || len(echoes) != 1 {
+			t.Fatalf("expected one echo from b, got %v, %v", echoes, err)
+		}
+		_, _, err = a.Update(echoes[0])
+		if !errors.Is(err, ErrEquivocation) {
+			t.Fatalf("expected equivocation, got %v", err)
+		}
+		// b may be lying about its copy, so neither c nor b is blamed
+		var blame *Blame
+		if errors.As(err, &blame) {
+			t.Fatalf("expected no blame, got %v", blame)
+		}
+		if !strings.Contains(err.Error(), "from c") || !strings.Contains(err.Error(), "echoed by b") {
+			t.Fatalf("expected the error to name c and b, got %v", err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		sm, out := newHello(parties[0], 2)
+		wrapped, _, err := WithEchoBroadcast(&Parameters{PartyID: parties[0], Parties: parties})(sm, out, nil)
+		if err != nil || wrapped != sm {
+			t.Fatal("expected the state machine to be returned unchanged")
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package tss
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+)
+
+// shareEncryptionDomain separates share encryption keys from any other use
+// of the parties' keys.
+const shareEncryptionDomain = "go-cggmp-tss/share-encryption/v1"
+
+// ErrDecryptShare is returned when an encrypted share does not decrypt.
+var ErrDecryptShare = errors.New("cannot decrypt share")
+
+// EncryptablePartyID is a PartyID with an encryption key distinct from its
+// identity key. P2P shares sent to it are encrypted to EncryptionKey()
+// rather than Key() (see EncryptionKeyOf).
+type EncryptablePartyID interface {
+	PartyID
+
+	// EncryptionKey returns the party's X25519 public key.
+	EncryptionKey() []byte
+}
+
+// EncryptionKeyOf returns the X25519 public key P2P shares are encrypted to
+// for party p: its EncryptionKey() if it is an EncryptablePartyID, otherwise
+// its Ed25519 identity key Key() converted to X25519.
+func EncryptionKeyOf(p PartyID) (*ecdh.PublicKey, error) {
+	if ep, ok := p.(EncryptablePartyID); ok && ep.EncryptionKey() != nil {
+		pub, err := ecdh.X25519().NewPublicKey(ep.EncryptionKey())
+		if err != nil {
+			return nil, fmt.Errorf("%w: encryption key of %s: %v", ErrInvalidParameters, p.ID(), err)
+		}
+		return pub, nil
+	}
+	if len(p.Key()) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: party %s has neither an encryption key nor an Ed25519 identity key", ErrInvalidParameters, p.ID())
+	}
+	point, err := new(edwards25519.Point).SetBytes(p.Key())
+	if err != nil {
+		return nil, fmt.Errorf("%w: identity key of %s: %v", ErrInvalidParameters, p.ID(), err)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(point.BytesMontgomery())
+	if err != nil {
+		return nil, fmt.Errorf("%w: identity key of %s: %v", ErrInvalidParameters, p.ID(), err)
+	}
+	return pub, nil
+}
+
+// ShareDecryptionKey returns the X25519 private key matching
+// EncryptionKeyOf(PartyID): EncryptionKey if set, otherwise SigningKey
+// converted to X25519.
+func (p *Parameters) ShareDecryptionKey() (*ecdh.PrivateKey, error) {
+	if p.EncryptionKey != nil {
+		priv, err := ecdh.X25519().NewPrivateKey(p.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: EncryptionKey: %v", ErrInvalidParameters, err)
+		}
+		return priv, nil
+	}
+	if len(p.SigningKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: EncryptShares needs EncryptionKey or SigningKey", ErrInvalidParameters)
+	}
+	// The X25519 scalar of an Ed25519 key is the first half of the hashed seed
+	h := sha512.Sum512(p.SigningKey.Seed())
+	return ecdh.X25519().NewPrivateKey(h[:32])
+}
+
+// validateShareEncryption checks that we can decrypt the shares sent to us
+// and encrypt those we send to every other party.
+func (p *Parameters) validateShareEncryption() error {
+	priv, err := p.ShareDecryptionKey()
+	if err != nil {
+		return err
+	}
+	for _, party := range p.Parties {
+		pub, err := EncryptionKeyOf(party)
+		if err != nil {
+			return err
+		}
+		if party.ID() == p.PartyID.ID() && !pub.Equal(priv.PublicKey()) {
+			return fmt.Errorf("%w: decryption key does not match our own encryption key", ErrInvalidParameters)
+		}
+	}
+	return nil
+}
+
+// EncryptShare encrypts a P2P share from the local party to recipient:
+// ECDH between a fresh ephemeral X25519 key and the recipient's key (see
+// EncryptionKeyOf), then AES-256-GCM. The session ID, sender and recipient
+// are authenticated, so a ciphertext cannot be moved to another session or
+// pair of parties. The output is the ephemeral public key followed by the
+// ciphertext.
+func (p *Parameters) EncryptShare(recipient PartyID, share []byte) ([]byte, error) {
+	pub, err := EncryptionKeyOf(recipient)
+	if err != nil {
+		return nil, err
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := eph.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := shareCipher(secret, eph.PublicKey().Bytes(), pub.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	out := eph.PublicKey().Bytes()
+	nonce := make([]byte, aead.NonceSize()) // The key is used once
+	return aead.Seal(out, nonce, share, p.shareAAD(p.PartyID, recipient)), nil
+}
+
+// DecryptShare decrypts a share encrypted by sender with EncryptShare.
+func (p *Parameters) DecryptShare(sender PartyID, data []byte) ([]byte, error) {
+	priv, err := p.ShareDecryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 32 {
+		return nil, fmt.Errorf("%w: too short", ErrDecryptShare)
+	}
+	ephPub, err := ecdh.X25519().NewPublicKey(data[:32])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptShare, err)
+	}
+	secret, err := priv.ECDH(ephPub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptShare, err)
+	}
+	aead, err := shareCipher(secret, data[:32], priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	share, err := aead.Open(nil, nonce, data[32:], p.shareAAD(sender, p.PartyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptShare, err)
+	}
+	return share, nil
+}
+
+// shareCipher derives the AES-256-GCM cipher from the ECDH secret and both
+// public keys.
+func shareCipher(secret, ephPub, recipientPub []byte) (cipher.AEAD, error) {
+	h := sha256.New()
+	h.Write([]byte(shareEncryptionDomain))
+	h.Write(secret)
+	h.Write(ephPub)
+	h.Write(recipientPub)
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// shareAAD returns the length-prefixed session ID, sender and recipient IDs.
+func (p *Parameters) shareAAD(from, to PartyID) []byte {
+	var buf bytes.Buffer
+	for _, field := range [][]byte{p.SessionID, []byte(from.ID()), []byte(to.ID())} {
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(field))))
+		buf.Write(field)
+	}
+	return buf.Bytes()
+}
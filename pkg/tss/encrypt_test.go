@@ -0,0 +1,82 @@
+package tss
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+// keyedPartyID is a PartyID with an Ed25519 identity key.
+type keyedPartyID struct {
+	id  string
+	pub ed25519.PublicKey
+}
+
+func (p *keyedPartyID) ID() string      { return p.id }
+func (p *keyedPartyID) Moniker() string { return p.id }
+func (p *keyedPartyID) Key() []byte     { return p.pub }
+
+func TestEncryptShare(t *testing.T) {
+	parties := make([]PartyID, 3)
+	params := make([]*Parameters, 3)
+	for i := range parties {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parties[i] = &keyedPartyID{id: string(rune('a' + i)), pub: pub}
+		params[i] = &Parameters{SigningKey: priv, SessionID: []byte("session")}
+	}
+	for i := range params {
+		params[i].PartyID = parties[i]
+		params[i].Parties = parties
+	}
+
+	// The identity key converts to the public half of the decryption key
+	pub, err := EncryptionKeyOf(parties[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := params[0].ShareDecryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(priv.PublicKey()) {
+		t.Fatal("Encryption key does not match the decryption key")
+	}
+
+	ct, err := params[0].EncryptShare(parties[1], []byte("share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := params[1].DecryptShare(parties[0], ct)
+	if err != nil || string(pt) != "share" {
+		t.Fatalf("Round trip failed: %q, %v", pt, err)
+	}
+
+	// Wrong recipient, claimed sender or session
+	if _, err := params[2].DecryptShare(parties[0], ct); !errors.Is(err, ErrDecryptShare) {
+		t.Fatalf("Expected ErrDecryptShare for another recipient, got %v", err)
+	}
+	if _, err := params[1].DecryptShare(parties[2], ct); !errors.Is(err, ErrDecryptShare) {
+		t.Fatalf("Expected ErrDecryptShare for another sender, got %v", err)
+	}
+	other := *params[1]
+	other.SessionID = []byte("other")
+	if _, err := other.DecryptShare(parties[0], ct); !errors.Is(err, ErrDecryptShare) {
+		t.Fatalf("Expected ErrDecryptShare for another session, got %v", err)
+	}
+	if _, err := params[1].DecryptShare(parties[0], ct[:20]); !errors.Is(err, ErrDecryptShare) {
+		t.Fatalf("Expected ErrDecryptShare for a truncated share, got %v", err)
+	}
+
+	// Validate requires a decryption key matching our encryption key
+	params[0].EncryptShares = true
+	if err := params[0].Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	params[0].SigningKey = params[1].SigningKey
+	if err := params[0].Validate(); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a mismatched key, got %v", err)
+	}
+}
@@ -29,3 +29,10 @@ func NewBlame(party PartyID, reason string, err error) *Blame {
 		Err:     err,
 	}
 }
+
+// MalformedPayload blames the sender of msg for a payload that does not
+// decode. Honest parties only send well-formed payloads, so garbage is
+// treated as cheating rather than as a transport error.
+func MalformedPayload(msg Message, err error) *Blame {
+	return NewBlame(msg.From(), fmt.Sprintf("round %d: malformed payload", msg.RoundNumber()), fmt.Errorf("%w: %w", ErrInvalidMsg, err))
+}
@@ -1,6 +1,15 @@
 package tss
 
-import "errors"
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
+)
 
 // Common errors returned by the TSS library
 var (
@@ -45,6 +54,10 @@ type Message interface {
 
 	// RoundNumber returns the protocol round this message belongs to.
 	RoundNumber() uint32
+
+	// SessionID returns the session this message belongs to.
+	// State machines reject messages whose session differs from Parameters.SessionID.
+	SessionID() []byte
 }
 
 // StateMachine is the core engine that drives the protocol.
@@ -70,11 +83,73 @@ type Parameters struct {
 	PartyID   PartyID   // The identity of the local party
 	Parties   []PartyID // List of all participants (sorted)
 	Threshold int       // The threshold (t)
-	Curve     string    // The elliptic curve to use (e.g., "secp256k1")
-	SessionID []byte    // Unique session identifier to prevent replay attacks
+	Curve     string    // The elliptic curve to use: "secp256k1" (default), "p384" or "secp256r1" (NIST P-256) (KeyGen and Sign)
+	SessionID []byte    // Unique session identifier to prevent replay attacks (see DeriveSessionID)
+
+	// Signing
+	ExpectedPublicKey *PublicKey // If set, Sign refuses key data whose group public key differs, e.g. from the on-chain or configured key
+	DerivationTweak   *big.Int   // If set, Sign signs for the child key P + t*G (see DerivePublicKeyPath)
+	SigningSet        []PartyID  // If set, only these members of Parties sign (at least Threshold+1)
+	DomainTag         []byte     // If set, Sign signs the tagged digest instead (see sign.DomainDigest)
 
 	// Optimization Flags
-	OneRoundKeyGen bool // If true, use 1-Round KeyGen (skipping commitment round)
+	OneRoundKeyGen   bool // If true, use 1-Round KeyGen (skipping commitment round)
+	ReusePaillierKey bool // If true, members kept across a Reshare reuse their Paillier key; only joining members generate one
+	MaxConcurrency   int  // Upper bound on the goroutines a party runs at once, e.g. for Paillier prime generation; 0 means runtime.GOMAXPROCS(0)
+
+	// Completion Flags
+	KeyGenAck bool // If true, KeyGen ends with an ACK round and finishes only once every party confirmed success
+
+	// Identification
+	IdentifyPaillierKey bool // If true, identify proofs also prove possession of the party's Paillier private key, and peers' proofs must do so
+
+	// Authentication
+	SigningKey       ed25519.PrivateKey // If set, messages are signed and peers' signatures checked against their Key() (see WithAuthentication)
+	StrictRoundOrder bool               // If true, senders may not go back to an earlier round (see WithRoundOrder)
+	EchoBroadcast    bool               // If true, broadcasts are checked for equivocation (see WithEchoBroadcast)
+
+	// Confidentiality
+	EncryptShares bool   // If true, KeyGen encrypts each VSS share to its recipient (see Parameters.EncryptShare)
+	EncryptionKey []byte // X25519 private key matching our EncryptionKey(); if nil, shares are decrypted with SigningKey (see ShareDecryptionKey)
+
+	// Diagnostics
+	Transcript io.Writer // If set, every incoming and outgoing message is recorded as JSON lines (see WithTranscript)
+	Logger     Logger    // Optional leveled logger for protocol diagnostics; nil discards all output
+
+	// Hooks
+	OnRoundComplete func(round int, out []Message) // If set, called with the outgoing messages of every round (see WithRoundCallback)
+	OnOwnMessage    func(msg Message)              // If set, called with incoming messages from our own PartyID (see IsOwnMessage)
+
+	// Recovery: a KeyGen restarted after a crash reuses its Paillier key by
+	// passing what PaillierCheckpoint received as PaillierCheckpointData.
+	// The checkpoint holds the secret key and must be stored as such.
+	PaillierCheckpoint     func([]byte) // If set, KeyGen passes it the new Paillier private key
+	PaillierCheckpointData []byte       // If set, KeyGen restores the Paillier key from it
+
+	// Testing
+	CurveImpl curves.Curve // Test-only: overrides the Curve name lookup, e.g. with a small-order toy curve. Never set in production
+}
+
+// Concurrency returns MaxConcurrency, or runtime.GOMAXPROCS(0) if it is not
+// set.
+func (p *Parameters) Concurrency() int {
+	if p == nil || p.MaxConcurrency <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return p.MaxConcurrency
+}
+
+// ResolveCurve returns params.CurveImpl if set, otherwise the curve
+// registered under params.Curve.
+func (p *Parameters) ResolveCurve() (curves.Curve, error) {
+	if p.CurveImpl != nil {
+		return p.CurveImpl, nil
+	}
+	curve, err := curves.Get(p.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+	}
+	return curve, nil
 }
 
 // ProtocolInitializer defines the function signature for starting a new protocol.
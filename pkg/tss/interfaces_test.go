@@ -1,7 +1,10 @@
 package tss
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/curves"
 )
 
 // MockPartyID implements PartyID for testing purposes.
@@ -31,6 +34,7 @@ type MockMessage struct {
 	isBroadcast bool
 	payload     []byte
 	round       uint32
+	sessionID   []byte
 }
 
 func (m *MockMessage) Type() string {
@@ -57,6 +61,10 @@ func (m *MockMessage) RoundNumber() uint32 {
 	return m.round
 }
 
+func (m *MockMessage) SessionID() []byte {
+	return m.sessionID
+}
+
 func TestInterfaces(t *testing.T) {
 	// Verify MockPartyID implements PartyID
 	var _ PartyID = &MockPartyID{}
@@ -84,3 +92,22 @@ func TestInterfaces(t *testing.T) {
 		t.Error("expected broadcast message")
 	}
 }
+
+func TestResolveCurve(t *testing.T) {
+	params := &Parameters{Curve: "toy"}
+	if _, err := params.ResolveCurve(); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for an unregistered curve, got %v", err)
+	}
+
+	// CurveImpl takes precedence over the name
+	params.CurveImpl = curves.NewP384()
+	curve, err := params.ResolveCurve()
+	if err != nil || curve != params.CurveImpl {
+		t.Fatalf("Expected CurveImpl to override the lookup, got %v, %v", curve, err)
+	}
+
+	curve, err = (&Parameters{}).ResolveCurve()
+	if err != nil || curve.Params().Name != curves.NewSecp256k1().Params().Name {
+		t.Fatalf("Expected secp256k1 by default, got %v, %v", curve, err)
+	}
+}
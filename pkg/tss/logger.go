@@ -0,0 +1,25 @@
+package tss
+
+// Logger receives leveled diagnostic output from the protocols.
+// keyvals are alternating key/value pairs, e.g. Debug("round done", "party", id).
+// Protocols never pass secret values (shares, coefficients, nonces) to a Logger.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+}
+
+// NopLogger discards everything. It is used when Parameters.Logger is nil.
+type NopLogger struct{}
+
+func (NopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (NopLogger) Info(msg string, keyvals ...interface{})  {}
+func (NopLogger) Warn(msg string, keyvals ...interface{})  {}
+
+// Log returns the configured Logger, or a NopLogger if none is set.
+func (p *Parameters) Log() Logger {
+	if p == nil || p.Logger == nil {
+		return NopLogger{}
+	}
+	return p.Logger
+}
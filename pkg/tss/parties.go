@@ -0,0 +1,102 @@
+package tss
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortParties returns a copy of parties in canonical order, sorted by ID.
+// Share indices are derived from a party's position in Parameters.Parties,
+// so every node must use the same order; the input slice is not modified.
+func SortParties(parties []PartyID) []PartyID {
+	sorted := make([]PartyID, len(parties))
+	copy(sorted, parties)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID() < sorted[j].ID()
+	})
+	return sorted
+}
+
+// ValidateCommittee checks the committee configuration: Parties is
+// non-empty, party IDs are unique and 0 <= Threshold < len(Parties).
+func (p *Parameters) ValidateCommittee() error {
+	if p == nil {
+		return ErrInvalidParameters
+	}
+	if len(p.Parties) == 0 {
+		return fmt.Errorf("%w: no parties", ErrInvalidParameters)
+	}
+	seen := make(map[string]bool, len(p.Parties))
+	for _, party := range p.Parties {
+		if party == nil {
+			return fmt.Errorf("%w: nil party", ErrInvalidParameters)
+		}
+		if seen[party.ID()] {
+			return fmt.Errorf("%w: duplicate party ID %q", ErrInvalidParameters, party.ID())
+		}
+		seen[party.ID()] = true
+	}
+	if p.Threshold < 0 {
+		return fmt.Errorf("%w: negative threshold %d", ErrInvalidParameters, p.Threshold)
+	}
+	if p.Threshold >= len(p.Parties) {
+		return fmt.Errorf("%w: threshold %d needs at least %d parties to sign, have %d", ErrInvalidParameters, p.Threshold, p.Threshold+1, len(p.Parties))
+	}
+	return nil
+}
+
+// Validate checks the committee (see ValidateCommittee), MaxConcurrency,
+// that the local party is one of Parties and, with EncryptShares, that every
+// party has an encryption key.
+func (p *Parameters) Validate() error {
+	if err := p.ValidateCommittee(); err != nil {
+		return err
+	}
+	if p.MaxConcurrency < 0 {
+		return fmt.Errorf("%w: negative MaxConcurrency %d", ErrInvalidParameters, p.MaxConcurrency)
+	}
+	if p.PartyID == nil {
+		return fmt.Errorf("%w: no local party", ErrInvalidParameters)
+	}
+	found := false
+	for _, party := range p.Parties {
+		if party.ID() == p.PartyID.ID() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: local party %s is not in Parties", ErrInvalidParameters, p.PartyID.ID())
+	}
+	if p.EncryptShares {
+		return p.validateShareEncryption()
+	}
+	return nil
+}
+
+// Canonical validates the parameters (see Validate) and returns a copy with
+// Parties in canonical order (see SortParties).
+func (p *Parameters) Canonical() (*Parameters, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	c := *p
+	c.Parties = SortParties(p.Parties)
+	return &c, nil
+}
+
+// IsOwnMessage reports whether msg claims to come from the local party.
+// Protocols drop such messages, which a transport may loop back. A second
+// node misconfigured with our PartyID looks the same, so every such message is
+// logged as a warning and passed to OnOwnMessage: a stream of them while we
+// are not sending points at a duplicate configuration.
+func (p *Parameters) IsOwnMessage(msg Message) bool {
+	if p.PartyID == nil || msg.From() == nil || msg.From().ID() != p.PartyID.ID() {
+		return false
+	}
+	p.Log().Warn("dropped message from our own party ID", "party", p.PartyID.ID(), "type", msg.Type(), "round", msg.RoundNumber())
+	if p.OnOwnMessage != nil {
+		p.OnOwnMessage(msg)
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package tss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSortParties(t *testing.T) {
+	p1 := &MockPartyID{id: "alice"}
+	p2 := &MockPartyID{id: "bob"}
+	p3 := &MockPartyID{id: "carol"}
+	in := []PartyID{p3, p1, p2}
+
+	sorted := SortParties(in)
+	for i, want := range []PartyID{p1, p2, p3} {
+		if sorted[i] != want {
+			t.Fatalf("position %d: got %s, want %s", i, sorted[i].ID(), want.ID())
+		}
+	}
+	if in[0] != p3 {
+		t.Error("SortParties modified its input")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	p1 := &MockPartyID{id: "1"}
+	p2 := &MockPartyID{id: "2"}
+	params := &Parameters{PartyID: p2, Parties: []PartyID{p2, p1}, Threshold: 1}
+
+	c, err := params.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if c.Parties[0] != p1 || c.Parties[1] != p2 || c.Threshold != 1 {
+		t.Error("Canonical did not sort Parties or lost other fields")
+	}
+	if params.Parties[0] != p2 {
+		t.Error("Canonical modified the original parameters")
+	}
+
+	params.PartyID = &MockPartyID{id: "3"}
+	if _, err := params.Canonical(); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("expected ErrInvalidParameters for a local party outside the committee, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	tests := []struct {
+		name   string
+		params *Parameters
+	}{
+		{"nil parameters", nil},
+		{"no parties", &Parameters{PartyID: p1, Threshold: 0}},
+		{"nil party", &Parameters{PartyID: p1, Parties: []PartyID{p1, nil}, Threshold: 1}},
+		{"duplicate party IDs", &Parameters{PartyID: p1, Parties: []PartyID{p1, p2, &MockPartyID{id: "2"}}, Threshold: 1}},
+		{"negative threshold", &Parameters{PartyID: p1, Parties: []PartyID{p1, p2}, Threshold: -1}},
+		{"threshold equals party count", &Parameters{PartyID: p1, Parties: []PartyID{p1, p2}, Threshold: 2}},
+		{"threshold too large", &Parameters{PartyID: p1, Parties: []PartyID{p1, p2}, Threshold: 5}},
+		{"no local party", &Parameters{Parties: []PartyID{p1, p2}, Threshold: 1}},
+		{"local party missing", &Parameters{PartyID: p3, Parties: []PartyID{p1, p2}, Threshold: 1}},
+		{"negative MaxConcurrency", &Parameters{PartyID: p1, Parties: []PartyID{p1, p2}, Threshold: 1, MaxConcurrency: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); !errors.Is(err, ErrInvalidParameters) {
+				t.Errorf("expected ErrInvalidParameters, got %v", err)
+			}
+		})
+	}
+
+	valid := &Parameters{PartyID: p2, Parties: []PartyID{p1, p2, p3}, Threshold: 2}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid parameters rejected: %v", err)
+	}
+}
+
+func TestIsOwnMessage(t *testing.T) {
+	p1 := &MockPartyID{id: "1"}
+	p2 := &MockPartyID{id: "2"}
+	var own int
+	params := &Parameters{
+		PartyID:      p1,
+		Parties:      []PartyID{p1, p2},
+		OnOwnMessage: func(Message) { own++ },
+	}
+
+	if params.IsOwnMessage(&MockMessage{from: p2}) {
+		t.Fatal("Message from party 2 reported as our own")
+	}
+	if !params.IsOwnMessage(&MockMessage{from: &MockPartyID{id: "1"}}) {
+		t.Fatal("Message with our party ID not reported")
+	}
+	if own != 1 {
+		t.Fatalf("OnOwnMessage called %d times, want 1", own)
+	}
+}
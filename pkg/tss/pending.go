@@ -0,0 +1,23 @@
+package tss
+
+// Replay feeds buffered messages to sm in order and returns the resulting
+// state together with out and every message produced along the way.
+//
+// Protocols buffer messages that arrive for a future round and call Replay
+// once they advance, so early delivery by the network does not abort the
+// session. Messages still ahead of the new round are buffered again by sm;
+// messages for a round that has already completed are ignored by sm.
+func Replay(sm StateMachine, out []Message, pending []Message) (StateMachine, []Message, error) {
+	for _, msg := range pending {
+		next, msgs, err := sm.Update(msg)
+		if err != nil {
+			// next is the protocol's terminal state if the message aborted it
+			return next, nil, err
+		}
+		if next != nil {
+			sm = next
+		}
+		out = append(out, msgs...)
+	}
+	return sm, out, nil
+}
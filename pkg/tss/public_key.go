@@ -0,0 +1,50 @@
+package tss
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// PublicKey is an elliptic curve point, e.g. a group public key.
+type PublicKey struct {
+	X, Y *big.Int
+}
+
+// Equal reports whether the key is the point (x, y).
+func (pk *PublicKey) Equal(x, y *big.Int) bool {
+	if pk == nil || pk.X == nil || pk.Y == nil || x == nil || y == nil {
+		return false
+	}
+	return pk.X.Cmp(x) == 0 && pk.Y.Cmp(y) == 0
+}
+
+// SerializeCompressed encodes the key as a 33-byte SEC 1 compressed
+// secp256k1 point, the form used for e.g. Bitcoin P2PKH addresses.
+func (pk *PublicKey) SerializeCompressed() []byte {
+	return pk.secp256k1().SerializeCompressed()
+}
+
+// SerializeUncompressed encodes the key as a 65-byte SEC 1 uncompressed
+// secp256k1 point (0x04 || X || Y), the form hashed into Ethereum addresses.
+func (pk *PublicKey) SerializeUncompressed() []byte {
+	return pk.secp256k1().SerializeUncompressed()
+}
+
+func (pk *PublicKey) secp256k1() *secp256k1.PublicKey {
+	var fx, fy secp256k1.FieldVal
+	fx.SetByteSlice(pk.X.Bytes())
+	fy.SetByteSlice(pk.Y.Bytes())
+	return secp256k1.NewPublicKey(&fx, &fy)
+}
+
+// ParsePublicKey decodes a compressed (33-byte) or uncompressed (65-byte)
+// SEC 1 encoding of a secp256k1 point. Points not on the curve are rejected.
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	key, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &PublicKey{X: key.X(), Y: key.Y()}, nil
+}
@@ -0,0 +1,53 @@
+package tss
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestPublicKeySerialize(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := priv.PubKey()
+	pk := &PublicKey{X: ref.X(), Y: ref.Y()}
+
+	compressed := pk.SerializeCompressed()
+	if len(compressed) != 33 || !bytes.Equal(compressed, ref.SerializeCompressed()) {
+		t.Fatalf("Compressed encoding mismatch: %x", compressed)
+	}
+	uncompressed := pk.SerializeUncompressed()
+	if len(uncompressed) != 65 || !bytes.Equal(uncompressed, ref.SerializeUncompressed()) {
+		t.Fatalf("Uncompressed encoding mismatch: %x", uncompressed)
+	}
+
+	for _, enc := range [][]byte{compressed, uncompressed} {
+		parsed, err := ParsePublicKey(enc)
+		if err != nil {
+			t.Fatalf("ParsePublicKey(%x): %v", enc, err)
+		}
+		if !parsed.Equal(pk.X, pk.Y) {
+			t.Fatalf("ParsePublicKey(%x) returned a different point", enc)
+		}
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	bad := make([]byte, 33)
+	bad[0] = 0x02
+	bad[32] = 0x07 // x = 7: x^3 + 7 is not a square mod p
+	tests := map[string][]byte{
+		"empty":        nil,
+		"wrong length": {0x02, 0x01},
+		"bad prefix":   append([]byte{0x05}, bad[1:]...),
+		"not on curve": bad,
+	}
+	for name, b := range tests {
+		if _, err := ParsePublicKey(b); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
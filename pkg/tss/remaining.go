@@ -0,0 +1,35 @@
+package tss
+
+// RoundCounter is implemented by protocol state machines that can report how
+// many messages the current round still needs. It is more precise than
+// RoundWaiter when a peer sends several messages in one round.
+type RoundCounter interface {
+	RemainingForRound() int
+}
+
+// RemainingForRound returns how many more messages sm needs before its
+// current round completes: the messages expected in the round minus those
+// received. It returns 0 if sm has finished or cannot tell.
+func RemainingForRound(sm StateMachine) int {
+	if c, ok := sm.(RoundCounter); ok {
+		return c.RemainingForRound()
+	}
+	return 0
+}
+
+// RemainingMessages returns how many messages the parties other than self
+// still owe, when each sends perPeer messages in the round. Messages beyond
+// perPeer from one party do not make up for another's. Protocols use it to
+// implement RoundCounter, alongside MissingSenders.
+func RemainingMessages(parties []PartyID, self PartyID, received map[string][]Message, perPeer int) int {
+	remaining := 0
+	for _, p := range parties {
+		if p.ID() == self.ID() {
+			continue
+		}
+		if n := len(received[p.ID()]); n < perPeer {
+			remaining += perPeer - n
+		}
+	}
+	return remaining
+}
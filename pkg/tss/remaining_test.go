@@ -0,0 +1,24 @@
+package tss
+
+import "testing"
+
+func TestRemainingMessages(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "p1"}, &MockPartyID{id: "p2"}, &MockPartyID{id: "p3"}
+	parties := []PartyID{p1, p2, p3}
+	msg := &MockMessage{}
+	received := map[string][]Message{
+		"p2": {msg, msg, msg}, // Extra messages from p2 do not cover p3
+		"p3": {msg},
+	}
+	if got := RemainingMessages(parties, p1, received, 2); got != 1 {
+		t.Fatalf("Expected 1 remaining, got %d", got)
+	}
+	if got := RemainingMessages(parties, p1, nil, 2); got != 4 {
+		t.Fatalf("Expected 4 remaining, got %d", got)
+	}
+
+	// State machines without RoundCounter report nothing
+	if got := RemainingForRound(WithTimeout(&plainMachine{})); got != 0 {
+		t.Fatalf("Expected 0, got %d", got)
+	}
+}
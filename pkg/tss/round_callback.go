@@ -0,0 +1,66 @@
+package tss
+
+// WithRoundCallback wraps the result of a protocol constructor so that
+// params.OnRoundComplete is called whenever a round produces outgoing
+// messages, with the round those messages belong to. If one Update yields
+// messages for several rounds (e.g. when early messages are replayed), the
+// callback fires once per round, in order.
+// If no callback is configured, the result is returned unchanged.
+//
+// Apply it outermost, so the callback sees exactly what Update returns:
+//
+//	return tss.WithRoundCallback(params)(tss.WithAuthentication(params)(tss.WithTranscript(params)(s.round1())))
+func WithRoundCallback(params *Parameters) func(StateMachine, []Message, error) (StateMachine, []Message, error) {
+	return func(sm StateMachine, msgs []Message, err error) (StateMachine, []Message, error) {
+		if err != nil || params == nil || params.OnRoundComplete == nil || sm == nil {
+			return sm, msgs, err
+		}
+		r := &roundCallbackStateMachine{inner: sm, params: params}
+		r.fire(msgs)
+		return r, msgs, nil
+	}
+}
+
+type roundCallbackStateMachine struct {
+	inner  StateMachine
+	params *Parameters
+}
+
+func (r *roundCallbackStateMachine) Update(msg Message) (StateMachine, []Message, error) {
+	next, out, err := r.inner.Update(msg)
+	if next == nil {
+		return nil, out, err
+	}
+	r.inner = next
+	r.fire(out)
+	return r, out, err
+}
+
+func (r *roundCallbackStateMachine) Result() interface{} {
+	return r.inner.Result()
+}
+
+func (r *roundCallbackStateMachine) Details() string {
+	return r.inner.Details()
+}
+
+func (r *roundCallbackStateMachine) WaitingFor() []PartyID {
+	return WaitingFor(r.inner)
+}
+
+func (r *roundCallbackStateMachine) RemainingForRound() int {
+	return RemainingForRound(r.inner)
+}
+
+// fire calls the callback once for each run of messages of the same round.
+func (r *roundCallbackStateMachine) fire(msgs []Message) {
+	for start := 0; start < len(msgs); {
+		round := msgs[start].RoundNumber()
+		end := start + 1
+		for end < len(msgs) && msgs[end].RoundNumber() == round {
+			end++
+		}
+		r.params.OnRoundComplete(int(round), msgs[start:end])
+		start = end
+	}
+}
@@ -0,0 +1,61 @@
+package tss
+
+import "testing"
+
+// scriptedMachine returns the next batch of messages on every Update.
+type scriptedMachine struct {
+	batches [][]Message
+}
+
+func (s *scriptedMachine) Update(msg Message) (StateMachine, []Message, error) {
+	if len(s.batches) == 0 {
+		return s, nil, nil
+	}
+	out := s.batches[0]
+	s.batches = s.batches[1:]
+	return s, out, nil
+}
+func (s *scriptedMachine) Result() interface{} { return nil }
+func (s *scriptedMachine) Details() string     { return "" }
+
+func TestWithRoundCallback(t *testing.T) {
+	p1 := &MockPartyID{id: "1"}
+	msg := func(round uint32) Message { return &MockMessage{from: p1, round: round} }
+
+	var rounds []int
+	var sizes []int
+	params := &Parameters{
+		PartyID: p1,
+		OnRoundComplete: func(round int, out []Message) {
+			rounds = append(rounds, round)
+			sizes = append(sizes, len(out))
+		},
+	}
+
+	// Round 3 and 4 messages from a single Update (replayed early messages)
+	inner := &scriptedMachine{batches: [][]Message{nil, {msg(2), msg(2)}, {msg(3), msg(4)}}}
+	sm, _, err := WithRoundCallback(params)(inner, []Message{msg(1)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if sm, _, err = sm.Update(msg(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantRounds, wantSizes := []int{1, 2, 3, 4}, []int{1, 2, 1, 1}
+	if len(rounds) != len(wantRounds) {
+		t.Fatalf("expected %d callbacks, got %v", len(wantRounds), rounds)
+	}
+	for i := range wantRounds {
+		if rounds[i] != wantRounds[i] || sizes[i] != wantSizes[i] {
+			t.Fatalf("callback %d: got round %d with %d messages, want round %d with %d", i, rounds[i], sizes[i], wantRounds[i], wantSizes[i])
+		}
+	}
+
+	// Without a callback the machine is returned unwrapped
+	if sm, _, _ := WithRoundCallback(&Parameters{})(inner, nil, nil); sm != inner {
+		t.Error("expected the inner machine when no callback is set")
+	}
+}
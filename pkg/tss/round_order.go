@@ -0,0 +1,63 @@
+package tss
+
+// WithRoundOrder wraps the result of a protocol constructor so that rounds
+// never go backwards per sender: once a message of round r from a party has
+// been accepted, a message of a lower round from that party is rejected with
+// a Blame wrapping ErrInvalidMsg. Honest parties send their rounds in order,
+// so a regression means the sender is trying to confuse the buffering of
+// early messages. Exact retransmissions of an earlier round are rejected too.
+// If params.StrictRoundOrder is not set, the result is returned unchanged.
+//
+// Apply it inside WithAuthentication, so only authenticated senders are
+// tracked:
+//
+//	return tss.WithAuthentication(params)(tss.WithRoundOrder(params)(tss.WithTranscript(params)(s.round1())))
+func WithRoundOrder(params *Parameters) func(StateMachine, []Message, error) (StateMachine, []Message, error) {
+	return func(sm StateMachine, msgs []Message, err error) (StateMachine, []Message, error) {
+		if err != nil || params == nil || !params.StrictRoundOrder || sm == nil {
+			return sm, msgs, err
+		}
+		return &roundOrderStateMachine{inner: sm, highest: make(map[string]uint32)}, msgs, nil
+	}
+}
+
+type roundOrderStateMachine struct {
+	inner   StateMachine
+	highest map[string]uint32 // Party ID -> highest round accepted from it
+}
+
+func (r *roundOrderStateMachine) Update(msg Message) (StateMachine, []Message, error) {
+	if msg == nil || msg.From() == nil {
+		return nil, nil, ErrInvalidMsg
+	}
+	sender := msg.From().ID()
+	if highest, seen := r.highest[sender]; seen && msg.RoundNumber() < highest {
+		return nil, nil, NewBlame(msg.From(), "round number regressed", ErrInvalidMsg)
+	}
+
+	next, out, err := r.inner.Update(msg)
+	if err == nil {
+		r.highest[sender] = msg.RoundNumber()
+	}
+	if next == nil {
+		return nil, out, err
+	}
+	r.inner = next
+	return r, out, err
+}
+
+func (r *roundOrderStateMachine) Result() interface{} {
+	return r.inner.Result()
+}
+
+func (r *roundOrderStateMachine) Details() string {
+	return r.inner.Details()
+}
+
+func (r *roundOrderStateMachine) WaitingFor() []PartyID {
+	return WaitingFor(r.inner)
+}
+
+func (r *roundOrderStateMachine) RemainingForRound() int {
+	return RemainingForRound(r.inner)
+}
@@ -0,0 +1,43 @@
+package tss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithRoundOrder(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	msg := func(from PartyID, round uint32) Message { return &MockMessage{from: from, round: round} }
+	params := &Parameters{PartyID: p1, StrictRoundOrder: true}
+
+	sm, _, err := WithRoundOrder(params)(&plainMachine{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []Message{msg(p2, 1), msg(p2, 3), msg(p3, 2)} {
+		if sm, _, err = sm.Update(m); err != nil {
+			t.Fatalf("in-order message rejected: %v", err)
+		}
+	}
+
+	// p2 regresses from round 3 to round 2
+	next, _, err := sm.Update(msg(p2, 2))
+	var blame *Blame
+	if !errors.As(err, &blame) || blame.PartyID.ID() != "2" || !errors.Is(err, ErrInvalidMsg) || next != nil {
+		t.Fatalf("expected blame on party 2, got %v, %v", next, err)
+	}
+
+	// Other senders are tracked separately; p2 may still repeat its round
+	if _, _, err := sm.Update(msg(p3, 2)); err != nil {
+		t.Fatalf("p3 round 2 rejected: %v", err)
+	}
+	if _, _, err := sm.Update(msg(p2, 3)); err != nil {
+		t.Fatalf("p2 round 3 rejected: %v", err)
+	}
+
+	// Without the flag the machine is returned unwrapped
+	inner := &plainMachine{}
+	if sm, _, _ := WithRoundOrder(&Parameters{})(inner, nil, nil); sm != inner {
+		t.Error("expected the inner machine when StrictRoundOrder is not set")
+	}
+}
@@ -0,0 +1,44 @@
+package tss
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveSessionID(t *testing.T) {
+	p1 := &MockPartyID{id: "1", key: []byte("k1")}
+	p2 := &MockPartyID{id: "2", key: []byte("k2")}
+	p3 := &MockPartyID{id: "3", key: []byte("k3")}
+	nonce := []byte("nonce-1")
+
+	base := DeriveSessionID("sign", []PartyID{p1, p2, p3}, nonce)
+	if len(base) != 32 {
+		t.Fatalf("expected 32-byte session id, got %d", len(base))
+	}
+
+	// Deterministic and independent of party order.
+	if !bytes.Equal(base, DeriveSessionID("sign", []PartyID{p3, p1, p2}, nonce)) {
+		t.Error("session id depends on party order")
+	}
+
+	// Different committees, nonces or purposes yield different ids.
+	others := map[string][]byte{
+		"committee":   DeriveSessionID("sign", []PartyID{p1, p2}, nonce),
+		"nonce":       DeriveSessionID("sign", []PartyID{p1, p2, p3}, []byte("nonce-2")),
+		"purpose":     DeriveSessionID("keygen", []PartyID{p1, p2, p3}, nonce),
+		"party key":   DeriveSessionID("sign", []PartyID{p1, p2, &MockPartyID{id: "3", key: []byte("other")}}, nonce),
+		"empty nonce": DeriveSessionID("sign", []PartyID{p1, p2, p3}, nil),
+	}
+	for name, id := range others {
+		if bytes.Equal(base, id) {
+			t.Errorf("%s change did not change the session id", name)
+		}
+	}
+
+	// Field boundaries are unambiguous.
+	a := DeriveSessionID("sign", []PartyID{&MockPartyID{id: "12"}, &MockPartyID{id: "3"}}, nonce)
+	b := DeriveSessionID("sign", []PartyID{&MockPartyID{id: "1"}, &MockPartyID{id: "23"}}, nonce)
+	if bytes.Equal(a, b) {
+		t.Error("ambiguous encoding of party ids")
+	}
+}
@@ -0,0 +1,102 @@
+package tss
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func newIdentity(t *testing.T, id string) (*MockPartyID, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &MockPartyID{id: id, key: pub}, priv
+}
+
+func TestSignedMessage(t *testing.T) {
+	alice, alicePriv := newIdentity(t, "alice")
+	bob, _ := newIdentity(t, "bob")
+	session := []byte("session-1")
+
+	msg := &MockMessage{msgType: "Round1", from: alice, isBroadcast: true, payload: []byte("commitment"), round: 1, sessionID: session}
+	signed := NewSignedMessage(msg)
+	if err := signed.Sign(alicePriv); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := signed.Verify(alice.Key()); err != nil {
+		t.Fatalf("Valid signature rejected: %v", err)
+	}
+
+	// Alice's signature relabelled as coming from Bob
+	forged := *msg
+	forged.from = bob
+	spoofed := &SignedMessage{Message: &forged, Signature: signed.Signature}
+	if err := spoofed.Verify(bob.Key()); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Forged From accepted: %v", err)
+	}
+
+	// Any change to the covered fields breaks the signature
+	tampered := *msg
+	tampered.payload = []byte("other")
+	if err := (&SignedMessage{Message: &tampered, Signature: signed.Signature}).Verify(alice.Key()); err == nil {
+		t.Fatal("Tampered payload accepted")
+	}
+	replayed := *msg
+	replayed.sessionID = []byte("session-2")
+	if err := (&SignedMessage{Message: &replayed, Signature: signed.Signature}).Verify(alice.Key()); err == nil {
+		t.Fatal("Signature replayed into another session")
+	}
+}
+
+func TestWithAuthentication(t *testing.T) {
+	alice, alicePriv := newIdentity(t, "alice")
+	bob, bobPriv := newIdentity(t, "bob")
+	mallory, malloryPriv := newIdentity(t, "mallory")
+	params := &Parameters{
+		PartyID:    alice,
+		Parties:    []PartyID{alice, bob},
+		SessionID:  []byte("session-auth"),
+		SigningKey: alicePriv,
+	}
+	sm, _, err := WithAuthentication(params)(&plainMachine{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromBob := &MockMessage{msgType: "Round1", from: bob, isBroadcast: true, payload: []byte("hi"), round: 1, sessionID: params.SessionID}
+	good := NewSignedMessage(fromBob)
+	if err := good.Sign(bobPriv); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sm.Update(good); err != nil {
+		t.Fatalf("Authenticated message rejected: %v", err)
+	}
+
+	// Mallory signs with her own key but claims to be Bob
+	forged := NewSignedMessage(fromBob)
+	if err := forged.Sign(malloryPriv); err != nil {
+		t.Fatal(err)
+	}
+	next, _, err := sm.Update(forged)
+	var blame *Blame
+	if !errors.Is(err, ErrInvalidSignature) || !errors.As(err, &blame) || blame.PartyID.ID() != "bob" {
+		t.Fatalf("Expected blame for forged message, got %v", err)
+	}
+	if next != sm {
+		t.Fatalf("Forged message ended the session: next state %v", next)
+	}
+
+	// Unsigned messages and unknown senders are rejected too
+	if _, _, err := sm.Update(fromBob); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Unsigned message accepted: %v", err)
+	}
+	fromMallory := NewSignedMessage(&MockMessage{msgType: "Round1", from: mallory, isBroadcast: true, round: 1, sessionID: params.SessionID})
+	if err := fromMallory.Sign(malloryPriv); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sm.Update(fromMallory); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Message from outsider accepted: %v", err)
+	}
+}
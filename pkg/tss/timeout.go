@@ -0,0 +1,120 @@
+package tss
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RoundWaiter is implemented by protocol state machines that can report which
+// parties' messages for the current round are still outstanding.
+type RoundWaiter interface {
+	WaitingFor() []PartyID
+}
+
+// WaitingFor returns the parties sm still needs messages from in its current
+// round. It returns nil if sm has finished or cannot tell.
+func WaitingFor(sm StateMachine) []PartyID {
+	if w, ok := sm.(RoundWaiter); ok {
+		return w.WaitingFor()
+	}
+	return nil
+}
+
+// TimeoutError reports the parties that had not delivered their messages
+// for a round when its deadline passed. It wraps ErrRoundTimeout.
+type TimeoutError struct {
+	Round   string    // Details() of the stalled state
+	Missing []PartyID // Parties whose messages are outstanding
+}
+
+func (e *TimeoutError) Error() string {
+	ids := make([]string, len(e.Missing))
+	for i, p := range e.Missing {
+		ids[i] = p.ID()
+	}
+	return fmt.Sprintf("%v: %s: waiting for %s", ErrRoundTimeout, e.Round, strings.Join(ids, ", "))
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrRoundTimeout
+}
+
+// TimedStateMachine wraps a StateMachine with a deadline so a coordinator can
+// detect stalled peers and decide to abort or retry. The deadline is not
+// enforced by Update; callers poll CheckTimeout, typically from a timer.
+type TimedStateMachine struct {
+	inner    StateMachine
+	deadline time.Time
+}
+
+// WithTimeout wraps sm. No deadline is set until SetDeadline is called.
+func WithTimeout(sm StateMachine) *TimedStateMachine {
+	return &TimedStateMachine{inner: sm}
+}
+
+// Update forwards msg to the wrapped state machine. The deadline is kept
+// across rounds; call SetDeadline again to give a new round its own budget.
+func (t *TimedStateMachine) Update(msg Message) (StateMachine, []Message, error) {
+	next, out, err := t.inner.Update(msg)
+	if next == nil {
+		return nil, out, err
+	}
+	t.inner = next
+	return t, out, err
+}
+
+func (t *TimedStateMachine) Result() interface{} {
+	return t.inner.Result()
+}
+
+func (t *TimedStateMachine) Details() string {
+	return t.inner.Details()
+}
+
+// WaitingFor reports the parties the wrapped state machine is waiting for.
+func (t *TimedStateMachine) WaitingFor() []PartyID {
+	return WaitingFor(t.inner)
+}
+
+// RemainingForRound reports how many messages the wrapped state machine
+// still needs in its current round.
+func (t *TimedStateMachine) RemainingForRound() int {
+	return RemainingForRound(t.inner)
+}
+
+// SetDeadline sets the time by which the outstanding messages must arrive.
+// The zero time disables the deadline.
+func (t *TimedStateMachine) SetDeadline(deadline time.Time) {
+	t.deadline = deadline
+}
+
+// CheckTimeout returns a *TimeoutError (wrapping ErrRoundTimeout) listing the
+// non-responders if the deadline has passed and messages are still
+// outstanding, and nil otherwise.
+func (t *TimedStateMachine) CheckTimeout() error {
+	if t.deadline.IsZero() || time.Now().Before(t.deadline) {
+		return nil
+	}
+	missing := t.WaitingFor()
+	if len(missing) == 0 {
+		return nil
+	}
+	return &TimeoutError{Round: t.inner.Details(), Missing: missing}
+}
+
+// MissingSenders returns the parties other than self that have sent fewer
+// than perPeer messages in received, in the order of parties. Protocols use
+// it to implement RoundWaiter.
+func MissingSenders(parties []PartyID, self PartyID, received map[string][]Message, perPeer int) []PartyID {
+	var missing []PartyID
+	for _, p := range parties {
+		if p.ID() == self.ID() {
+			continue
+		}
+		if len(received[p.ID()]) < perPeer {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,51 @@
+package tss
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitingMachine is a StateMachine that reports a fixed set of missing parties.
+type waitingMachine struct {
+	missing []PartyID
+}
+
+func (w *waitingMachine) Update(msg Message) (StateMachine, []Message, error) { return w, nil, nil }
+func (w *waitingMachine) Result() interface{}                                 { return nil }
+func (w *waitingMachine) Details() string                                     { return "Test Round 1" }
+func (w *waitingMachine) WaitingFor() []PartyID                               { return w.missing }
+
+// plainMachine is a StateMachine without WaitingFor.
+type plainMachine struct{}
+
+func (p *plainMachine) Update(msg Message) (StateMachine, []Message, error) { return p, nil, nil }
+func (p *plainMachine) Result() interface{}                                 { return nil }
+func (p *plainMachine) Details() string                                     { return "" }
+
+func TestCheckTimeout(t *testing.T) {
+	p2 := &MockPartyID{id: "p2"}
+	inner := &waitingMachine{missing: []PartyID{p2}}
+	timed := WithTimeout(inner)
+	timed.SetDeadline(time.Now().Add(-time.Millisecond))
+
+	err := timed.CheckTimeout()
+	if !errors.Is(err, ErrRoundTimeout) {
+		t.Fatalf("Expected ErrRoundTimeout, got %v", err)
+	}
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || len(timeout.Missing) != 1 || timeout.Missing[0].ID() != "p2" || timeout.Round != "Test Round 1" {
+		t.Fatalf("Unexpected timeout error: %v", err)
+	}
+
+	// Nothing outstanding: no timeout even past the deadline
+	inner.missing = nil
+	if err := timed.CheckTimeout(); err != nil {
+		t.Fatalf("Expected no timeout, got %v", err)
+	}
+
+	// State machines without RoundWaiter report nothing
+	if got := WaitingFor(&plainMachine{}); got != nil {
+		t.Fatalf("Expected nil, got %v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package tss
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Transcript directions
+const (
+	TranscriptIn  = "in"
+	TranscriptOut = "out"
+)
+
+// TranscriptEntry is one machine-readable transcript record.
+// Entries are written as JSON lines to Parameters.Transcript.
+type TranscriptEntry struct {
+	Party     string   `json:"party"`     // Local party recording the entry
+	Direction string   `json:"direction"` // TranscriptIn or TranscriptOut
+	Type      string   `json:"type"`
+	Round     uint32   `json:"round"`
+	From      string   `json:"from"`
+	To        []string `json:"to,omitempty"`
+	Broadcast bool     `json:"broadcast"`
+	Payload   string   `json:"payload"` // Hex-encoded payload
+}
+
+// WithTranscript wraps the result of a protocol constructor so that every
+// outgoing and incoming message is recorded to params.Transcript.
+// If no transcript writer is configured, the result is returned unchanged.
+//
+// Usage:
+//
+//	return tss.WithTranscript(params)(s.round1())
+func WithTranscript(params *Parameters) func(StateMachine, []Message, error) (StateMachine, []Message, error) {
+	return func(sm StateMachine, msgs []Message, err error) (StateMachine, []Message, error) {
+		if err != nil || params == nil || params.Transcript == nil || sm == nil {
+			return sm, msgs, err
+		}
+		t := &transcriptStateMachine{inner: sm, params: params}
+		t.record(TranscriptOut, msgs...)
+		return t, msgs, nil
+	}
+}
+
+// ParseTranscript reads back the JSON-lines transcript written by WithTranscript.
+func ParseTranscript(r io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e TranscriptEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("invalid transcript entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PayloadBytes decodes the hex-encoded message payload of the entry.
+func (e *TranscriptEntry) PayloadBytes() ([]byte, error) {
+	return hex.DecodeString(e.Payload)
+}
+
+type transcriptStateMachine struct {
+	inner  StateMachine
+	params *Parameters
+}
+
+func (t *transcriptStateMachine) Update(msg Message) (StateMachine, []Message, error) {
+	t.record(TranscriptIn, msg)
+
+	next, out, err := t.inner.Update(msg)
+	if next == nil {
+		return nil, out, err
+	}
+	t.inner = next
+	t.record(TranscriptOut, out...)
+	return t, out, err
+}
+
+func (t *transcriptStateMachine) Result() interface{} {
+	return t.inner.Result()
+}
+
+func (t *transcriptStateMachine) Details() string {
+	return t.inner.Details()
+}
+
+func (t *transcriptStateMachine) WaitingFor() []PartyID {
+	return WaitingFor(t.inner)
+}
+
+func (t *transcriptStateMachine) RemainingForRound() int {
+	return RemainingForRound(t.inner)
+}
+
+func (t *transcriptStateMachine) record(direction string, msgs ...Message) {
+	enc := json.NewEncoder(t.params.Transcript)
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+		entry := TranscriptEntry{
+			Direction: direction,
+			Type:      msg.Type(),
+			Round:     msg.RoundNumber(),
+			Broadcast: msg.IsBroadcast(),
+			Payload:   hex.EncodeToString(msg.Payload()),
+		}
+		if t.params.PartyID != nil {
+			entry.Party = t.params.PartyID.ID()
+		}
+		if msg.From() != nil {
+			entry.From = msg.From().ID()
+		}
+		for _, to := range msg.To() {
+			entry.To = append(entry.To, to.ID())
+		}
+		// The transcript is best-effort diagnostics; write errors must not abort the protocol
+		_ = enc.Encode(entry)
+	}
+}
@@ -0,0 +1,154 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrTransportClosed is returned by a Transport that has been closed.
+var ErrTransportClosed = errors.New("transport closed")
+
+// Transport carries the messages of one party to and from its peers.
+// Messages are not authenticated by the transport; set
+// Parameters.SigningKey to have them signed (see WithAuthentication).
+type Transport interface {
+	// Send delivers msg to the parties in msg.To().
+	Send(msg Message) error
+	// Broadcast delivers msg to every party but its sender.
+	Broadcast(msg Message) error
+	// Recv blocks until a message for the local party arrives.
+	Recv() (Message, error)
+}
+
+// Deliver sends msg over t: with Broadcast if msg is a broadcast or has no
+// recipients, otherwise with Send.
+func Deliver(t Transport, msg Message) error {
+	if msg.IsBroadcast() || len(msg.To()) == 0 {
+		return t.Broadcast(msg)
+	}
+	return t.Send(msg)
+}
+
+// MemoryNetwork connects parties in one process through in-memory queues,
+// e.g. to run every party of a test in its own goroutine. Queues are
+// unbounded, so sending never blocks.
+type MemoryNetwork struct {
+	queues map[string]*memoryQueue // Fixed at creation
+}
+
+// NewMemoryNetwork returns a network with a queue for each party.
+func NewMemoryNetwork(parties []PartyID) *MemoryNetwork {
+	n := &MemoryNetwork{queues: make(map[string]*memoryQueue, len(parties))}
+	for _, p := range parties {
+		n.queues[p.ID()] = &memoryQueue{ready: make(chan struct{}, 1)}
+	}
+	return n
+}
+
+// Transport returns the transport of the party with the given ID.
+func (n *MemoryNetwork) Transport(id string) Transport {
+	return &memoryTransport{network: n, self: id}
+}
+
+// Close makes every pending and later Recv return ErrTransportClosed.
+func (n *MemoryNetwork) Close() {
+	for _, q := range n.queues {
+		q.close()
+	}
+}
+
+func (n *MemoryNetwork) push(id string, msg Message) error {
+	q, ok := n.queues[id]
+	if !ok {
+		return fmt.Errorf("unknown party %s", id)
+	}
+	return q.push(msg)
+}
+
+type memoryTransport struct {
+	network *MemoryNetwork
+	self    string
+}
+
+func (t *memoryTransport) Send(msg Message) error {
+	for _, p := range msg.To() {
+		if err := t.network.push(p.ID(), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *memoryTransport) Broadcast(msg Message) error {
+	for id, q := range t.network.queues {
+		if id == msg.From().ID() {
+			continue
+		}
+		if err := q.push(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *memoryTransport) Recv() (Message, error) {
+	q, ok := t.network.queues[t.self]
+	if !ok {
+		return nil, fmt.Errorf("unknown party %s", t.self)
+	}
+	return q.pop()
+}
+
+// memoryQueue is an unbounded FIFO with a single reader. ready holds a token
+// whenever messages may be waiting.
+type memoryQueue struct {
+	mu     sync.Mutex
+	msgs   []Message
+	closed bool
+	ready  chan struct{}
+}
+
+func (q *memoryQueue) push(msg Message) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrTransportClosed
+	}
+	q.msgs = append(q.msgs, msg)
+	q.mu.Unlock()
+	q.signal()
+	return nil
+}
+
+func (q *memoryQueue) pop() (Message, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return nil, ErrTransportClosed
+		}
+		if len(q.msgs) > 0 {
+			msg := q.msgs[0]
+			q.msgs = q.msgs[1:]
+			q.mu.Unlock()
+			return msg, nil
+		}
+		q.mu.Unlock()
+		<-q.ready
+	}
+}
+
+func (q *memoryQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *memoryQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
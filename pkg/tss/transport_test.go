@@ -0,0 +1,50 @@
+package tss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryNetwork(t *testing.T) {
+	p1, p2, p3 := &MockPartyID{id: "1"}, &MockPartyID{id: "2"}, &MockPartyID{id: "3"}
+	network := NewMemoryNetwork([]PartyID{p1, p2, p3})
+	t1, t2, t3 := network.Transport("1"), network.Transport("2"), network.Transport("3")
+
+	bcast := &MockMessage{msgType: "bcast", from: p1, isBroadcast: true}
+	p2p := &MockMessage{msgType: "p2p", from: p1, to: []PartyID{p3}}
+	if err := Deliver(t1, bcast); err != nil {
+		t.Fatal(err)
+	}
+	if err := Deliver(t1, p2p); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg, err := t2.Recv(); err != nil || msg != bcast {
+		t.Fatalf("party 2: expected the broadcast, got %v, %v", msg, err)
+	}
+	for _, want := range []Message{bcast, p2p} {
+		if msg, err := t3.Recv(); err != nil || msg != want {
+			t.Fatalf("party 3: expected %s, got %v, %v", want.Type(), msg, err)
+		}
+	}
+
+	// The sender does not receive its own broadcast; Close unblocks it
+	done := make(chan error)
+	go func() {
+		_, err := t1.Recv()
+		done <- err
+	}()
+	network.Close()
+	if err := <-done; !errors.Is(err, ErrTransportClosed) {
+		t.Fatalf("expected ErrTransportClosed, got %v", err)
+	}
+	if err := t2.Send(&MockMessage{from: p2, to: []PartyID{p1}}); !errors.Is(err, ErrTransportClosed) {
+		t.Fatalf("expected ErrTransportClosed on send, got %v", err)
+	}
+
+	// Unknown recipients are an error
+	network = NewMemoryNetwork([]PartyID{p1})
+	if err := network.Transport("1").Send(&MockMessage{from: p1, to: []PartyID{p2}}); err == nil {
+		t.Fatal("expected an error for an unknown recipient")
+	}
+}
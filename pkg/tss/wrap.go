@@ -0,0 +1,16 @@
+package tss
+
+// Wrap wraps the result of a protocol constructor in every wrapper that
+// params can enable, in the order they must be applied, from the outside
+// in: WithRoundCallback, WithAuthentication, WithEchoBroadcast,
+// WithRoundOrder and WithTranscript. peers are passed to WithAuthentication
+// (e.g. the old committee of a reshare).
+//
+// Usage:
+//
+//	return tss.Wrap(params)(s.round1())
+func Wrap(params *Parameters, peers ...PartyID) func(StateMachine, []Message, error) (StateMachine, []Message, error) {
+	return func(sm StateMachine, msgs []Message, err error) (StateMachine, []Message, error) {
+		return WithRoundCallback(params)(WithAuthentication(params, peers...)(WithEchoBroadcast(params)(WithRoundOrder(params)(WithTranscript(params)(sm, msgs, err)))))
+	}
+}
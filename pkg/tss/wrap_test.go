@@ -0,0 +1,42 @@
+package tss
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	parties := []PartyID{&MockPartyID{id: "a"}, &MockPartyID{id: "b"}}
+
+	sm, out := newHello(parties[0], 1)
+	wrapped, _, err := Wrap(&Parameters{PartyID: parties[0], Parties: parties})(sm, out, nil)
+	if err != nil || wrapped != sm {
+		t.Fatal("expected the state machine to be returned unchanged")
+	}
+
+	var rounds []int
+	params := &Parameters{
+		PartyID:          parties[0],
+		Parties:          parties,
+		EchoBroadcast:    true,
+		StrictRoundOrder: true,
+		OnRoundComplete:  func(round int, _ []Message) { rounds = append(rounds, round) },
+	}
+	sm, out = newHello(parties[0], 1)
+	wrapped, _, err = Wrap(params)(sm, out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Outermost first
+	r, ok := wrapped.(*roundCallbackStateMachine)
+	if !ok {
+		t.Fatalf("expected the round callback outermost, got %T", wrapped)
+	}
+	e, ok := r.inner.(*echoStateMachine)
+	if !ok {
+		t.Fatalf("expected the echo wrapper inside the round callback, got %T", r.inner)
+	}
+	if _, ok := e.inner.(*roundOrderStateMachine); !ok {
+		t.Fatalf("expected the round order wrapper inside the echo wrapper, got %T", e.inner)
+	}
+	if len(rounds) != 1 || rounds[0] != 1 {
+		t.Fatalf("expected the callback for round 1, got %v", rounds)
+	}
+}
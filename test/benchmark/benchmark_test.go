@@ -242,14 +242,20 @@ func BenchmarkOnlineSign3of3(b *testing.B) {
 				SessionID: []byte(fmt.Sprintf("online-session-%d", i)),
 			}
 			var err error
-			onlineSMs[j], onlineOutMsgs[j], err = sign.NewOnlineStateMachine(params, keyData[j], preSignatures[j], msg[:])
+			// Presignatures are single-use; signing the same message with a
+			// fresh copy reveals nothing and keeps presigning out of the loop
+			p := preSignatures[j]
+			preSig := &sign.PreSignature{R: p.R, Rx: p.Rx, Ry: p.Ry, Ki: p.Ki, SigmaI: p.SigmaI, PreSignSessionID: p.PreSignSessionID}
+			onlineSMs[j], onlineOutMsgs[j], err = sign.NewOnlineStateMachine(params, keyData[j], preSig, msg[:])
 			if err != nil {
 				b.Fatal(err)
 			}
 		}
 
-		// Online phase is just 1 round
-		onlineSMs, onlineOutMsgs = route(parties, onlineSMs, onlineOutMsgs)
+		// Online phase: presignature check, then s_i
+		for r := 0; r < 2; r++ {
+			onlineSMs, onlineOutMsgs = route(parties, onlineSMs, onlineOutMsgs)
+		}
 
 		for j := 0; j < 3; j++ {
 			if onlineSMs[j].Result() == nil {
@@ -0,0 +1,29 @@
+//go:build tsstest
+
+package benchmark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
+)
+
+// BenchmarkKeyGenTestKeySource compares KeyGen with freshly generated
+// Paillier keys and with those of the test key source, as used by
+// go test -tags tsstest.
+func BenchmarkKeyGenTestKeySource(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "Generated"
+		if enabled {
+			name = "Cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			paillier.SetTestKeySource(enabled)
+			defer paillier.SetTestKeySource(false)
+			for i := 0; i < b.N; i++ {
+				runKeyGen(setupParties(3), 1, fmt.Sprintf("keygen-session-%d", i))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package e2e
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// TestCoordinatorKeyGenToSign runs keygen and signing entirely through
+// tss.LocalCoordinator, without any routing code of its own.
+func TestCoordinatorKeyGenToSign(t *testing.T) {
+	parties := setupParties(3)
+
+	keygenCoord := tss.NewLocalCoordinator()
+	for _, p := range parties {
+		sm, out, err := keygen.NewStateMachine(&tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("coordinator-keygen"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		keygenCoord.Add(p.ID(), sm, out)
+	}
+	keys, err := keygenCoord.Run()
+	if err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("coordinated"))
+	signCoord := tss.NewLocalCoordinator()
+	for _, p := range parties {
+		sm, out, err := sign.NewStateMachine(&tss.Parameters{
+			PartyID:   p,
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("coordinator-sign"),
+		}, keys[p.ID()].(*keygen.LocalPartySaveData), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signCoord.Add(p.ID(), sm, out)
+	}
+	sigs, err := signCoord.Run()
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// Round 5 already verifies the signature against the group key
+	var sig0 *sign.Signature
+	for _, p := range parties {
+		sig, ok := sigs[p.ID()].(*sign.Signature)
+		if !ok {
+			t.Fatalf("party %s: expected a signature, got %T", p.ID(), sigs[p.ID()])
+		}
+		if sig0 == nil {
+			sig0 = sig
+		} else if sig.R.Cmp(sig0.R) != 0 || sig.S.Cmp(sig0.S) != 0 {
+			t.Fatalf("party %s has a different signature", p.ID())
+		}
+	}
+}
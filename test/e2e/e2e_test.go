@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"github.com/smallyu/go-cggmp-tss/internal/crypto/paillier"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/refresh"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/reshare"
 	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
 	"github.com/smallyu/go-cggmp-tss/pkg/tss"
 )
@@ -30,10 +32,14 @@ func setupParties(n int) []tss.PartyID {
 	return parties
 }
 
+// route delivers every message to its recipients. Messages go through the
+// canonical wire codec, as they would over a real transport.
 func route(parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Message, t *testing.T) ([]tss.StateMachine, [][]tss.Message) {
 	allMsgs := []tss.Message{}
 	for _, msgs := range outMsgs {
-		allMsgs = append(allMsgs, msgs...)
+		for _, msg := range msgs {
+			allMsgs = append(allMsgs, overWire(t, msg))
+		}
 	}
 	newOutMsgs := make([][]tss.Message, len(sms))
 
@@ -72,6 +78,30 @@ func route(parties []tss.PartyID, sms []tss.StateMachine, outMsgs [][]tss.Messag
 	return sms, newOutMsgs
 }
 
+// overWire encodes msg, decodes it again and checks nothing was lost.
+func overWire(t *testing.T, msg tss.Message) tss.Message {
+	t.Helper()
+	data, err := tss.EncodeMessage(msg)
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", msg.Type(), err)
+	}
+	decoded, err := tss.DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("Failed to decode %s: %v", msg.Type(), err)
+	}
+	if decoded.Type() != msg.Type() || decoded.RoundNumber() != msg.RoundNumber() ||
+		decoded.From().ID() != msg.From().ID() || decoded.IsBroadcast() != msg.IsBroadcast() ||
+		len(decoded.To()) != len(msg.To()) || !bytes.Equal(decoded.Payload(), msg.Payload()) {
+		t.Fatalf("%s did not survive the wire round trip", msg.Type())
+	}
+	for i, to := range msg.To() {
+		if decoded.To()[i].ID() != to.ID() {
+			t.Fatalf("%s recipients changed on the wire", msg.Type())
+		}
+	}
+	return decoded
+}
+
 func runKeyGen(parties []tss.PartyID, threshold int, sessionID string, t *testing.T) []*keygen.LocalPartySaveData {
 	n := len(parties)
 	keygenSMs := make([]tss.StateMachine, n)
@@ -274,9 +304,199 @@ func TestKeyRefreshFlow(t *testing.T) {
 	}
 }
 
-// TestKeyReshareFlow tests the KeyGen -> Reshare flow with committee change.
-// Note: This test is skipped because the reshare protocol has known issues
-// that need to be investigated.
+// TestKeyReshareFlow tests the KeyGen -> Reshare flow with committee change
+// {1,2,3} -> {1,2,4}, then signs with the new committee.
 func TestKeyReshareFlow(t *testing.T) {
-	t.Skip("Skipping reshare test - protocol implementation needs investigation")
+	// Union of both committees: 1, 2 stay, 3 leaves, 4 joins
+	allParties := setupParties(4)
+	oldParties := allParties[:3]
+	newParties := []tss.PartyID{allParties[0], allParties[1], allParties[3]}
+
+	oldKeyData := runKeyGen(oldParties, 1, "reshare-keygen-session", t)
+
+	// Run reshare among every involved party
+	oldParams := &tss.Parameters{
+		Parties:   oldParties,
+		Threshold: 1,
+		Curve:     "secp256k1",
+	}
+	reshareSMs := make([]tss.StateMachine, 4)
+	outMsgs := make([][]tss.Message, 4)
+	for i, p := range allParties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   newParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("reshare-session"),
+		}
+		var myOldData *keygen.LocalPartySaveData
+		if i < len(oldKeyData) {
+			myOldData = oldKeyData[i]
+		}
+		var err error
+		reshareSMs[i], outMsgs[i], err = reshare.NewStateMachine(params, oldParams, myOldData)
+		if err != nil {
+			t.Fatalf("Failed to create reshare state machine for party %d: %v", i, err)
+		}
+	}
+
+	for r := 1; r <= 4; r++ {
+		reshareSMs, outMsgs = route(allParties, reshareSMs, outMsgs, t)
+	}
+
+	// The old-only member terminates cleanly without a result
+	if res := reshareSMs[2].Result(); res != nil {
+		t.Fatalf("Old-only party returned a result: %T", res)
+	}
+	if details := reshareSMs[2].Details(); details != "Reshare Finished" {
+		t.Fatalf("Old-only party did not finish: %s", details)
+	}
+
+	// New committee members get key data for the unchanged public key
+	newKeyData := make([]*keygen.LocalPartySaveData, 0, 3)
+	for _, i := range []int{0, 1, 3} {
+		res := reshareSMs[i].Result()
+		if res == nil {
+			t.Fatalf("Reshare failed for party %d", i)
+		}
+		data := res.(*keygen.LocalPartySaveData)
+		if data.PublicKeyX.Cmp(oldKeyData[0].PublicKeyX) != 0 ||
+			data.PublicKeyY.Cmp(oldKeyData[0].PublicKeyY) != 0 {
+			t.Fatalf("Public key changed after reshare for party %d", i)
+		}
+		newKeyData = append(newKeyData, data)
+	}
+	t.Log("Public key preserved after reshare")
+
+	// The new committee can sign under the original key
+	msg := sha256.Sum256([]byte("after reshare"))
+	signSMs := make([]tss.StateMachine, 3)
+	signOutMsgs := make([][]tss.Message, 3)
+	for i, p := range newParties {
+		params := &tss.Parameters{
+			PartyID:   p,
+			Parties:   newParties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("reshare-sign-session"),
+		}
+		var err error
+		signSMs[i], signOutMsgs[i], err = sign.NewStateMachine(params, newKeyData[i], msg[:])
+		if err != nil {
+			t.Fatalf("Failed to create sign state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 5; r++ {
+		signSMs, signOutMsgs = route(newParties, signSMs, signOutMsgs, t)
+	}
+	for i := range newParties {
+		if _, ok := signSMs[i].Result().(*sign.Signature); !ok {
+			t.Fatalf("Sign after reshare failed for party %d", i)
+		}
+	}
+}
+
+// TestOneRoundKeyGenWithAckFlow covers the one-round KeyGen and ACK message
+// types, which the other flows do not exchange.
+func TestOneRoundKeyGenWithAckFlow(t *testing.T) {
+	parties := setupParties(3)
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:        parties[i],
+			Parties:        parties,
+			Threshold:      1,
+			Curve:          "secp256k1",
+			SessionID:      []byte("one-round-ack-session"),
+			OneRoundKeyGen: true,
+			KeyGenAck:      true,
+		}
+		var err error
+		sms[i], outMsgs[i], err = keygen.NewStateMachine(params)
+		if err != nil {
+			t.Fatalf("Failed to create keygen state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 2; r++ {
+		sms, outMsgs = route(parties, sms, outMsgs, t)
+	}
+	for i := range parties {
+		if _, ok := sms[i].Result().(*keygen.LocalPartySaveData); !ok {
+			t.Fatalf("KeyGen failed for party %d (%s)", i, sms[i].Details())
+		}
+	}
+}
+
+// TestBatchKeyGenToSign generates three keys in one batch ceremony and signs
+// with each of them.
+func TestBatchKeyGenToSign(t *testing.T) {
+	parties := setupParties(3)
+	sms := make([]tss.StateMachine, 3)
+	outMsgs := make([][]tss.Message, 3)
+	for i := range parties {
+		params := &tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("batch-keygen-session"),
+		}
+		var err error
+		sms[i], outMsgs[i], err = keygen.NewBatchStateMachine(params, 3)
+		if err != nil {
+			t.Fatalf("Failed to create batch keygen state machine: %v", err)
+		}
+	}
+	for r := 1; r <= 4; r++ {
+		sms, outMsgs = route(parties, sms, outMsgs, t)
+	}
+
+	batches := make([]*keygen.BatchKeyGenResult, 3)
+	for i := range parties {
+		res, ok := sms[i].Result().(*keygen.BatchKeyGenResult)
+		if !ok || len(res.Keys) != 3 {
+			t.Fatalf("Batch keygen failed for party %d: %v", i, sms[i].Result())
+		}
+		batches[i] = res
+	}
+
+	for k := 0; k < 3; k++ {
+		// Keys are independent, but share each party's Paillier key
+		for j := 0; j < k; j++ {
+			if batches[0].Keys[k].PublicKeyX.Cmp(batches[0].Keys[j].PublicKeyX) == 0 {
+				t.Fatalf("Keys %d and %d are equal", j, k)
+			}
+		}
+		if batches[0].Keys[k].PaillierPk.N.Cmp(batches[0].Keys[0].PaillierPk.N) != 0 {
+			t.Fatalf("Key %d has its own Paillier key", k)
+		}
+
+		msg := sha256.Sum256([]byte(fmt.Sprintf("batch key %d", k)))
+		signSMs := make([]tss.StateMachine, 3)
+		signOut := make([][]tss.Message, 3)
+		for i := range parties {
+			params := &tss.Parameters{
+				PartyID:   parties[i],
+				Parties:   parties,
+				Threshold: 1,
+				Curve:     "secp256k1",
+				SessionID: []byte(fmt.Sprintf("batch-sign-session-%d", k)),
+			}
+			var err error
+			signSMs[i], signOut[i], err = sign.NewStateMachine(params, batches[i].Keys[k], msg[:])
+			if err != nil {
+				t.Fatalf("Failed to create sign state machine for key %d: %v", k, err)
+			}
+		}
+		for r := 1; r <= 5; r++ {
+			signSMs, signOut = route(parties, signSMs, signOut, t)
+		}
+		for i := range parties {
+			if _, ok := signSMs[i].Result().(*sign.Signature); !ok {
+				t.Fatalf("Signing with key %d failed for party %d", k, i)
+			}
+		}
+	}
 }
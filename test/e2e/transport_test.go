@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/keygen"
+	"github.com/smallyu/go-cggmp-tss/internal/protocol/sign"
+	"github.com/smallyu/go-cggmp-tss/pkg/tss"
+)
+
+// runOverNetwork runs every party in its own goroutine with tss.RunParty over
+// an in-memory network and returns the results in party order.
+func runOverNetwork(t *testing.T, parties []tss.PartyID, start func(i int) (tss.StateMachine, []tss.Message, error)) []interface{} {
+	network := tss.NewMemoryNetwork(parties)
+	defer network.Close()
+
+	results := make([]interface{}, len(parties))
+	errs := make([]error, len(parties))
+	var wg sync.WaitGroup
+	for i, p := range parties {
+		sm, out, err := start(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(i int, tr tss.Transport) {
+			defer wg.Done()
+			results[i], errs[i] = tss.RunParty(sm, out, tr)
+			if errs[i] != nil {
+				network.Close() // Unblock the others
+			}
+		}(i, network.Transport(p.ID()))
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("party %s: %v", parties[i].ID(), err)
+		}
+	}
+	return results
+}
+
+// TestKeyGenToSignOverMemoryTransport runs keygen and signing with every
+// party in its own goroutine, exchanging messages only through the
+// transport.
+func TestKeyGenToSignOverMemoryTransport(t *testing.T) {
+	parties := setupParties(3)
+
+	keyResults := runOverNetwork(t, parties, func(i int) (tss.StateMachine, []tss.Message, error) {
+		return keygen.NewStateMachine(&tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("transport-keygen"),
+		})
+	})
+	keys := make([]*keygen.LocalPartySaveData, len(parties))
+	for i, res := range keyResults {
+		keys[i] = res.(*keygen.LocalPartySaveData)
+		if keys[i].PublicKeyX.Cmp(keys[0].PublicKeyX) != 0 {
+			t.Fatalf("party %d has a different public key", i)
+		}
+	}
+
+	digest := sha256.Sum256([]byte("over the wire"))
+	sigResults := runOverNetwork(t, parties, func(i int) (tss.StateMachine, []tss.Message, error) {
+		return sign.NewStateMachine(&tss.Parameters{
+			PartyID:   parties[i],
+			Parties:   parties,
+			Threshold: 1,
+			Curve:     "secp256k1",
+			SessionID: []byte("transport-sign"),
+		}, keys[i], digest[:])
+	})
+	pub := (&tss.PublicKey{X: keys[0].PublicKeyX, Y: keys[0].PublicKeyY}).SerializeCompressed()
+	for i, res := range sigResults {
+		ok, err := sign.VerifyWithPubKeyBytes(pub, digest[:], res.(*sign.Signature))
+		if err != nil || !ok {
+			t.Fatalf("party %d: invalid signature: %v", i, err)
+		}
+	}
+}